@@ -0,0 +1,43 @@
+// cmd/adept/checkconfig.go
+//
+// `adept check-config` – offline configuration validation.
+//
+// Workflow
+// --------
+//  1. config.CheckVault probes every `vault:` URI and reports all failures
+//     at once, keyed by config path.  Secret values are never printed.
+//  2. If every reference resolved, config.Load runs the full pipeline
+//     (unmarshal + struct validation) exactly as the server would.
+//  3. Exit code 0 on success, 1 on any failure.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/yanizio/adept/internal/config"
+)
+
+func runCheckConfig(ctx context.Context, _ []string) int {
+	refs, err := config.CheckVault(ctx)
+	for _, r := range refs {
+		if r.OK() {
+			fmt.Printf("ok    %-32s %s#%s\n", r.Key, r.Path, r.Field)
+			continue
+		}
+		fmt.Printf("FAIL  %-32s %s\n", r.Key, r.Err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check-config: %v\n", err)
+		return 1
+	}
+
+	if _, err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "check-config: %v\n", err)
+		return 1
+	}
+	fmt.Printf("config valid (%d vault reference(s) resolved)\n", len(refs))
+	return 0
+}
@@ -0,0 +1,50 @@
+// cmd/adept/forms.go
+//
+// `adept forms validate [dir …]` – offline form definition check.
+//
+// Workflow
+// --------
+//  1. form.CheckForms walks <dir>/components/**.yaml for each dir (default
+//     ".", the app root), exactly as RegisterForms does at boot.
+//  2. Every error and warning is printed as path:line: message.
+//  3. Exit code 0 when every file loads (warnings allowed), 1 on any error,
+//     2 on bad usage.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yanizio/adept/internal/form"
+)
+
+func runForms(_ context.Context, args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "usage: adept forms validate [dir ...]")
+		return 2
+	}
+	dirs := args[1:]
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	return formsReport(os.Stdout, form.CheckForms(dirs))
+}
+
+// formsReport prints rep to out and returns the exit code.
+func formsReport(out io.Writer, rep *form.CheckReport) int {
+	for _, e := range rep.Errors {
+		fmt.Fprintf(out, "FAIL  %v\n", e)
+	}
+	for _, w := range rep.Warnings {
+		fmt.Fprintf(out, "warn  %v\n", w)
+	}
+	fmt.Fprintf(out, "%d form file(s) checked, %d error(s), %d warning(s)\n",
+		rep.Files, len(rep.Errors), len(rep.Warnings))
+	if !rep.OK() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+// cmd/adept/forms_test.go
+//
+// Unit-tests for the `forms validate` report and exit code.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/form"
+)
+
+func TestFormsReport_FailsOnBadFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "components", "shop", "forms")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "order.yaml"), []byte("title: no id\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	code := formsReport(&out, form.CheckForms([]string{root}))
+	if code != 1 {
+		t.Fatalf("exit = %d, want 1\n%s", code, out.String())
+	}
+	for _, msg := range []string{"missing required 'id'", "must have 'fields' or 'steps'"} {
+		if want := "FAIL  " + filepath.Join(dir, "order.yaml") + ":1: " + msg; !strings.Contains(out.String(), want) {
+			t.Fatalf("report lacks %q:\n%s", want, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "2 error(s)") {
+		t.Fatalf("report:\n%s", out.String())
+	}
+
+	if code := formsReport(&out, form.CheckForms([]string{t.TempDir()})); code != 0 {
+		t.Fatalf("empty tree exit = %d", code)
+	}
+}
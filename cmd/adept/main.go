@@ -0,0 +1,70 @@
+// cmd/adept/main.go
+//
+// Adept – operator CLI.
+//
+// Responsibilities
+// ----------------
+//   1. Dispatch `adept <command> [args]` to a small set of offline tools.
+//   2. Never start the HTTP server; every command exits when done.
+//   3. Exit non-zero when a check fails so CI and deploy hooks can gate on it.
+//
+// Commands
+// --------
+//   check-config   Merge config layers, probe every `vault:` URI, then run
+//                  the normal Load + validation pass.
+//   forms validate Load every form YAML as RegisterForms would and report
+//                  each error and warning with file and line.
+//
+// Notes
+// -----
+// • Output is plain text on stdout; failures go to stderr.
+// • Oxford commas, two spaces after periods.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// command is one CLI verb.  run returns the process exit code.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) int
+}
+
+var commands = []command{
+	{"check-config", "validate configuration and every vault: reference", runCheckConfig},
+	{"forms", "validate every form definition (forms validate [dir ...])", runForms},
+}
+
+func main() {
+	// Quiet logger: commands print their own report.
+	zap.ReplaceGlobals(zap.NewNop())
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			os.Exit(c.run(context.Background(), os.Args[2:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "adept: unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+// usage prints the command table to stderr.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: adept <command> [args]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.usage)
+	}
+}
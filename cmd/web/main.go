@@ -7,10 +7,19 @@
 //   1. Parse configuration and bootstrap logging / Vault / DB.
 //   2. Maintain an LRU cache of live *tenant.Tenant aggregates.
 //   3. Load all YAML-defined forms at startup so form widgets work.
-//   4. Expose Prometheus metrics at /metrics.
+//   4. Expose Prometheus metrics at /metrics, including per-tenant request
+//      count, latency, and in-flight requests, and, when admin.token is
+//      set, the tenant-cache API at /admin/tenants.
 //   5. Route every incoming host to its per-tenant chi.Router.
 //   6. Enforce optional HTTPS, always set security headers.
-//   7. Start an http.Server with sane production timeouts.
+//   7. Serve ACL-protected operator endpoints under /admin/ and, in dev
+//      mode, hot-reload themes on file changes.
+//   8. Start an http.Server with sane production timeouts, plus an
+//      optional HTTP/2 TLS listener (cert files or ACME autocert).
+//   9. On SIGINT/SIGTERM drain in-flight requests within a configurable
+//      grace period, then stop the evictor, close the per-tenant and
+//      global DB pools, stop the Vault renew loop, and flush the log, in
+//      that order.  Exit 1 if the drain timed out.
 //
 // Notes
 // -----
@@ -22,27 +31,41 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
 	"strings"
-	"time"
+	"syscall"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/acme/autocert"
 
 	// Side-effect imports: components self-register in init().
-	_ "github.com/yanizio/adept/components/auth"    // auth routes + widgets
+	"github.com/yanizio/adept/components/auth"      // auth routes + widgets
 	_ "github.com/yanizio/adept/components/example" // sample component
 
+	"github.com/yanizio/adept/internal/admin"
+	authctx "github.com/yanizio/adept/internal/auth"
+	"github.com/yanizio/adept/internal/component"
 	"github.com/yanizio/adept/internal/config"
 	"github.com/yanizio/adept/internal/database"
 	"github.com/yanizio/adept/internal/form"
 	"github.com/yanizio/adept/internal/logger"
+	"github.com/yanizio/adept/internal/message"
+	"github.com/yanizio/adept/internal/metrics"
 	"github.com/yanizio/adept/internal/middleware"
 	"github.com/yanizio/adept/internal/server"
 	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/ua"
 	"github.com/yanizio/adept/internal/vault"
+	"github.com/yanizio/adept/internal/view"
 )
 
 // runningInTTY returns true when stdout is a character device (dev mode).
@@ -56,6 +79,10 @@ func runningInTTY() bool {
 func main() {
 	/*──────────────────────── Bootstrap phase ─────────────────────────────*/
 
+	// SIGINT/SIGTERM cancel sigCtx; background loops and the server watch it.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 0. Early dev logger ensures boot errors are visible in TTY.
 	zap.ReplaceGlobals(zap.Must(zap.NewDevelopment()))
 
@@ -70,11 +97,55 @@ func main() {
 	if err != nil {
 		zap.L().Fatal("logger init failed", zap.Error(err))
 	}
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		logOut.Fatalw("log level invalid", zap.Error(err))
+	}
+
+	//    Components registered under a name already taken were ignored in
+	//    init(); repeat that loudly (ADEPT_COMPONENT_DUPLICATES=panic fails
+	//    the boot instead, for CI).
+	for _, d := range component.Duplicates() {
+		logOut.Errorw("component registered twice – second ignored",
+			"component", d.Name, "kept", d.Kept, "rejected", d.Rejected)
+	}
+
+	//    Component dependency graph: a cycle or a missing dependency would
+	//    mount and Init Components in the wrong order, so refuse to start.
+	if err := component.CheckDependencies(); err != nil {
+		logOut.Fatalw("component dependencies invalid", zap.Error(err))
+	}
+
+	//    Two Components claiming the same route: the later one wins, so say
+	//    which, once, at boot.
+	for _, col := range component.Collisions(component.All()) {
+		logOut.Warnw("component route collision",
+			"method", col.Method, "path", col.Path, "components", col.Components)
+	}
+
+	//    Routes no Component declared Access for are served without an ACL
+	//    check; make "accidentally public" visible.
+	for _, rt := range component.Undeclared(component.All()) {
+		logOut.Warnw("route has no declared permission – served without ACL",
+			"component", rt.Component, "method", rt.Method, "path", rt.Path)
+	}
 
 	// 3. Vault client (AppRole token is already exported at startup by daemon).
-	vaultCli, err := vault.New(context.Background(), logOut.Debugf)
-	if err != nil {
-		logOut.Fatalw("vault init failed", zap.Error(err))
+	//    Its renew loop lives until stopVault runs during shutdown.  Without
+	//    Vault (dev, CI) tenant passwords come from the same env and file
+	//    secrets config resolved with.
+	vaultCtx, stopVault := context.WithCancel(context.Background())
+	var vaultCli *vault.Client
+	secrets := config.SecretStore()
+	if cfg.Secrets.Provider == config.ProviderVault {
+		vaultCli, err = vault.New(vaultCtx, logOut.Debugf)
+		if err != nil {
+			logOut.Fatalw("vault init failed", zap.Error(err))
+		}
+		secrets = vaultCli
+		logOut.Infow("secrets provider", "provider", cfg.Secrets.Provider)
+	} else {
+		logOut.Warnw("secrets provider: Vault disabled – secrets from env and file",
+			"provider", cfg.Secrets.Provider, "file", cfg.Secrets.File)
 	}
 
 	// 4. Global DB pool (holds cross-tenant + internal tables).
@@ -86,7 +157,66 @@ func main() {
 	if err != nil {
 		logOut.Fatalw("global DB connect failed", zap.Error(err))
 	}
-	defer globalDB.Close()
+
+	//    Optional read replica for site_config loads and site listings.  A
+	//    replica that will not connect is logged and skipped, never fatal.
+	var globalRead *sqlx.DB
+	if cfg.Database.GlobalReplica != "" {
+		rdsn := func() string {
+			c := config.Get()
+			return fmt.Sprintf(c.Database.GlobalReplica, c.Database.GlobalPassword)
+		}
+		globalRead, err = database.OpenProvider(context.Background(), rdsn, database.Options{})
+		if err != nil {
+			logOut.Warnw("global read replica unavailable – reads use primary", zap.Error(err))
+			globalRead = nil
+		}
+	}
+	database.InitDefaultReplica(globalRead)
+
+	//    UA device-class corrections from config (compiled once).
+	uaOverrides := make([]ua.Override, len(cfg.UA.DeviceOverrides))
+	for i, o := range cfg.UA.DeviceOverrides {
+		uaOverrides[i] = ua.Override{Pattern: o.Pattern, Device: o.Device}
+	}
+	if err := ua.SetOverrides(uaOverrides); err != nil {
+		logOut.Fatalw("ua overrides invalid", zap.Error(err))
+	}
+
+	//    Shared outbound HTTP client for webhook-style actions.
+	outbound, err := message.NewHTTPClient(outboundOptions(cfg.Outbound))
+	if err != nil {
+		logOut.Fatalw("outbound client invalid", zap.Error(err))
+	}
+	message.SetHTTPClient(outbound)
+
+	//    Email dedup marks live in the global DB so a retry on another
+	//    node still sees that the first send went out.
+	message.SetDedupStore(message.NewSQLDedup(globalDB))
+
+	//    Session emails resolve to users.id on the global DB, so ACL
+	//    guards see the signed-in user.
+	authctx.SetUserStore(authctx.NewSQLUsers(globalDB))
+
+	//    Dev and test only: capture or file outbound mail instead of
+	//    sending it.  SinkFromConfig refuses outside those environments.
+	maildir := cfg.Mail.Maildir
+	if maildir != "" && !filepath.IsAbs(maildir) {
+		maildir = filepath.Join(cfg.Paths.Root, maildir)
+	}
+	sink, err := message.SinkFromConfig(cfg.Mail.Sink, maildir, cfg.Mail.From, os.Getenv("ADEPT_ENV"))
+	if err != nil {
+		logOut.Fatalw("mail sink invalid", zap.Error(err))
+	}
+	if sink != nil {
+		message.SetEmailSink(sink)
+		logOut.Warnw("email sink active – mail is not delivered", "sink", cfg.Mail.Sink, "maildir", maildir)
+	}
+
+	//    Default locale-to-theme mapping; tenants override per locale.
+	if err := view.SetLocaleThemes(cfg.Theme.LocaleMap); err != nil {
+		logOut.Fatalw("theme locale_map invalid", zap.Error(err))
+	}
 
 	// 5. Load *all* YAML-defined forms now so widgets can render later.
 	//    We pass only the repo root – form.RegisterForms walks the tree.
@@ -94,45 +224,370 @@ func main() {
 		logOut.Fatalw("form load failed", zap.Error(err))
 	}
 
-	// 6. Tenant LRU cache (30-min idle-TTL, max 100 tenants in memory).
-	cache := tenant.New(globalDB, 30*time.Minute, 100, logOut, vaultCli)
+	// 6. Tenant LRU cache; idle TTL, size cap, and evictor cadence come
+	//    from the tenant config block (30m, 100, 5m by default).
+	cache := tenant.New(globalDB, cfg.Tenant.CacheIdleTTL(), cfg.Tenant.CacheMaxEntries(),
+		logOut, secrets)
+	cache.SetEvictInterval(cfg.Tenant.CacheEvictInterval())
+	cache.SetReadDB(globalRead)
+	if d := cfg.Tenant.NegativeTTL; d > 0 {
+		cache.SetNegativeTTL(d)
+	}
+	if d := cfg.Tenant.RecheckInterval; d != 0 {
+		cache.SetRecheckInterval(d) // < 0 turns the on-hit probe off
+	}
+	if d := cfg.Tenant.PollInterval; d > 0 {
+		go cache.WatchSites(sigCtx, d) // drop tenants whose site row changed
+	}
+
+	//    Warm busy sites (site.preload = 1) before the listener opens.
+	if _, _, err := cache.Warm(sigCtx); err != nil {
+		logOut.Warnw("tenant warm-up skipped", zap.Error(err))
+	}
+
+	//    Dev mode (TTY): reload themes as files change.
+	if runningInTTY() {
+		go func() {
+			if err := cache.WatchThemes(sigCtx, "themes"); err != nil {
+				logOut.Warnw("theme watcher disabled", "err", err)
+			}
+		}()
+	}
+
+	//    Hot reload: SIGHUP, and edits under conf/ when reload.watch_files
+	//    is set, re-run config.Load; applyConfig pushes the new values into
+	//    the subsystems that copied them at boot.
+	config.Subscribe(applyConfig(logOut, cache))
+	go func() {
+		if err := config.Watch(sigCtx, cfg.Reload.WatchFiles); err != nil {
+			logOut.Warnw("config file watcher disabled – SIGHUP only", "err", err)
+		}
+	}()
 
 	/*──────────────────────── HTTP handler setup ──────────────────────────*/
 
-	// 7. Prometheus metrics endpoint.
+	// 7. Prometheus metrics endpoint and the token-protected cache API.
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
 
-	// 8. Root handler: map Host → tenant → chi.Router.
-	root := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		host := stripPort(r.Host)
-		ten, err := cache.Get(host)
-		if err != nil {
+	//    Cross-tenant cache API (snapshot, evict, reload), only when an
+	//    operator token is configured.  It answers on every host: on its
+	//    own listener with admin.listen_addr, else on the public ones over
+	//    TLS only.
+	var adminSrv *http.Server
+	if cfg.Admin.Token != "" {
+		tenantsAPI := admin.NewTenants(cache, cfg.Admin.Token)
+		apiMux := mux
+		if cfg.Admin.ListenAddr != "" {
+			apiMux = http.NewServeMux()
+			adminSrv = server.New(cfg.Admin.ListenAddr, apiMux)
+		} else {
+			tenantsAPI = admin.RequireTLS(tenantsAPI)
+		}
+		apiMux.Handle(admin.TenantsPrefix, tenantsAPI)
+		apiMux.Handle(admin.TenantsPrefix+"/", tenantsAPI)
+	}
+
+	// 8. Root handler: map Host → tenant → chi.Router.  The tenant rides on
+	//    the request context so middleware (security headers, remember-me
+	//    refresh, throttle tunables, admin ACL) can reach its DB and
+	//    site_config, and its child logger is what logger.FromContext
+	//    returns downstream.
+	adminH := admin.New(cache)
+	dispatch := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ten := tenant.FromContext(r.Context())
+		if ten == nil {
 			http.NotFound(w, r)
 			return
 		}
-		ten.Router().ServeHTTP(w, r)
+		if strings.HasPrefix(r.URL.Path, admin.Prefix) {
+			auth.RefreshSession(adminH).ServeHTTP(w, r)
+			return
+		}
+		auth.RefreshSession(ten.Router()).ServeHTTP(w, r)
 	})
 
-	// 9. Middleware chain: Security headers always, HTTPS redirect optional.
-	var handler http.Handler = root
-	handler = middleware.Security(handler)
+	// 9. Security headers always (per-tenant CSP once the tenant is on the
+	//    context), HTTPS redirect optional.
+	secured := middleware.Security(dispatch)
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ten, err := cache.Get(stripPort(r.Host))
+		var alias *tenant.Redirect
+		switch {
+		case err == nil:
+			metrics.SetRequestHost(r.Context(), ten.Host())
+			// Tenant (and its pool, for database.Conn) plus its child
+			// logger, so logger.FromContext in form actions and Components
+			// tags entries with "tenant"=host.
+			ctx := tenant.Bind(r.Context(), ten)
+			ctx = logger.WithContext(ctx, logger.Wrap(ten.GetLogger()))
+			r = r.WithContext(ctx)
+		case errors.As(err, &alias):
+			metrics.SetRequestHost(r.Context(), alias.Host)
+			aliasRedirect(w, r, alias.Host) // vanity domain → canonical host
+			return
+		}
+		secured.ServeHTTP(w, r)
+	})
 	if cfg.HTTP.ForceHTTPS {
 		handler = middleware.ForceHTTPS(cache, handler)
 	}
-	mux.Handle("/", handler)
+	mux.Handle("/", metrics.InstrumentHTTP(handler)) // per-tenant request series
 
-	// 10. Build http.Server with sane production timeouts, then listen.
+	// 10. Build http.Server(s) with sane production timeouts, then listen.
+	//     The plain listener always runs; the TLS listener joins it when
+	//     http.tls.listen_addr is set.
 	srv := server.New(cfg.HTTP.ListenAddr, mux)
+	servers := []*http.Server{srv}
 
-	logOut.Infow("listening", "addr", cfg.HTTP.ListenAddr)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		logOut.Fatalw("http server stopped unexpectedly", zap.Error(err))
+	tlsSrv, acme, err := newTLSServer(cfg.HTTP.TLS, mux, cache)
+	if err != nil {
+		logOut.Fatalw("tls listener setup failed", zap.Error(err))
+	}
+	if acme != nil {
+		srv.Handler = acme.HTTPHandler(mux) // answer HTTP-01 challenges
+	}
+	if tlsSrv != nil {
+		servers = append(servers, tlsSrv)
+	}
+	if adminSrv != nil {
+		servers = append(servers, adminSrv)
+	}
+
+	errCh := make(chan error, len(servers))
+	go func() {
+		logOut.Infow("listening", "addr", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	if tlsSrv != nil {
+		go func() {
+			logOut.Infow("listening (tls)", "addr", tlsSrv.Addr, "autocert", acme != nil)
+			errCh <- tlsSrv.ListenAndServeTLS("", "") // certs live in TLSConfig
+		}()
+	}
+	if adminSrv != nil {
+		go func() {
+			logOut.Infow("listening (admin api)", "addr", adminSrv.Addr)
+			errCh <- adminSrv.ListenAndServe()
+		}()
+	}
+
+	/*──────────────────────── Graceful shutdown ───────────────────────────*/
+
+	// 11. On SIGINT/SIGTERM run the shutdown sequence (server.Sequence),
+	//     strictly in this order so nothing shared closes under a request
+	//     that is still running:
+	//       http          stop accepting and drain (http.shutdown_timeout)
+	//       evictor       stop the tenant evictor
+	//       tenant-pools  close every cached tenant's DB pools
+	//       global-db     close the global pool and its replica
+	//       vault         stop both Vault renew loops and wait for them
+	//       log           flush buffered log lines
+	//     The exit code is non-zero when the drain missed its deadline, so
+	//     the deploy tooling can tell a clean stop from a cut-off one.
+	drained := true
+	var tenantsClosed int
+	seq := server.NewSequence(logOut)
+	seq.Add("http", func(ctx context.Context) error {
+		var errs []error
+		for _, sv := range servers {
+			if err := sv.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sv.Addr, err))
+			}
+		}
+		drained = len(errs) == 0
+		return errors.Join(errs...)
+	})
+	seq.Add("evictor", func(context.Context) error { cache.Stop(); return nil })
+	seq.Add("tenant-pools", func(context.Context) error {
+		tenantsClosed = cache.CloseAll()
+		return nil
+	})
+	seq.Add("global-db", func(context.Context) error {
+		if globalRead != nil {
+			_ = globalRead.Close()
+		}
+		return globalDB.Close()
+	})
+	seq.Add("vault", func(ctx context.Context) error {
+		stopVault() // the app's client, then the config loader's singleton
+		if vaultCli != nil {
+			select {
+			case <-vaultCli.Done():
+			case <-ctx.Done():
+				return fmt.Errorf("renew loop still running: %w", ctx.Err())
+			}
+		}
+		return config.CloseVault(ctx)
+	})
+	seq.Add("log", func(context.Context) error {
+		logOut.Infow("shutdown complete", "tenants_drained", tenantsClosed, "drained", drained)
+		_ = logOut.Sync() // stdout/stderr Sync errors are noise
+		return nil
+	})
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			logOut.Fatalw("http server stopped unexpectedly", zap.Error(err))
+		}
+		_ = seq.Run(context.Background())
+	case <-sigCtx.Done():
+		grace := config.Get().HTTP.GracePeriod()
+		logOut.Infow("shutdown signal received – draining connections",
+			"timeout_sec", grace.Seconds())
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		_ = seq.Run(ctx) // each failed phase is logged by the sequence
+		cancel()
+	}
+
+	if !drained {
+		os.Exit(1)
 	}
 }
 
 /*────────────────────────── Utility helpers ──────────────────────────────*/
 
+// newTLSServer returns the HTTPS listener described by c, or nil when TLS
+// is left to a proxy.  In autocert mode the Manager is returned too; its
+// HostPolicy admits only hosts present in the site table, by exact host
+// or alias; wildcard sites need a certificate from elsewhere.
+func newTLSServer(c config.TLS, h http.Handler, cache *tenant.Cache) (*http.Server, *autocert.Manager, error) {
+	switch {
+	case c.ListenAddr == "":
+		return nil, nil, nil
+	case c.Autocert:
+		return server.NewAutocert(c.ListenAddr, h, server.AutocertOptions{
+			CacheDir:   c.CacheDir,
+			Email:      c.Email,
+			HostPolicy: cache.KnownHost,
+		})
+	default:
+		srv, err := server.NewTLS(c.ListenAddr, h, c.CertFile, c.KeyFile)
+		return srv, nil, err
+	}
+}
+
+// applyConfig returns the config subscriber that updates live subsystems
+// after a reload.  Settings consumed only at boot are logged as needing a
+// restart instead.
+func applyConfig(log *zap.SugaredLogger, cache *tenant.Cache) func(old, cur *config.Config) {
+	return func(old, cur *config.Config) {
+		if old.Log.Level != cur.Log.Level {
+			if err := logger.SetLevel(cur.Log.Level); err != nil {
+				log.Errorw("reload: log level not applied", zap.Error(err))
+			}
+		}
+
+		ot, nt := old.Tenant, cur.Tenant
+		if ot.CacheEvictInterval() != nt.CacheEvictInterval() {
+			cache.SetEvictInterval(nt.CacheEvictInterval())
+		}
+		if ot.NegativeTTL != nt.NegativeTTL {
+			d := nt.NegativeTTL
+			if d == 0 {
+				d = tenant.NegativeTTL
+			}
+			cache.SetNegativeTTL(d)
+		}
+		if ot.RecheckInterval != nt.RecheckInterval {
+			d := nt.RecheckInterval
+			if d == 0 {
+				d = tenant.RecheckInterval
+			}
+			cache.SetRecheckInterval(d)
+		}
+
+		if !reflect.DeepEqual(old.Outbound, cur.Outbound) {
+			if c, err := message.NewHTTPClient(outboundOptions(cur.Outbound)); err != nil {
+				log.Errorw("reload: outbound client not rebuilt", zap.Error(err))
+			} else {
+				message.SetHTTPClient(c)
+			}
+		}
+		if !reflect.DeepEqual(old.UA, cur.UA) {
+			overrides := make([]ua.Override, len(cur.UA.DeviceOverrides))
+			for i, o := range cur.UA.DeviceOverrides {
+				overrides[i] = ua.Override{Pattern: o.Pattern, Device: o.Device}
+			}
+			if err := ua.SetOverrides(overrides); err != nil {
+				log.Errorw("reload: ua overrides not applied", zap.Error(err))
+			}
+		}
+		if !reflect.DeepEqual(old.Theme, cur.Theme) {
+			if err := view.SetLocaleThemes(cur.Theme.LocaleMap); err != nil {
+				log.Errorw("reload: theme locale_map not applied", zap.Error(err))
+			}
+		}
+
+		var restart []string
+		if old.HTTP.ListenAddr != cur.HTTP.ListenAddr || old.HTTP.ForceHTTPS != cur.HTTP.ForceHTTPS {
+			restart = append(restart, "http")
+		}
+		if old.HTTP.TLS != cur.HTTP.TLS {
+			restart = append(restart, "http.tls")
+		}
+		if old.Admin != cur.Admin {
+			restart = append(restart, "admin")
+		}
+		if old.Secrets != cur.Secrets && (old.Secrets.Provider == config.ProviderVault || cur.Secrets.Provider == config.ProviderVault) {
+			restart = append(restart, "secrets")
+		}
+		if old.Database.GlobalReplica != cur.Database.GlobalReplica {
+			restart = append(restart, "database.global_replica_dsn")
+		}
+		if ot.PollInterval != nt.PollInterval || ot.IdleTTL != nt.IdleTTL || ot.MaxEntries != nt.MaxEntries {
+			restart = append(restart, "tenant")
+		}
+		if old.Admin.Token != cur.Admin.Token {
+			restart = append(restart, "admin.token")
+		}
+		if old.Mail != cur.Mail {
+			restart = append(restart, "mail")
+		}
+		if old.Reload != cur.Reload {
+			restart = append(restart, "reload")
+		}
+		if len(restart) > 0 {
+			log.Warnw("reload: changed settings take effect after restart", "sections", restart)
+		}
+	}
+}
+
+// outboundOptions maps the outbound config block onto message options.
+func outboundOptions(o config.Outbound) message.ClientOptions {
+	opts := message.ClientOptions{
+		Timeout:             o.Timeout,
+		MaxIdleConns:        o.MaxIdleConns,
+		MaxIdleConnsPerHost: o.MaxIdleConnsPerHost,
+		MaxRedirects:        o.MaxRedirects,
+		Proxy:               o.Proxy,
+		Policy: message.AddressPolicy{
+			AllowPrivate: o.AllowPrivate,
+			AllowHosts:   o.AllowHosts,
+			AllowCIDRs:   o.AllowCIDRs,
+			DenyCIDRs:    o.DenyCIDRs,
+		},
+	}
+	if o.TLSMinVersion == "1.3" {
+		opts.TLSMinVersion = tls.VersionTLS13
+	}
+	return opts
+}
+
+// aliasRedirect answers a redirect-flagged alias with 301 to the canonical
+// host, keeping scheme, port, path, and query.
+func aliasRedirect(w http.ResponseWriter, r *http.Request, canonical string) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if i := strings.IndexByte(r.Host, ':'); i != -1 {
+		canonical += r.Host[i:]
+	}
+	http.Redirect(w, r, scheme+"://"+canonical+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
 // stripPort("example.com:443") → "example.com".
 func stripPort(h string) string {
 	if i := strings.IndexByte(h, ':'); i != -1 {
@@ -8,6 +8,9 @@
 //   • Render the login form via widget “auth/login”.
 //   • Validate submissions using the form subsystem.
 //   • Authenticate users (stubbed here) and start a session.
+//   • Throttle repeated failures per email and per IP (see throttle.go).
+//   • Issue "remember me" refresh tokens and restore sessions from them
+//     (see refresh.go).
 //
 // Notes
 // -----
@@ -36,20 +39,42 @@ import (
 
 // Compile-time assertion: *Component implements component.Component.
 var _ component.Component = (*Component)(nil)
+var _ component.ConfigSchema = (*Component)(nil)
+var _ component.Guarded = (*Component)(nil)
 
 // template keys (no “.html” extension).
 const tplLogin = "login"
 
 // Component encapsulates authentication routes.
-type Component struct{}
+type Component struct {
+	throttle *throttle  // failed-login counters, shared across tenants
+	refresh  *refresher // remember-me tokens, store resolved per tenant
+}
+
+// defaultRefresher backs both the registered Component and RefreshSession.
+var defaultRefresher = newRefresher()
 
 /*──────────────── component.Component interface ─────────────────────────*/
 
 // Name returns the canonical component key.
 func (c *Component) Name() string { return "auth" }
 
-// Migrations returns nil – auth currently has no DB schema.
-func (c *Component) Migrations() []string { return nil }
+// Migrations returns the tenant schema for remember-me refresh tokens.
+func (c *Component) Migrations() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS auth_refresh_token (
+    token_hash  CHAR(64)     PRIMARY KEY,
+    family      VARCHAR(64)  NOT NULL,
+    email       VARCHAR(254) NOT NULL,
+    expires_at  TIMESTAMP    NOT NULL,
+    used_at     TIMESTAMP    NULL,
+    revoked_at  TIMESTAMP    NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_auth_refresh_family (family),
+    INDEX idx_auth_refresh_email (email)
+)`,
+	}
+}
 
 // Init satisfies component.Initializer; no per-tenant boot work needed.
 func (c *Component) Init(component.TenantInfo) error { return nil }
@@ -62,8 +87,15 @@ func (c *Component) Routes() chi.Router {
 	return r
 }
 
+// Access declares the login routes public; they are how users get in.
+func (c *Component) Access() map[string]component.Access {
+	return map[string]component.Access{"/login": component.Public}
+}
+
 // Register the component during program init.
-func init() { component.Register(&Component{}) }
+func init() {
+	component.Register(&Component{throttle: newThrottle(), refresh: defaultRefresher})
+}
 
 /*──────────────────────────── Route handlers ─────────────────────────────*/
 
@@ -80,10 +112,29 @@ func (c *Component) handleLoginGET(w http.ResponseWriter, r *http.Request) {
 func (c *Component) handleLoginPOST(w http.ResponseWriter, r *http.Request) {
 	vctx := tenant.NewContext(r)
 
+	// Locked-out email or IP: refuse before touching credentials.
+	keys := throttleKeys(r, r.PostFormValue("email"))
+	if wait := c.throttle.blocked(keys...); wait > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(wait))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = view.Render(vctx, w, "auth", tplLogin, map[string]any{
+			"FormErrors": []form.ErrorField{{
+				Message: "Too many attempts.  Please wait and try again.",
+			}},
+			"FormPrefill": r.PostForm,
+		}, view.CacheSkip)
+		return
+	}
+
 	data, err := form.HandleSubmit("auth/login", r)
 	if err != nil {
 		if form.IsValidationError(err) {
 			// Re-render form with field errors and preserved input.
+			if form.IsTooLarge(err) {
+				// Headers first: Render's Content-Type is lost after WriteHeader.
+				w.Header().Set("Content-Type", view.ContentType(tplLogin))
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
 			_ = view.Render(vctx, w, "auth", tplLogin, map[string]any{
 				"FormErrors":  err,
 				"FormPrefill": r.PostForm,
@@ -98,6 +149,7 @@ func (c *Component) handleLoginPOST(w http.ResponseWriter, r *http.Request) {
 	email := data["email"].(string)
 	pass := data["password"].(string)
 	if !checkCredentials(email, pass) {
+		c.throttle.fail(tenantLimits(r), keys...)
 		_ = view.Render(vctx, w, "auth", tplLogin, map[string]any{
 			"FormErrors": []form.ErrorField{{
 				Name:    "password",
@@ -108,11 +160,16 @@ func (c *Component) handleLoginPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c.throttle.reset(emailKey(r, email)) // the IP bucket decays on its own
 	session.LoginUser(w, r, email)
+	if remember, _ := data["remember_me"].(bool); remember {
+		c.refresh.remember(w, r, email)
+	}
 	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 }
 
 /*────────────────────── Stub credential checker ─────────────────────────*/
 
-// checkCredentials is a placeholder.  Replace with real auth logic.
-func checkCredentials(_, _ string) bool { return false }
+// checkCredentials is a placeholder.  Replace with real auth logic.  It is a
+// variable so tests can substitute a known-good credential.
+var checkCredentials = func(_, _ string) bool { return false }
@@ -0,0 +1,384 @@
+// components/auth/refresh.go
+//
+// "Remember me" refresh tokens.
+//
+// Context
+// -------
+// The session cookie lives only as long as the browser session.  When the
+// login form's remember_me box is ticked, handleLoginPOST additionally
+// issues a long-lived refresh token in its own cookie.  RefreshSession, a
+// middleware wired ahead of the tenant router, notices a request without a
+// session but with a refresh cookie, silently mints a new session, and
+// rotates the refresh token.
+//
+// Workflow
+// --------
+//  1. issue     – 32 random bytes, base64url in the cookie, SHA-256 hex in
+//     auth_refresh_token.  The raw token is never stored.
+//  2. redeem    – look up by hash, reject revoked or expired rows, then mark
+//     the row used and issue a successor in the same family.
+//  3. reuse     – presenting a token that was already used means a copy has
+//     leaked; every token in that family is revoked and the user must log
+//     in again.  Within refreshGrace of the rotation it is instead treated
+//     as a concurrent request from the same browser (parallel asset or XHR
+//     loads all carry the old cookie) and answered with the successor.
+//  4. revoke    – RevokeRefreshTokens drops every token for an email and is
+//     meant to be called by the password-change flow.
+//
+// Notes
+// -----
+// • Tokens live in the tenant schema (see Migrations in auth.go), so a token
+//   minted on one site is meaningless on another.
+// • Without a tenant on the request context the middleware is a no-op.
+// • Successors are remembered in memory only, for refreshGrace.  A racing
+//   request on another instance, or one that arrives before the winner has
+//   issued the successor, is served anonymously but keeps its cookie, so
+//   the winner's rotated cookie is not cleared.
+// • Oxford commas, two spaces after periods.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/session"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+const (
+	refreshCookie     = "adept_refresh"
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	refreshGrace      = 30 * time.Second // spent tokens still answer with their successor
+)
+
+var (
+	errRefreshInvalid = errors.New("refresh token invalid")
+	errRefreshExpired = errors.New("refresh token expired")
+	errRefreshReused  = errors.New("refresh token reused")
+	errRefreshRaced   = errors.New("refresh token rotated by a concurrent request")
+)
+
+// refreshToken is one row of auth_refresh_token.
+type refreshToken struct {
+	Hash      string       `db:"token_hash"`
+	Family    string       `db:"family"`
+	Email     string       `db:"email"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+}
+
+// refreshStore persists refresh tokens.  sqlRefreshStore is the production
+// implementation; tests substitute an in-memory one.
+type refreshStore interface {
+	insert(ctx context.Context, t refreshToken) error
+	lookup(ctx context.Context, hash string) (*refreshToken, error) // nil, nil when absent
+	markUsed(ctx context.Context, hash string, at time.Time) (bool, error)
+	revokeFamily(ctx context.Context, family string, at time.Time) error
+	revokeEmail(ctx context.Context, email string, at time.Time) error
+}
+
+// refresher issues and redeems tokens.  One instance serves every tenant;
+// the store is resolved per request.
+type refresher struct {
+	ttl   time.Duration
+	grace time.Duration
+	now   func() time.Time                   // injectable clock for tests
+	store func(r *http.Request) refreshStore // nil result disables refresh
+
+	mu     sync.Mutex
+	recent map[string]rotation // spent token hash → successor, kept for grace
+}
+
+// rotation is a successor handed out by redeem.
+type rotation struct {
+	email, next string
+	at          time.Time
+}
+
+func newRefresher() *refresher {
+	return &refresher{
+		ttl:    defaultRefreshTTL,
+		grace:  refreshGrace,
+		now:    time.Now,
+		store:  tenantRefreshStore,
+		recent: make(map[string]rotation),
+	}
+}
+
+// issue stores a new token in family (a fresh family when empty) and
+// returns the raw value for the cookie.
+func (rf *refresher) issue(ctx context.Context, st refreshStore, email, family string) (string, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if family == "" {
+		if family, err = randomToken(); err != nil {
+			return "", err
+		}
+	}
+	err = st.insert(ctx, refreshToken{
+		Hash:      hashToken(raw),
+		Family:    family,
+		Email:     email,
+		ExpiresAt: rf.now().Add(rf.ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// redeem consumes raw and returns the owning email plus its successor.
+func (rf *refresher) redeem(ctx context.Context, st refreshStore, raw string) (email, next string, err error) {
+	now := rf.now()
+	tok, err := st.lookup(ctx, hashToken(raw))
+	if err != nil {
+		return "", "", err
+	}
+	if tok == nil || tok.RevokedAt.Valid {
+		return "", "", errRefreshInvalid
+	}
+	if tok.UsedAt.Valid {
+		return rf.spent(ctx, st, tok, tok.UsedAt.Time, now)
+	}
+	if !now.Before(tok.ExpiresAt) {
+		return "", "", errRefreshExpired
+	}
+
+	// Conditional update: a concurrent redeem of the same token loses here.
+	ok, err := st.markUsed(ctx, tok.Hash, now)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return rf.spent(ctx, st, tok, now, now)
+	}
+
+	next, err = rf.issue(ctx, st, tok.Email, tok.Family)
+	if err != nil {
+		return "", "", err
+	}
+	rf.rotated(tok.Hash, rotation{email: tok.Email, next: next, at: now})
+	return tok.Email, next, nil
+}
+
+// spent handles a token already redeemed at usedAt.  Within the grace
+// window it returns the successor, or errRefreshRaced when none is known
+// here; after it, the token is a leaked copy and the family is revoked.
+func (rf *refresher) spent(ctx context.Context, st refreshStore, tok *refreshToken, usedAt, now time.Time) (email, next string, err error) {
+	if now.Sub(usedAt) >= rf.grace {
+		return "", "", rf.reused(ctx, st, tok, now)
+	}
+	rf.mu.Lock()
+	rot, ok := rf.recent[tok.Hash]
+	rf.mu.Unlock()
+	if !ok {
+		return "", "", errRefreshRaced
+	}
+	return rot.email, rot.next, nil
+}
+
+// rotated remembers hash's successor for the grace window and forgets
+// rotations older than it.
+func (rf *refresher) rotated(hash string, rot rotation) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	for h, old := range rf.recent {
+		if rot.at.Sub(old.at) >= rf.grace {
+			delete(rf.recent, h)
+		}
+	}
+	rf.recent[hash] = rot
+}
+
+// reused revokes the whole family and reports errRefreshReused.
+func (rf *refresher) reused(ctx context.Context, st refreshStore, tok *refreshToken, now time.Time) error {
+	zap.L().Warn("refresh token reuse detected – revoking family",
+		zap.String("email", tok.Email))
+	if err := st.revokeFamily(ctx, tok.Family, now); err != nil {
+		return err
+	}
+	return errRefreshReused
+}
+
+/*──────────────────────────── HTTP wiring ───────────────────────────────*/
+
+// RefreshSession mints a session from a valid refresh cookie when the
+// request carries none, rotating the refresh token as it does so.  Any
+// failure clears the refresh cookie and serves the request anonymously.
+func RefreshSession(next http.Handler) http.Handler {
+	return refreshSession(defaultRefresher, next)
+}
+
+func refreshSession(rf *refresher, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := session.CurrentEmail(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		c, err := r.Cookie(refreshCookie)
+		if err != nil || c.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		st := rf.store(r)
+		if st == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		email, raw, err := rf.redeem(r.Context(), st, c.Value)
+		if errors.Is(err, errRefreshRaced) {
+			// Another request is rotating this cookie; keep its result.
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, errRefreshInvalid) && !errors.Is(err, errRefreshExpired) &&
+				!errors.Is(err, errRefreshReused) {
+				zap.L().Error("refresh token redeem", zap.Error(err))
+			}
+			clearRefreshCookie(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session.LoginUser(w, r, email)
+		setRefreshCookie(w, r, raw, rf.ttl)
+
+		// Let handlers later in this request see the new session.
+		r = r.Clone(r.Context())
+		session.Inject(r, email)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RevokeRefreshTokens invalidates every refresh token issued to email on
+// the tenant whose schema db points at.  Call it after a password change.
+func RevokeRefreshTokens(ctx context.Context, db *sqlx.DB, email string) error {
+	return sqlRefreshStore{db}.revokeEmail(ctx, email, time.Now())
+}
+
+// remember issues a refresh token for a fresh login.  Errors are logged and
+// swallowed: the user still gets a normal session.
+func (rf *refresher) remember(w http.ResponseWriter, r *http.Request, email string) {
+	st := rf.store(r)
+	if st == nil {
+		return
+	}
+	raw, err := rf.issue(r.Context(), st, email, "")
+	if err != nil {
+		zap.L().Error("refresh token issue", zap.Error(err))
+		return
+	}
+	setRefreshCookie(w, r, raw, rf.ttl)
+}
+
+func setRefreshCookie(w http.ResponseWriter, r *http.Request, raw string, ttl time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookie,
+		Value:    raw,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(ttl / time.Second),
+	})
+}
+
+func clearRefreshCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+// tenantRefreshStore resolves the tenant DB from the request context.
+func tenantRefreshStore(r *http.Request) refreshStore {
+	ten := tenant.FromContext(r.Context())
+	if ten == nil || ten.GetDB() == nil {
+		return nil
+	}
+	return sqlRefreshStore{ten.GetDB()}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+/*──────────────────────────── SQL store ─────────────────────────────────*/
+
+// sqlRefreshStore backs refreshStore with the tenant's auth_refresh_token.
+type sqlRefreshStore struct{ db *sqlx.DB }
+
+func (s sqlRefreshStore) insert(ctx context.Context, t refreshToken) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO auth_refresh_token (token_hash, family, email, expires_at) VALUES (?, ?, ?, ?)`,
+		t.Hash, t.Family, t.Email, t.ExpiresAt)
+	return err
+}
+
+func (s sqlRefreshStore) lookup(ctx context.Context, hash string) (*refreshToken, error) {
+	var t refreshToken
+	err := s.db.GetContext(ctx, &t,
+		`SELECT token_hash, family, email, expires_at, used_at, revoked_at
+		   FROM auth_refresh_token WHERE token_hash = ?`, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s sqlRefreshStore) markUsed(ctx context.Context, hash string, at time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE auth_refresh_token SET used_at = ? WHERE token_hash = ? AND used_at IS NULL`,
+		at, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s sqlRefreshStore) revokeFamily(ctx context.Context, family string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_refresh_token SET revoked_at = ? WHERE family = ? AND revoked_at IS NULL`,
+		at, family)
+	return err
+}
+
+func (s sqlRefreshStore) revokeEmail(ctx context.Context, email string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE auth_refresh_token SET revoked_at = ? WHERE email = ? AND revoked_at IS NULL`,
+		at, email)
+	return err
+}
@@ -0,0 +1,234 @@
+// components/auth/refresh_test.go
+//
+// Unit-tests for remember-me refresh tokens.
+//
+// Context
+// -------
+// An in-memory refreshStore stands in for auth_refresh_token so the tests
+// cover the token lifecycle without a database:
+//
+//   • redeem rotates: old token spent, successor works
+//   • replaying a spent token revokes the whole family
+//   • within the grace window a spent token answers with its successor
+//   • expired tokens are refused
+//   • RefreshSession mints a session and rotates the cookie
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/session"
+)
+
+// memRefreshStore is a goroutine-safe refreshStore for tests.
+type memRefreshStore struct {
+	mu   sync.Mutex
+	rows map[string]*refreshToken
+}
+
+func newMemRefreshStore() *memRefreshStore {
+	return &memRefreshStore{rows: make(map[string]*refreshToken)}
+}
+
+func (m *memRefreshStore) insert(_ context.Context, t refreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[t.Hash] = &t
+	return nil
+}
+
+func (m *memRefreshStore) lookup(_ context.Context, hash string) (*refreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.rows[hash]; ok {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memRefreshStore) markUsed(_ context.Context, hash string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.rows[hash]
+	if !ok || t.UsedAt.Valid {
+		return false, nil
+	}
+	t.UsedAt = sql.NullTime{Time: at, Valid: true}
+	return true, nil
+}
+
+func (m *memRefreshStore) revokeFamily(_ context.Context, family string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.rows {
+		if t.Family == family {
+			t.RevokedAt = sql.NullTime{Time: at, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (m *memRefreshStore) revokeEmail(_ context.Context, email string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.rows {
+		if t.Email == email {
+			t.RevokedAt = sql.NullTime{Time: at, Valid: true}
+		}
+	}
+	return nil
+}
+
+func newTestRefresher(st refreshStore) (*refresher, *time.Time) {
+	now := time.Now()
+	rf := newRefresher()
+	rf.now = func() time.Time { return now }
+	rf.store = func(*http.Request) refreshStore { return st }
+	return rf, &now
+}
+
+func TestRefresh_Rotation(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, _ := newTestRefresher(st)
+	ctx := context.Background()
+
+	first, err := rf.issue(ctx, st, "a@example.com", "")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	email, second, err := rf.redeem(ctx, st, first)
+	if err != nil || email != "a@example.com" {
+		t.Fatalf("redeem = %q, %v", email, err)
+	}
+	if second == first {
+		t.Fatal("token not rotated")
+	}
+	if _, _, err := rf.redeem(ctx, st, second); err != nil {
+		t.Fatalf("successor rejected: %v", err)
+	}
+	for h := range st.rows {
+		if h == first || h == second {
+			t.Fatal("raw token stored instead of hash")
+		}
+	}
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, now := newTestRefresher(st)
+	ctx := context.Background()
+
+	first, _ := rf.issue(ctx, st, "b@example.com", "")
+	_, second, err := rf.redeem(ctx, st, first)
+	if err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+
+	// Attacker replays the stolen, already-spent token after the grace.
+	*now = now.Add(rf.grace)
+	if _, _, err := rf.redeem(ctx, st, first); !errors.Is(err, errRefreshReused) {
+		t.Fatalf("replay err = %v, want errRefreshReused", err)
+	}
+	// The legitimate successor is now dead too.
+	if _, _, err := rf.redeem(ctx, st, second); !errors.Is(err, errRefreshInvalid) {
+		t.Fatalf("successor err = %v, want errRefreshInvalid", err)
+	}
+}
+
+func TestRefresh_GraceReturnsSuccessor(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, now := newTestRefresher(st)
+	ctx := context.Background()
+
+	first, _ := rf.issue(ctx, st, "g@example.com", "")
+	_, second, err := rf.redeem(ctx, st, first)
+	if err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+
+	// A parallel request from the same browser still carries first.
+	*now = now.Add(rf.grace - time.Second)
+	email, again, err := rf.redeem(ctx, st, first)
+	if err != nil || email != "g@example.com" || again != second {
+		t.Fatalf("racing redeem = %q, %q, %v; want the successor", email, again, err)
+	}
+	if _, _, err := rf.redeem(ctx, st, second); err != nil {
+		t.Fatalf("successor revoked by the race: %v", err)
+	}
+
+	// Spent within the grace but rotated elsewhere: refused, not revoked.
+	other, _ := rf.issue(ctx, st, "g@example.com", "")
+	_, _ = st.markUsed(ctx, hashToken(other), *now)
+	if _, _, err := rf.redeem(ctx, st, other); !errors.Is(err, errRefreshRaced) {
+		t.Fatalf("err = %v, want errRefreshRaced", err)
+	}
+	if tok, _ := st.lookup(ctx, hashToken(other)); tok.RevokedAt.Valid {
+		t.Fatal("family revoked within the grace window")
+	}
+}
+
+func TestRefresh_Expiry(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, now := newTestRefresher(st)
+	ctx := context.Background()
+
+	raw, _ := rf.issue(ctx, st, "c@example.com", "")
+	*now = now.Add(rf.ttl)
+	if _, _, err := rf.redeem(ctx, st, raw); !errors.Is(err, errRefreshExpired) {
+		t.Fatalf("err = %v, want errRefreshExpired", err)
+	}
+}
+
+func TestRefresh_RevokeEmail(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, now := newTestRefresher(st)
+	ctx := context.Background()
+
+	raw, _ := rf.issue(ctx, st, "d@example.com", "")
+	_ = st.revokeEmail(ctx, "d@example.com", *now)
+	if _, _, err := rf.redeem(ctx, st, raw); !errors.Is(err, errRefreshInvalid) {
+		t.Fatalf("err = %v, want errRefreshInvalid", err)
+	}
+}
+
+func TestRefreshSession_MintsSessionAndRotates(t *testing.T) {
+	st := newMemRefreshStore()
+	rf, _ := newTestRefresher(st)
+	raw, _ := rf.issue(context.Background(), st, "e@example.com", "")
+
+	var seen string
+	h := refreshSession(rf, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen, _ = session.CurrentEmail(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: refreshCookie, Value: raw})
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if seen != "e@example.com" {
+		t.Fatalf("downstream session = %q", seen)
+	}
+	var rotated string
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == refreshCookie {
+			rotated = c.Value
+		}
+	}
+	if rotated == "" || rotated == raw {
+		t.Fatalf("refresh cookie not rotated: %q", rotated)
+	}
+}
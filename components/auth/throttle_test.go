@@ -0,0 +1,142 @@
+// components/auth/throttle_test.go
+//
+// Unit-tests for failed-login throttling.
+//
+// Context
+// -------
+// The tests drive POST /login through the real handler (form validation,
+// CSRF, and timing checks included) so the lockout is exercised end to end:
+//
+//   • N wrong passwords                         → lockout engaged
+//   • correct password during cooldown          → 429 + Retry-After
+//   • cooldown elapsed                          → correct password accepted
+//   • successful login                          → only the email key reset
+//
+// Notes
+// -----
+// • Templates are not resolvable from the package directory, so view.Render
+//   fails silently; assertions rely on status codes and headers only.
+// • Oxford commas, two spaces after periods.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/form"
+)
+
+const goodPass = "correct-horse"
+
+func loginRequest(t *testing.T, email, pass string) *http.Request {
+	t.Helper()
+	tok, err := form.GenerateToken()
+	if err != nil {
+		t.Fatalf("csrf token: %v", err)
+	}
+	v := url.Values{
+		"email":      {email},
+		"password":   {pass},
+		"csrf_token": {tok},
+		"render_ts":  {strconv.FormatInt(time.Now().Add(-5*time.Second).UnixMicro(), 10)},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(v.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.RemoteAddr = "203.0.113.7:5555"
+	return req
+}
+
+func setup(t *testing.T) (*Component, *time.Time) {
+	t.Helper()
+	if err := form.RegisterForms([]string{"../.."}); err != nil {
+		t.Fatalf("register forms: %v", err)
+	}
+	orig := checkCredentials
+	checkCredentials = func(_, pass string) bool { return pass == goodPass }
+	t.Cleanup(func() { checkCredentials = orig })
+
+	now := time.Now()
+	th := newThrottle()
+	th.now = func() time.Time { return now }
+	return &Component{throttle: th}, &now
+}
+
+func TestLogin_LockoutBlocksCorrectPassword(t *testing.T) {
+	c, _ := setup(t)
+
+	for i := 0; i < defaultMaxAttempts; i++ {
+		rr := httptest.NewRecorder()
+		c.handleLoginPOST(rr, loginRequest(t, "a@example.com", "wrong-password"))
+		if rr.Code == http.StatusTooManyRequests {
+			t.Fatalf("attempt %d throttled before reaching the limit", i+1)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	c.handleLoginPOST(rr, loginRequest(t, "a@example.com", goodPass))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rr.Code)
+	}
+	if ra := rr.Header().Get("Retry-After"); ra == "" {
+		t.Fatal("missing Retry-After header")
+	}
+	if rr.Header().Get("Set-Cookie") != "" {
+		t.Fatal("session cookie issued during lockout")
+	}
+}
+
+func TestLogin_CooldownExpires(t *testing.T) {
+	c, now := setup(t)
+
+	for i := 0; i < defaultMaxAttempts; i++ {
+		c.handleLoginPOST(httptest.NewRecorder(), loginRequest(t, "b@example.com", "wrong-password"))
+	}
+	*now = now.Add(defaultWindow + time.Second)
+
+	rr := httptest.NewRecorder()
+	c.handleLoginPOST(rr, loginRequest(t, "b@example.com", goodPass))
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303 after cooldown", rr.Code)
+	}
+}
+
+func TestThrottle_ResetOnSuccess(t *testing.T) {
+	th := newThrottle()
+	l := limits{max: 3, window: time.Minute}
+
+	th.fail(l, "k")
+	th.fail(l, "k")
+	th.reset("k")
+	th.fail(l, "k")
+	if d := th.blocked("k"); d != 0 {
+		t.Fatalf("blocked after reset: %v", d)
+	}
+}
+
+func TestLogin_SuccessKeepsIPCount(t *testing.T) {
+	c, _ := setup(t)
+
+	// One client sprays other accounts, then logs into its own.
+	for i := 0; i < defaultMaxAttempts-1; i++ {
+		c.handleLoginPOST(httptest.NewRecorder(),
+			loginRequest(t, "victim"+strconv.Itoa(i)+"@example.com", "wrong-password"))
+	}
+	rr := httptest.NewRecorder()
+	c.handleLoginPOST(rr, loginRequest(t, "own@example.com", goodPass))
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("own login status = %d, want 303", rr.Code)
+	}
+
+	c.handleLoginPOST(httptest.NewRecorder(), loginRequest(t, "victim9@example.com", "wrong-password"))
+	rr = httptest.NewRecorder()
+	c.handleLoginPOST(rr, loginRequest(t, "victim10@example.com", "wrong-password"))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429: the success reset the IP bucket", rr.Code)
+	}
+}
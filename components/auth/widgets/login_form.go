@@ -21,7 +21,7 @@
 //
 //	type Widget interface {
 //	    ID() string
-//	    Render(ctx any, params map[string]any) (html string, cache int, err error)
+//	    Render(ctx widget.Context, params map[string]any) (html string, cache int, err error)
 //	}
 //
 // Therefore Render returns (string, int, error) rather than template.HTML.
@@ -44,7 +44,7 @@ func (LoginFormWidget) ID() string { return "auth/login" }
 
 // Render outputs the HTML generated by the Forms subsystem and disables
 // fragment caching by returning view.CacheSkip.
-func (LoginFormWidget) Render(_ any, params map[string]any) (string, int, error) {
+func (LoginFormWidget) Render(_ widget.Context, params map[string]any) (string, int, error) {
 	// Optional previously-submitted values.
 	var prefill map[string]string
 	if v, ok := params["prefill"].(map[string]string); ok {
@@ -18,6 +18,7 @@ import (
 var (
 	_ component.Component   = (*Comp)(nil)
 	_ component.Initializer = (*Comp)(nil)
+	_ component.Guarded     = (*Comp)(nil)
 )
 
 // Comp implements component.Component; no per-tenant state needed.
@@ -27,6 +28,14 @@ func (c *Comp) Name() string                      { return "example" }
 func (c *Comp) Migrations() []string              { return nil }
 func (c *Comp) Init(_ component.TenantInfo) error { return nil }
 
+// Access keeps both demo routes public.
+func (c *Comp) Access() map[string]component.Access {
+	return map[string]component.Access{
+		"GET /example":     component.Public,
+		"GET /api/example": component.Public,
+	}
+}
+
 func (c *Comp) Routes() chi.Router {
 	r := chi.NewRouter()
 
@@ -17,16 +17,17 @@ type Widget struct{}
 
 func (w *Widget) ID() string { return "example/example" }
 
-// Render converts ctx to *tenant.Context, builds data, and returns the
-// rendered HTML string plus cache policy.
-func (w *Widget) Render(ctx any, _ map[string]any) (string, int, error) {
+// Render needs the parsed UA, which only *tenant.Context carries, so it
+// narrows ctx and skips output for any other Context implementation.  The
+// output is per visitor (IP, UA), so it is never cached.
+func (w *Widget) Render(ctx widget.Context, _ map[string]any) (string, int, error) {
 	rctx, ok := ctx.(*tenant.Context)
 	if !ok {
 		return "", int(view.CacheSkip), nil
 	}
 
 	data := map[string]any{
-		"IP":      rctx.Request.RemoteAddr,
+		"IP":      ctx.GetRequest().RemoteAddr,
 		"Browser": rctx.UA.Browser,
 		"Device":  rctx.UA.Device,
 		"OS":      rctx.UA.OS,
@@ -34,8 +35,8 @@ func (w *Widget) Render(ctx any, _ map[string]any) (string, int, error) {
 		"IsBot":   rctx.UA.IsBot,
 	}
 
-	html, policy, err := view.RenderToString(rctx, "example", "widgets/example", data)
-	return string(html), int(policy), err
+	html, _, err := view.RenderToString(rctx, "example", "widgets/example", data)
+	return string(html), int(view.CacheSkip), err
 }
 
 func init() { widget.Register(&Widget{}) }
@@ -0,0 +1,194 @@
+// internal/acl/cache.go
+//
+// Per-tenant cache of role sets and permission answers.
+//
+// Context
+// -------
+// RequireRole and RequirePermission used to run UserRoles, and
+// RoleAllowed, on every request, which made user_role and role_acl the
+// busiest queries on tenant databases.  Cache memoises both answers for a
+// short TTL:
+//
+//   - role sets by user ID, and
+//   - the permission matrix, keyed by (role set, component, action).
+//
+// One Cache hangs off each Tenant (tenant.Attachment), so it is dropped
+// with the tenant on eviction or invalidation.
+//
+// Freshness
+// ---------
+// Correctness beats freshness: the TTL is short (site_config
+// acl.cache_ttl, DefaultCacheTTL when unset, "0s" disables caching), and
+// admin tooling calls InvalidateUser after editing one user's roles, or
+// InvalidateAll after editing roles or role_acl.  Errors are never cached.
+// A fetch that races an invalidation is not stored: each call notes the
+// generation it read under (per user for role sets), and the answer is
+// dropped if InvalidateUser or InvalidateAll moved it meanwhile.
+//
+// Notes
+// -----
+// • Both maps are bounded LRUs (internal/cache), guarded by one mutex.
+// • Oxford commas, two spaces after periods.
+
+package acl
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// DefaultCacheTTL is used when site_config acl.cache_ttl is unset.
+const DefaultCacheTTL = 30 * time.Second
+
+const (
+	maxUsers = 10000 // cached role sets per tenant
+	maxPerms = 4096  // cached permission answers per tenant
+)
+
+func init() {
+	component.DeclareConfig(component.ConfigKey{
+		Name: "acl.cache_ttl", Type: component.ConfigDuration, Default: DefaultCacheTTL.String(),
+	})
+}
+
+// Cache memoises UserRoles and RoleAllowed for one tenant.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time // stubbed in tests
+
+	mu      sync.Mutex
+	roles   *lru.LRU         // user ID → cachedRoles
+	perms   *lru.LRU         // permKey → cachedPerm
+	gen     uint64           // bumped by InvalidateAll
+	userGen map[int64]uint64 // bumped by InvalidateUser; reset by InvalidateAll
+}
+
+type cachedRoles struct {
+	roles []string
+	exp   time.Time
+}
+
+type cachedPerm struct {
+	ok  bool
+	exp time.Time
+}
+
+type permKey struct {
+	roles             string // sorted, comma-joined
+	component, action string
+}
+
+// NewCache returns a Cache whose entries live for ttl; ttl <= 0 disables
+// caching.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		roles:   lru.New(maxUsers),
+		perms:   lru.New(maxPerms),
+		userGen: map[int64]uint64{},
+	}
+}
+
+type cacheKey struct{}
+
+// For returns t's Cache, creating it with the tenant's acl.cache_ttl.
+func For(t *tenant.Tenant) *Cache {
+	return t.Attachment(cacheKey{}, func() any {
+		return NewCache(t.Config.Duration("acl.cache_ttl", DefaultCacheTTL))
+	}).(*Cache)
+}
+
+// InvalidateUser drops the cached role set of userID on t.
+func InvalidateUser(t *tenant.Tenant, userID int64) { For(t).InvalidateUser(userID) }
+
+// InvalidateAll drops every cached role set and permission answer on t.
+func InvalidateAll(t *tenant.Tenant) { For(t).InvalidateAll() }
+
+// UserRoles is the cached form of the package-level UserRoles.
+func (c *Cache) UserRoles(ctx context.Context, db *sql.DB, userID int64) ([]string, error) {
+	if c.ttl <= 0 {
+		return UserRoles(ctx, db, userID)
+	}
+	c.mu.Lock()
+	if v, ok := c.roles.Get(userID); ok {
+		e := v.(cachedRoles)
+		if c.now().Before(e.exp) {
+			c.mu.Unlock()
+			return e.roles, nil
+		}
+		c.roles.Remove(userID)
+	}
+	gen, ugen := c.gen, c.userGen[userID]
+	c.mu.Unlock()
+
+	roles, err := UserRoles(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.gen == gen && c.userGen[userID] == ugen {
+		c.roles.Add(userID, cachedRoles{roles: roles, exp: c.now().Add(c.ttl)})
+	}
+	c.mu.Unlock()
+	return roles, nil
+}
+
+// RoleAllowed is the cached form of the package-level RoleAllowed.
+func (c *Cache) RoleAllowed(ctx context.Context, db *sql.DB, roles []string, component, action string) (bool, error) {
+	if c.ttl <= 0 || len(roles) == 0 {
+		return RoleAllowed(ctx, db, roles, component, action)
+	}
+	sorted := append([]string(nil), roles...)
+	sort.Strings(sorted)
+	k := permKey{strings.Join(sorted, ","), component, action}
+
+	c.mu.Lock()
+	if v, ok := c.perms.Get(k); ok {
+		e := v.(cachedPerm)
+		if c.now().Before(e.exp) {
+			c.mu.Unlock()
+			return e.ok, nil
+		}
+		c.perms.Remove(k)
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	ok, err := RoleAllowed(ctx, db, roles, component, action)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	if c.gen == gen {
+		c.perms.Add(k, cachedPerm{ok: ok, exp: c.now().Add(c.ttl)})
+	}
+	c.mu.Unlock()
+	return ok, nil
+}
+
+// InvalidateUser drops the cached role set of userID.
+func (c *Cache) InvalidateUser(userID int64) {
+	c.mu.Lock()
+	c.roles.Remove(userID)
+	c.userGen[userID]++
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops every cached role set and permission answer.
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	c.roles = lru.New(maxUsers)
+	c.perms = lru.New(maxPerms)
+	c.gen++
+	c.userGen = map[int64]uint64{} // in-flight fetches already fail on gen
+	c.mu.Unlock()
+}
@@ -0,0 +1,156 @@
+// internal/acl/cache_test.go
+//
+// Unit-tests for the per-tenant ACL cache using sqlmock.
+
+package acl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestCache_UserRolesTTLAndInvalidate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	c := NewCache(30 * time.Second)
+	clock := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return clock }
+
+	expect := func(roles ...string) {
+		rows := sqlmock.NewRows([]string{"name"})
+		for _, r := range roles {
+			rows.AddRow(r)
+		}
+		mock.ExpectQuery("SELECT r.name").WithArgs(int64(7)).WillReturnRows(rows)
+	}
+
+	expect("editor")
+	for i := 0; i < 3; i++ { // one query, then hits
+		if got, err := c.UserRoles(ctx, db, 7); err != nil || len(got) != 1 || got[0] != "editor" {
+			t.Fatalf("UserRoles = %v, %v", got, err)
+		}
+	}
+
+	expect("admin") // after an explicit invalidation
+	c.InvalidateUser(7)
+	if got, _ := c.UserRoles(ctx, db, 7); got[0] != "admin" {
+		t.Fatalf("after InvalidateUser = %v", got)
+	}
+
+	expect("viewer") // after the TTL
+	clock = clock.Add(31 * time.Second)
+	if got, _ := c.UserRoles(ctx, db, 7); got[0] != "viewer" {
+		t.Fatalf("after TTL = %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_InvalidateDuringFetchDropsStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	c := NewCache(time.Minute)
+
+	for _, inval := range []func(){
+		func() { c.InvalidateUser(7) },
+		c.InvalidateAll,
+	} {
+		// The fetch reads the old roles; an admin edit lands mid-query.
+		mock.ExpectQuery("SELECT r.name").WithArgs(int64(7)).
+			WillDelayFor(300 * time.Millisecond).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("admin"))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = c.UserRoles(ctx, db, 7)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		inval()
+		<-done
+
+		mock.ExpectQuery("SELECT r.name").WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("viewer"))
+		if got, _ := c.UserRoles(ctx, db, 7); len(got) != 1 || got[0] != "viewer" {
+			t.Fatalf("after mid-fetch invalidation = %v, want a fresh read", got)
+		}
+		c.InvalidateUser(7)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_RoleAllowedMatrix(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	c := NewCache(time.Minute)
+
+	mock.ExpectQuery("SELECT 1").WithArgs("admin", "editor", "content", "edit").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	for _, roles := range [][]string{{"admin", "editor"}, {"editor", "admin"}} { // order-insensitive
+		if ok, err := c.RoleAllowed(ctx, db, roles, "content", "edit"); err != nil || !ok {
+			t.Fatalf("RoleAllowed(%v) = %v, %v", roles, ok, err)
+		}
+	}
+
+	mock.ExpectQuery("SELECT 1").WithArgs("admin", "editor", "content", "edit").
+		WillReturnRows(sqlmock.NewRows([]string{"1"})) // revoked
+	c.InvalidateAll()
+	if ok, _ := c.RoleAllowed(ctx, db, []string{"admin", "editor"}, "content", "edit"); ok {
+		t.Fatal("InvalidateAll kept the old permission answer")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_ZeroTTLAlwaysQueries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c := NewCache(0)
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT r.name").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		if _, err := c.UserRoles(context.Background(), db, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFor_OnePerTenantWithConfiguredTTL(t *testing.T) {
+	ten := &tenant.Tenant{Config: tenant.SiteConfig{"acl.cache_ttl": "5s"}}
+	c := For(ten)
+	if c != For(ten) {
+		t.Fatal("For built a second cache for the same tenant")
+	}
+	if c.ttl != 5*time.Second {
+		t.Fatalf("ttl = %v, want 5s", c.ttl)
+	}
+	if other := For(&tenant.Tenant{}); other == c || other.ttl != DefaultCacheTTL {
+		t.Fatalf("default tenant cache = %p ttl %v", other, other.ttl)
+	}
+}
@@ -1,6 +1,9 @@
 // internal/acl/middleware.go
 //
-// Chi middleware helpers that enforce RBAC.
+// Chi middleware helpers that enforce RBAC.  Role and permission lookups go
+// through the tenant's acl Cache (cache.go).  RequirePermission is also the
+// tenant router's PermissionGuard, which applies the route permissions
+// Components declare (component/access.go).
 
 package acl
 
@@ -13,6 +16,8 @@ import (
 	"github.com/yanizio/adept/internal/tenant"
 )
 
+func init() { tenant.PermissionGuard = RequirePermission }
+
 // RequireRole ensures the current user possesses ANY of the supplied roles.
 func RequireRole(names ...string) func(http.Handler) http.Handler {
 	if len(names) == 0 {
@@ -37,7 +42,7 @@ func RequireRole(names ...string) func(http.Handler) http.Handler {
 			}
 
 			// NOTE: t.GetDB() is *sqlx.DB.  Pass its .DB field.
-			roles, err := UserRoles(r.Context(), t.GetDB().DB, uid)
+			roles, err := For(t).UserRoles(r.Context(), t.GetDB().DB, uid)
 			if err != nil {
 				zap.L().Error("acl user roles", zap.Error(err))
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -69,14 +74,15 @@ func RequirePermission(component, action string) func(http.Handler) http.Handler
 				return
 			}
 
-			roles, err := UserRoles(r.Context(), t.GetDB().DB, uid)
+			cache := For(t)
+			roles, err := cache.UserRoles(r.Context(), t.GetDB().DB, uid)
 			if err != nil {
 				zap.L().Error("acl user roles", zap.Error(err))
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 
-			allowed, err := RoleAllowed(r.Context(), t.GetDB().DB, roles, component, action)
+			allowed, err := cache.RoleAllowed(r.Context(), t.GetDB().DB, roles, component, action)
 			if err != nil {
 				zap.L().Error("acl role allowed", zap.Error(err))
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -0,0 +1,159 @@
+// internal/acl/middleware_test.go
+//
+// Integration tests: a RequireRole route behind a root handler that binds
+// the tenant the way cmd/web does, and a declared permission reached
+// through the tenant router with a real session cookie.
+//
+// Notes
+// -----
+// • The fake tenant's pool is a sqlmock registered with database the way
+//   the tenant loader registers real pools.
+// • Oxford commas, two spaces after periods.
+
+package acl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/auth"
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/database"
+	"github.com/yanizio/adept/internal/session"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestRequireRole_BehindBoundTenant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	pool := sqlx.NewDb(db, "mysql")
+	ten := &tenant.Tenant{DB: pool}
+	database.RegisterTenant(ten.Host(), pool)
+	defer database.UnregisterTenant(ten.Host(), pool)
+
+	var sawTenant *tenant.Tenant
+	var sawDB *sqlx.DB
+	r := chi.NewRouter()
+	r.With(RequireRole("editor")).Get("/edit", func(w http.ResponseWriter, req *http.Request) {
+		sawTenant = tenant.FromContext(req.Context())
+		sawDB = database.Conn(req.Context())
+	})
+
+	// Root handler: bind the tenant, then a session middleware sets the user.
+	root := func(bind bool) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			if bind {
+				ctx = tenant.Bind(ctx, ten)
+			}
+			r.ServeHTTP(w, req.WithContext(auth.WithUser(ctx, 7)))
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	root(false).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/edit", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("unbound tenant: status %d, want 500", rr.Code)
+	}
+
+	mock.ExpectQuery("SELECT r.name").WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("editor"))
+	rr = httptest.NewRecorder()
+	root(true).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/edit", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("bound tenant: status %d", rr.Code)
+	}
+	if sawTenant != ten || sawDB != pool {
+		t.Fatalf("FromContext = %p, Conn = %p; want %p, %p", sawTenant, sawDB, ten, pool)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sessionComp is a Component whose /edit route requires "secret/edit".
+type sessionComp struct{}
+
+func (sessionComp) Name() string                    { return "secret" }
+func (sessionComp) Migrations() []string            { return nil }
+func (sessionComp) Init(component.TenantInfo) error { return nil }
+func (sessionComp) Access() map[string]component.Access {
+	return map[string]component.Access{"/edit": component.Permission("edit")}
+}
+func (sessionComp) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/edit", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(tenant.NewContext(req).User.Email))
+	})
+	return r
+}
+
+// fakeUsers is an auth.UserStore over a map.
+type fakeUsers map[string]int64
+
+func (f fakeUsers) UserID(_ context.Context, email string) (int64, bool, error) {
+	id, ok := f[email]
+	return id, ok, nil
+}
+
+func TestRequirePermission_ThroughSession(t *testing.T) {
+	component.Register(sessionComp{})
+	auth.SetUserStore(fakeUsers{"ed@example.com": 7})
+	t.Cleanup(func() { auth.SetUserStore(nil) })
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+	missing := errors.New("Error 1146: Table doesn't exist")
+	mock.ExpectQuery("FROM component_acl").WillReturnError(missing)
+	mock.ExpectQuery("mount_prefix").WillReturnError(missing)
+	ten := &tenant.Tenant{DB: sqlx.NewDb(db, "mysql")}
+	h := ten.Router()
+
+	// Log in the way components/auth does, then replay the cookie.
+	login := httptest.NewRecorder()
+	session.LoginUser(login, httptest.NewRequest(http.MethodPost, "/login", nil), "ed@example.com")
+	get := func(cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/edit", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req.WithContext(tenant.Bind(req.Context(), ten)))
+		return rr
+	}
+
+	if rr := get(); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("no session: status %d, want 401", rr.Code)
+	}
+
+	mock.ExpectQuery("SELECT r.name").WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("editor"))
+	mock.ExpectQuery("FROM role_acl").WithArgs("editor", "secret", "edit").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	rr := get(login.Result().Cookies()...)
+	if rr.Code != http.StatusOK || rr.Body.String() != "ed@example.com" {
+		t.Fatalf("session: status %d body %q, want 200 ed@example.com", rr.Code, rr.Body)
+	}
+
+	stranger := &http.Cookie{Name: login.Result().Cookies()[0].Name, Value: "who@example.com"}
+	if rr := get(stranger); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown user: status %d, want 401", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,39 @@
+// internal/admin/acl.go
+//
+// POST /admin/acl/invalidate – drop cached ACL answers.
+//
+// Role and permission lookups are cached per tenant for a short TTL (see
+// acl/cache.go).  Tooling that edits user_role calls this with `user=<id>`
+// to drop that user's role set; without it, every cached role set and
+// permission answer of the tenant serving the request is dropped, which is
+// what edits to role or role_acl need.
+
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yanizio/adept/internal/acl"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func (h *Handler) invalidateACL(w http.ResponseWriter, r *http.Request) {
+	t := tenant.FromContext(r.Context())
+	if t == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no tenant"})
+		return
+	}
+	if s := r.URL.Query().Get("user"); s != "" {
+		uid, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad user id"})
+			return
+		}
+		acl.InvalidateUser(t, uid)
+		writeJSON(w, http.StatusOK, map[string]any{"invalidated": "user", "user": uid})
+		return
+	}
+	acl.InvalidateAll(t)
+	writeJSON(w, http.StatusOK, map[string]any{"invalidated": "all"})
+}
@@ -0,0 +1,78 @@
+// internal/admin/admin.go
+//
+// Operator endpoints mounted under /admin/ on every tenant host.
+//
+// Context
+// -------
+// Some maintenance actions (theme reload today, more later) must run
+// against live tenant state without a restart.  They are served from the
+// root handler in cmd/web, after the tenant has been placed on the request
+// context, and every route is wrapped in acl.RequireRole(AdminRole).
+//
+// Routes
+// ------
+//   POST /admin/theme/reload              reload this host's theme
+//   POST /admin/tenant/invalidate         drop this host's cached tenant
+//   POST /admin/acl/invalidate            drop this host's cached ACL answers
+//   POST /admin/acl/invalidate?user=id    drop one user's cached role set
+//   GET  /admin/routes                    list this host's live routes
+//
+// Notes
+// -----
+// • Responses are JSON so scripts and CI hooks can parse them.
+// • A tenant's admin role covers that tenant only.  Nothing here may act
+//   on another host; cross-tenant actions live in tenants.go behind the
+//   operator token.
+// • Oxford commas, two spaces after periods.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/acl"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// Prefix is the URL prefix the root handler routes here.
+const Prefix = "/admin/"
+
+// AdminRole is the tenant role required for every admin route.
+const AdminRole = "admin"
+
+// Handler serves the admin routes against one tenant cache.
+type Handler struct {
+	cache  *tenant.Cache
+	router chi.Router
+}
+
+// New builds the admin router.
+func New(c *tenant.Cache) *Handler {
+	h := &Handler{cache: c}
+	r := chi.NewRouter()
+	r.Use(acl.RequireRole(AdminRole))
+	r.Post("/admin/theme/reload", h.reloadTheme)
+	r.Post("/admin/tenant/invalidate", h.invalidateTenant)
+	r.Post("/admin/acl/invalidate", h.invalidateACL)
+	r.Get("/admin/routes", h.listRoutes)
+	h.router = r
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+// writeJSON encodes v with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		zap.L().Warn("admin response encode", zap.Error(err))
+	}
+}
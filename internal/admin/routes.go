@@ -0,0 +1,40 @@
+// internal/admin/routes.go
+//
+// GET /admin/routes – list the routes this host serves.
+//
+// The answer comes from the tenant's live router (tenant.Routes), so it
+// shows what requests hit right now: every method and pattern, the
+// Component that mounted it, and the permission the router checks, or
+// whether the route is declared public or not declared at all.  Read-only;
+// other methods get 405.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+type routesResult struct {
+	Host         string             `json:"host"`
+	RouteVersion int                `json:"route_version"`
+	Routes       []tenant.RouteInfo `json:"routes"`
+}
+
+func (h *Handler) listRoutes(w http.ResponseWriter, r *http.Request) {
+	t := tenant.FromContext(r.Context())
+	if t == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no tenant"})
+		return
+	}
+	routes := t.Routes()
+	if routes == nil {
+		routes = []tenant.RouteInfo{}
+	}
+	writeJSON(w, http.StatusOK, routesResult{
+		Host:         t.Host(),
+		RouteVersion: t.RouteVersion(),
+		Routes:       routes,
+	})
+}
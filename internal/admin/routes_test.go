@@ -0,0 +1,39 @@
+// internal/admin/routes_test.go
+//
+// Unit-test for GET /admin/routes.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestListRoutes_LiveRouter(t *testing.T) {
+	h := &Handler{}
+	ten := &tenant.Tenant{}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/routes", nil)
+	req = req.WithContext(tenant.WithContext(context.Background(), ten))
+	rr := httptest.NewRecorder()
+	h.listRoutes(rr, req)
+
+	var got routesResult
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("status %d, decode %v", rr.Code, err)
+	}
+	if len(got.Routes) != len(ten.Routes()) || got.Routes[0].Pattern != "/assets/*" {
+		t.Fatalf("routes = %+v", got.Routes)
+	}
+
+	rr = httptest.NewRecorder()
+	h.listRoutes(rr, httptest.NewRequest(http.MethodGet, "/admin/routes", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("without tenant: %d", rr.Code)
+	}
+}
@@ -0,0 +1,58 @@
+// internal/admin/tenant.go
+//
+// POST /admin/tenant/invalidate – drop this host's cached tenant state.
+//
+// Only the tenant serving the request is dropped: the route sits behind
+// that tenant's own admin role, so it must not reach any other site.
+// Cross-tenant eviction is the operator API in tenants.go
+// (DELETE /admin/tenants/{host}, bearer token).  `host=` and `all=` are
+// refused with 400 rather than ignored, so old scripts fail loudly.
+//
+// The next request for the host cold-loads its site row, site_config, and
+// theme.  Requests already in flight finish on the old state (see
+// tenant/invalidate.go).  The response lists the host removed; a host that
+// was not cached is not an error, since the goal (no stale copy) already
+// holds.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+type invalidateResult struct {
+	Invalidated []string `json:"invalidated"`
+	Count       int      `json:"count"`
+}
+
+func (h *Handler) invalidateTenant(w http.ResponseWriter, r *http.Request) {
+	t, ok := currentTenant(w, r)
+	if !ok {
+		return
+	}
+	res := invalidateResult{Invalidated: []string{}}
+	if h.cache.Invalidate(t.Host()) {
+		res.Invalidated = append(res.Invalidated, t.Host())
+		res.Count = 1
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// currentTenant returns the tenant serving r, answering 400 when there is
+// none or when the query names another target (host=, all=).
+func currentTenant(w http.ResponseWriter, r *http.Request) (*tenant.Tenant, bool) {
+	if q := r.URL.Query(); q.Has("host") || q.Has("all") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "host and all are not accepted here; use the operator API under " + TenantsPrefix,
+		})
+		return nil, false
+	}
+	t := tenant.FromContext(r.Context())
+	if t == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no tenant"})
+		return nil, false
+	}
+	return t, true
+}
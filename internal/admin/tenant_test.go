@@ -0,0 +1,59 @@
+// internal/admin/tenant_test.go
+//
+// Unit-tests for the per-tenant admin routes' scoping.
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestInvalidateTenant_CurrentTenantOnly(t *testing.T) {
+	c := tenant.New(nil, 0, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	h := &Handler{cache: c}
+	ten := &tenant.Tenant{}
+
+	for _, q := range []string{"?all=1", "?host=other.example", "?host="} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/tenant/invalidate"+q, nil)
+		req = req.WithContext(tenant.Bind(req.Context(), ten))
+		rec := httptest.NewRecorder()
+		h.invalidateTenant(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/tenant/invalidate", nil)
+	rec := httptest.NewRecorder()
+	h.invalidateTenant(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no tenant: status = %d, want 400", rec.Code)
+	}
+
+	req = req.WithContext(tenant.Bind(req.Context(), ten))
+	rec = httptest.NewRecorder()
+	h.invalidateTenant(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("own tenant: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestReloadTheme_RefusesOtherTargets(t *testing.T) {
+	h := &Handler{}
+	for _, q := range []string{"?all=1", "?host=other.example"} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/theme/reload"+q, nil)
+		req = req.WithContext(tenant.Bind(req.Context(), &tenant.Tenant{}))
+		rec := httptest.NewRecorder()
+		h.reloadTheme(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// internal/admin/tenants.go
+//
+// Cross-tenant cache inspection API.
+//
+// Context
+// -------
+// The routes in admin.go act on the tenant serving the request and sit
+// behind that tenant's ACL.  Inspecting the whole cache is a process-level
+// concern instead, so these routes are mounted outside the tenant router
+// in cmd/web, answer on any host, and require a bearer token from config
+// (admin.token).  With admin.listen_addr they get a listener of their own;
+// otherwise they share the public listeners behind RequireTLS.
+//
+// Routes
+// ------
+//   GET    /admin/tenants               Cache.Snapshot as JSON
+//   DELETE /admin/tenants/{host}        evict one tenant
+//   POST   /admin/tenants/{host}/reload invalidate, then cold-load again
+//   POST   /admin/tenants/{host}/routes rebuild the router only (component_acl
+//                                       changes), keeping pools and config
+//   POST   /admin/tenants/{host}/diagnose
+//                                       dry-run the cold load step by step
+//                                       (tenant.Diagnose); nothing is cached
+//
+// Notes
+// -----
+// • The token is compared in constant time; a missing or wrong token is a
+//   401 with no hint which.
+// • RequireTLS refuses plain HTTP before the token is looked at, so a
+//   client sending it in clear is never served, even behind a
+//   TLS-terminating proxy; use admin.listen_addr there.
+// • Eviction here is Cache.Invalidate, so requests in flight keep their
+//   pool for tenant.InvalidateGrace.
+// • Oxford commas, two spaces after periods.
+
+package admin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// TenantsPrefix is the path the tenant-cache API is mounted at.
+const TenantsPrefix = "/admin/tenants"
+
+// NewTenants builds the token-protected tenant-cache API.
+func NewTenants(c *tenant.Cache, token string) http.Handler {
+	h := &Handler{cache: c}
+	r := chi.NewRouter()
+	r.Use(requireToken(token))
+	r.Get(TenantsPrefix, h.listTenants)
+	r.Delete(TenantsPrefix+"/{host}", h.evictTenant)
+	r.Post(TenantsPrefix+"/{host}/reload", h.reloadTenant)
+	r.Post(TenantsPrefix+"/{host}/routes", h.rebuildRoutes)
+	r.Post(TenantsPrefix+"/{host}/diagnose", h.diagnoseTenant)
+	return r
+}
+
+// RequireTLS wraps h so that plain-HTTP requests get 403 and never reach
+// it.  cmd/web applies it when the API shares the public listeners.
+func RequireTLS(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "https required"})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+// An empty token rejects everything.
+func requireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type tenantsResult struct {
+	Tenants []tenant.EntryInfo `json:"tenants"`
+	Count   int                `json:"count"`
+}
+
+func (h *Handler) listTenants(w http.ResponseWriter, _ *http.Request) {
+	snap := h.cache.Snapshot()
+	writeJSON(w, http.StatusOK, tenantsResult{Tenants: snap, Count: len(snap)})
+}
+
+func (h *Handler) evictTenant(w http.ResponseWriter, r *http.Request) {
+	host := chi.URLParam(r, "host")
+	if !h.cache.Invalidate(host) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "tenant not cached"})
+		return
+	}
+	writeJSON(w, http.StatusOK, invalidateResult{Invalidated: []string{host}, Count: 1})
+}
+
+func (h *Handler) reloadTenant(w http.ResponseWriter, r *http.Request) {
+	host := chi.URLParam(r, "host")
+	t, err := h.cache.Reload(host)
+	var redirect *tenant.Redirect
+	switch {
+	case errors.Is(err, tenant.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown host"})
+		return
+	case errors.As(err, &redirect):
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "redirect alias", "canonical": redirect.Host})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"reloaded": t.Host()})
+}
+
+func (h *Handler) rebuildRoutes(w http.ResponseWriter, r *http.Request) {
+	host := chi.URLParam(r, "host")
+	var target *tenant.Tenant
+	h.cache.Range(func(k string, t *tenant.Tenant) bool {
+		if k == host {
+			target = t
+			return false
+		}
+		return true
+	})
+	if target == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "tenant not cached"})
+		return
+	}
+	if err := target.RebuildRouter(); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"rebuilt": host})
+}
+
+func (h *Handler) diagnoseTenant(w http.ResponseWriter, r *http.Request) {
+	rep, err := h.cache.Diagnose(r.Context(), chi.URLParam(r, "host"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
@@ -0,0 +1,37 @@
+// internal/admin/theme.go
+//
+// POST /admin/theme/reload – hot-reload this host's theme templates.
+//
+// Only the tenant serving the request is reloaded; like tenant/invalidate,
+// the route is guarded by that tenant's admin role and refuses `host=` and
+// `all=` (currentTenant).  Other tenants pick up theme edits through the
+// operator API (POST /admin/tenants/{host}/reload).
+//
+// The response lists the reloaded host or, on failure, the parse error with
+// status 500; the tenant then keeps its old templates.
+
+package admin
+
+import (
+	"net/http"
+)
+
+type reloadResult struct {
+	Reloaded []string          `json:"reloaded"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+func (h *Handler) reloadTheme(w http.ResponseWriter, r *http.Request) {
+	t, ok := currentTenant(w, r)
+	if !ok {
+		return
+	}
+	res := reloadResult{Reloaded: []string{}, Errors: map[string]string{}}
+	if err := t.ReloadTheme(); err != nil {
+		res.Errors[t.Host()] = err.Error()
+		writeJSON(w, http.StatusInternalServerError, res)
+		return
+	}
+	res.Reloaded = append(res.Reloaded, t.Host())
+	writeJSON(w, http.StatusOK, res)
+}
@@ -6,7 +6,8 @@
 //
 // Usage
 // -----
-//     // Attach user 123 to the request context (after login).
+//     // Attach user 123 to the request context.  The tenant router does
+//     // this for every request with a session (LookupUser, users.go).
 //     ctx = auth.WithUser(ctx, 123)
 //
 //     // Downstream code retrieves the ID.
@@ -0,0 +1,131 @@
+// internal/auth/users.go
+//
+// Session email → user ID.
+//
+// Context
+// -------
+// The session cookie (internal/session) carries only the signed-in email,
+// while ACL checks (internal/acl) key on users.id.  The tenant router
+// resolves the email through LookupUser once per request and attaches the
+// ID with WithUser, so acl.RequireRole, acl.RequirePermission, and
+// tenant.Context.User all see the same user.
+//
+// Workflow
+// --------
+//   1. cmd/web installs SQLUsers over the global DB with SetUserStore.
+//   2. LookupUser asks the store and remembers the answer, found or not,
+//      for userTTL in a bounded LRU, so a busy session costs one query
+//      a minute.
+//   3. Store errors are never remembered; the caller treats the request
+//      as anonymous.
+//
+// Notes
+// -----
+// • Only users with status 'Active' resolve.  A user blocked mid-session
+//   keeps the cookie but fails every ACL check within userTTL.
+// • With no store installed every lookup misses, so tests and tools that
+//   never call SetUserStore stay anonymous.
+// • Oxford commas, two spaces after periods.
+
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	lru "github.com/yanizio/adept/internal/cache"
+)
+
+const (
+	userTTL     = time.Minute // how long a lookup answer is reused
+	userEntries = 10000       // bound on remembered emails
+)
+
+// UserStore resolves a session email to users.id.
+type UserStore interface {
+	// UserID returns the ID of the active user with email; ok is false
+	// when there is none.
+	UserID(ctx context.Context, email string) (id int64, ok bool, err error)
+}
+
+type cachedUser struct {
+	id  int64
+	ok  bool
+	exp time.Time
+}
+
+var (
+	usersMu   sync.Mutex
+	userStore UserStore
+	userGen   uint64 // bumped by SetUserStore
+	userLRU   = lru.New(userEntries)
+	userNow   = time.Now // stubbed in tests
+)
+
+// SetUserStore installs the store LookupUser asks and forgets every
+// remembered answer.  nil leaves every request anonymous.
+func SetUserStore(s UserStore) {
+	usersMu.Lock()
+	userStore = s
+	userGen++
+	userLRU = lru.New(userEntries)
+	usersMu.Unlock()
+}
+
+// LookupUser returns the user ID for a session email.  ok is false for an
+// empty email, an unknown or inactive user, or when no store is installed.
+func LookupUser(ctx context.Context, email string) (id int64, ok bool, err error) {
+	if email == "" {
+		return 0, false, nil
+	}
+	usersMu.Lock()
+	s, gen := userStore, userGen
+	if v, hit := userLRU.Get(email); hit {
+		e := v.(cachedUser)
+		if userNow().Before(e.exp) {
+			usersMu.Unlock()
+			return e.id, e.ok, nil
+		}
+		userLRU.Remove(email)
+	}
+	usersMu.Unlock()
+	if s == nil {
+		return 0, false, nil
+	}
+
+	id, ok, err = s.UserID(ctx, email)
+	if err != nil {
+		return 0, false, err
+	}
+	usersMu.Lock()
+	if userGen == gen { // store not swapped meanwhile
+		userLRU.Add(email, cachedUser{id: id, ok: ok, exp: userNow().Add(userTTL)})
+	}
+	usersMu.Unlock()
+	return id, ok, nil
+}
+
+// SQLUsers is a UserStore over the `users` table.
+type SQLUsers struct{ db *sqlx.DB }
+
+// NewSQLUsers returns a store backed by db, normally the global DB.
+func NewSQLUsers(db *sqlx.DB) *SQLUsers { return &SQLUsers{db: db} }
+
+// UserID implements UserStore.
+func (s *SQLUsers) UserID(ctx context.Context, email string) (int64, bool, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id FROM users WHERE email = ? AND status = 'Active'`, email).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
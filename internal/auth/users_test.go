@@ -0,0 +1,61 @@
+// internal/auth/users_test.go
+//
+// Unit-tests for the session email → user ID lookup.
+
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestLookupUser_CachesAnswersNotErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	SetUserStore(NewSQLUsers(sqlx.NewDb(db, "mysql")))
+	t.Cleanup(func() { SetUserStore(nil); userNow = time.Now })
+	clock := time.Unix(1_700_000_000, 0)
+	userNow = func() time.Time { return clock }
+
+	q := "SELECT id FROM users WHERE email = \\? AND status = 'Active'"
+	mock.ExpectQuery(q).WithArgs("ed@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(q).WithArgs("gone@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(q).WithArgs("flaky@example.com").
+		WillReturnError(errors.New("db down"))
+	mock.ExpectQuery(q).WithArgs("flaky@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+	for i := 0; i < 2; i++ { // second round from the cache
+		if id, ok, err := LookupUser(t.Context(), "ed@example.com"); id != 7 || !ok || err != nil {
+			t.Fatalf("ed: %d %v %v", id, ok, err)
+		}
+		if _, ok, err := LookupUser(t.Context(), "gone@example.com"); ok || err != nil {
+			t.Fatalf("gone: %v %v", ok, err)
+		}
+	}
+	if _, _, err := LookupUser(t.Context(), "flaky@example.com"); err == nil {
+		t.Fatal("store error swallowed")
+	}
+	if id, ok, _ := LookupUser(t.Context(), "flaky@example.com"); id != 9 || !ok {
+		t.Fatalf("retry after error = %d %v, want 9", id, ok)
+	}
+
+	clock = clock.Add(userTTL)
+	mock.ExpectQuery(q).WithArgs("ed@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, ok, _ := LookupUser(t.Context(), "ed@example.com"); ok {
+		t.Fatal("expired entry served: user deactivated meanwhile")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -56,5 +56,29 @@ func (c *LRU) Add(key, val any) {
 	}
 }
 
+// Remove deletes key when present.
+func (c *LRU) Remove(key any) {
+	if ele, hit := c.dict[key]; hit {
+		c.ll.Remove(ele)
+		delete(c.dict, key)
+	}
+}
+
+// RemoveIf deletes every entry whose key satisfies fn and returns the count
+// removed.  It walks the whole list, so reserve it for rare bulk purges.
+func (c *LRU) RemoveIf(fn func(key any) bool) int {
+	var n int
+	for ele := c.ll.Front(); ele != nil; {
+		next := ele.Next()
+		if k := ele.Value.(pair).key; fn(k) {
+			c.ll.Remove(ele)
+			delete(c.dict, k)
+			n++
+		}
+		ele = next
+	}
+	return n
+}
+
 // Len reports current size.
 func (c *LRU) Len() int { return c.ll.Len() }
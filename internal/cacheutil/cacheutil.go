@@ -0,0 +1,169 @@
+// internal/cacheutil/cacheutil.go
+//
+// Request-coalescing TTL cache for expensive Component queries.
+//
+// Context
+// -------
+// A "popular content" widget on a busy page runs the same heavy query for
+// every concurrent request.  GetOrLoad collapses identical loads into one
+// call (singleflight) and keeps the result for a short TTL, so a burst of N
+// requests costs one DB hit instead of N.
+//
+// Workflow
+// --------
+//  1. A fresh entry for key is returned straight from the LRU.
+//  2. Otherwise the loader runs under singleflight on key; concurrent callers
+//     wait for that one run and share its result.
+//  3. A successful result is stored with expiry now+ttl.  Errors are handed
+//     to every waiting caller but never stored, so the next request retries.
+//
+// Keys
+// ----
+// The cache is process-wide and shared by all tenants, so keys must be
+// tenant-scoped by convention.  Build them with Key:
+//
+//	k := cacheutil.Key(host, "popular", "limit=5")   // "a.example|popular|limit=5"
+//	items, err := cacheutil.GetOrLoad(k, 30*time.Second, loadPopular)
+//
+// ForgetTenant drops every key built for one host; it runs automatically
+// when the tenant is evicted or invalidated (tenant.OnEvict).
+//
+// Notes
+// -----
+// • Bounded: the default Store holds DefaultCapacity entries and evicts the
+//   least recently used one beyond that.  Expired entries are dropped lazily.
+// • Values are shared between callers; treat them as read-only.
+// • Oxford commas, two spaces after periods.
+
+package cacheutil
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	lru "github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// DefaultCapacity bounds the package-level Store.
+const DefaultCapacity = 1024
+
+// keySep separates the parts of a Key.  Hosts never contain it.
+const keySep = "|"
+
+// Store is a bounded, TTL-aware, request-coalescing cache.  The zero value
+// is not usable; call NewStore.
+type Store struct {
+	mu  sync.Mutex
+	lru *lru.LRU // key → item; not goroutine-safe, guarded by mu
+	sfg singleflight.Group
+	now func() time.Time // stubbed in tests
+}
+
+type item struct {
+	val     any
+	expires time.Time
+}
+
+// NewStore returns a Store holding at most capacity entries.
+func NewStore(capacity int) *Store {
+	return &Store{lru: lru.New(capacity), now: time.Now}
+}
+
+var defaultStore = NewStore(DefaultCapacity)
+
+func init() {
+	tenant.OnEvict(func(host string) { ForgetTenant(host) })
+}
+
+// Key joins host and parts into a tenant-scoped cache key.
+func Key(host string, parts ...string) string {
+	return strings.Join(append([]string{host}, parts...), keySep)
+}
+
+// GetOrLoad returns the cached value for key, or runs loader once for all
+// concurrent callers and caches its result for ttl.  Loader errors are not
+// cached.  It uses the package-level Store.
+func GetOrLoad[T any](key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	v, err := defaultStore.GetOrLoad(key, ttl, func() (any, error) { return loader() })
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	t, _ := v.(T) // comma-ok: a nil interface T is stored as untyped nil
+	return t, nil
+}
+
+// Forget drops key from the package-level Store.
+func Forget(key string) { defaultStore.Forget(key) }
+
+// ForgetTenant drops every key built with Key(host, …) from the
+// package-level Store.
+func ForgetTenant(host string) int { return defaultStore.ForgetTenant(host) }
+
+// GetOrLoad is the untyped form of the package-level GetOrLoad.
+func (s *Store) GetOrLoad(key string, ttl time.Duration, loader func() (any, error)) (any, error) {
+	if v, ok := s.get(key); ok {
+		return v, nil
+	}
+	v, err, _ := s.sfg.Do(key, func() (any, error) {
+		// Double-check: a flight that finished just before this one started
+		// may already have stored the value.
+		if v, ok := s.get(key); ok {
+			return v, nil
+		}
+		v, err := loader()
+		if err != nil {
+			return nil, err // never cached
+		}
+		s.mu.Lock()
+		s.lru.Add(key, item{val: v, expires: s.now().Add(ttl)})
+		s.mu.Unlock()
+		return v, nil
+	})
+	return v, err
+}
+
+// get returns a fresh value for key, dropping it when expired.
+func (s *Store) get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	it := v.(item)
+	if !s.now().Before(it.expires) {
+		s.lru.Remove(key)
+		return nil, false
+	}
+	return it.val, true
+}
+
+// Forget drops key.
+func (s *Store) Forget(key string) {
+	s.mu.Lock()
+	s.lru.Remove(key)
+	s.mu.Unlock()
+}
+
+// ForgetTenant drops every key built with Key(host, …) and returns the count.
+func (s *Store) ForgetTenant(host string) int {
+	prefix := host + keySep
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.RemoveIf(func(k any) bool {
+		ks := k.(string)
+		return ks == host || strings.HasPrefix(ks, prefix)
+	})
+}
+
+// Len reports the number of entries, fresh or not yet swept.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
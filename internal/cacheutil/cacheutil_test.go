@@ -0,0 +1,134 @@
+// internal/cacheutil/cacheutil_test.go
+//
+// Unit-tests for the request-coalescing cache.
+//
+// Notes
+// -----
+// • Each test builds its own Store with a stubbed clock; only
+//   TestGetOrLoad_Typed touches the package-level Store.
+// • Oxford commas, two spaces after periods.
+
+package cacheutil
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testStore(capacity int) (*Store, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewStore(capacity)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestGetOrLoad_CachesUntilTTL(t *testing.T) {
+	s, now := testStore(8)
+	var calls int
+	load := func() (any, error) { calls++; return calls, nil }
+
+	for i := 0; i < 3; i++ {
+		v, err := s.GetOrLoad("a|k", time.Minute, load)
+		if err != nil || v != 1 {
+			t.Fatalf("call %d = %v, %v; want 1, nil", i, v, err)
+		}
+	}
+
+	*now = now.Add(time.Minute)
+	if v, _ := s.GetOrLoad("a|k", time.Minute, load); v != 2 {
+		t.Fatalf("after TTL = %v, want 2 (reloaded)", v)
+	}
+}
+
+func TestGetOrLoad_ErrorsNotCached(t *testing.T) {
+	s, _ := testStore(8)
+	boom := errors.New("boom")
+
+	if _, err := s.GetOrLoad("a|k", time.Minute, func() (any, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("error result was cached")
+	}
+	v, err := s.GetOrLoad("a|k", time.Minute, func() (any, error) { return "ok", nil })
+	if err != nil || v != "ok" {
+		t.Fatalf("retry = %v, %v; want ok, nil", v, err)
+	}
+}
+
+func TestGetOrLoad_CoalescesConcurrentLoads(t *testing.T) {
+	s, _ := testStore(8)
+	var calls int32
+	release := make(chan struct{})
+	load := func() (any, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "v", nil
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			if v, err := s.GetOrLoad("a|popular", time.Minute, load); err != nil || v != "v" {
+				t.Errorf("got %v, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond) // let the callers pile up on the flight
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("loader ran %d times, want 1", got)
+	}
+}
+
+func TestStore_Bounded(t *testing.T) {
+	s, _ := testStore(2)
+	for _, k := range []string{"a|1", "a|2", "a|3"} {
+		_, _ = s.GetOrLoad(k, time.Minute, func() (any, error) { return k, nil })
+	}
+	if s.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", s.Len())
+	}
+	var reloaded bool
+	_, _ = s.GetOrLoad("a|1", time.Minute, func() (any, error) { reloaded = true; return nil, nil })
+	if !reloaded {
+		t.Fatal("oldest key was not evicted")
+	}
+}
+
+func TestForgetTenant(t *testing.T) {
+	s, _ := testStore(8)
+	keys := []string{Key("a.example", "x"), Key("a.example", "y"), Key("a.example.org", "x")}
+	for _, k := range keys {
+		_, _ = s.GetOrLoad(k, time.Minute, func() (any, error) { return k, nil })
+	}
+	if n := s.ForgetTenant("a.example"); n != 2 {
+		t.Fatalf("ForgetTenant removed %d, want 2", n)
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len = %d, want 1 (other tenant kept)", s.Len())
+	}
+}
+
+func TestGetOrLoad_Typed(t *testing.T) {
+	k := Key("typed.example", "popular", "limit=2")
+	t.Cleanup(func() { Forget(k) })
+
+	got, err := GetOrLoad(k, time.Minute, func() ([]string, error) {
+		return []string{"a", "b"}, nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if k != "typed.example|popular|limit=2" {
+		t.Fatalf("Key = %q", k)
+	}
+}
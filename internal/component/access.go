@@ -0,0 +1,128 @@
+// internal/component/access.go
+//
+// Declarative route permissions.
+//
+// Context
+// -------
+// acl.RequirePermission had to be attached to each route by hand inside
+// Routes(), and a forgotten one left the endpoint open.  A Component that
+// implements Guarded instead returns a map from route pattern to Access,
+// and the tenant router wraps each matching route in the ACL check
+// (tenant/mount.go).  Routes that map to Public stay open on purpose.
+//
+// Keys
+// ----
+//   - "GET /posts/{id}"  – one method and the exact chi pattern
+//   - "/posts/{id}"      – the exact pattern, every method
+//   - "/admin/*"         – the pattern and every route under it
+//
+// Patterns are those registered in Routes(), before any mount prefix.  An
+// exact key beats a wildcard, the longest wildcard wins, and a key naming
+// the method beats one that does not.
+//
+// Notes
+// -----
+// • A route no key covers is "undeclared".  It is still served, without a
+//   check, and Undeclared lists it so cmd/web can warn at boot.
+// • Oxford commas, two spaces after periods.
+
+package component
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Access is what a route requires: a permission, or nothing when Public.
+type Access struct {
+	Component string // role_acl.component; "" means the declaring Component
+	Action    string // role_acl.action
+	Public    bool   // open to everyone, on purpose
+}
+
+// Public marks a route as deliberately open.
+var Public = Access{Public: true}
+
+// Permission requires action on the declaring Component.
+func Permission(action string) Access { return Access{Action: action} }
+
+// Guarded is optional.  Access maps route patterns to what they require;
+// see the file header for the key syntax.
+type Guarded interface {
+	Access() map[string]Access
+}
+
+// AccessOf returns what c declares for method and route, with Component
+// filled in.  ok is false when c declares nothing for the route.
+func AccessOf(c Component, method, route string) (a Access, ok bool) {
+	g, isGuarded := c.(Guarded)
+	if !isGuarded {
+		return Access{}, false
+	}
+	a, ok = match(g.Access(), method, route)
+	if ok && !a.Public && a.Component == "" {
+		a.Component = c.Name()
+	}
+	return a, ok
+}
+
+// match picks the most specific key of m covering method and route.
+func match(m map[string]Access, method, route string) (Access, bool) {
+	if a, ok := m[method+" "+route]; ok {
+		return a, true
+	}
+	if a, ok := m[route]; ok {
+		return a, true
+	}
+
+	best, bestLen, bestMethod, found := Access{}, -1, false, false
+	for key, a := range m {
+		keyMethod, pattern := "", key
+		if i := strings.IndexByte(key, ' '); i > 0 {
+			keyMethod, pattern = key[:i], key[i+1:]
+		}
+		if keyMethod != "" && keyMethod != method {
+			continue
+		}
+		base, wild := strings.CutSuffix(pattern, "*")
+		if !wild || !strings.HasPrefix(route, base) {
+			continue
+		}
+		if len(base) > bestLen || (len(base) == bestLen && keyMethod != "" && !bestMethod) {
+			best, bestLen, bestMethod, found = a, len(base), keyMethod != "", true
+		}
+	}
+	return best, found
+}
+
+// Route is one method and pattern of a Component, as registered in
+// Routes().
+type Route struct {
+	Component string
+	Method    string
+	Path      string
+}
+
+// Undeclared returns every route of comps that no Access key covers,
+// sorted by Component and path.
+func Undeclared(comps []Component) []Route {
+	var out []Route
+	for _, c := range comps {
+		_ = chi.Walk(c.Routes(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			if _, ok := AccessOf(c, method, route); !ok {
+				out = append(out, Route{Component: c.Name(), Method: method, Path: route})
+			}
+			return nil
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Component != out[j].Component {
+			return out[i].Component < out[j].Component
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
@@ -0,0 +1,60 @@
+// internal/component/access_test.go
+//
+// Unit-tests for declarative route permissions.
+
+package component
+
+import "testing"
+
+// guarded is a routed Component with an Access map.
+type guarded struct {
+	routed
+	access map[string]Access
+}
+
+func (g guarded) Access() map[string]Access { return g.access }
+
+func TestAccessOf_Precedence(t *testing.T) {
+	c := guarded{routed: routed{bare: "blog"}, access: map[string]Access{
+		"GET /posts/{id}":  Public,
+		"/posts/{id}":      Permission("edit"),
+		"/admin/*":         Permission("admin"),
+		"/admin/reports/*": {Component: "reports", Action: "view"},
+		"POST /admin/*":    Permission("admin.write"),
+	}}
+
+	for _, tc := range []struct {
+		method, route string
+		want          Access
+		ok            bool
+	}{
+		{"GET", "/posts/{id}", Public, true},
+		{"DELETE", "/posts/{id}", Access{Component: "blog", Action: "edit"}, true},
+		{"GET", "/admin/users", Access{Component: "blog", Action: "admin"}, true},
+		{"POST", "/admin/users", Access{Component: "blog", Action: "admin.write"}, true},
+		{"POST", "/admin/reports/daily", Access{Component: "reports", Action: "view"}, true},
+		{"GET", "/about", Access{}, false},
+	} {
+		got, ok := AccessOf(c, tc.method, tc.route)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("AccessOf(%s %s) = %+v, %v; want %+v, %v", tc.method, tc.route, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestUndeclared(t *testing.T) {
+	got := Undeclared([]Component{
+		guarded{routed: routed{bare: "blog", paths: []string{"/posts", "/drafts"}},
+			access: map[string]Access{"/posts": Public}},
+		routed{bare: "shop", paths: []string{"/cart"}},
+	})
+	want := []Route{{"blog", "GET", "/drafts"}, {"shop", "GET", "/cart"}}
+	if len(got) != len(want) {
+		t.Fatalf("Undeclared = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Undeclared = %v, want %v", got, want)
+		}
+	}
+}
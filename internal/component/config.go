@@ -0,0 +1,100 @@
+// internal/component/config.go
+//
+// Declared site_config keys.
+//
+// Context
+// -------
+// site_config is free-form, so a typo such as `auth.max_atempts` used to be
+// ignored silently and the default applied.  Components now declare the
+// keys they read; the tenant loader checks every tenant's rows against the
+// union of declarations and logs a WARN for unknown or unparsable keys.
+// Nothing is rejected: undeclared keys still load.
+//
+// Declaring keys
+// --------------
+// A Component implements ConfigSchema:
+//
+//	func (c *Component) ConfigKeys() []component.ConfigKey {
+//	    return []component.ConfigKey{
+//	        {Name: "auth.max_attempts", Type: component.ConfigInt, Default: "5"},
+//	    }
+//	}
+//
+// Packages that are not Components (core, view) call DeclareConfig from
+// init() instead.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package component
+
+import "sync"
+
+// ConfigType is the expected shape of a site_config value.
+type ConfigType int
+
+const (
+	ConfigString   ConfigType = iota // any value
+	ConfigInt                        // strconv.Atoi
+	ConfigBool                       // strconv.ParseBool
+	ConfigDuration                   // time.ParseDuration
+)
+
+// String names the type in log lines.
+func (t ConfigType) String() string {
+	switch t {
+	case ConfigInt:
+		return "int"
+	case ConfigBool:
+		return "bool"
+	case ConfigDuration:
+		return "duration"
+	}
+	return "string"
+}
+
+// ConfigKey declares one site_config key.  Default is documentation only;
+// consumers still pass their default to the SiteConfig accessors.
+type ConfigKey struct {
+	Name    string
+	Type    ConfigType
+	Default string
+}
+
+// ConfigSchema is optional.  Components that read site_config implement it.
+type ConfigSchema interface {
+	ConfigKeys() []ConfigKey
+}
+
+var (
+	declMu   sync.RWMutex
+	declared []ConfigKey
+)
+
+// DeclareConfig registers keys read by non-component packages.  Call from
+// init().
+func DeclareConfig(keys ...ConfigKey) {
+	declMu.Lock()
+	declared = append(declared, keys...)
+	declMu.Unlock()
+}
+
+// ConfigKeys returns every declared key, from DeclareConfig and from each
+// registered ConfigSchema, keyed by name.
+func ConfigKeys() map[string]ConfigKey {
+	out := map[string]ConfigKey{}
+	declMu.RLock()
+	for _, k := range declared {
+		out[k.Name] = k
+	}
+	declMu.RUnlock()
+	for _, c := range All() {
+		if s, ok := c.(ConfigSchema); ok {
+			for _, k := range s.ConfigKeys() {
+				out[k.Name] = k
+			}
+		}
+	}
+	return out
+}
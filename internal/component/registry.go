@@ -4,12 +4,26 @@
 //
 // Each concrete component lives under components/<name> and calls
 // component.Register() in an init() function.  The tenant loader mounts
-// every component’s Routes() at “/” and, after cold-load, invokes Init()
+// every component’s Routes() at “/”, or at Prefix() when the component
+// implements Prefixer (routes.go), and, after cold-load, invokes Init()
 // when the component implements the Initializer interface.
+//
+// Components that need another one initialised first implement Dependent.
+// All() returns components in dependency order (ties broken by name), so
+// mounting and Init both see auth before a profile component that uses it.
+// CheckDependencies reports cycles and unknown names; cmd/web calls it at
+// startup.
 
 package component
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/go-chi/chi/v5"
@@ -25,9 +39,17 @@ type Initializer interface {
 	Init(TenantInfo) error
 }
 
+// Dependent is optional.  Dependencies returns the names of Components that
+// must be mounted and initialised before this one.
+type Dependent interface {
+	Dependencies() []string
+}
+
 // Component contract.
 //
-// Migrations() may return nil if the component has no schema changes.
+// Migrations() may return nil if the component has no schema changes.  The
+// list is append-only: the tenant loader applies entries a tenant has not
+// seen yet, tracked by position in schema_migrations (internal/migrate).
 // Routes() should mount BOTH page and API endpoints, e.g:
 //
 //	r := chi.NewRouter()
@@ -46,28 +68,151 @@ type Component interface {
 //
 
 var (
-	mu       sync.RWMutex
-	registry = map[string]Component{}
+	mu         sync.RWMutex
+	registry   = map[string]Component{}
+	duplicates []Duplicate
 )
 
-// Register is invoked from each component package’s init().
+// PanicOnDuplicate makes Register panic when a name is already taken.  It
+// defaults to ADEPT_COMPONENT_DUPLICATES=panic, so CI can fail the build on
+// a conflict; otherwise the conflict is logged and the first Component
+// keeps the name.
+var PanicOnDuplicate = os.Getenv("ADEPT_COMPONENT_DUPLICATES") == "panic"
+
+// Duplicate records a Register call rejected because its name was taken.
+type Duplicate struct {
+	Name     string
+	Kept     string // package-qualified type of the registered Component
+	Rejected string // … and of the one that was turned away
+}
+
+// Register is invoked from each component package’s init().  A second
+// Component with the same name never replaces the first: Register panics
+// when PanicOnDuplicate is set and otherwise logs and records it (see
+// Duplicates).
 func Register(c Component) {
 	mu.Lock()
-	registry[c.Name()] = c
-	mu.Unlock()
+	defer mu.Unlock()
+	prev, taken := registry[c.Name()]
+	if !taken {
+		registry[c.Name()] = c
+		return
+	}
+
+	d := Duplicate{Name: c.Name(), Kept: typeName(prev), Rejected: typeName(c)}
+	if PanicOnDuplicate {
+		panic(fmt.Sprintf("component: %q registered twice: %s and %s", d.Name, d.Kept, d.Rejected))
+	}
+	duplicates = append(duplicates, d)
+	// zap is not configured yet during init(); the standard logger writes
+	// to stderr, and cmd/web repeats the warning once its logger is up.
+	log.Printf("component: DUPLICATE %q – keeping %s, ignoring %s", d.Name, d.Kept, d.Rejected)
 }
 
-// All returns every registered component in arbitrary order.
-func All() []Component {
+// Duplicates returns the rejected registrations, in the order they
+// happened.
+func Duplicates() []Duplicate {
 	mu.RLock()
 	defer mu.RUnlock()
-	out := make([]Component, 0, len(registry))
-	for _, c := range registry {
-		out = append(out, c)
+	return append([]Duplicate(nil), duplicates...)
+}
+
+// typeName is "github.com/x/y/components/auth.Component" for c.
+func typeName(c Component) string {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
 	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// All returns every registered component in dependency order.  Members of
+// a cycle, which CheckDependencies reports at startup, follow the rest in
+// name order.
+func All() []Component {
+	mu.RLock()
+	defer mu.RUnlock()
+	out, _ := order(registry)
 	return out
 }
 
+// CheckDependencies reports every dependency cycle and every dependency on
+// a Component that is not registered.
+func CheckDependencies() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, err := order(registry)
+	return err
+}
+
+// dependencies of c, or nil when it does not implement Dependent.
+func dependencies(c Component) []string {
+	if d, ok := c.(Dependent); ok {
+		return d.Dependencies()
+	}
+	return nil
+}
+
+// order sorts reg topologically (Kahn's algorithm, ready set kept in name
+// order so the result is stable).  Unknown dependencies are reported and
+// otherwise ignored; unresolved cycle members are appended by name.
+func order(reg map[string]Component) ([]Component, error) {
+	var errs []error
+	indeg := make(map[string]int, len(reg))
+	users := make(map[string][]string, len(reg)) // dep → components needing it
+	for name, c := range reg {
+		indeg[name] += 0
+		for _, dep := range dependencies(c) {
+			if _, ok := reg[dep]; !ok {
+				errs = append(errs, fmt.Errorf("component %q depends on unregistered %q", name, dep))
+				continue
+			}
+			indeg[name]++
+			users[dep] = append(users[dep], name)
+		}
+	}
+
+	var ready []string
+	for name, n := range indeg {
+		if n == 0 {
+			ready = append(ready, name)
+		}
+	}
+	sort.Strings(ready)
+
+	out := make([]Component, 0, len(reg))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		out = append(out, reg[name])
+		for _, u := range users[name] {
+			if indeg[u]--; indeg[u] == 0 {
+				ready = append(ready, u)
+			}
+		}
+		sort.Strings(ready)
+	}
+
+	if len(out) < len(reg) {
+		var stuck []string
+		for name, n := range indeg {
+			if n > 0 {
+				stuck = append(stuck, name)
+			}
+		}
+		sort.Strings(stuck)
+		errs = append(errs, fmt.Errorf("component dependency cycle among: %s",
+			strings.Join(stuck, ", ")))
+		for _, name := range stuck {
+			out = append(out, reg[name])
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
 // AllNames returns a set of every registered component name.
 // Used by tenant.Router() as a fallback when component_acl is empty.
 func AllNames() map[string]struct{} {
@@ -0,0 +1,123 @@
+// internal/component/registry_test.go
+//
+// Unit-tests for dependency ordering and cycle detection.
+
+package component
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// bare does not implement Dependent.
+type bare string
+
+func (b bare) Name() string        { return string(b) }
+func (bare) Routes() chi.Router    { return chi.NewRouter() }
+func (bare) Migrations() []string  { return nil }
+func (bare) Init(TenantInfo) error { return nil }
+
+type stub struct {
+	bare
+	deps []string
+}
+
+func (s stub) Dependencies() []string { return s.deps }
+
+func names(cs []Component) string {
+	out := make([]string, len(cs))
+	for i, c := range cs {
+		out[i] = c.Name()
+	}
+	return strings.Join(out, " ")
+}
+
+func reg(cs ...Component) map[string]Component {
+	m := make(map[string]Component, len(cs))
+	for _, c := range cs {
+		m[c.Name()] = c
+	}
+	return m
+}
+
+func TestOrder_DependenciesFirst(t *testing.T) {
+	got, err := order(reg(
+		stub{bare("profile"), []string{"auth"}},
+		stub{bare("billing"), []string{"profile", "auth"}},
+		stub{bare("auth"), nil},
+		bare("blog"),
+	))
+	if err != nil {
+		t.Fatalf("order: %v", err)
+	}
+	if want := "auth blog profile billing"; names(got) != want {
+		t.Fatalf("order = %q, want %q", names(got), want)
+	}
+}
+
+func TestOrder_CycleAndUnknown(t *testing.T) {
+	got, err := order(reg(
+		stub{bare("a"), []string{"b"}},
+		stub{bare("b"), []string{"a"}},
+		stub{bare("c"), []string{"ghost"}},
+	))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	for _, s := range []string{"cycle among: a, b", `unregistered "ghost"`} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("err = %v, want it to mention %s", err, s)
+		}
+	}
+	// Everything is still returned: c (unknown dep ignored), then the cycle.
+	if want := "c a b"; names(got) != want {
+		t.Fatalf("order = %q, want %q", names(got), want)
+	}
+}
+
+// withRegistry swaps in an empty registry for the test.
+func withRegistry(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	saved, savedDup, savedPanic := registry, duplicates, PanicOnDuplicate
+	registry, duplicates = map[string]Component{}, nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		registry, duplicates, PanicOnDuplicate = saved, savedDup, savedPanic
+		mu.Unlock()
+	})
+}
+
+func TestRegister_DuplicateKeepsFirst(t *testing.T) {
+	withRegistry(t)
+	PanicOnDuplicate = false
+	first := bare("auth")
+	Register(first)
+	Register(stub{bare: "auth"})
+
+	if got := All(); len(got) != 1 || got[0] != Component(first) {
+		t.Fatalf("registry = %v, want the first auth", got)
+	}
+	d := Duplicates()
+	if len(d) != 1 || d[0].Name != "auth" ||
+		d[0].Kept != "github.com/yanizio/adept/internal/component.bare" ||
+		d[0].Rejected != "github.com/yanizio/adept/internal/component.stub" {
+		t.Fatalf("duplicates = %+v", d)
+	}
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	withRegistry(t)
+	PanicOnDuplicate = true
+	Register(bare("auth"))
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, `"auth" registered twice`) || !strings.Contains(msg, "component.stub") {
+			t.Fatalf("panic = %q", msg)
+		}
+	}()
+	Register(stub{bare: "auth"})
+}
@@ -0,0 +1,136 @@
+// internal/component/routes.go
+//
+// Component mount prefixes, Component middleware, and route-collision
+// detection.
+//
+// Context
+// -------
+// Components mount their Routes() at “/” unless they implement Prefixer,
+// in which case the tenant router mounts them at Prefix() (“/auth”).  Two
+// root-mounted Components that both register GET /login end up in one
+// route tree and the later one wins, so Collisions lists every method and
+// path claimed by more than one Component; cmd/web logs them at boot.
+//
+// A Component that implements MiddlewareProvider gets its Middlewares()
+// wrapped around every one of its routes by the tenant router, so a
+// Component that needs, say, auth on everything does not have to repeat
+// it inside Routes().
+//
+// Notes
+// -----
+// • Paths are compared after the prefix is applied, so /auth + /login and a
+//   root-mounted /auth/login collide too.
+// • Two Components with the same non-root prefix collide as a whole; the
+//   tenant router mounts only the first (see tenant/mount.go).
+// • Oxford commas, two spaces after periods.
+
+package component
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Prefixer is optional.  Prefix returns the path the Component's Routes()
+// are mounted under.  "" or "/" mounts at the root.
+type Prefixer interface {
+	Prefix() string
+}
+
+// PrefixOf returns c's mount prefix, normalised by NormalizePrefix.
+func PrefixOf(c Component) string {
+	p, ok := c.(Prefixer)
+	if !ok {
+		return "/"
+	}
+	return NormalizePrefix(p.Prefix())
+}
+
+// MiddlewareProvider is optional.  Middlewares returns middleware the
+// tenant router applies around the Component's mount, first entry
+// outermost.  They run after the tenant-wide middleware (alias rewrite,
+// request info, and CORS) and before any middleware the Component's own
+// Routes() router registers.  See tenant/mount.go for how unmatched paths
+// are treated.
+type MiddlewareProvider interface {
+	Middlewares() []func(http.Handler) http.Handler
+}
+
+// MiddlewaresOf returns c's Middlewares(), or nil.
+func MiddlewaresOf(c Component) []func(http.Handler) http.Handler {
+	if p, ok := c.(MiddlewareProvider); ok {
+		return p.Middlewares()
+	}
+	return nil
+}
+
+// NormalizePrefix turns "auth", "/auth/", and "/auth" into "/auth", and ""
+// into "/".
+func NormalizePrefix(p string) string {
+	return "/" + strings.Trim(p, "/")
+}
+
+// MountAll is the Method of a Collision between two Components that share
+// a prefix.
+const MountAll = "MOUNT"
+
+// Collision is one method and path registered by more than one Component.
+type Collision struct {
+	Method     string
+	Path       string
+	Components []string // in mount order; the last one serves the route
+}
+
+// Collisions walks the routes of comps, in the order given, and returns
+// every method and full path claimed more than once, sorted by path.
+func Collisions(comps []Component) []Collision {
+	return CollisionsWith(comps, PrefixOf)
+}
+
+// CollisionsWith is Collisions with the mount prefix of each Component
+// chosen by prefixOf, e.g. a per-tenant override.
+func CollisionsWith(comps []Component, prefixOf func(Component) string) []Collision {
+	type key struct{ method, path string }
+	owners := map[key][]string{}
+	var order []key
+	claim := func(k key, name string) {
+		if _, seen := owners[k]; !seen {
+			order = append(order, k)
+		}
+		owners[k] = append(owners[k], name)
+	}
+
+	for _, c := range comps {
+		prefix := prefixOf(c)
+		if prefix != "/" {
+			claim(key{MountAll, prefix}, c.Name())
+		}
+		_ = chi.Walk(c.Routes(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			claim(key{method, joinPath(prefix, route)}, c.Name())
+			return nil
+		})
+	}
+
+	var out []Collision
+	for _, k := range order {
+		if names := owners[k]; len(names) > 1 {
+			out = append(out, Collision{Method: k.method, Path: k.path, Components: names})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// joinPath appends route to prefix without doubling the slash.
+func joinPath(prefix, route string) string {
+	if prefix == "/" {
+		return route
+	}
+	if route == "/" {
+		return prefix
+	}
+	return prefix + route
+}
@@ -0,0 +1,64 @@
+// internal/component/routes_test.go
+//
+// Unit-tests for mount prefixes and route-collision detection.
+
+package component
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routed registers GET on each path, under an optional prefix.
+type routed struct {
+	bare
+	prefix string
+	paths  []string
+}
+
+func (r routed) Prefix() string { return r.prefix }
+func (r routed) Routes() chi.Router {
+	m := chi.NewRouter()
+	for _, p := range r.paths {
+		m.Get(p, func(http.ResponseWriter, *http.Request) {})
+	}
+	return m
+}
+
+func TestPrefixOf(t *testing.T) {
+	for in, want := range map[string]string{"": "/", "/": "/", "auth": "/auth", "/auth/": "/auth", "/a/b": "/a/b"} {
+		if got := PrefixOf(routed{bare: "x", prefix: in}); got != want {
+			t.Errorf("PrefixOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := PrefixOf(bare("plain")); got != "/" {
+		t.Errorf("PrefixOf(no Prefixer) = %q", got)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	got := Collisions([]Component{
+		routed{bare: "auth", paths: []string{"/login", "/logout"}},
+		routed{bare: "sso", paths: []string{"/login"}},
+		routed{bare: "blog", prefix: "/auth", paths: []string{"/login"}},
+		routed{bare: "admin", paths: []string{"/auth/login"}},
+		routed{bare: "shop", prefix: "/auth", paths: []string{"/cart"}},
+	})
+	if len(got) != 3 {
+		t.Fatalf("collisions = %+v, want 3", got)
+	}
+	want := []Collision{
+		{MountAll, "/auth", []string{"blog", "shop"}},
+		{http.MethodGet, "/auth/login", []string{"blog", "admin"}},
+		{http.MethodGet, "/login", []string{"auth", "sso"}},
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Method != w.Method || g.Path != w.Path || len(g.Components) != 2 ||
+			g.Components[0] != w.Components[0] || g.Components[1] != w.Components[1] {
+			t.Errorf("collision %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// internal/component/sitemap.go
+//
+// Sitemap contributions from Components.
+//
+// Context
+// -------
+// internal/sitemap serves /sitemap.xml for every tenant.  Aliases from
+// route_alias are listed automatically; content that has no alias (blog
+// posts, product pages) is listed by the Component that owns it, through
+// the optional SitemapProvider interface.  Only Components enabled for the
+// tenant are asked.
+//
+// Notes
+// -----
+// • Loc is normally the absolute component path ("/blog/post/42"); the
+//   sitemap prints it in its public form (routing.PublicPath) on the
+//   tenant's host.  A full URL is kept only when it is on that host.
+// • Oxford commas, two spaces after periods.
+
+package component
+
+import (
+	"context"
+	"time"
+)
+
+// SitemapEntry is one <url> in a tenant's sitemap.
+type SitemapEntry struct {
+	Loc        string    // "/blog/post/42" or a full URL on the tenant's host
+	LastMod    time.Time // zero: omitted
+	ChangeFreq string    // "daily", "weekly", …; "" omitted
+	Priority   float64   // 0.1–1.0; 0 omitted
+}
+
+// SitemapProvider is optional.  SitemapEntries lists the Component's pages
+// for one tenant.  An error drops this Component's entries from the build
+// and is logged; the rest of the sitemap is still served.
+type SitemapProvider interface {
+	SitemapEntries(ctx context.Context, t TenantInfo) ([]SitemapEntry, error)
+}
@@ -3,6 +3,8 @@ package component
 
 import (
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
 	"github.com/yanizio/adept/internal/theme"
 	"github.com/yanizio/adept/internal/vault"
 )
@@ -12,5 +14,6 @@ type TenantInfo interface {
 	GetDB() *sqlx.DB
 	GetConfig() map[string]string
 	GetTheme() *theme.Theme
-	GetVault() *vault.Client
+	GetVault() vault.Secrets
+	GetLogger() *zap.SugaredLogger // child logger with "tenant"=host
 }
@@ -4,18 +4,24 @@
 //
 // Context
 // -------
-// `Load()` builds one immutable `Config` struct from three layers (highest
+// `Load()` builds one immutable `Config` struct from four layers (highest
 // precedence last):
 //
 //  1. Optional `.env` file — first `<root>/conf/.env`, then jail-wide fallback.
 //  2. `conf/global.yaml`.
-//  3. Environment variables prefixed `ADEPT_`, where `__` maps to “.”
-//     (e.g., `ADEPT_HTTP__LISTEN_ADDR → http.listen_addr`).
+//  3. Optional `conf/<env>.yaml`, where `<env>` is `ADEPT_ENV` (e.g., "dev",
+//     "staging", "prod").  A missing overlay file is not an error.
+//  4. Environment variables prefixed `ADEPT_`, where `__` maps to “.”
+//     (e.g., `ADEPT_HTTP__LISTEN_ADDR → http.listen_addr`).  Nested keys
+//     work at any depth, and a comma-separated value fills a list field
+//     (`ADEPT_HTTP__TRUSTED_PROXIES="10.0.0.0/8,172.16.0.0/12"`).
 //
 // **Vault integration** — any string value that begins with the prefix
 // `vault:` is treated as a Vault URI of the form
 // `vault:<secret-path>#<key>` and is resolved through `internal/vault.Client`
-// before unmarshalling, so callers stay oblivious.
+// before unmarshalling, so callers stay oblivious.  Without VAULT_ADDR (or
+// with `secrets.provider: local`) the same URIs resolve from environment
+// variables and conf/secrets.dev.yaml instead (secrets.go).
 //
 // Instrumentation
 // ---------------
@@ -24,23 +30,40 @@
 //   - INFO  span  — final “config loaded” with key highlights.
 //   - Logs use the global *sugared* logger (`zap.S()`), so early boot issues
 //     surface even before the file logger is installed.
+//   - Fields tagged `secret:"true"` are masked in every log line and error
+//     (see redact.go).
 //
 // Notes
 // -----
 //   - Oxford commas, two spaces after sentence periods.
-//   - The singleton Vault client fails fast; the binary will refuse to start
-//     if Vault cannot be reached.
+//   - The singleton Vault client fails fast; with the vault provider the
+//     binary will refuse to start if Vault cannot be reached.
+//   - Load and Reload share that one client, so reloads never add renew
+//     goroutines.  CloseVault stops its loop at shutdown.
+//   - Reload re-fetches every Vault value, so a rotated secret lands on the
+//     next reload.  Subscribers and the SIGHUP/file watcher live in
+//     reload.go.
+//   - A Reload after the VAULT_* environment changed (new address, token,
+//     namespace, …) builds a fresh client and only then stops the old one's
+//     renew loop.  If the new client cannot be built, the old one stays.
 package config
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/joho/godotenv"
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/env"
@@ -55,19 +78,79 @@ var current atomic.Pointer[Config]
 
 /*────────────────── singleton Vault client & bootstrap ─────────────────────*/
 
-var vaultCli *adepvault.Client // nil means init failed
+// The singleton is created on first use and shared by every Load and
+// Reload, so repeated reloads never start a second renew loop.  Its loop
+// runs until CloseVault or a reinit; the client owns that lifetime, so ctx
+// only bounds construction.
+var (
+	vaultMu  sync.Mutex
+	vaultCli *adepvault.Client // nil until first use, and after CloseVault
+	vaultEnv string            // vaultParams() the singleton was built from
+)
 
-func ensureVault(ctx context.Context) error {
-	if vaultCli != nil {
-		return nil
+func ensureVault(ctx context.Context) (*adepvault.Client, error) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	params := vaultParams()
+	if vaultCli != nil && params == vaultEnv {
+		return vaultCli, nil
 	}
 
-	cli, err := adepvault.New(ctx, zap.S().Debugf)
+	cli, err := adepvault.New(context.WithoutCancel(ctx), zap.S().Debugf)
 	if err != nil {
-		return err
+		if vaultCli != nil {
+			// Reload with broken parameters: keep serving from the client
+			// that works rather than failing the whole reload.
+			zap.S().Warnw("vault reinit failed – keeping previous client", "err", err)
+			return vaultCli, nil
+		}
+		return nil, err
+	}
+
+	// Swap, then stop the old renew loop.  Callers still holding the old
+	// client can finish their reads: Close only cancels renewal, and the
+	// API client underneath stays usable.
+	if old := vaultCli; old != nil {
+		old.Close()
+		zap.S().Infow("vault client reinitialised after parameter change")
+	}
+	vaultCli, vaultEnv = cli, params
+	return cli, nil
+}
+
+// vaultParams fingerprints the VAULT_* environment the SDK reads its
+// connection settings from.  The token is among them, so only a hash is
+// kept.
+func vaultParams() string {
+	var kv []string
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "VAULT_") {
+			kv = append(kv, e)
+		}
+	}
+	sort.Strings(kv)
+	h := sha256.Sum256([]byte(strings.Join(kv, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+// CloseVault stops the singleton's token-renewal loop and waits, bounded by
+// ctx, for it to exit.  A later Load starts a fresh client.  Call it once
+// at shutdown.
+func CloseVault(ctx context.Context) error {
+	vaultMu.Lock()
+	cli := vaultCli
+	vaultCli, vaultEnv = nil, ""
+	vaultMu.Unlock()
+	if cli == nil {
+		return nil
+	}
+	cli.Close()
+	select {
+	case <-cli.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	vaultCli = cli
-	return nil
 }
 
 /*──────────────────────────── root discovery ───────────────────────────────*/
@@ -102,91 +185,208 @@ func rootDir() string {
 /*─────────────────────────────── loader ───────────────────────────────────*/
 
 // Load reads .env, YAML, env overrides, resolves Vault URIs, validates, and
-// caches Config.  It is safe for concurrent use.
-func Load() (*Config, error) {
-	ctx := context.Background()
+// caches Config.  It is safe for concurrent use.  Vault values may come
+// from the client's cache; Reload always re-fetches them.
+func Load() (*Config, error) { return load(false) }
 
-	// Fail fast if Vault is unreachable.
-	if err := ensureVault(ctx); err != nil {
-		zap.S().Errorw("vault init failed", "err", err)
-		return nil, err
-	}
+// loadMu orders swaps so subscribers see reloads in sequence.
+var loadMu sync.Mutex
+
+// load is Load; fresh bypasses the Vault KV cache.  A failure leaves the
+// current Config in place.
+func load(fresh bool) (*Config, error) {
+	ctx := context.Background()
+	loadMu.Lock()
+	defer loadMu.Unlock()
 
 	root := rootDir()
 	zap.S().Debugw("config root resolved", "root", root)
 
-	// .env (optional, no error if missing)
-	_ = godotenv.Load(filepath.Join(root, "conf", ".env"))
-
-	k := koanf.New(".")
-
-	yamlPath := filepath.Join(root, "conf", "global.yaml")
-	if err := k.Load(file.Provider(yamlPath), yaml.Parser()); err != nil {
-		zap.S().Errorw("config yaml load failed", "file", yamlPath, "err", err)
+	k, err := loadTree(root)
+	if err != nil {
 		return nil, err
 	}
-	zap.S().Debugw("config yaml loaded", "file", yamlPath)
 
-	// Env overrides: ADEPT_HTTP__LISTEN_ADDR → http.listen_addr
-	if err := k.Load(env.Provider("ADEPT_", ".", func(s string) string {
-		return strings.ToLower(strings.ReplaceAll(s, "__", "."))
-	}), nil); err != nil {
-		zap.S().Errorw("config env overlay failed", "err", err)
+	// Vault or env/file secrets (secrets.go); fail fast if unusable.
+	store, sc, err := openSecrets(ctx, root, k)
+	if err != nil {
+		zap.S().Errorw("secrets provider init failed", "provider", sc.Provider, "err", err)
 		return nil, err
 	}
 
 	// Resolve Vault URIs in-place.
-	if err := resolveVaultURIs(ctx, k); err != nil {
+	ttl := vaultTTL
+	if fresh {
+		ttl = 0
+	}
+	if err := resolveVaultURIs(ctx, store, k, ttl); err != nil {
 		zap.S().Errorw("config vault resolve failed", "err", err)
 		return nil, err
 	}
 
+	// Errors past this point may quote resolved values; redactErr masks
+	// every `secret:"true"` field before they are logged or returned.
 	var cfg Config
-	if err := k.Unmarshal("", &cfg); err != nil {
+	if err := unmarshal(k, &cfg); err != nil {
+		err = redactErr(err, k)
 		zap.S().Errorw("config unmarshal failed", "err", err)
 		return nil, err
 	}
 
 	cfg.Paths.Root = root
+	cfg.Secrets = sc
 	if err := validateStruct(&cfg); err != nil {
+		err = redactErr(err, k)
 		zap.S().Errorw("config validation failed", "err", err)
 		return nil, err
 	}
 
-	current.Store(&cfg)
+	old := current.Swap(&cfg)
+	secretStore.Store(&store)
 	zap.S().Infow("config loaded",
 		"listen_addr", cfg.HTTP.ListenAddr,
 		"force_https", cfg.HTTP.ForceHTTPS,
 		"root", cfg.Paths.Root,
+		"secrets", cfg.Secrets.Provider,
 	)
+	zap.S().Debugw("config effective", "config", cfg.Redacted())
+	if old != nil {
+		notify(old, &cfg)
+	}
 	return &cfg, nil
 }
 
+/*──────────────────────────── layer merge ─────────────────────────────────*/
+
+// loadTree merges .env, global YAML, the ADEPT_ENV overlay, and ADEPT_ env
+// overrides into a fresh Koanf instance.  Vault URIs are left untouched so
+// callers decide how to resolve them (Load resolves in-place; CheckVault
+// only probes).
+func loadTree(root string) (*koanf.Koanf, error) {
+	// .env (optional, no error if missing)
+	_ = godotenv.Load(filepath.Join(root, "conf", ".env"))
+
+	k := koanf.New(".")
+
+	yamlPath := filepath.Join(root, "conf", "global.yaml")
+	if err := k.Load(file.Provider(yamlPath), yaml.Parser()); err != nil {
+		zap.S().Errorw("config yaml load failed", "file", yamlPath, "err", err)
+		return nil, err
+	}
+	zap.S().Debugw("config yaml loaded", "file", yamlPath)
+
+	// Environment overlay: conf/<ADEPT_ENV>.yaml, optional.
+	if name := os.Getenv("ADEPT_ENV"); name != "" {
+		if !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid ADEPT_ENV %q (want [a-z0-9_-]+)", name)
+		}
+		envPath := filepath.Join(root, "conf", name+".yaml")
+		switch _, err := os.Stat(envPath); {
+		case err == nil:
+			if err := k.Load(file.Provider(envPath), yaml.Parser()); err != nil {
+				zap.S().Errorw("config env overlay load failed", "file", envPath, "err", err)
+				return nil, err
+			}
+			zap.S().Debugw("config env overlay loaded", "file", envPath)
+		case os.IsNotExist(err):
+			zap.S().Debugw("config env overlay absent", "file", envPath)
+		default:
+			return nil, err
+		}
+	}
+
+	// Env overrides: ADEPT_HTTP__LISTEN_ADDR → http.listen_addr.  The
+	// provider hands the callback the full variable name, prefix included.
+	if err := k.Load(env.Provider(envPrefix, ".", envKey), nil); err != nil {
+		zap.S().Errorw("config env overlay failed", "err", err)
+		return nil, err
+	}
+	return k, nil
+}
+
+// unmarshal decodes the merged tree into cfg.  Env overrides arrive as
+// plain strings, so splitCSVHook turns "a, b" into []string{"a", "b"}
+// whenever the target field is a slice.
+func unmarshal(k *koanf.Koanf, cfg *Config) error {
+	return k.UnmarshalWithConf("", cfg, koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.TextUnmarshallerHookFunc(),
+				splitCSVHook),
+			Result:           cfg,
+			WeaklyTypedInput: true,
+			TagName:          "koanf",
+		},
+	})
+}
+
+// splitCSVHook converts a string into a slice of trimmed, non-empty parts.
+func splitCSVHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Slice {
+		return data, nil
+	}
+	out := []string{}
+	for _, p := range strings.Split(data.(string), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+const envPrefix = "ADEPT_"
+
+// envNameRe keeps ADEPT_ENV a plain file stem (no separators or dots).
+var envNameRe = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// envKey maps ADEPT_COMPONENTS__AUTH__MAX_ATTEMPTS → components.auth.max_attempts.
+func envKey(s string) string {
+	s = strings.TrimPrefix(s, envPrefix)
+	return strings.ToLower(strings.ReplaceAll(s, "__", "."))
+}
+
 /*──────────────────────────── helpers ─────────────────────────────────────*/
 
-func Get() *Config  { return current.Load() }
-func Reload() error { _, err := Load(); return err }
+func Get() *Config { return current.Load() }
+
+// Reload is Load with every Vault URI re-fetched rather than served from
+// the cache.  On failure the previous Config stays active.
+func Reload() error { _, err := load(true); return err }
 
 /*──────────────────── Vault URI resolver ───────────────────────────────────*/
 
-func resolveVaultURIs(ctx context.Context, k *koanf.Koanf) error {
-	const prefix = "vault:"
+const vaultPrefix = "vault:"
 
+// vaultTTL is how long Load reuses a resolved secret.
+const vaultTTL = 10 * time.Minute
+
+// parseVaultURI splits "vault:<path>#<key>" into its two halves.
+func parseVaultURI(val string) (secretPath, field string, err error) {
+	body := strings.TrimPrefix(val, vaultPrefix)
+	parts := strings.SplitN(body, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid vault URI %q (want vault:path#key)", val)
+	}
+	return parts[0], parts[1], nil
+}
+
+// resolveVaultURIs replaces every vault: value in k.  ttl 0 skips the
+// client's cache.
+func resolveVaultURIs(ctx context.Context, vcli adepvault.Secrets, k *koanf.Koanf, ttl time.Duration) error {
 	keys := k.Keys() // snapshot to avoid concurrent mutation
 	for _, key := range keys {
 		val, ok := k.Get(key).(string)
-		if !ok || !strings.HasPrefix(val, prefix) {
+		if !ok || !strings.HasPrefix(val, vaultPrefix) {
 			continue
 		}
 
-		body := strings.TrimPrefix(val, prefix)
-		parts := strings.SplitN(body, "#", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid vault URI %q (want vault:path#key)", val)
+		secretPath, field, err := parseVaultURI(val)
+		if err != nil {
+			return err
 		}
-		secretPath, field := parts[0], parts[1]
 
-		plain, err := vaultCli.GetKV(ctx, secretPath, field, 10*time.Minute)
+		plain, err := vcli.GetKV(ctx, secretPath, field, ttl)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,212 @@
+// internal/config/loader_test.go
+//
+// Unit-tests for the Koanf pipeline: list and map values, and layering.
+//
+// Context
+// -------
+// Each test writes a throw-away conf/global.yaml, sets ADEPT_ overrides,
+// and runs loadTree → unmarshal → validateStruct exactly as Load does,
+// minus Vault.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const baseYAML = `
+http:
+  listen_addr: "127.0.0.1:8080"
+  trusted_proxies:
+    - 10.0.0.0/8
+database:
+  global_dsn: "adept:%s@tcp(127.0.0.1:3306)/adept"
+  global_password: "plain"
+features:
+  new_checkout: false
+components:
+  auth:
+    max_attempts: 5
+`
+
+func loadFrom(t *testing.T, yml string) (*Config, error) {
+	t.Helper()
+	return loadFiles(t, map[string]string{"global.yaml": yml})
+}
+
+// loadFiles writes each name → body under <tmp>/conf and runs the pipeline.
+func loadFiles(t *testing.T, files map[string]string) (*Config, error) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "conf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(root, "conf", name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	k, err := loadTree(root)
+	if err != nil {
+		t.Fatalf("loadTree: %v", err)
+	}
+	var cfg Config
+	if err := unmarshal(k, &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return &cfg, validateStruct(&cfg)
+}
+
+func TestLoad_ListAndMapFromYAML(t *testing.T) {
+	cfg, err := loadFrom(t, baseYAML)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.HTTP.TrustedProxies, []string{"10.0.0.0/8"}) {
+		t.Fatalf("trusted_proxies = %v", cfg.HTTP.TrustedProxies)
+	}
+	if v, ok := cfg.Features["new_checkout"]; !ok || v {
+		t.Fatalf("features = %v", cfg.Features)
+	}
+	if got := cfg.Component("auth")["max_attempts"]; got != 5 {
+		t.Fatalf("components.auth.max_attempts = %v (%T)", got, got)
+	}
+}
+
+func TestLoad_EnvOverridesNestedKeys(t *testing.T) {
+	t.Setenv("ADEPT_HTTP__TRUSTED_PROXIES", "172.16.0.0/12, 192.168.0.0/16")
+	t.Setenv("ADEPT_FEATURES__NEW_CHECKOUT", "true")
+	t.Setenv("ADEPT_COMPONENTS__AUTH__MAX_ATTEMPTS", "10")
+	t.Setenv("ADEPT_COMPONENTS__BLOG__PAGE_SIZE", "20")
+
+	cfg, err := loadFrom(t, baseYAML)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	want := []string{"172.16.0.0/12", "192.168.0.0/16"}
+	if !reflect.DeepEqual(cfg.HTTP.TrustedProxies, want) {
+		t.Fatalf("trusted_proxies = %v, want %v", cfg.HTTP.TrustedProxies, want)
+	}
+	if !cfg.Features["new_checkout"] {
+		t.Fatal("feature flag not overridden")
+	}
+	if got := cfg.Component("auth")["max_attempts"]; got != "10" {
+		t.Fatalf("components.auth.max_attempts = %v", got)
+	}
+	if got := cfg.Component("blog")["page_size"]; got != "20" {
+		t.Fatalf("env-only component block = %v", cfg.Component("blog"))
+	}
+}
+
+func TestLoad_ValidationCoversNewShapes(t *testing.T) {
+	cases := map[string]string{
+		"bad cidr": strings.Replace(baseYAML, "10.0.0.0/8", "not-a-cidr", 1),
+		"bad feature key": strings.Replace(baseYAML,
+			"new_checkout: false", "New-Checkout: false", 1),
+		"bad component key": strings.Replace(baseYAML, "  auth:", "  Auth Module:", 1),
+		"tenant dsn without password": strings.Replace(baseYAML, "database:\n",
+			"database:\n  tenant_dsn: \"{key}@tcp(db:3306)/{key}\"\n", 1),
+	}
+	for name, yml := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadFrom(t, yml); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestLoad_TenantDSNTemplate(t *testing.T) {
+	cfg, err := loadFrom(t, strings.Replace(baseYAML, "database:\n",
+		"database:\n  tenant_dsn: \"{key}:{password}@tcp({db_host})/{key}?tls=true\"\n"+
+			"  tenant_db_host: \"db.internal:3306\"\n", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Database.TenantDSNTemplate(); got != "{key}:{password}@tcp({db_host})/{key}?tls=true" {
+		t.Fatalf("TenantDSNTemplate = %q", got)
+	}
+	if got := cfg.Database.TenantHost(); got != "db.internal:3306" {
+		t.Fatalf("TenantHost = %q", got)
+	}
+
+	var zero Database
+	if zero.TenantDSNTemplate() != DefaultTenantDSN || zero.TenantHost() != DefaultTenantDBHost {
+		t.Fatal("unset tenant DSN fields must fall back to the defaults")
+	}
+}
+
+func TestLoad_TenantCacheTunables(t *testing.T) {
+	cfg, err := loadFrom(t, baseYAML+"tenant:\n  idle_ttl: \"10m\"\n  max_entries: -1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Tenant.CacheIdleTTL(); got != 10*time.Minute {
+		t.Fatalf("CacheIdleTTL = %v", got)
+	}
+	if got := cfg.Tenant.CacheMaxEntries(); got != 0 {
+		t.Fatalf("CacheMaxEntries = %d, want 0 (no cap)", got)
+	}
+	if got := cfg.Tenant.CacheEvictInterval(); got != DefaultTenantEvictInterval {
+		t.Fatalf("CacheEvictInterval = %v, want default", got)
+	}
+
+	var zero Tenant
+	if zero.CacheIdleTTL() != DefaultTenantIdleTTL || zero.CacheMaxEntries() != DefaultTenantMaxEntries {
+		t.Fatal("unset cache tunables must fall back to the defaults")
+	}
+}
+
+func TestLoad_EnvOverlayPrecedence(t *testing.T) {
+	t.Setenv("ADEPT_ENV", "staging")
+	t.Setenv("ADEPT_HTTP__FORCE_HTTPS", "false")
+	overlay := `
+http:
+  listen_addr: "0.0.0.0:9090"
+  force_https: true
+features:
+  new_checkout: true
+`
+	cfg, err := loadFiles(t, map[string]string{"global.yaml": baseYAML, "staging.yaml": overlay})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if cfg.HTTP.ListenAddr != "0.0.0.0:9090" {
+		t.Fatalf("overlay did not beat base: %s", cfg.HTTP.ListenAddr)
+	}
+	if cfg.HTTP.ForceHTTPS {
+		t.Fatal("env var did not beat overlay")
+	}
+	if !cfg.Features["new_checkout"] {
+		t.Fatal("overlay map value lost")
+	}
+	if got := cfg.Component("auth")["max_attempts"]; got != 5 {
+		t.Fatalf("base value lost under overlay: %v", got)
+	}
+}
+
+func TestLoad_EnvOverlayMissingIsFine(t *testing.T) {
+	t.Setenv("ADEPT_ENV", "prod")
+	if _, err := loadFrom(t, baseYAML); err != nil {
+		t.Fatalf("missing overlay should not fail: %v", err)
+	}
+}
+
+func TestLoad_EnvOverlayRejectsPaths(t *testing.T) {
+	t.Setenv("ADEPT_ENV", "../secrets")
+	root := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(root, "conf"), 0o755)
+	_ = os.WriteFile(filepath.Join(root, "conf", "global.yaml"), []byte(baseYAML), 0o644)
+	if _, err := loadTree(root); err == nil {
+		t.Fatal("expected error for ADEPT_ENV with path separators")
+	}
+}
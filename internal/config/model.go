@@ -5,10 +5,11 @@
 // Context
 // -------
 // These structs define the shape of the configuration tree that
-// `internal/config/loader.go` builds from three overlay layers:
+// `internal/config/loader.go` builds from four overlay layers:
 //
 //   • optional `.env`                         – dotenv values,
 //   • `conf/global.yaml`                      – primary static file,
+//   • optional `conf/<ADEPT_ENV>.yaml`        – per-environment overlay,
 //   • `ADEPT_`-prefixed environment overrides – highest precedence.
 //
 // Any value whose string begins with the prefix `vault:` is resolved
@@ -23,18 +24,59 @@
 //   • Struct tags use `koanf:"…"`, not `yaml:"…"`—Koanf ignores `yaml` tags
 //     unless configured otherwise.
 //   • The `Paths` block is filled at runtime; YAML must not try to set it.
+//   • Tag credentials `secret:"true"` so logs and errors mask them
+//     (see redact.go).
 //   • Oxford commas, two spaces after periods.  No em-dash.
 
 package config
 
+import "time"
+
 //
 // HTTP section
 //
 
 // HTTP holds web-server tunables.
+//
+// TrustedProxies lists CIDRs whose forwarding headers are believed.  In
+// YAML it is a sequence; as an env override it is comma-separated:
+//
+//	ADEPT_HTTP__TRUSTED_PROXIES="10.0.0.0/8, 192.168.0.0/16"
 type HTTP struct {
-	ListenAddr string `koanf:"listen_addr" validate:"required,hostname_port"`
-	ForceHTTPS bool   `koanf:"force_https"`
+	ListenAddr     string   `koanf:"listen_addr"     validate:"required,hostname_port"`
+	ForceHTTPS     bool     `koanf:"force_https"`
+	TrustedProxies []string `koanf:"trusted_proxies" validate:"omitempty,dive,cidr"`
+	TLS            TLS      `koanf:"tls"`
+
+	// ShutdownTimeout bounds the drain after SIGTERM ("20s").  Zero means
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration `koanf:"shutdown_timeout" validate:"gte=0"`
+}
+
+// DefaultShutdownTimeout applies when http.shutdown_timeout is unset.
+const DefaultShutdownTimeout = 20 * time.Second
+
+// GracePeriod returns ShutdownTimeout or the default.
+func (h HTTP) GracePeriod() time.Duration {
+	if h.ShutdownTimeout > 0 {
+		return h.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// TLS enables the built-in HTTPS listener.  Leave ListenAddr empty when a
+// proxy terminates TLS.  Either CertFile and KeyFile, or Autocert with a
+// CacheDir, must be set; autocert issues certificates only for hosts in the
+// site table.  CertFile and KeyFile may be `vault:` URIs whose secrets hold
+// the PEM itself.  The plain listener keeps running beside this one.
+// Both are tagged secret for that reason, so logs mask even plain paths.
+type TLS struct {
+	ListenAddr string `koanf:"listen_addr" validate:"omitempty,hostname_port"`
+	CertFile   string `koanf:"cert_file"   validate:"required_with=KeyFile" secret:"true"`
+	KeyFile    string `koanf:"key_file"    validate:"required_with=CertFile" secret:"true"`
+	Autocert   bool   `koanf:"autocert"    validate:"excluded_with=CertFile"`
+	CacheDir   string `koanf:"cache_dir"   validate:"required_if=Autocert true"`
+	Email      string `koanf:"email"       validate:"omitempty,email"`
 }
 
 //
@@ -50,10 +92,233 @@ type HTTP struct {
 //   - *LocalhostAlias* lets dev instances map the host string "localhost"
 //     to a unique schema/user key (default "devlocal") so they do not
 //     collide with production names.
+//   - *Tenant* DSN template (`TenantDSN`) builds each tenant's DSN from the
+//     placeholders {key} (schema and user), {password} (from Vault), and
+//     {db_host}.  {db_host} is the site row's db_host when set, else
+//     `TenantDBHost`, so tenants can live on a remote cluster or on hosts
+//     of their own.
+//   - *GlobalReplicaDSN*, when set, opens a read replica of the global
+//     database (same %s password) for site_config loads and site listings.
+//     Per-tenant replicas come from the site row's db_replica_host.
 type Database struct {
 	GlobalDSN      string `koanf:"global_dsn"      validate:"required"`
-	GlobalPassword string `koanf:"global_password" validate:"required"`
+	GlobalPassword string `koanf:"global_password" validate:"required" secret:"true"`
+	GlobalReplica  string `koanf:"global_replica_dsn" validate:"omitempty"`
 	LocalhostAlias string `koanf:"localhost_alias" validate:"omitempty"`
+	TenantDSN      string `koanf:"tenant_dsn"      validate:"omitempty,contains={key},contains={password}"`
+	TenantDBHost   string `koanf:"tenant_db_host"  validate:"omitempty,hostname_port"`
+}
+
+// Defaults for the tenant DSN; they reproduce the historical hard-coded
+// "{key}:{password}@tcp(127.0.0.1:3306)/{key}?parseTime=true&loc=Local".
+const (
+	DefaultTenantDSN    = "{key}:{password}@tcp({db_host})/{key}?parseTime=true&loc=Local"
+	DefaultTenantDBHost = "127.0.0.1:3306"
+)
+
+// TenantDSNTemplate returns TenantDSN, or DefaultTenantDSN when unset.
+func (d Database) TenantDSNTemplate() string {
+	if d.TenantDSN != "" {
+		return d.TenantDSN
+	}
+	return DefaultTenantDSN
+}
+
+// TenantHost returns TenantDBHost, or DefaultTenantDBHost when unset.
+func (d Database) TenantHost() string {
+	if d.TenantDBHost != "" {
+		return d.TenantDBHost
+	}
+	return DefaultTenantDBHost
+}
+
+//
+// Secrets section
+//
+
+// Secret providers (Secrets.Provider).
+const (
+	ProviderVault = "vault" // HashiCorp Vault at VAULT_ADDR
+	ProviderLocal = "local" // env vars, then File (vault.Local)
+)
+
+// DefaultSecretsFile is the local provider's file, relative to the root.
+const DefaultSecretsFile = "conf/secrets.dev.yaml"
+
+// Secrets chooses where `vault:` URIs and tenant DB passwords resolve.
+// Provider "" picks vault when VAULT_ADDR is set and local otherwise; Load
+// replaces it with the provider actually used.  File is the local
+// provider's YAML (DefaultSecretsFile when empty; relative paths are from
+// the root).  Both are read before any vault: URI is resolved, so neither
+// may itself be one.
+type Secrets struct {
+	Provider string `koanf:"provider" validate:"omitempty,oneof=vault local"`
+	File     string `koanf:"file"`
+}
+
+//
+// Tenant section
+//
+
+// Tenant holds tenant-cache tunables.  Zero values keep the defaults in
+// internal/tenant.
+//
+// NegativeTTL is how long a Host that matched no site is answered with 404
+// from memory before the site table is consulted again ("30s").
+// PollInterval, when set, re-reads site.updated_at on that cadence and
+// drops cached tenants whose row changed ("1m"); zero disables polling.
+// RecheckInterval is how old a cache hit may be before it probes its own
+// site row in the background ("30s"); a negative value disables the probe.
+// IdleTTL, MaxEntries, and EvictInterval size the cache and its evictor;
+// zero keeps the defaults below, and MaxEntries -1 disables LRU eviction.
+type Tenant struct {
+	NegativeTTL     time.Duration `koanf:"negative_ttl"     validate:"gte=0"`
+	PollInterval    time.Duration `koanf:"poll_interval"    validate:"gte=0"`
+	RecheckInterval time.Duration `koanf:"recheck_interval"`
+	IdleTTL         time.Duration `koanf:"idle_ttl"         validate:"gte=0"`
+	MaxEntries      int           `koanf:"max_entries"      validate:"gte=-1"`
+	EvictInterval   time.Duration `koanf:"evict_interval"   validate:"gte=0"`
+}
+
+// Tenant cache defaults, applied when the matching field is zero.
+const (
+	DefaultTenantIdleTTL       = 30 * time.Minute
+	DefaultTenantMaxEntries    = 100
+	DefaultTenantEvictInterval = 5 * time.Minute
+)
+
+// CacheIdleTTL returns IdleTTL or the default.
+func (t Tenant) CacheIdleTTL() time.Duration {
+	if t.IdleTTL > 0 {
+		return t.IdleTTL
+	}
+	return DefaultTenantIdleTTL
+}
+
+// CacheMaxEntries returns MaxEntries, the default when zero, or 0 (no size
+// limit) when -1.
+func (t Tenant) CacheMaxEntries() int {
+	switch {
+	case t.MaxEntries < 0:
+		return 0
+	case t.MaxEntries > 0:
+		return t.MaxEntries
+	}
+	return DefaultTenantMaxEntries
+}
+
+// CacheEvictInterval returns EvictInterval or the default.
+func (t Tenant) CacheEvictInterval() time.Duration {
+	if t.EvictInterval > 0 {
+		return t.EvictInterval
+	}
+	return DefaultTenantEvictInterval
+}
+
+//
+// Admin section
+//
+
+// Admin configures the cross-tenant operator API (GET /admin/tenants and
+// friends).  Token is the bearer token it requires; leave it empty to keep
+// the API unmounted.  Use a vault: URI in production.
+//
+// ListenAddr serves the API on its own plain-HTTP listener, meant for a
+// loopback or private address.  Empty mounts it on the public listeners,
+// where it answers over TLS only so the token never crosses the wire in
+// clear.
+type Admin struct {
+	Token      string `koanf:"token"       validate:"omitempty,min=16" secret:"true"`
+	ListenAddr string `koanf:"listen_addr" validate:"omitempty,hostname_port"`
+}
+
+//
+// UA section
+//
+
+// UA holds User-Agent parsing corrections.
+//
+// DeviceOverrides are applied in order after uasurfer has run; the first
+// pattern (Go regexp) matching the raw header sets Info.Device.
+type UA struct {
+	DeviceOverrides []DeviceOverride `koanf:"device_overrides" validate:"omitempty,dive"`
+}
+
+// DeviceOverride maps a UA pattern to a device class.
+type DeviceOverride struct {
+	Pattern string `koanf:"pattern" validate:"required"`
+	Device  string `koanf:"device"  validate:"required,oneof=Desktop Mobile Tablet Other"`
+}
+
+//
+// Outbound section
+//
+
+// Outbound tunes the shared HTTP client used by webhook-style actions
+// (internal/message).  Zero values keep message.DefaultClientOptions.
+// TLSMinVersion is "1.2" or "1.3".  The Allow*/Deny* fields form the
+// address policy: internal ranges are blocked unless AllowPrivate is set
+// or AllowCIDRs carves them out, and a non-empty AllowHosts restricts
+// requests to those hosts.
+type Outbound struct {
+	Timeout             time.Duration `koanf:"timeout"                 validate:"gte=0"`
+	MaxIdleConns        int           `koanf:"max_idle_conns"          validate:"gte=0"`
+	MaxIdleConnsPerHost int           `koanf:"max_idle_conns_per_host" validate:"gte=0"`
+	MaxRedirects        int           `koanf:"max_redirects"           validate:"gte=-1"`
+	Proxy               string        `koanf:"proxy"                   validate:"omitempty,url"`
+	TLSMinVersion       string        `koanf:"tls_min_version"         validate:"omitempty,oneof=1.2 1.3"`
+	AllowPrivate        bool          `koanf:"allow_private"`
+	AllowHosts          []string      `koanf:"allow_hosts"`
+	AllowCIDRs          []string      `koanf:"allow_cidrs"             validate:"omitempty,dive,cidr"`
+	DenyCIDRs           []string      `koanf:"deny_cidrs"              validate:"omitempty,dive,cidr"`
+}
+
+//
+// Theme section
+//
+
+// Theme holds the default locale-to-theme mapping.  LocaleMap keys are
+// locales ("ar", "he_IL"); values are theme directory names.  Tenants
+// override entries with site_config theme.locale_map (internal/view).
+type Theme struct {
+	LocaleMap map[string]string `koanf:"locale_map"`
+}
+
+//
+// Mail section
+//
+
+// Mail selects where outbound email goes.  Sink "" or "log" uses the
+// transport; "capture" keeps mail in memory and "maildir" files it under
+// Maildir (relative paths are from the Adept root).  Either sink is
+// refused at boot unless ADEPT_ENV is dev, test, or local.  From is the
+// sender the maildir sink renders when a message has none.
+type Mail struct {
+	Sink    string `koanf:"sink"    validate:"omitempty,oneof=log capture maildir"`
+	Maildir string `koanf:"maildir" validate:"required_if=Sink maildir"`
+	From    string `koanf:"from"    validate:"omitempty,email"`
+}
+
+//
+// Log section
+//
+
+// Log holds logger settings.  Level is the minimum level written ("debug",
+// "info", "warn", or "error"); empty means info.  It takes effect on
+// reload without a restart.
+type Log struct {
+	Level string `koanf:"level" validate:"omitempty,oneof=debug info warn error"`
+}
+
+//
+// Reload section
+//
+
+// HotReload controls hot reload.  SIGHUP always reloads; WatchFiles also
+// reloads when conf/global.yaml or the ADEPT_ENV overlay changes on disk.
+// Only read at boot.
+type HotReload struct {
+	WatchFiles bool `koanf:"watch_files"`
 }
 
 //
@@ -73,8 +338,28 @@ type Paths struct {
 
 // Config is the immutable aggregate returned by Load() and cached in an
 // atomic.Pointer for lock-free reads throughout the app lifetime.
+//
+// Features and Components are open-ended maps.  Keys must match config_key
+// (lowercase snake case) because env overrides arrive lowercased:
+//
+//	ADEPT_FEATURES__NEW_CHECKOUT=true           → features.new_checkout
+//	ADEPT_COMPONENTS__AUTH__MAX_ATTEMPTS=10     → components.auth.max_attempts
 type Config struct {
-	HTTP     HTTP     `koanf:"http"`
-	Database Database `koanf:"database"`
-	Paths    Paths    `koanf:"-"` // not loaded from config files
+	HTTP       HTTP                      `koanf:"http"`
+	Database   Database                  `koanf:"database"`
+	Secrets    Secrets                   `koanf:"secrets"`
+	Tenant     Tenant                    `koanf:"tenant"`
+	Admin      Admin                     `koanf:"admin"`
+	UA         UA                        `koanf:"ua"`
+	Theme      Theme                     `koanf:"theme"`
+	Outbound   Outbound                  `koanf:"outbound"`
+	Mail       Mail                      `koanf:"mail"`
+	Log        Log                       `koanf:"log"`
+	Reload     HotReload                 `koanf:"reload"`
+	Features   map[string]bool           `koanf:"features"   validate:"omitempty,dive,keys,config_key,endkeys"`
+	Components map[string]map[string]any `koanf:"components" validate:"omitempty,dive,keys,config_key,endkeys"`
+	Paths      Paths                     `koanf:"-"` // not loaded from config files
 }
+
+// Component returns the settings block for one component, or nil.
+func (c *Config) Component(name string) map[string]any { return c.Components[name] }
@@ -0,0 +1,90 @@
+// internal/config/preflight.go
+//
+// Vault pre-flight for the `check-config` command.
+//
+// Context
+// -------
+// `resolveVaultURIs` stops at the first secret it cannot fetch, which is the
+// right call during boot but turns fixing a fresh deployment into a series
+// of one-secret-per-restart loops.  CheckVault walks the same merged tree,
+// tries *every* `vault:` URI, and returns one VaultRef per URI so operators
+// see all missing or unreadable secrets in a single pass.
+//
+// Notes
+// -----
+//   - Resolved values are discarded immediately; a VaultRef never carries
+//     the secret itself, so the report is safe to print or ship to CI logs.
+//   - Nothing is cached: neither the Config singleton nor the Vault KV cache
+//     (ttl 0) is touched.
+//   - References resolve through the provider Load would pick (secrets.go),
+//     so without Vault the check covers the env and file secrets instead.
+//   - Oxford commas, two spaces after periods.
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VaultRef describes one `vault:` URI found in the merged configuration.
+type VaultRef struct {
+	Key   string // dotted config key, e.g. "database.global_password"
+	URI   string // original value, "vault:<path>#<field>"
+	Path  string // secret path, empty when the URI is malformed
+	Field string // key inside the secret
+	Err   error  // nil when the secret resolved
+}
+
+// OK reports whether the reference resolved successfully.
+func (r VaultRef) OK() bool { return r.Err == nil }
+
+// CheckVault merges every config layer, then attempts to resolve each
+// `vault:` URI independently.  The returned slice is sorted by key.  The
+// error is non-nil when the tree cannot be built, Vault cannot be reached,
+// or at least one reference failed; in the last case the slice still
+// holds the full report.
+func CheckVault(ctx context.Context) ([]VaultRef, error) {
+	root := rootDir()
+	k, err := loadTree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []VaultRef
+	for _, key := range k.Keys() {
+		val, ok := k.Get(key).(string)
+		if !ok || !strings.HasPrefix(val, vaultPrefix) {
+			continue
+		}
+		ref := VaultRef{Key: key, URI: val}
+		ref.Path, ref.Field, ref.Err = parseVaultURI(val)
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Key < refs[j].Key })
+
+	if len(refs) == 0 {
+		return refs, nil
+	}
+	vcli, sc, err := openSecrets(ctx, root, k)
+	if err != nil {
+		return refs, fmt.Errorf("%s secrets init: %w", sc.Provider, err)
+	}
+
+	var failed int
+	for i := range refs {
+		r := &refs[i]
+		if r.Err == nil {
+			// ttl 0 bypasses the client cache; the value is dropped here.
+			_, r.Err = vcli.GetKV(ctx, r.Path, r.Field, 0)
+		}
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return refs, fmt.Errorf("%d of %d vault reference(s) failed", failed, len(refs))
+	}
+	return refs, nil
+}
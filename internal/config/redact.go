@@ -0,0 +1,133 @@
+// internal/config/redact.go
+//
+// Tag-driven masking of secret config values.
+//
+// Context
+// -------
+// Fields tagged `secret:"true"` (passwords, API keys, tokens) must never
+// reach a log line or an error string.  Rather than remembering to skip
+// them at every call site, the model declares them once:
+//
+//	GlobalPassword string `koanf:"global_password" secret:"true"`
+//
+// and three helpers do the rest:
+//
+//   - Config.Redacted – nested map of every field, secrets masked, keyed by
+//     koanf name.  Safe for zap.Any and JSON.
+//   - Config.String   – fmt-friendly form of Redacted, so %v and %+v of a
+//     Config never print a secret.
+//   - redactErr       – scrubs secret values out of unmarshal and
+//     validation errors before they are logged or returned.
+//
+// Notes
+// -----
+// • Empty secrets render as "" so "not set" stays distinguishable from
+//   "set but hidden".
+// • Oxford commas, two spaces after periods.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	koanf "github.com/knadh/koanf/v2"
+)
+
+// mask replaces every non-empty secret value.
+const mask = "******"
+
+// Redacted returns the configuration as a nested map with secrets masked.
+func (c Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(c))
+}
+
+// String implements fmt.Stringer using Redacted.
+func (c Config) String() string { return fmt.Sprint(c.Redacted()) }
+
+func redactStruct(v reflect.Value) map[string]any {
+	t := v.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := fieldName(f)
+		fv := v.Field(i)
+		switch {
+		case f.Tag.Get("secret") == "true":
+			if fv.IsZero() {
+				out[name] = ""
+			} else {
+				out[name] = mask
+			}
+		case fv.Kind() == reflect.Struct:
+			out[name] = redactStruct(fv)
+		default:
+			out[name] = fv.Interface()
+		}
+	}
+	return out
+}
+
+// fieldName prefers the koanf tag, falling back to the Go field name for
+// runtime-only fields such as Paths (`koanf:"-"`).
+func fieldName(f reflect.StructField) string {
+	if n := f.Tag.Get("koanf"); n != "" && n != "-" {
+		return n
+	}
+	return f.Name
+}
+
+// secretKeys lists the dotted koanf paths of every secret field in Config.
+func secretKeys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			n := f.Tag.Get("koanf")
+			if n == "" || n == "-" {
+				continue
+			}
+			switch {
+			case f.Tag.Get("secret") == "true":
+				keys = append(keys, prefix+n)
+			case f.Type.Kind() == reflect.Struct:
+				walk(f.Type, prefix+n+".")
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+// redactedError hides secret values from Error() while keeping the chain
+// intact for errors.Is / errors.As.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactErr replaces every secret value found in k inside err's message.
+func redactErr(err error, k *koanf.Koanf) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	scrubbed := msg
+	for _, key := range secretKeys() {
+		if s := k.String(key); s != "" {
+			scrubbed = strings.ReplaceAll(scrubbed, s, mask)
+		}
+	}
+	if scrubbed == msg {
+		return err
+	}
+	return &redactedError{msg: scrubbed, err: err}
+}
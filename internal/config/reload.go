@@ -0,0 +1,169 @@
+// internal/config/reload.go
+//
+// Hot reload: SIGHUP and file-change triggers, plus change subscribers.
+//
+// Context
+// -------
+// Get() always returns the newest Config, but most subsystems copy values
+// out of it at boot (logger level, tenant cache tunables, the outbound
+// client).  Subscribe lets them react when a reload swaps in a new Config;
+// Watch turns SIGHUP, and optionally edits under conf/, into reloads.
+//
+// Workflow
+// --------
+//  1. main calls `go config.Watch(ctx, cfg.Reload.WatchFiles)` after boot.
+//  2. SIGHUP, or a write to conf/global.yaml or conf/<ADEPT_ENV>.yaml when
+//     file watching is on, calls Reload.  File events are debounced, so an
+//     editor's write-rename-chmod burst reloads once.
+//  3. Reload re-reads every layer and re-fetches Vault values.  A config
+//     that fails to parse or validate is logged and dropped; the previous
+//     Config stays active and no subscriber runs.
+//  4. On success every subscriber is called with (old, new), in the order
+//     they subscribed, on the reloading goroutine.
+//
+// Notes
+// -----
+//   - Subscribers must be quick and must not call Load or Reload.  A
+//     panicking subscriber is logged; the rest still run.
+//   - The boot Load does not notify; there is no old Config to compare.
+//   - conf/.env is not watched: dotenv never overrides variables already
+//     in the environment, so an edit there would not take effect anyway.
+//   - Oxford commas, two spaces after periods.
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// ReloadDebounce is how long Watch waits after the last file event before
+// reloading.
+const ReloadDebounce = 500 * time.Millisecond
+
+/*──────────────────────────── subscribers ─────────────────────────────────*/
+
+type subscriber struct{ fn func(old, new *Config) }
+
+var (
+	subMu sync.Mutex
+	subs  []*subscriber
+)
+
+// Subscribe registers fn to run after every successful reload.  The
+// returned cancel removes it; calling cancel twice is harmless.
+func Subscribe(fn func(old, new *Config)) (cancel func()) {
+	s := &subscriber{fn: fn}
+	subMu.Lock()
+	subs = append(subs, s)
+	subMu.Unlock()
+	return func() {
+		subMu.Lock()
+		defer subMu.Unlock()
+		for i, x := range subs {
+			if x == s {
+				subs = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify runs every subscriber with the swapped pair.
+func notify(old, cfg *Config) {
+	subMu.Lock()
+	list := append([]*subscriber(nil), subs...)
+	subMu.Unlock()
+	for _, s := range list {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					zap.S().Errorw("config subscriber panicked", "panic", r)
+				}
+			}()
+			s.fn(old, cfg)
+		}()
+	}
+}
+
+/*──────────────────────────── triggers ────────────────────────────────────*/
+
+// Watch reloads on SIGHUP, and on edits to the config files when
+// watchFiles is set, until ctx is done.  It returns an error only when the
+// file watcher cannot start.
+func Watch(ctx context.Context, watchFiles bool) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	var names map[string]bool
+	if watchFiles {
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		dir, watched := configFiles()
+		// Watch the directory, not the files: editors that save by rename
+		// would otherwise detach the watch after the first edit.
+		if err := w.Add(dir); err != nil {
+			return err
+		}
+		events, errs, names = w.Events, w.Errors, watched
+		zap.S().Infow("config file watcher started", "dir", dir)
+	}
+
+	debounce := time.NewTimer(ReloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			reloadFrom("sighup")
+		case ev := <-events:
+			if names[filepath.Base(ev.Name)] && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce.Reset(ReloadDebounce)
+			}
+		case err := <-errs:
+			zap.S().Warnw("config file watcher error", "err", err)
+		case <-debounce.C:
+			reloadFrom("file")
+		}
+	}
+}
+
+// reloadFrom runs Reload and logs the outcome.
+func reloadFrom(trigger string) {
+	if err := Reload(); err != nil {
+		zap.S().Errorw("config reload failed – keeping previous config",
+			"trigger", trigger, "err", err)
+		return
+	}
+	zap.S().Infow("config reloaded", "trigger", trigger)
+}
+
+// configFiles returns the conf directory and the file names in it that
+// feed Load.
+func configFiles() (string, map[string]bool) {
+	root := rootDir()
+	if c := Get(); c != nil && c.Paths.Root != "" {
+		root = c.Paths.Root
+	}
+	names := map[string]bool{"global.yaml": true}
+	if env := os.Getenv("ADEPT_ENV"); envNameRe.MatchString(env) {
+		names[env+".yaml"] = true
+	}
+	return filepath.Join(root, "conf"), names
+}
@@ -0,0 +1,178 @@
+// internal/config/reload_test.go
+//
+// Unit-tests for hot reload: subscribers, failed reloads, and the SIGHUP
+// and file triggers.
+//
+// Notes
+// -----
+// • Each test points ADEPT_ROOT at a throw-away tree and runs the real
+//   Load.  Vault is configured but never contacted: no value is a vault:
+//   URI.
+
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reloadRoot writes global.yaml under a temp ADEPT_ROOT, runs the boot
+// Load, and returns a function that rewrites the file.
+func reloadRoot(t *testing.T, yml string) func(string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "conf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(body string) {
+		if err := os.WriteFile(filepath.Join(root, "conf", "global.yaml"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(yml)
+	t.Setenv("ADEPT_ROOT", root)
+	t.Setenv("ADEPT_ENV", "")
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:1")
+	t.Setenv("VAULT_TOKEN", "test-token")
+	t.Cleanup(func() {
+		current.Store(nil)
+		_ = CloseVault(context.Background())
+	})
+
+	current.Store(nil)
+	if _, err := Load(); err != nil {
+		t.Fatalf("boot Load: %v", err)
+	}
+	return write
+}
+
+func TestReload_NotifiesSubscribers(t *testing.T) {
+	write := reloadRoot(t, baseYAML)
+	boot := Get()
+
+	var gotOld, gotNew *Config
+	calls := 0
+	cancel := Subscribe(func(old, cur *Config) { gotOld, gotNew = old, cur; calls++ })
+	defer cancel()
+
+	write(baseYAML + "log:\n  level: debug\n")
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || gotOld != boot || gotNew != Get() {
+		t.Fatalf("calls = %d, old = %p (boot %p), new = %p (current %p)", calls, gotOld, boot, gotNew, Get())
+	}
+	if gotOld.Log.Level != "" || gotNew.Log.Level != "debug" {
+		t.Fatalf("level %q → %q", gotOld.Log.Level, gotNew.Log.Level)
+	}
+
+	cancel()
+	cancel() // harmless
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("cancelled subscriber ran, calls = %d", calls)
+	}
+}
+
+func TestReload_InvalidKeepsPrevious(t *testing.T) {
+	write := reloadRoot(t, baseYAML)
+	boot := Get()
+
+	calls := 0
+	defer Subscribe(func(_, _ *Config) { calls++ })()
+
+	write(baseYAML + "log:\n  level: loud\n")
+	if err := Reload(); err == nil {
+		t.Fatal("invalid config reloaded")
+	}
+	write("http: [not, a, map")
+	if err := Reload(); err == nil {
+		t.Fatal("unparsable config reloaded")
+	}
+	if Get() != boot {
+		t.Fatal("failed reload replaced the active config")
+	}
+	if calls != 0 {
+		t.Fatalf("subscriber ran %d times for failed reloads", calls)
+	}
+}
+
+func TestReload_PanickingSubscriberDoesNotStopOthers(t *testing.T) {
+	reloadRoot(t, baseYAML)
+
+	ran := false
+	defer Subscribe(func(_, _ *Config) { panic("boom") })()
+	defer Subscribe(func(_, _ *Config) { ran = true })()
+
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Fatal("subscriber after a panicking one did not run")
+	}
+}
+
+func TestWatch_SIGHUPAndFileEdit(t *testing.T) {
+	write := reloadRoot(t, baseYAML)
+
+	// Our own registration keeps a stray SIGHUP from killing the test
+	// binary before Watch has registered.
+	guard := make(chan os.Signal, 8)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	reloaded := make(chan *Config, 64)
+	defer Subscribe(func(_, cur *Config) {
+		select {
+		case reloaded <- cur:
+		default:
+		}
+	})()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Watch(ctx, true) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch: %v", err)
+		}
+	}()
+
+	// Watch may not be listening yet, so signal until a reload arrives.
+	deadline := time.After(5 * time.Second)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+hup:
+	for {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		select {
+		case <-reloaded:
+			break hup
+		case <-tick.C:
+		case <-deadline:
+			t.Fatal("SIGHUP did not trigger a reload")
+		}
+	}
+
+	// Watch is running now; one write must be enough.  Reloads from
+	// surplus signals may still arrive first.
+	write(baseYAML + "log:\n  level: warn\n")
+	for {
+		select {
+		case cfg := <-reloaded:
+			if cfg.Log.Level == "warn" {
+				return
+			}
+		case <-deadline:
+			t.Fatal("file edit did not trigger a reload")
+		}
+	}
+}
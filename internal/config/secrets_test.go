@@ -0,0 +1,146 @@
+// internal/config/secrets_test.go
+//
+// Unit-tests for secrets provider selection: one config file resolved
+// through the local provider (file, then env) and through Vault.
+//
+// Notes
+// -----
+// • The Vault case runs against an httptest server that answers the KV v2
+//   read; token renewal calls get 403 and back off.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// secretsYAML is shared by every provider test.
+const secretsYAML = `
+http:
+  listen_addr: "127.0.0.1:8080"
+database:
+  global_dsn: "adept:%s@tcp(127.0.0.1:3306)/adept"
+  global_password: "vault:secret/adept/global/db#password"
+`
+
+const devSecrets = `
+secret/adept/global/db:
+  password: "file-pw"
+secret/adept/tenant/examplecom/db:
+  password: "tenant-pw"
+`
+
+// secretsRoot writes conf/global.yaml, plus conf/secrets.dev.yaml when
+// dev is non-empty, and points ADEPT_ROOT at it with VAULT_ADDR unset.
+func secretsRoot(t *testing.T, dev string) {
+	t.Helper()
+	root := t.TempDir()
+	conf := filepath.Join(root, "conf")
+	if err := os.MkdirAll(conf, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(conf, "global.yaml"), []byte(secretsYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if dev != "" {
+		if err := os.WriteFile(filepath.Join(conf, "secrets.dev.yaml"), []byte(dev), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ADEPT_ROOT", root)
+	t.Setenv("ADEPT_ENV", "")
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "test-token")
+	current.Store(nil)
+	secretStore.Store(nil)
+	t.Cleanup(func() {
+		current.Store(nil)
+		secretStore.Store(nil)
+		_ = CloseVault(context.Background())
+	})
+}
+
+func TestSecrets_LocalFromFile(t *testing.T) {
+	secretsRoot(t, devSecrets)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Secrets.Provider != ProviderLocal || cfg.Database.GlobalPassword != "file-pw" {
+		t.Fatalf("provider %q, password %q", cfg.Secrets.Provider, cfg.Database.GlobalPassword)
+	}
+	pw, err := SecretStore().GetKV(context.Background(), "secret/adept/tenant/examplecom/db", "password", 0)
+	if err != nil || pw != "tenant-pw" {
+		t.Fatalf("tenant lookup = %q, %v", pw, err)
+	}
+}
+
+func TestSecrets_LocalFromEnv(t *testing.T) {
+	secretsRoot(t, "")
+	t.Setenv("SECRET_ADEPT_GLOBAL_DB__PASSWORD", "env-pw")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.GlobalPassword != "env-pw" {
+		t.Fatalf("password %q", cfg.Database.GlobalPassword)
+	}
+}
+
+func TestSecrets_LocalMissingFailsLoad(t *testing.T) {
+	secretsRoot(t, "")
+	if _, err := Load(); err == nil {
+		t.Fatal("unresolvable vault: URI loaded")
+	}
+	if Get() != nil {
+		t.Fatal("failed load installed a config")
+	}
+}
+
+func TestSecrets_VaultProvider(t *testing.T) {
+	secretsRoot(t, devSecrets) // present, but Vault must win
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/adept/global/db" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data":     map[string]any{"password": "vault-pw"},
+				"metadata": map[string]any{"version": 1},
+			},
+		})
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Secrets.Provider != ProviderVault || cfg.Database.GlobalPassword != "vault-pw" {
+		t.Fatalf("provider %q, password %q", cfg.Secrets.Provider, cfg.Database.GlobalPassword)
+	}
+}
+
+func TestSecrets_ExplicitLocalIgnoresVaultAddr(t *testing.T) {
+	secretsRoot(t, devSecrets)
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:1")
+	t.Setenv("ADEPT_SECRETS__PROVIDER", "local")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Secrets.Provider != ProviderLocal || cfg.Database.GlobalPassword != "file-pw" {
+		t.Fatalf("provider %q, password %q", cfg.Secrets.Provider, cfg.Database.GlobalPassword)
+	}
+}
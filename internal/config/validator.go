@@ -13,7 +13,9 @@
 // fields such as `Database.GlobalDSN` and the newly-added
 // `Database.GlobalPassword`.  Additional custom rules—e.g., “dsn must
 // contain exactly one %s verb” or tenant-name pattern checks—can be
-// registered here as the configuration surface grows.
+// registered here as the configuration surface grows.  `config_key`
+// guards map keys under `features` and `components`, and `dive,cidr`
+// checks every entry of `http.trusted_proxies`.
 //
 // Notes
 // -----
@@ -22,13 +24,29 @@
 
 package config
 
-import "github.com/go-playground/validator/v10"
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
 
 //
 // validator instance (package-level singleton)
 //
 
-var v = validator.New()
+var v = newValidator()
+
+// configKeyRe matches keys that survive the env overlay's lowercasing.
+var configKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func newValidator() *validator.Validate {
+	val := validator.New()
+	// config_key – map keys under features / components.
+	_ = val.RegisterValidation("config_key", func(fl validator.FieldLevel) bool {
+		return configKeyRe.MatchString(fl.Field().String())
+	})
+	return val
+}
 
 //
 // public API
@@ -0,0 +1,98 @@
+// internal/config/vault_test.go
+//
+// Unit-tests for the shared Vault client used by Load and Reload.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEnsureVault_SingletonAcrossReloads(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:1") // renew attempts fail fast
+	t.Setenv("VAULT_TOKEN", "test-token")
+	t.Cleanup(func() { _ = CloseVault(context.Background()) })
+
+	first, err := ensureVault(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ { // each Load/Reload calls ensureVault
+		cli, err := ensureVault(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cli != first {
+			t.Fatal("reload created a second client and renew loop")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := CloseVault(ctx); err != nil {
+		t.Fatalf("CloseVault: %v", err)
+	}
+	select {
+	case <-first.Done():
+	default:
+		t.Fatal("renew loop still running after CloseVault")
+	}
+
+	next, err := ensureVault(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == first {
+		t.Fatal("ensureVault after CloseVault returned the closed client")
+	}
+}
+
+func TestEnsureVault_ReinitOnParameterChange(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:1")
+	t.Setenv("VAULT_TOKEN", "test-token")
+	t.Cleanup(func() { _ = CloseVault(context.Background()) })
+
+	first, err := ensureVault(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New address: a fresh client replaces the old, whose loop stops.
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:2")
+	second, err := ensureVault(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Fatal("address change did not reinitialise the client")
+	}
+	select {
+	case <-first.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("old renew loop still running after reinit")
+	}
+
+	// A caller still holding the old client gets an error, not a panic.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := first.GetKV(ctx, "secret/x", "k", 0); err == nil {
+		t.Fatal("GetKV against a closed port succeeded")
+	}
+
+	// Parameters the SDK rejects: the working client is kept.
+	t.Setenv("VAULT_MAX_RETRIES", "not-a-number")
+	kept, err := ensureVault(context.Background())
+	if err != nil {
+		t.Fatalf("failed reinit surfaced an error: %v", err)
+	}
+	if kept != second {
+		t.Fatal("failed reinit replaced the working client")
+	}
+	select {
+	case <-second.Done():
+		t.Fatal("failed reinit stopped the working client's renew loop")
+	default:
+	}
+}
@@ -0,0 +1,73 @@
+// internal/database/database_test.go
+//
+// Unit-tests for the tenant registry: Conn and its read mirror ConnRead.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestConnRead_FallsBackToPrimary(t *testing.T) {
+	primary, replica := &sqlx.DB{}, &sqlx.DB{}
+	RegisterTenant("a.example", primary)
+	t.Cleanup(func() {
+		regMu.Lock()
+		delete(tenantDB, "a.example")
+		delete(tenantReadDB, "a.example")
+		regMu.Unlock()
+	})
+
+	ctx := WithTenant(context.Background(), "a.example")
+	if got := ConnRead(ctx); got != primary {
+		t.Fatal("ConnRead without replica should return the primary")
+	}
+
+	RegisterTenantReplica("a.example", replica)
+	if got := ConnRead(ctx); got != replica {
+		t.Fatal("ConnRead should prefer the tenant replica")
+	}
+	if got := Conn(ctx); got != primary {
+		t.Fatal("Conn must keep returning the primary")
+	}
+
+	RegisterTenantReplica("a.example", nil)
+	if got := ConnRead(ctx); got != primary {
+		t.Fatal("removing the replica should restore the primary")
+	}
+}
+
+func TestConnRead_DefaultReplica(t *testing.T) {
+	primary, replica := &sqlx.DB{}, &sqlx.DB{}
+	InitDefault(primary)
+	t.Cleanup(func() { InitDefault(nil); InitDefaultReplica(nil) })
+
+	if got := ConnRead(context.Background()); got != primary {
+		t.Fatal("ConnRead without replica should return the default DB")
+	}
+	InitDefaultReplica(replica)
+	if got := ConnRead(context.Background()); got != replica {
+		t.Fatal("ConnRead should prefer the default replica")
+	}
+}
+
+func TestUnregisterTenant_KeepsNewerPool(t *testing.T) {
+	old, fresh, replica := &sqlx.DB{}, &sqlx.DB{}, &sqlx.DB{}
+	ctx := WithTenant(context.Background(), "b.example")
+
+	RegisterTenant("b.example", old)
+	RegisterTenant("b.example", fresh) // reload registered its pool first
+	RegisterTenantReplica("b.example", replica)
+	UnregisterTenant("b.example", old) // then the old Tenant closed
+	if Conn(ctx) != fresh || ConnRead(ctx) != replica {
+		t.Fatal("closing the old pool dropped the reloaded tenant's entry")
+	}
+
+	UnregisterTenant("b.example", fresh)
+	if Conn(ctx) != nil || ConnRead(ctx) != nil {
+		t.Fatal("UnregisterTenant left the pools registered")
+	}
+}
@@ -9,10 +9,19 @@
 //
 // Key additions
 //   •  Registry of tenant IDs → *sqlx.DB, protected by sync.RWMutex.
-//   •  RegisterTenant(id, db) for boot-time wiring.
+//   •  RegisterTenant(id, db) for boot-time wiring; UnregisterTenant when
+//      the pool closes.
 //   •  WithTenant(ctx, id) helper embeds the tenant string in context.
 //   •  Conn(ctx) fetches the DB for ctx’s tenant, falling back to defaultDB.
 //   •  InitDefault(dsn) one-liner opens a default (global) connection.
+//   •  ConnRead(ctx) mirrors Conn for read-only queries, preferring a replica
+//      registered with RegisterTenantReplica or InitDefaultReplica.
+//
+// Replica staleness
+//   •  Replicas lag the primary by the replication delay (usually
+//      milliseconds, occasionally seconds).  Use ConnRead only for reads
+//      that tolerate that: listings, lookups, reports.  Read-your-writes
+//      flows (insert, then select the new row) must stay on Conn.
 //
 // Oxford commas, two-space sentence spacing, concise inline notes.
 //
@@ -158,6 +167,9 @@ var (
 	regMu     sync.RWMutex
 	tenantDB  = make(map[string]*sqlx.DB)
 	defaultDB *sqlx.DB
+
+	tenantReadDB  = make(map[string]*sqlx.DB)
+	defaultReadDB *sqlx.DB
 )
 
 type ctxKey struct{}
@@ -167,6 +179,15 @@ func WithTenant(ctx context.Context, tenantID string) context.Context {
 	return context.WithValue(ctx, ctxKey{}, tenantID)
 }
 
+// TenantID returns the tenant ctx was tagged with by WithTenant.
+func TenantID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok
+}
+
 // RegisterTenant associates tenantID with db.  Call once during tenant
 // bootstrap (e.g., after migrations).
 func RegisterTenant(tenantID string, db *sqlx.DB) {
@@ -175,9 +196,37 @@ func RegisterTenant(tenantID string, db *sqlx.DB) {
 	tenantDB[tenantID] = db
 }
 
+// UnregisterTenant forgets tenantID's pools, but only while db is still
+// the registered primary: a reloaded tenant registers its new pool before
+// the old one closes, and the old Close must not drop the new entry.
+func UnregisterTenant(tenantID string, db *sqlx.DB) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	if tenantDB[tenantID] != db {
+		return
+	}
+	delete(tenantDB, tenantID)
+	delete(tenantReadDB, tenantID)
+}
+
+// RegisterTenantReplica associates tenantID with a read-replica pool.
+// Passing nil removes it, so ConnRead falls back to the primary.
+func RegisterTenantReplica(tenantID string, db *sqlx.DB) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	if db == nil {
+		delete(tenantReadDB, tenantID)
+		return
+	}
+	tenantReadDB[tenantID] = db
+}
+
 // InitDefault sets the global fallback connection used when ctx has no tenant.
 func InitDefault(db *sqlx.DB) { defaultDB = db }
 
+// InitDefaultReplica sets the global read replica; nil disables it.
+func InitDefaultReplica(db *sqlx.DB) { defaultReadDB = db }
+
 // Conn returns the *sqlx.DB for the current tenant, or defaultDB when the
 // context is not tenant-scoped.  It returns a zero sqlx.DB pointer if neither
 // is registered, allowing callers to check for nil.
@@ -193,6 +242,27 @@ func Conn(ctx context.Context) *sqlx.DB {
 	return defaultDB
 }
 
+// ConnRead is Conn for read-only queries: it returns the replica registered
+// for ctx's tenant (or the global replica), else whatever Conn returns.
+// Results may lag recent writes; see "Replica staleness" above.
+func ConnRead(ctx context.Context) *sqlx.DB {
+	if ctx != nil {
+		if id, ok := ctx.Value(ctxKey{}).(string); ok {
+			regMu.RLock()
+			db := tenantReadDB[id]
+			regMu.RUnlock()
+			if db != nil {
+				return db
+			}
+			return Conn(ctx)
+		}
+	}
+	if defaultReadDB != nil {
+		return defaultReadDB
+	}
+	return Conn(ctx)
+}
+
 //
 // Utility shim for sql.DB compat callers (rare)
 //
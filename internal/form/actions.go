@@ -6,7 +6,25 @@
 //   A FormDef may contain default actions.  ExecuteActions dispatches to
 //   runEmail, runStore, runWebhook, or runPDF (stub) after validation.  Each
 //   helper queues work via Adept’s messaging subsystem so HTTP requests return
-//   promptly.
+//   promptly.  Webhooks go out inline through the shared message.HTTPClient,
+//   bounded by its timeout and by the submitting request's context.  The
+//   client's address policy (message/ssrf.go) refuses internal targets.
+//
+// Workflow
+//   •  Actions run in YAML order, and by default each runs whatever
+//      happened before it.  `on_error: stop` skips everything after a
+//      failure; `depends_on` skips one action when a named earlier action
+//      failed or was skipped.  RunActions returns the outcome as an
+//      *ActionsError; ExecuteActions only logs it.
+//   •  runStore also writes the submitter's IP and UA when the form opts in
+//      with `audit: true` (audit.go).
+//   •  ExecuteActionsSync (sync.go) runs the same pipeline for JSON APIs:
+//      webhooks go out inline even with a queue installed, and each
+//      action's output (inserted ID, webhook status) is returned.
+//   •  Email actions carry a dedup key built from the submission key, so a
+//      retried send reaches the inbox once.  One with a `list` is bulk
+//      mail: one message per recipient with List-Unsubscribe headers
+//      (message/unsubscribe.go).  Without one it is transactional.
 //
 // Style
 //   Two-space sentence spacing, Oxford comma, concise inline notes.
@@ -18,7 +36,10 @@ package form
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -27,41 +48,190 @@ import (
 	"github.com/yanizio/adept/internal/database"
 	"github.com/yanizio/adept/internal/logger"
 	"github.com/yanizio/adept/internal/message"
+	"github.com/yanizio/adept/internal/requestinfo"
+)
+
+// ActionCtx carries request-scoped helpers for action execution.  Info is
+// the submitting request's metadata, nil outside HTTP; audited forms
+// record it (audit.go).  SubmissionKey identifies one logical submission
+// (see submissionKey); email actions derive their dedup key from it, and
+// an empty key turns dedup off.
+type ActionCtx struct {
+	Ctx           context.Context
+	Info          *requestinfo.RequestInfo
+	SubmissionKey string
+
+	action    int            // index of the running action in fd.Actions
+	spamScore *float64       // set when the form is scored (spam.go)
+	sync      bool           // ExecuteActionsSync: no queue, collect output
+	output    map[string]any // the running action's output when sync
+}
+
+// record stores one output value of the running action (sync runs only).
+func (a ActionCtx) record(key string, v any) {
+	if a.output != nil {
+		a.output[key] = v
+	}
+}
+
+// Action failure policies (ActionDef.OnError).
+const (
+	OnErrorContinue = "continue" // default: later actions still run
+	OnErrorStop     = "stop"     // skip every later action
 )
 
-// ActionCtx carries request-scoped helpers for action execution.
-type ActionCtx struct{ Ctx context.Context }
+// ActionResult is the outcome of one action.  Err is nil on success;
+// Skipped is set when a stop or a failed dependency kept it from running.
+// Output is what the action produced, filled by ExecuteActionsSync only.
+type ActionResult struct {
+	Name    string
+	Type    string
+	Err     error
+	Skipped bool
+	Output  map[string]any
+}
+
+// ActionsError summarises the actions that failed or were skipped.
+type ActionsError struct {
+	FormID  string
+	Results []ActionResult // every action, in YAML order
+}
+
+func (e *ActionsError) Error() string {
+	var failed, skipped []string
+	for _, r := range e.Results {
+		switch {
+		case r.Skipped:
+			skipped = append(skipped, r.Name)
+		case r.Err != nil:
+			failed = append(failed, fmt.Sprintf("%s: %v", r.Name, r.Err))
+		}
+	}
+	msg := fmt.Sprintf("form %s: %d action(s) failed", e.FormID, len(failed))
+	if len(failed) > 0 {
+		msg += " (" + strings.Join(failed, "; ") + ")"
+	}
+	if len(skipped) > 0 {
+		msg += ", skipped " + strings.Join(skipped, ", ")
+	}
+	return msg
+}
+
+// Unwrap exposes the individual action errors to errors.Is and As.
+func (e *ActionsError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
 
 // ExecuteActions performs all YAML-declared actions.  Errors are logged but not
-// returned, keeping user flow uninterrupted.
+// returned, keeping user flow uninterrupted.  A submission scoring at or
+// above the form's spam threshold is quarantined instead (spam.go).
 func ExecuteActions(formID string, data map[string]any, actx ActionCtx) {
+	_ = RunActions(formID, data, actx)
+}
+
+// RunActions is ExecuteActions for callers that want the outcome: nil
+// when every action ran and succeeded, else an *ActionsError.
+//
+// Actions run in YAML order.  One with depends_on is skipped unless every
+// earlier action of those names succeeded; a failure in an action with
+// on_error: stop skips all that follow.  Failures are logged either way.
+func RunActions(formID string, data map[string]any, actx ActionCtx) error {
+	_, err := runActions(formID, data, actx)
+	if errors.Is(err, ErrQuarantined) {
+		return nil
+	}
+	return err
+}
+
+// runActions is RunActions returning every result, and ErrQuarantined
+// for a submission diverted by the spam screen.
+func runActions(formID string, data map[string]any, actx ActionCtx) ([]ActionResult, error) {
 	fd, ok := GetFormDef(formID)
 	if !ok || len(fd.Actions) == 0 {
-		return
+		return nil, nil
+	}
+	if screenSpam(fd, data, &actx) {
+		return nil, ErrQuarantined
 	}
 
-	for _, ac := range fd.Actions {
-		switch ac.Type {
-		case "email":
-			if err := runEmail(fd, ac.Params, data, actx); err != nil {
-				logErr(actx, fd.ID, "email", err)
-			}
-		case "store":
-			if err := runStore(fd, ac.Params, data, actx); err != nil {
-				logErr(actx, fd.ID, "store", err)
-			}
-		case "webhook":
-			if err := runWebhook(fd, ac.Params, data, actx); err != nil {
-				logErr(actx, fd.ID, "webhook", err)
-			}
-		case "pdf":
-			if err := runPDF(fd, ac.Params, data, actx); err != nil {
-				logErr(actx, fd.ID, "pdf", err)
-			}
-		default:
+	results := make([]ActionResult, len(fd.Actions))
+	failed := make(map[string]bool) // names that failed or were skipped
+	stopped, bad := false, false
+	for i, ac := range fd.Actions {
+		actx.action = i
+		r := &results[i]
+		r.Name, r.Type = ac.Name(), ac.Type
+
+		if stopped || anyFailed(failed, ac.DependsOn) {
+			r.Skipped, bad = true, true
+			failed[r.Name] = true
+			logWarn(actx, fd.ID, ac.Type, "skipped: "+skipReason(stopped))
+			continue
+		}
+
+		if actx.sync {
+			actx.output = make(map[string]any)
+			r.Output = actx.output
+		}
+		r.Err = runAction(fd, ac, data, actx)
+		if errors.Is(r.Err, errUnsupportedAction) {
+			r.Err = nil // the loader already warned; not a failure
 			logWarn(actx, fd.ID, ac.Type, "unsupported action")
+			continue
+		}
+		if r.Err == nil {
+			continue
+		}
+		bad = true
+		failed[r.Name] = true
+		logErr(actx, fd.ID, ac.Type, r.Err)
+		if ac.OnError == OnErrorStop {
+			stopped = true
+		}
+	}
+	if !bad {
+		return results, nil
+	}
+	return results, &ActionsError{FormID: fd.ID, Results: results}
+}
+
+// runAction dispatches one action by type.
+func runAction(fd *FormDef, ac ActionDef, data map[string]any, actx ActionCtx) error {
+	switch ac.Type {
+	case "email":
+		return runEmail(fd, ac.Params, data, actx)
+	case "store":
+		return runStore(fd, ac.Params, data, actx)
+	case "webhook":
+		return runWebhook(fd, ac.Params, data, actx)
+	case "pdf":
+		return runPDF(fd, ac.Params, data, actx)
+	}
+	return errUnsupportedAction
+}
+
+var errUnsupportedAction = errors.New("unsupported action")
+
+func anyFailed(failed map[string]bool, deps []string) bool {
+	for _, d := range deps {
+		if failed[d] {
+			return true
 		}
 	}
+	return false
+}
+
+func skipReason(stopped bool) string {
+	if stopped {
+		return "an earlier action failed with on_error: stop"
+	}
+	return "a dependency failed"
 }
 
 // -----------------------------------------------------------------------------
@@ -92,13 +262,75 @@ func runEmail(fd *FormDef, p map[string]any, data map[string]any, actx ActionCtx
 		subject = fmt.Sprintf("Adept form submission: %s", fd.Title)
 	}
 
+	from, _ := p["from"].(string)
+	envelope, _ := p["envelope_from"].(string)
 	body, _ := json.MarshalIndent(data, "", "  ")
 	msg := message.Email{
-		To:      to,
-		Subject: subject,
-		Text:    string(body),
+		From:         from,
+		EnvelopeFrom: envelope,
+		To:           to,
+		Subject:      subject,
+		Text:         string(body),
+		DedupKey:     emailDedupKey(fd.ID, actx),
+	}
+
+	list, _ := p["list"].(string)
+	if list == "" {
+		if err := message.EnqueueEmail(actx.Ctx, msg); err != nil {
+			return err // transactional
+		}
+		actx.record("queued", 1)
+		return nil
 	}
-	return message.EnqueueEmail(actx.Ctx, msg)
+	return enqueueBulk(actx, p, list, msg)
+}
+
+// enqueueBulk sends msg once per recipient, each with its own signed
+// unsubscribe link.  Params: "unsubscribe_url" (https endpoint),
+// "unsubscribe_mailto", and "one_click" (default true with a URL).
+func enqueueBulk(actx ActionCtx, p map[string]any, list string, msg message.Email) error {
+	base, _ := p["unsubscribe_url"].(string)
+	mailto, _ := p["unsubscribe_mailto"].(string)
+	if base == "" && mailto == "" {
+		return fmt.Errorf("list %q needs 'unsubscribe_url' or 'unsubscribe_mailto'", list)
+	}
+	oneClick := base != ""
+	if v, ok := p["one_click"].(bool); ok {
+		oneClick = v && base != ""
+	}
+
+	key := msg.DedupKey
+	for _, rcpt := range msg.To {
+		m := msg
+		m.To = []string{rcpt}
+		m.Unsubscribe = &message.Unsubscribe{Mailto: mailto, OneClick: oneClick}
+		if base != "" {
+			u, err := message.UnsubscribeURL(base, rcpt, list)
+			if err != nil {
+				return err
+			}
+			m.Unsubscribe.URL = u
+		}
+		if key != "" {
+			m.DedupKey = key + ":" + strings.ToLower(rcpt)
+		}
+		if err := message.EnqueueEmail(actx.Ctx, m); err != nil {
+			return err
+		}
+	}
+	actx.record("queued", len(msg.To))
+	return nil
+}
+
+// emailDedupKey names one email action of one submission, so a retried
+// send is suppressed while other submissions and other email actions of
+// the same form are not.  "" without a submission key.
+func emailDedupKey(formID string, actx ActionCtx) string {
+	if actx.SubmissionKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", formID, actx.SubmissionKey, actx.action)))
+	return "form:" + hex.EncodeToString(sum[:])
 }
 
 // -----------------------------------------------------------------------------
@@ -117,14 +349,29 @@ func runStore(fd *FormDef, p map[string]any, data map[string]any, actx ActionCtx
 		return err
 	}
 
-	_, err = db.ExecContext(
+	res, err := db.ExecContext(
 		actx.Ctx,
 		fmt.Sprintf(`INSERT INTO %s (form_id, submitted_at, data) VALUES ($1,$2,$3)`, table),
 		fd.ID,
 		time.Now().UTC(),
 		j,
 	)
-	return err
+	if err != nil || (!fd.Audit && !actx.sync) {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("insert id: %w", err)
+	}
+	actx.record("id", id)
+	actx.record("table", table)
+	if !fd.Audit {
+		return nil
+	}
+	if err := storeAudit(actx, db, table, id); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
 }
 
 // -----------------------------------------------------------------------------
@@ -156,7 +403,14 @@ func runWebhook(fd *FormDef, p map[string]any, data map[string]any, actx ActionC
 			req.Header.Set(strings.TrimPrefix(k, "header."), fmt.Sprint(v))
 		}
 	}
-	return message.EnqueueWebhook(actx.Ctx, req)
+	if !actx.sync {
+		return message.EnqueueWebhook(actx.Ctx, req)
+	}
+	status, err := message.SendWebhook(actx.Ctx, req)
+	if status != 0 {
+		actx.record("status", status)
+	}
+	return err
 }
 
 // -----------------------------------------------------------------------------
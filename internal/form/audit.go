@@ -0,0 +1,89 @@
+// internal/form/audit.go
+//
+// Adept – Forms subsystem: submission audit metadata.
+//
+// Context
+//   A stored submission says what was sent but not who sent it, which is
+//   what abuse investigations and data-subject requests need.  A form with
+//   `audit: true` gets a companion form_submission_meta row for every store
+//   action: the submitter's IP, UA family, and request timestamp, all taken
+//   from requestinfo, plus the spam score when the form is scored.  The
+//   submission table itself is unchanged.
+//
+// Rules
+//   •  Opt-in only.  IP addresses are personal data, so nothing is captured
+//      unless the form's YAML says `audit: true`.
+//   •  The meta row is written after the submission row and references it
+//      by table name and id.  A failed meta insert is logged as a store
+//      error; the submission row is kept.
+//   •  No request info (e.g. ExecuteActions called outside HTTP) means no
+//      meta row.
+//
+// Retention
+//   Meta rows should not outlive their purpose.  Tenants that enable audit
+//   are expected to schedule PurgeAudit, which deletes rows older than the
+//   cutoff; AuditRetention is the suggested default.  Purging meta leaves
+//   the submissions themselves in place.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package form
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditRetention is the suggested lifetime of form_submission_meta rows.
+const AuditRetention = 90 * 24 * time.Hour
+
+// auditTable holds the per-submission audit metadata.
+const auditTable = "form_submission_meta"
+
+// storeAudit records actx's request info for the row id just inserted into
+// table.
+func storeAudit(actx ActionCtx, db *sqlx.DB, table string, id int64) error {
+	info := actx.Info
+	if info == nil {
+		return nil
+	}
+	var ip any // NULL when the middleware found no address
+	if info.Geo.IP != nil {
+		ip = info.Geo.IP.String()
+	}
+	ts := info.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	var score any // NULL when the form is not spam-scored
+	if actx.spamScore != nil {
+		score = *actx.spamScore
+	}
+	_, err := db.ExecContext(
+		actx.Ctx,
+		`INSERT INTO `+auditTable+` (submission_table, submission_id, ip, ua_family, submitted_at, spam_score) VALUES ($1,$2,$3,$4,$5,$6)`,
+		table,
+		id,
+		ip,
+		info.UA.Browser,
+		ts.UTC(),
+		score,
+	)
+	return err
+}
+
+// PurgeAudit deletes audit rows recorded before cutoff and returns how many
+// went.
+func PurgeAudit(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM `+auditTable+` WHERE submitted_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,101 @@
+// internal/form/audit_test.go
+//
+// Unit-tests for submission audit metadata.
+
+package form
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/database"
+	"github.com/yanizio/adept/internal/requestinfo"
+	"github.com/yanizio/adept/internal/ua"
+)
+
+// auditCtx registers a mock tenant DB and returns an ActionCtx bound to it.
+func auditCtx(t *testing.T) (ActionCtx, sqlmock.Sqlmock) {
+	t.Helper()
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sqlx.NewDb(raw, "mysql")
+	database.RegisterTenant(t.Name(), db)
+	t.Cleanup(func() { database.UnregisterTenant(t.Name(), db) })
+
+	info := &requestinfo.RequestInfo{
+		UA:        ua.Info{Browser: "Firefox"},
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	info.Geo.IP = net.ParseIP("203.0.113.7")
+	return ActionCtx{Ctx: database.WithTenant(context.Background(), t.Name()), Info: info}, mock
+}
+
+func TestRunStore_AuditOn(t *testing.T) {
+	actx, mock := auditCtx(t)
+	fd := &FormDef{ID: "audit/on", Audit: true}
+
+	mock.ExpectExec("INSERT INTO form_submission ").
+		WillReturnResult(sqlmock.NewResult(41, 1))
+	mock.ExpectExec("INSERT INTO form_submission_meta").
+		WithArgs("form_submission", int64(41), "203.0.113.7", "Firefox", actx.Info.Timestamp, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err := runStore(fd, nil, map[string]any{"a": "b"}, actx); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunStore_AuditOff(t *testing.T) {
+	actx, mock := auditCtx(t)
+	fd := &FormDef{ID: "audit/off"}
+
+	mock.ExpectExec("INSERT INTO form_submission ").
+		WillReturnResult(sqlmock.NewResult(42, 1))
+
+	if err := runStore(fd, nil, map[string]any{"a": "b"}, actx); err != nil {
+		t.Fatal(err)
+	}
+	// An unexpected meta insert would have failed runStore above.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunStore_AuditWithoutRequestInfo(t *testing.T) {
+	actx, mock := auditCtx(t)
+	actx.Info = nil
+	fd := &FormDef{ID: "audit/noinfo", Audit: true}
+
+	mock.ExpectExec("INSERT INTO form_submission ").
+		WillReturnResult(sqlmock.NewResult(43, 1))
+
+	if err := runStore(fd, nil, map[string]any{}, actx); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeAudit(t *testing.T) {
+	actx, mock := auditCtx(t)
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM form_submission_meta WHERE submitted_at <").
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	n, err := PurgeAudit(actx.Ctx, database.Conn(actx.Ctx), cutoff)
+	if err != nil || n != 7 {
+		t.Fatalf("PurgeAudit = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,68 @@
+// internal/form/bulk_test.go
+//
+// Unit-tests for bulk (list) email actions.
+
+package form
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/yanizio/adept/internal/message"
+)
+
+// captureQueue records published jobs.
+type captureQueue struct{ jobs []message.Job }
+
+func (q *captureQueue) Publish(_ context.Context, j message.Job) error {
+	q.jobs = append(q.jobs, j)
+	return nil
+}
+
+func TestRunEmail_Bulk(t *testing.T) {
+	t.Setenv("ADEPT_UNSUBSCRIBE_KEY", base64.RawURLEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	q := &captureQueue{}
+	message.SetQueue(q)
+	t.Cleanup(func() { message.SetQueue(nil) })
+
+	fd := &FormDef{ID: "news/signup"}
+	actx := ActionCtx{Ctx: context.Background(), SubmissionKey: "k"}
+	p := map[string]any{
+		"to":              []any{"a@example.com", "b@example.com"},
+		"from":            "News <news@example.com>",
+		"list":            "digest",
+		"unsubscribe_url": "https://example.com/unsubscribe",
+	}
+	if err := runEmail(fd, p, map[string]any{}, actx); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.jobs) != 2 {
+		t.Fatalf("jobs = %d, want one per recipient", len(q.jobs))
+	}
+	a, b := q.jobs[0].Email, q.jobs[1].Email
+	if a.Unsubscribe == nil || !a.Unsubscribe.OneClick || a.Unsubscribe.URL == b.Unsubscribe.URL {
+		t.Fatalf("unsubscribe = %+v / %+v", a.Unsubscribe, b.Unsubscribe)
+	}
+	if a.From != "News <news@example.com>" || a.DedupKey == b.DedupKey {
+		t.Errorf("From = %q, dedup keys %q / %q", a.From, a.DedupKey, b.DedupKey)
+	}
+
+	// Transactional: no list, one message, no headers.
+	q.jobs = nil
+	delete(p, "list")
+	if err := runEmail(fd, p, map[string]any{}, actx); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.jobs) != 1 || q.jobs[0].Email.Unsubscribe != nil {
+		t.Fatalf("transactional jobs = %+v", q.jobs)
+	}
+
+	// A list without an unsubscribe target is refused.
+	p["list"] = "digest"
+	delete(p, "unsubscribe_url")
+	if err := runEmail(fd, p, map[string]any{}, actx); err == nil {
+		t.Fatal("bulk email without unsubscribe accepted")
+	}
+}
@@ -0,0 +1,243 @@
+// internal/form/check.go
+//
+// Adept – Forms subsystem: offline validation of every form definition.
+//
+// Context
+//   RegisterForms stops at the first bad YAML, and only at server boot.
+//   CheckForms walks the same directories the same way but loads each file
+//   on its own, never touches the registry, and reports every error and
+//   warning with its file and line.  `adept forms validate` prints the
+//   report and exits non-zero on any error, so CI and deploy hooks can gate
+//   on it.
+//
+// Workflow
+//   •  LoadFormDef decodes into a yaml.Node first, so indexLines can record
+//      where the document, each field, and each action start.
+//   •  Validation failures become *DefError{Path, Line, Msg}; YAML syntax
+//      and type errors take their line from the yaml.v3 message.
+//   •  Validation keeps going after a problem, so one run lists every bad
+//      field, group, and action in a file (DefErrors).
+//   •  Unknown action types are warnings: they never fail a check.
+//
+// Style
+//   Comments follow Adept’s guide: full sentences, two spaces after periods,
+//   Oxford commas, and clear roles.  Helper comments use short noun phrases.
+//
+//------------------------------------------------------------------------------
+
+package form
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefError is one problem in a form definition file.  Line is 1-based; 0
+// means the line is unknown.
+type DefError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *DefError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// DefErrors is every problem found in one form definition, in file order.
+// errors.As reaches each *DefError through Unwrap.
+type DefErrors []*DefError
+
+func (es DefErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (es DefErrors) Unwrap() []error {
+	out := make([]error, len(es))
+	for i, e := range es {
+		out[i] = e
+	}
+	return out
+}
+
+// CheckReport is the result of CheckForms.
+type CheckReport struct {
+	Files    int         // YAML files examined
+	Errors   []*DefError // problems that would stop RegisterForms
+	Warnings []*DefError // unknown action types and the like
+}
+
+// OK reports whether no file failed.
+func (r *CheckReport) OK() bool { return len(r.Errors) == 0 }
+
+// CheckForms loads every “*.yaml” under “<base>/components/” for each base,
+// as RegisterForms does, and reports all problems instead of stopping at
+// the first.  Missing directories are skipped, as in RegisterForms.
+func CheckForms(baseDirs []string) *CheckReport {
+	rep := &CheckReport{}
+	for _, base := range baseDirs {
+		root := filepath.Join(base, "components")
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				if !errors.Is(walkErr, fs.ErrNotExist) {
+					rep.Errors = append(rep.Errors, &DefError{Path: path, Msg: walkErr.Error()})
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+				return nil
+			}
+
+			rep.Files++
+			_, warnings, err := loadFormDef(path)
+			rep.Warnings = append(rep.Warnings, warnings...)
+			var des DefErrors
+			var de *DefError
+			switch {
+			case err == nil:
+			case errors.As(err, &des):
+				rep.Errors = append(rep.Errors, des...)
+			case errors.As(err, &de):
+				rep.Errors = append(rep.Errors, de)
+			default:
+				rep.Errors = append(rep.Errors, &DefError{Path: path, Msg: err.Error()})
+			}
+			return nil
+		})
+		if err != nil {
+			rep.Errors = append(rep.Errors, &DefError{Path: root, Msg: err.Error()})
+		}
+	}
+	return rep
+}
+
+// -----------------------------------------------------------------------------
+// Line helpers
+// -----------------------------------------------------------------------------
+
+var yamlLineRE = regexp.MustCompile(`line (\d+)`)
+
+// yamlError turns a yaml.v3 error into a *DefError, taking the first line
+// number the message mentions.
+func yamlError(path string, err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	line := 0
+	if m := yamlLineRE.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+	}
+	return &DefError{Path: path, Line: line, Msg: "parse YAML: " + msg}
+}
+
+// defLines records where the parts of one form definition start.
+type defLines struct {
+	root    int
+	fields  []int   // top-level fields
+	steps   [][]int // steps[si][fi]
+	groups  []int   // top-level groups
+	sgroups [][]int // step groups, sgroups[si][gi]
+	actions []int
+}
+
+// field returns the line of fields[fi], or of steps[si].fields[fi] when si
+// is not negative, falling back to the document line.
+func (l defLines) field(si, fi int) int {
+	list := l.fields
+	if si >= 0 {
+		list = nil
+		if si < len(l.steps) {
+			list = l.steps[si]
+		}
+	}
+	if fi < len(list) {
+		return list[fi]
+	}
+	return l.root
+}
+
+// group returns the line of groups[gi], or of steps[si].groups[gi] when si
+// is not negative, falling back to the document line.
+func (l defLines) group(si, gi int) int {
+	list := l.groups
+	if si >= 0 {
+		list = nil
+		if si < len(l.sgroups) {
+			list = l.sgroups[si]
+		}
+	}
+	if gi >= 0 && gi < len(list) {
+		return list[gi]
+	}
+	return l.root
+}
+
+// action returns the line of actions[i], falling back to the document line.
+func (l defLines) action(i int) int {
+	if i < len(l.actions) {
+		return l.actions[i]
+	}
+	return l.root
+}
+
+// indexLines walks a decoded document and fills defLines.
+func indexLines(doc *yaml.Node) defLines {
+	var l defLines
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return l
+	}
+	top := doc.Content[0]
+	l.root = top.Line
+	seqLines := func(n *yaml.Node) []int {
+		if n == nil {
+			return nil
+		}
+		out := make([]int, 0, len(n.Content))
+		for _, item := range n.Content {
+			out = append(out, item.Line)
+		}
+		return out
+	}
+	for k, v := range mapping(top) {
+		switch k {
+		case "fields":
+			l.fields = seqLines(v)
+		case "actions":
+			l.actions = seqLines(v)
+		case "groups":
+			l.groups = seqLines(v)
+		case "steps":
+			for _, step := range v.Content {
+				m := mapping(step)
+				l.steps = append(l.steps, seqLines(m["fields"]))
+				l.sgroups = append(l.sgroups, seqLines(m["groups"]))
+			}
+		}
+	}
+	return l
+}
+
+// mapping returns a mapping node's values by key.  Non-mapping nodes yield
+// an empty map.
+func mapping(n *yaml.Node) map[string]*yaml.Node {
+	out := map[string]*yaml.Node{}
+	if n == nil || n.Kind != yaml.MappingNode {
+		return out
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		out[n.Content[i].Value] = n.Content[i+1]
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+// internal/form/check_test.go
+//
+// Unit-tests for CheckForms over a fixture tree.
+
+package form
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeForm(t *testing.T, root, rel, body string) string {
+	t.Helper()
+	p := filepath.Join(root, "components", filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCheckForms_ReportsEveryProblemWithLines(t *testing.T) {
+	root := t.TempDir()
+	writeForm(t, root, "auth/forms/login.yaml", `id: auth/login
+fields:
+  - name: email
+    label: Email
+    type: email
+actions:
+  - type: store
+  - type: fax
+`)
+	bad := writeForm(t, root, "shop/forms/order.yaml", `id: shop/order
+fields:
+  - name: qty
+    label: Quantity
+    type: number
+  - name: note
+    label: Note
+`)
+	broken := writeForm(t, root, "blog/forms/comment.yaml", "id: blog/comment\nfields: [\n")
+	writeForm(t, root, "blog/forms/README.md", "not a form")
+
+	rep := CheckForms([]string{root, filepath.Join(root, "missing")})
+
+	if rep.Files != 3 || rep.OK() || len(rep.Errors) != 2 {
+		t.Fatalf("report = %d files, errors %v", rep.Files, rep.Errors)
+	}
+	byPath := map[string]*DefError{}
+	for _, e := range rep.Errors {
+		byPath[e.Path] = e
+	}
+	if e := byPath[bad]; e == nil || e.Line != 6 || e.Msg != "field 'note' missing 'type'" {
+		t.Fatalf("bad field error = %v", e)
+	}
+	if e := byPath[broken]; e == nil || e.Line == 0 || !strings.HasPrefix(e.Msg, "parse YAML") {
+		t.Fatalf("syntax error = %v", e)
+	}
+
+	if len(rep.Warnings) != 1 || rep.Warnings[0].Line != 8 ||
+		!strings.Contains(rep.Warnings[0].Error(), "unrecognized action type 'fax'") {
+		t.Fatalf("warnings = %v", rep.Warnings)
+	}
+
+	// The registry is untouched.
+	if _, ok := GetFormDef("auth/login"); ok {
+		t.Fatal("CheckForms registered a form")
+	}
+}
+
+func TestCheckForms_EveryErrorInAFile(t *testing.T) {
+	root := t.TempDir()
+	p := writeForm(t, root, "shop/forms/order.yaml", `id: shop/order
+fields:
+  - name: qty
+    label: Quantity
+  - name: note
+    type: text
+groups:
+  - {fields: [qty]}
+actions:
+  - {type: store, on_error: later}
+`)
+	rep := CheckForms([]string{root})
+
+	var got []string
+	for _, e := range rep.Errors {
+		if e.Path != p {
+			t.Fatalf("error for %s, want %s", e.Path, p)
+		}
+		got = append(got, fmt.Sprintf("%d: %s", e.Line, e.Msg))
+	}
+	want := []string{
+		"3: field 'qty' missing 'type'",
+		"5: field 'note' missing 'label'",
+		"8: group 'group1' missing 'legend'",
+		"10: action 0: on_error must be 'continue' or 'stop', not 'later'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,35 @@
+// internal/form/dedup_test.go
+//
+// Unit-tests for submission keys and email dedup keys.
+
+package form
+
+import "testing"
+
+func TestEmailDedupKey(t *testing.T) {
+	data := map[string]any{"name": "Ada", "msg": "hi"}
+	k := submissionKey("tok1", data)
+
+	if submissionKey("tok1", map[string]any{"msg": "hi", "name": "Ada"}) != k {
+		t.Error("same render and values should share a submission key")
+	}
+	if submissionKey("tok1", map[string]any{"name": "Ada", "msg": "bye"}) == k {
+		t.Error("edited resubmit shares the key")
+	}
+	if submissionKey("tok2", data) == k {
+		t.Error("another render shares the key")
+	}
+
+	a := ActionCtx{SubmissionKey: k}
+	b := a
+	b.action = 1
+	if emailDedupKey("f", a) == emailDedupKey("f", b) {
+		t.Error("two email actions share a dedup key")
+	}
+	if emailDedupKey("f", a) == emailDedupKey("g", a) {
+		t.Error("two forms share a dedup key")
+	}
+	if emailDedupKey("f", ActionCtx{}) != "" {
+		t.Error("no submission key should mean no dedup")
+	}
+}
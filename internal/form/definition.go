@@ -12,12 +12,15 @@
 //   registry by ID, guaranteeing a single source of truth.
 //
 // Workflow
-//   •  Structs mirror the YAML schema: FormDef → StepDef → FieldDef / ActionDef.
+//   •  Structs mirror the YAML schema: FormDef → StepDef → FieldDef / GroupDef /
+//      ActionDef.
 //   •  LoadFormDef parses a single YAML file and validates structural rules.
 //   •  RegisterForms walks one or more base directories, discovers YAMLs,
 //      loads them via LoadFormDef, and adds them to the registry, respecting
 //      tenant-level override precedence.
 //   •  GetFormDef offers safe, read-only access to a parsed form by ID.
+//   •  CheckForms (check.go) runs the same loader over every file without
+//      registering anything and reports all problems with file and line.
 //
 // Style
 //   Comments follow Adept’s guide: full sentences, two spaces after periods,
@@ -55,7 +58,21 @@ type FormDef struct {
 	Title   string      `yaml:"title"`   // Display title, optional.
 	Fields  []FieldDef  `yaml:"fields"`  // Flat list of fields (single-step).
 	Steps   []StepDef   `yaml:"steps"`   // Multi-step definition.  Mutually exclusive with Fields.
+	Groups  []GroupDef  `yaml:"groups"`  // Fieldsets over Fields.  Steps carry their own.
 	Actions []ActionDef `yaml:"actions"` // Post-submit actions.  May be empty.
+
+	// Body limits in bytes; 0 inherits the tenant or package default
+	// (limits.go).
+	MaxBytes  int64 `yaml:"max_bytes"`  // Whole request body.
+	MaxMemory int64 `yaml:"max_memory"` // Multipart parts held in memory.
+
+	// Audit records the submitter's IP, UA family, and timestamp with each
+	// stored submission (audit.go).  Off by default: that is personal data.
+	Audit bool `yaml:"audit"`
+
+	// SpamThreshold diverts submissions whose spam score reaches it to
+	// form_quarantine (spam.go).  0 turns scoring off.
+	SpamThreshold float64 `yaml:"spam_threshold"`
 }
 
 // FieldDef describes a single input control on the form.  Validation metadata
@@ -79,6 +96,16 @@ type StepDef struct {
 	ID     string     `yaml:"id"`    // Unique per form.  If blank, we derive one.
 	Title  string     `yaml:"title"` // Display heading, optional.
 	Fields []FieldDef `yaml:"fields"`
+	Groups []GroupDef `yaml:"groups"` // Fieldsets over this step's Fields.
+}
+
+// GroupDef wraps a run of fields in a <fieldset> with a <legend>, e.g. an
+// address block.  Grouping is purely presentational: field names,
+// validation, and submitted data are exactly as without it.
+type GroupDef struct {
+	ID     string   `yaml:"id"`     // Unique per form.  If blank, we derive one.
+	Legend string   `yaml:"legend"` // Required; the fieldset's accessible name.
+	Fields []string `yaml:"fields"` // Consecutive field names from the same list.
 }
 
 // ActionDef configures an automated action executed after validation.
@@ -88,6 +115,19 @@ type StepDef struct {
 type ActionDef struct {
 	Type   string         `yaml:"type"`    // email, store, webhook, pdf, etc.
 	Params map[string]any `yaml:",inline"` // Provider-specific fields inline.
+
+	// Ordering and failure policy (actions.go).
+	ID        string   `yaml:"id"`         // Name for depends_on; defaults to Type.
+	OnError   string   `yaml:"on_error"`   // continue (default) or stop.
+	DependsOn []string `yaml:"depends_on"` // Earlier actions that must succeed.
+}
+
+// Name returns the action's depends_on name: its ID, else its Type.
+func (a ActionDef) Name() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return a.Type
 }
 
 // -----------------------------------------------------------------------------
@@ -114,23 +154,38 @@ func GetFormDef(id string) (*FormDef, bool) {
 // -----------------------------------------------------------------------------
 
 // LoadFormDef parses one YAML file, validates its structure, and returns a
-// populated FormDef.  It NEVER mutates the global registry.
+// populated FormDef.  It NEVER mutates the global registry.  Errors are
+// *DefError values carrying the file and, when known, the line.  Warnings
+// (unknown action types) go to stderr; CheckForms collects them instead.
 func LoadFormDef(path string) (*FormDef, error) {
+	fd, warnings, err := loadFormDef(path)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", w)
+	}
+	return fd, err
+}
+
+// loadFormDef is LoadFormDef with warnings returned rather than printed.
+func loadFormDef(path string) (*FormDef, []*DefError, error) {
 	raw, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("read form file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("read form file %s: %w", path, err)
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, yamlError(path, err)
+	}
 	var fd FormDef
-	if err := yaml.Unmarshal(raw, &fd); err != nil {
-		return nil, fmt.Errorf("parse YAML %s: %w", path, err)
+	if err := doc.Decode(&fd); err != nil {
+		return nil, nil, yamlError(path, err)
 	}
 
-	if err := validateFormDef(&fd, path); err != nil {
-		return nil, err
+	warnings, err := validateFormDef(&fd, path, indexLines(&doc))
+	if err != nil {
+		return nil, warnings, err
 	}
-
-	return &fd, nil
+	return &fd, warnings, nil
 }
 
 // RegisterForms walks one or more base directories and loads every “*.yaml”
@@ -187,33 +242,42 @@ func register(fd *FormDef) {
 // -----------------------------------------------------------------------------
 
 // validateFormDef enforces structural rules that cannot be expressed via YAML
-// tags alone.  It returns a descriptive error referencing the offending file.
-func validateFormDef(fd *FormDef, path string) error {
+// tags alone.  It returns every warning and every error, each a *DefError
+// pointing at the offending line; the error is a DefErrors, or nil.
+func validateFormDef(fd *FormDef, path string, lines defLines) ([]*DefError, error) {
+	var errs DefErrors
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, &DefError{Path: path, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
 	if fd.ID == "" {
-		return fmt.Errorf("form definition %s: missing required 'id'", path)
+		fail(lines.root, "missing required 'id'")
+	}
+
+	if fd.MaxBytes < 0 || fd.MaxMemory < 0 {
+		fail(lines.root, "'max_bytes' and 'max_memory' must not be negative")
 	}
 
 	// Either flat fields OR steps, not both.
 	if len(fd.Fields) > 0 && len(fd.Steps) > 0 {
-		return fmt.Errorf("form definition %s: cannot have both 'fields' and 'steps'", path)
+		fail(lines.root, "cannot have both 'fields' and 'steps'")
 	}
 	if len(fd.Fields) == 0 && len(fd.Steps) == 0 {
-		return fmt.Errorf("form definition %s: must have 'fields' or 'steps'", path)
+		fail(lines.root, "must have 'fields' or 'steps'")
 	}
 
 	// Normalize step IDs if needed and collect field names for uniqueness check.
 	fieldNames := make(map[string]struct{})
 
-	if len(fd.Fields) > 0 {
-		for i := range fd.Fields {
-			if err := validateField(&fd.Fields[i], path); err != nil {
-				return err
-			}
-			if _, dup := fieldNames[fd.Fields[i].Name]; dup {
-				return fmt.Errorf("form %s: duplicate field name '%s'", path, fd.Fields[i].Name)
-			}
-			fieldNames[fd.Fields[i].Name] = struct{}{}
+	for i := range fd.Fields {
+		line := lines.field(-1, i)
+		if msg := validateField(&fd.Fields[i]); msg != "" {
+			fail(line, "%s", msg)
+		}
+		if _, dup := fieldNames[fd.Fields[i].Name]; dup {
+			fail(line, "duplicate field name '%s'", fd.Fields[i].Name)
 		}
+		fieldNames[fd.Fields[i].Name] = struct{}{}
 	}
 
 	for si := range fd.Steps {
@@ -222,18 +286,36 @@ func validateFormDef(fd *FormDef, path string) error {
 			s.ID = fmt.Sprintf("step%d", si+1)
 		}
 		for fi := range s.Fields {
-			if err := validateField(&s.Fields[fi], path); err != nil {
-				return err
+			line := lines.field(si, fi)
+			if msg := validateField(&s.Fields[fi]); msg != "" {
+				fail(line, "%s", msg)
 			}
 			if _, dup := fieldNames[s.Fields[fi].Name]; dup {
-				return fmt.Errorf("form %s: duplicate field name '%s' across steps", path, s.Fields[fi].Name)
+				fail(line, "duplicate field name '%s' across steps", s.Fields[fi].Name)
 			}
 			fieldNames[s.Fields[fi].Name] = struct{}{}
 		}
 	}
 
+	// Groups: top-level ones cover fd.Fields, step ones their step.
+	groupIDs := make(map[string]struct{})
+	if len(fd.Groups) > 0 && len(fd.Steps) > 0 {
+		fail(lines.group(-1, 0), "multi-step forms declare 'groups' per step")
+	} else {
+		validateGroups(fd.Groups, fd.Fields, "group", groupIDs, func(gi int, msg string) {
+			fail(lines.group(-1, gi), "%s", msg)
+		})
+	}
+	for si := range fd.Steps {
+		s := &fd.Steps[si]
+		validateGroups(s.Groups, s.Fields, s.ID+"-group", groupIDs, func(gi int, msg string) {
+			fail(lines.group(si, gi), "step '%s': %s", s.ID, msg)
+		})
+	}
+
 	// Validate actions: ensure known type strings only.  Unknown types are
-	// allowed for forward compatibility but emit a warning so developers notice.
+	// allowed for forward compatibility but produce a warning so developers
+	// notice.
 	validActions := map[string]bool{
 		"email":   true,
 		"store":   true,
@@ -241,39 +323,109 @@ func validateFormDef(fd *FormDef, path string) error {
 		"pdf":     true,
 	}
 
-	for _, ac := range fd.Actions {
+	var warnings []*DefError
+	earlier := make(map[string]bool, len(fd.Actions))
+	for i, ac := range fd.Actions {
 		if !validActions[ac.Type] {
-			fmt.Fprintf(os.Stderr, "WARNING: form %s: unrecognized action type '%s'\n", fd.ID, ac.Type)
+			warnings = append(warnings, &DefError{Path: path, Line: lines.action(i),
+				Msg: fmt.Sprintf("form %s: unrecognized action type '%s'", fd.ID, ac.Type)})
+		}
+		if ac.OnError != "" && ac.OnError != OnErrorContinue && ac.OnError != OnErrorStop {
+			fail(lines.action(i), "action %d: on_error must be 'continue' or 'stop', not '%s'", i, ac.OnError)
 		}
+		for _, dep := range ac.DependsOn {
+			if !earlier[dep] {
+				fail(lines.action(i), "action %d: depends_on '%s' names no earlier action", i, dep)
+			}
+		}
+		earlier[ac.Name()] = true
 	}
 
-	return nil
+	if len(errs) > 0 {
+		return warnings, errs
+	}
+	return warnings, nil
 }
 
-// validateField confirms that essential attributes are present and sane.
-func validateField(f *FieldDef, path string) error {
+// validateField confirms that essential attributes are present and sane.  It
+// returns a description of the first problem, or "".
+func validateField(f *FieldDef) string {
 	if f.Name == "" {
-		return fmt.Errorf("form %s: field missing 'name'", path)
+		return "field missing 'name'"
 	}
 	if f.Label == "" {
-		return fmt.Errorf("form %s: field '%s' missing 'label'", path, f.Name)
+		return fmt.Sprintf("field '%s' missing 'label'", f.Name)
 	}
 	if f.Type == "" {
-		return fmt.Errorf("form %s: field '%s' missing 'type'", path, f.Name)
+		return fmt.Sprintf("field '%s' missing 'type'", f.Name)
 	}
 
 	if f.Pattern != "" {
 		if _, err := regexp.Compile(f.Pattern); err != nil {
-			return fmt.Errorf("form %s: field '%s' invalid regex pattern: %v", path, f.Name, err)
+			return fmt.Sprintf("field '%s' invalid regex pattern: %v", f.Name, err)
 		}
 	}
 
 	if f.MinLength < 0 || f.MaxLength < 0 {
-		return fmt.Errorf("form %s: field '%s' minlength/maxlength cannot be negative", path, f.Name)
+		return fmt.Sprintf("field '%s' minlength/maxlength cannot be negative", f.Name)
 	}
 	if f.MaxLength > 0 && f.MinLength > f.MaxLength {
-		return fmt.Errorf("form %s: field '%s' minlength greater than maxlength", path, f.Name)
+		return fmt.Sprintf("field '%s' minlength greater than maxlength", f.Name)
 	}
 
-	return nil
+	return ""
+}
+
+// validateGroups checks groups against the fields they draw on, deriving
+// blank IDs as prefix1, prefix2, ….  ids collects IDs across the whole form.
+// It calls bad with the index and problem of each bad group, reporting the
+// first problem per group.
+func validateGroups(groups []GroupDef, fields []FieldDef, prefix string, ids map[string]struct{}, bad func(gi int, msg string)) {
+	pos := make(map[string]int, len(fields))
+	for i, f := range fields {
+		pos[f.Name] = i
+	}
+	grouped := make(map[string]string)
+
+	for gi := range groups {
+		if msg := validateGroup(&groups[gi], gi, fields, pos, prefix, ids, grouped); msg != "" {
+			bad(gi, msg)
+		}
+	}
+}
+
+// validateGroup checks groups[gi] and returns its first problem, or "".
+func validateGroup(g *GroupDef, gi int, fields []FieldDef, pos map[string]int, prefix string, ids map[string]struct{}, grouped map[string]string) string {
+	if g.ID == "" {
+		g.ID = fmt.Sprintf("%s%d", prefix, gi+1)
+	}
+	if _, dup := ids[g.ID]; dup {
+		return fmt.Sprintf("duplicate group id '%s'", g.ID)
+	}
+	ids[g.ID] = struct{}{}
+	if g.Legend == "" {
+		return fmt.Sprintf("group '%s' missing 'legend'", g.ID)
+	}
+	if len(g.Fields) == 0 {
+		return fmt.Sprintf("group '%s' has no fields", g.ID)
+	}
+
+	lo, hi := len(fields), -1
+	for _, name := range g.Fields {
+		p, ok := pos[name]
+		if !ok {
+			return fmt.Sprintf("group '%s' names unknown field '%s'", g.ID, name)
+		}
+		if other, dup := grouped[name]; dup && other == g.ID {
+			return fmt.Sprintf("group '%s' lists field '%s' twice", g.ID, name)
+		} else if dup {
+			return fmt.Sprintf("field '%s' is in groups '%s' and '%s'", name, other, g.ID)
+		}
+		grouped[name] = g.ID
+		lo, hi = min(lo, p), max(hi, p)
+	}
+	if hi-lo+1 != len(g.Fields) {
+		return fmt.Sprintf("group '%s' fields must be consecutive in 'fields'", g.ID)
+	}
+	return ""
 }
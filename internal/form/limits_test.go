@@ -0,0 +1,113 @@
+// internal/form/limits_test.go
+//
+// Unit-tests for submission body limits.
+
+package form
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// limitForm registers a one-field form with the given body cap.
+func limitForm(t *testing.T, id string, maxBytes int64) {
+	t.Helper()
+	register(&FormDef{ID: id, MaxBytes: maxBytes,
+		Fields: []FieldDef{{Name: "note", Label: "Note", Type: "textarea"}}})
+}
+
+// submission returns valid hidden fields plus note.
+func submission(t *testing.T, note string) url.Values {
+	t.Helper()
+	tok, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url.Values{
+		"csrf_token": {tok},
+		"render_ts":  {strconv.FormatInt(time.Now().Add(-time.Minute).UnixMicro(), 10)},
+		"note":       {note},
+	}
+}
+
+func postForm(v url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(v.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestHandleSubmit_BodyLimit(t *testing.T) {
+	limitForm(t, "test/limit", 1024)
+
+	data, err := HandleSubmit("test/limit", postForm(submission(t, "short")))
+	if err != nil || data["note"] != "short" {
+		t.Fatalf("under limit = %v, %v", data, err)
+	}
+
+	_, err = HandleSubmit("test/limit", postForm(submission(t, strings.Repeat("x", 2048))))
+	if !IsTooLarge(err) || !IsValidationError(err) {
+		t.Fatalf("over limit err = %v, want a too-large validation error", err)
+	}
+}
+
+func TestHandleSubmit_MultipartLimit(t *testing.T) {
+	limitForm(t, "test/upload", 4096)
+
+	multipartReq := func(size int) *http.Request {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for k, v := range submission(t, "n") {
+			_ = mw.WriteField(k, v[0])
+		}
+		fw, _ := mw.CreateFormFile("file", "a.bin")
+		_, _ = fw.Write(bytes.Repeat([]byte("b"), size))
+		_ = mw.Close()
+		r := httptest.NewRequest(http.MethodPost, "/submit", &buf)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return r
+	}
+
+	if _, err := HandleSubmit("test/upload", multipartReq(512)); err != nil {
+		t.Fatalf("under limit: %v", err)
+	}
+	if _, err := HandleSubmit("test/upload", multipartReq(8192)); !IsTooLarge(err) {
+		t.Fatalf("over limit err = %v", err)
+	}
+}
+
+func TestBodyLimits_Precedence(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if b, m := bodyLimits(nil, r); b != DefaultMaxRequestBytes || m != DefaultMaxMultipartMemory || m > b {
+		t.Fatalf("defaults = %d, %d", b, m)
+	}
+
+	ten := &tenant.Tenant{Config: tenant.SiteConfig{
+		MaxRequestBytesKey: "300", MaxMultipartMemoryKey: "200"}}
+	r = r.WithContext(tenant.WithContext(r.Context(), ten))
+	if b, m := bodyLimits(&FormDef{}, r); b != 300 || m != 200 {
+		t.Fatalf("tenant = %d, %d", b, m)
+	}
+	if b, m := bodyLimits(&FormDef{MaxBytes: 900}, r); b != 900 || m != 200 {
+		t.Fatalf("form over tenant = %d, %d", b, m)
+	}
+	if b, m := bodyLimits(&FormDef{MaxBytes: 100}, r); b != 100 || m != 100 {
+		t.Fatalf("memory above the body cap = %d, %d, want it clamped", b, m)
+	}
+
+	// The tenant default applies to forms without their own limit.
+	limitForm(t, "test/tenantlimit", 0)
+	post := postForm(submission(t, strings.Repeat("x", 400)))
+	post = post.WithContext(tenant.WithContext(post.Context(), ten))
+	if _, err := HandleSubmit("test/tenantlimit", post); !IsTooLarge(err) {
+		t.Fatalf("tenant cap err = %v", err)
+	}
+}
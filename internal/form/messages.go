@@ -0,0 +1,232 @@
+// internal/form/messages.go
+//
+// Adept – Forms subsystem: localized error messages.
+//
+// Context
+//   Validation errors are shown to site visitors, so they must speak the
+//   site's language.  Every user-facing string in validate.go and limits.go
+//   is a message ID looked up in a catalog keyed by locale.  Templates use
+//   fmt verbs; "%[1]d" lets a translation move an argument.
+//
+// Precedence
+//   •  A field's error_msg always wins for that field's errors.
+//   •  Locale: the tenant's Meta.Locale, else the request's Accept-Language
+//      (highest q the catalog knows), else English.
+//   •  Lookup: the exact locale ("fr_ca"), then its language ("fr"), then
+//      English.  A translation missing one ID falls back to English for
+//      that ID only.
+//
+// Notes
+//   •  English, French, German, and Spanish ship built in.  RegisterMessages
+//      adds or overrides a catalog at boot.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package form
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// Message IDs.
+const (
+	MsgRequired         = "required"
+	MsgInvalid          = "invalid"
+	MsgPattern          = "pattern"
+	MsgMinLength        = "min_length"  // %d minimum
+	MsgMaxLength        = "max_length"  // %d maximum
+	MsgUnsupportedType  = "unsupported" // %q field type
+	MsgUnknownForm      = "unknown_form"
+	MsgCSRF             = "csrf"
+	MsgTimestampMissing = "timestamp_missing"
+	MsgTimestampBad     = "timestamp_bad"
+	MsgTooFast          = "too_fast"
+	MsgExpired          = "expired"
+	MsgTooLarge         = "too_large"
+)
+
+// DefaultLocale is the catalog every lookup falls back to.
+const DefaultLocale = "en"
+
+var (
+	catalogMu sync.RWMutex
+	catalogs  = map[string]map[string]string{
+		"en": {
+			MsgRequired:         "This field is required.",
+			MsgInvalid:          "Invalid input.",
+			MsgPattern:          "Input does not match required format.",
+			MsgMinLength:        "Must be at least %d characters.",
+			MsgMaxLength:        "Must be less than %d characters.",
+			MsgUnsupportedType:  "Unsupported field type %q.",
+			MsgUnknownForm:      "Unknown form.",
+			MsgCSRF:             "Security token invalid.  Please refresh and try again.",
+			MsgTimestampMissing: "Timestamp missing.  Please reload the page.",
+			MsgTimestampBad:     "Bad timestamp.  Please retry.",
+			MsgTooFast:          "Form submitted too quickly.  Please enter the fields manually.",
+			MsgExpired:          "Form expired.  Please reload and submit again.",
+			MsgTooLarge:         "Submission is too large.",
+		},
+		"fr": {
+			MsgRequired:         "Ce champ est obligatoire.",
+			MsgInvalid:          "Saisie invalide.",
+			MsgPattern:          "La saisie ne respecte pas le format requis.",
+			MsgMinLength:        "Doit contenir au moins %d caractères.",
+			MsgMaxLength:        "Doit contenir moins de %d caractères.",
+			MsgUnsupportedType:  "Type de champ non pris en charge : %q.",
+			MsgUnknownForm:      "Formulaire inconnu.",
+			MsgCSRF:             "Jeton de sécurité invalide.  Veuillez actualiser la page et réessayer.",
+			MsgTimestampMissing: "Horodatage manquant.  Veuillez recharger la page.",
+			MsgTimestampBad:     "Horodatage incorrect.  Veuillez réessayer.",
+			MsgTooFast:          "Formulaire envoyé trop rapidement.  Veuillez saisir les champs manuellement.",
+			MsgExpired:          "Formulaire expiré.  Veuillez recharger la page et l'envoyer à nouveau.",
+			MsgTooLarge:         "L'envoi est trop volumineux.",
+		},
+		"de": {
+			MsgRequired:         "Dieses Feld ist erforderlich.",
+			MsgInvalid:          "Ungültige Eingabe.",
+			MsgPattern:          "Die Eingabe entspricht nicht dem erforderlichen Format.",
+			MsgMinLength:        "Muss mindestens %d Zeichen lang sein.",
+			MsgMaxLength:        "Muss weniger als %d Zeichen lang sein.",
+			MsgUnsupportedType:  "Nicht unterstützter Feldtyp %q.",
+			MsgUnknownForm:      "Unbekanntes Formular.",
+			MsgCSRF:             "Sicherheitstoken ungültig.  Bitte laden Sie die Seite neu und versuchen Sie es erneut.",
+			MsgTimestampMissing: "Zeitstempel fehlt.  Bitte laden Sie die Seite neu.",
+			MsgTimestampBad:     "Ungültiger Zeitstempel.  Bitte versuchen Sie es erneut.",
+			MsgTooFast:          "Formular zu schnell abgeschickt.  Bitte füllen Sie die Felder manuell aus.",
+			MsgExpired:          "Formular abgelaufen.  Bitte laden Sie die Seite neu und senden Sie es erneut.",
+			MsgTooLarge:         "Die Übermittlung ist zu groß.",
+		},
+		"es": {
+			MsgRequired:         "Este campo es obligatorio.",
+			MsgInvalid:          "Entrada no válida.",
+			MsgPattern:          "La entrada no tiene el formato requerido.",
+			MsgMinLength:        "Debe tener al menos %d caracteres.",
+			MsgMaxLength:        "Debe tener menos de %d caracteres.",
+			MsgUnsupportedType:  "Tipo de campo no admitido: %q.",
+			MsgUnknownForm:      "Formulario desconocido.",
+			MsgCSRF:             "Token de seguridad no válido.  Actualice la página e inténtelo de nuevo.",
+			MsgTimestampMissing: "Falta la marca de tiempo.  Vuelva a cargar la página.",
+			MsgTimestampBad:     "Marca de tiempo incorrecta.  Inténtelo de nuevo.",
+			MsgTooFast:          "Formulario enviado demasiado rápido.  Rellene los campos manualmente.",
+			MsgExpired:          "El formulario ha caducado.  Vuelva a cargar la página y envíelo de nuevo.",
+			MsgTooLarge:         "El envío es demasiado grande.",
+		},
+	}
+)
+
+// RegisterMessages adds msgs to the catalog for locale, replacing IDs it
+// already has.  An ID the English catalog lacks is an error.
+func RegisterMessages(locale string, msgs map[string]string) error {
+	loc := normLocale(locale)
+	if loc == "" {
+		return fmt.Errorf("form messages: empty locale")
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	for id := range msgs {
+		if _, ok := catalogs[DefaultLocale][id]; !ok {
+			return fmt.Errorf("form messages %s: unknown message ID %q", locale, id)
+		}
+	}
+	cat := catalogs[loc]
+	if cat == nil {
+		cat = make(map[string]string, len(msgs))
+		catalogs[loc] = cat
+	}
+	for id, tmpl := range msgs {
+		cat[id] = tmpl
+	}
+	return nil
+}
+
+// Msg formats message id for locale.
+func Msg(locale, id string, args ...any) string {
+	catalogMu.RLock()
+	tmpl, ok := lookupMsg(normLocale(locale), id)
+	catalogMu.RUnlock()
+	if !ok {
+		return id
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
+
+// lookupMsg walks locale → language → English.  Callers hold catalogMu.
+func lookupMsg(loc, id string) (string, bool) {
+	if tmpl, ok := catalogs[loc][id]; ok {
+		return tmpl, true
+	}
+	if lang, _, found := strings.Cut(loc, "_"); found {
+		if tmpl, ok := catalogs[lang][id]; ok {
+			return tmpl, true
+		}
+	}
+	tmpl, ok := catalogs[DefaultLocale][id]
+	return tmpl, ok
+}
+
+// hasCatalog reports whether loc or its language has a catalog.
+func hasCatalog(loc string) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	lang, _, _ := strings.Cut(loc, "_")
+	return catalogs[loc] != nil || catalogs[lang] != nil
+}
+
+// RequestLocale picks the message locale for r (see Precedence).
+func RequestLocale(r *http.Request) string {
+	if t := tenant.FromContext(r.Context()); t != nil && t.Meta.Locale != "" {
+		return normLocale(t.Meta.Locale)
+	}
+	for _, loc := range acceptLanguages(r.Header.Get("Accept-Language")) {
+		if hasCatalog(loc) {
+			return loc
+		}
+	}
+	return DefaultLocale
+}
+
+// acceptLanguages returns the Accept-Language tags by descending q, ties
+// in header order.  Wildcards and q=0 are dropped.
+func acceptLanguages(h string) []string {
+	type tag struct {
+		loc string
+		q   float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(h, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if loc := normLocale(name); loc != "" && loc != "*" && q > 0 {
+			tags = append(tags, tag{loc, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.loc
+	}
+	return out
+}
+
+// normLocale folds "fr-CA" and "FR_ca" to "fr_ca".
+func normLocale(l string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(l), "-", "_"))
+}
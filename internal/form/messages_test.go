@@ -0,0 +1,111 @@
+// internal/form/messages_test.go
+//
+// Unit-tests for localized validation messages.
+
+package form
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func TestValidateFormLocale_SwitchesLanguage(t *testing.T) {
+	register(&FormDef{ID: "test/i18n", Fields: []FieldDef{
+		{Name: "name", Label: "Name", Type: "text", Required: true},
+		{Name: "code", Label: "Code", Type: "text", MinLength: 4},
+		{Name: "zip", Label: "Zip", Type: "text", Required: true, ErrorMsg: "Custom."},
+	}})
+	posted := submission(t, "")
+	posted.Set("code", "ab")
+
+	msgs := func(loc string) map[string]string {
+		_, errs := ValidateFormLocale("test/i18n", posted, loc)
+		out := map[string]string{}
+		for _, e := range errs {
+			out[e.Name] = e.Message
+		}
+		return out
+	}
+
+	en, fr := msgs("en_US"), msgs("fr_FR")
+	if en["name"] != "This field is required." || en["code"] != "Must be at least 4 characters." {
+		t.Fatalf("en = %v", en)
+	}
+	if fr["name"] != "Ce champ est obligatoire." || fr["code"] != "Doit contenir au moins 4 caractères." {
+		t.Fatalf("fr = %v", fr)
+	}
+	if en["zip"] != "Custom." || fr["zip"] != "Custom." {
+		t.Fatalf("error_msg override lost: en %q, fr %q", en["zip"], fr["zip"])
+	}
+	if got := msgs("xx")["name"]; got != en["name"] {
+		t.Fatalf("unknown locale = %q, want English", got)
+	}
+}
+
+func TestRegisterMessages_PartialCatalog(t *testing.T) {
+	if err := RegisterMessages("pt-BR", map[string]string{MsgRequired: "Campo obrigatório."}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Msg("pt_br", MsgRequired); got != "Campo obrigatório." {
+		t.Fatalf("pt_br required = %q", got)
+	}
+	if got := Msg("pt_BR", MsgMaxLength, 9); got != "Must be less than 9 characters." {
+		t.Fatalf("missing ID = %q, want the English fallback", got)
+	}
+	if err := RegisterMessages("pt", map[string]string{"nope": "x"}); err == nil {
+		t.Fatal("unknown message ID accepted")
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	req := func(accept string, locale string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if accept != "" {
+			r.Header.Set("Accept-Language", accept)
+		}
+		if locale != "" {
+			ten := &tenant.Tenant{Meta: meta.Record{Locale: locale}}
+			r = r.WithContext(tenant.WithContext(r.Context(), ten))
+		}
+		return r
+	}
+	cases := []struct{ accept, locale, want string }{
+		{"", "", DefaultLocale},
+		{"ja, de-CH;q=0.8, fr;q=0.9", "", "fr"},
+		{"zz, *;q=0.5", "", DefaultLocale},
+		{"es;q=0, de;q=0.1", "", "de"},
+		{"fr", "es-MX", "es_mx"}, // the tenant's locale wins
+	}
+	for _, c := range cases {
+		if got := RequestLocale(req(c.accept, c.locale)); got != c.want {
+			t.Errorf("RequestLocale(%q, %q) = %q, want %q", c.accept, c.locale, got, c.want)
+		}
+	}
+}
+
+func TestHandleSubmit_LocalizedErrors(t *testing.T) {
+	register(&FormDef{ID: "test/i18n-submit",
+		Fields: []FieldDef{{Name: "note", Label: "Note", Type: "text", Required: true}}})
+	tok, _ := GenerateToken()
+	v := url.Values{
+		"csrf_token": {tok},
+		"render_ts":  {strconv.FormatInt(time.Now().Add(-time.Minute).UnixMicro(), 10)},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(v.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+
+	_, err := HandleSubmit("test/i18n-submit", r)
+	ve, ok := err.(validationError)
+	if !ok || len(ve.Fields) != 1 || ve.Fields[0].Message != "Dieses Feld ist erforderlich." {
+		t.Fatalf("err = %#v", err)
+	}
+}
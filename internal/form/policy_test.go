@@ -0,0 +1,132 @@
+// internal/form/policy_test.go
+//
+// Unit-tests for action ordering, on_error, and depends_on.
+
+package form
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/yanizio/adept/internal/message"
+)
+
+// policyForm registers a store, a webhook, and an email with the given
+// policy on the store and dependencies on the webhook.
+func policyForm(id, storeOnError string, webhookDeps []string) {
+	register(&FormDef{ID: id, Actions: []ActionDef{
+		{Type: "store", OnError: storeOnError},
+		{Type: "webhook", DependsOn: webhookDeps, Params: map[string]any{"url": "http://127.0.0.1:1/"}},
+		{Type: "email", ID: "notify", Params: map[string]any{"to": "ops@example.com"}},
+	}})
+}
+
+func results(t *testing.T, err error) map[string]ActionResult {
+	t.Helper()
+	var ae *ActionsError
+	if !errors.As(err, &ae) {
+		t.Fatalf("err = %v, want *ActionsError", err)
+	}
+	out := make(map[string]ActionResult)
+	for _, r := range ae.Results {
+		out[r.Name] = r
+	}
+	return out
+}
+
+// failingStore returns an ActionCtx whose tenant DB rejects the insert.
+func failingStore(t *testing.T) ActionCtx {
+	actx, mock := auditCtx(t)
+	mock.ExpectExec("INSERT INTO form_submission").WillReturnError(errors.New("db down"))
+	return actx
+}
+
+func TestRunActions_StopOnError(t *testing.T) {
+	q := &captureQueue{}
+	useQueue(t, q)
+	policyForm("policy/stop", OnErrorStop, nil)
+
+	rs := results(t, RunActions("policy/stop", map[string]any{}, failingStore(t)))
+	if rs["store"].Err == nil || !rs["webhook"].Skipped || !rs["notify"].Skipped {
+		t.Fatalf("results = %+v", rs)
+	}
+	if len(q.jobs) != 0 {
+		t.Fatalf("%d messages sent after stop", len(q.jobs))
+	}
+}
+
+func TestRunActions_DependsOnSkips(t *testing.T) {
+	q := &captureQueue{}
+	useQueue(t, q)
+	policyForm("policy/deps", "", []string{"store"})
+
+	err := RunActions("policy/deps", map[string]any{}, failingStore(t))
+	rs := results(t, err)
+	if !rs["webhook"].Skipped || rs["notify"].Skipped || rs["notify"].Err != nil {
+		t.Fatalf("results = %+v", rs)
+	}
+	if len(q.jobs) != 1 || q.jobs[0].Email == nil {
+		t.Fatalf("jobs = %+v, want the email only", q.jobs)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "store: db down") || !strings.Contains(msg, "skipped webhook") {
+		t.Errorf("summary = %q", msg)
+	}
+}
+
+func TestRunActions_DefaultContinues(t *testing.T) {
+	q := &captureQueue{}
+	useQueue(t, q)
+	policyForm("policy/continue", "", nil)
+
+	rs := results(t, RunActions("policy/continue", map[string]any{}, failingStore(t)))
+	if rs["webhook"].Skipped || rs["notify"].Skipped {
+		t.Fatalf("results = %+v, want nothing skipped", rs)
+	}
+	if len(q.jobs) != 2 {
+		t.Fatalf("jobs = %d, want webhook and email", len(q.jobs))
+	}
+}
+
+func TestRunActions_Success(t *testing.T) {
+	actx, mock := auditCtx(t)
+	mock.ExpectExec("INSERT INTO form_submission").WillReturnResult(sqlmock.NewResult(1, 1))
+	register(&FormDef{ID: "policy/ok", Actions: []ActionDef{{Type: "store"}}})
+	if err := RunActions("policy/ok", map[string]any{}, actx); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLoadFormDef_ActionPolicyErrors(t *testing.T) {
+	cases := map[string]string{
+		"bad on_error": "  - type: store\n    on_error: retry\n",
+		"forward dep":  "  - type: webhook\n    url: https://x.example\n    depends_on: [store]\n  - type: store\n",
+		"unknown dep":  "  - type: webhook\n    url: https://x.example\n    depends_on: [nope]\n",
+	}
+	for name, actions := range cases {
+		p := writeForm(t, t.TempDir(), "x/forms/bad.yaml",
+			"id: policy/bad\nfields:\n  - name: a\n    label: A\n    type: text\nactions:\n"+actions)
+		if _, err := LoadFormDef(p); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	p := writeForm(t, t.TempDir(), "x/forms/ok.yaml", "id: policy/good\nfields:\n  - name: a\n    label: A\n    type: text\nactions:\n"+
+		"  - type: store\n    on_error: stop\n  - type: webhook\n    url: https://x.example\n    depends_on: [store]\n")
+	fd, err := LoadFormDef(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ac := fd.Actions[1]; len(ac.DependsOn) != 1 || ac.Params["depends_on"] != nil || ac.Params["url"] == nil {
+		t.Errorf("action parsed as %+v", ac)
+	}
+}
+
+// useQueue installs q as the primary message queue for the test.
+func useQueue(t *testing.T, q *captureQueue) {
+	t.Helper()
+	message.SetQueue(q)
+	t.Cleanup(func() { message.SetQueue(nil) })
+}
@@ -11,7 +11,9 @@
 //
 // Workflow
 //   •  RenderForm looks up the FormDef by ID, selects the requested step (if
-//      multi-step), and writes each field via writeField.
+//      multi-step), and writes each field via writeField.  Fields named by
+//      a GroupDef are wrapped in <fieldset><legend> by writeFields; the
+//      grouping never changes field names, so validation is unaffected.
 //   •  Required, minlength, maxlength, pattern, and placeholder attributes are
 //      attached where relevant.  Select/radio options are rendered from the
 //      YAML Options slice.
@@ -26,6 +28,8 @@
 //   Output HTML is deliberately plain – no framework classes – so themes can
 //   style via element selectors or class hooks.  Each input gets id="fld-{name}"
 //   and is wrapped in <div class="form-field"> for consistent styling.
+//   Groups render as <fieldset class="form-group" id="grp-{id}"> whose first
+//   child is the <legend>, which gives assistive technology the group's name.
 //
 //------------------------------------------------------------------------------
 
@@ -60,7 +64,7 @@ func RenderForm(formID string, opts RenderOptions) (template.HTML, error) {
 	}
 
 	// Resolve which fields to render based on step selection.
-	fields, stepIndex, err := selectFields(fd, opts.StepID)
+	fields, groups, stepIndex, err := selectFields(fd, opts.StepID)
 	if err != nil {
 		return "", err
 	}
@@ -69,11 +73,8 @@ func RenderForm(formID string, opts RenderOptions) (template.HTML, error) {
 	// Form wrapper div to allow per-form CSS targeting if desired.
 	buf.WriteString(`<div class="adept-form">` + "\n")
 
-	// Iterate fields in definition order.
-	for _, f := range fields {
-		if err := writeField(&buf, &f, opts.Prefill); err != nil {
-			return "", err
-		}
+	if err := writeFields(&buf, fields, groups, opts.Prefill); err != nil {
+		return "", err
 	}
 
 	// Hidden meta inputs.
@@ -88,23 +89,56 @@ func RenderForm(formID string, opts RenderOptions) (template.HTML, error) {
 	return template.HTML(buf.String()), nil
 }
 
-// selectFields returns the slice of FieldDefs to render for the requested step.
-// If the form is single-step, the entire Fields list is returned.  For
+// selectFields returns the FieldDefs and GroupDefs to render for the requested
+// step.  If the form is single-step, the top-level lists are returned.  For
 // multi-step forms, the step is chosen by ID (or first if StepID is blank).
-func selectFields(fd *FormDef, stepID string) ([]FieldDef, int, error) {
+func selectFields(fd *FormDef, stepID string) ([]FieldDef, []GroupDef, int, error) {
 	if len(fd.Steps) == 0 {
-		return fd.Fields, -1, nil // single-step
+		return fd.Fields, fd.Groups, -1, nil // single-step
 	}
 
 	if stepID == "" {
-		return fd.Steps[0].Fields, 0, nil
+		return fd.Steps[0].Fields, fd.Steps[0].Groups, 0, nil
 	}
 	for i, s := range fd.Steps {
 		if s.ID == stepID {
-			return s.Fields, i, nil
+			return s.Fields, s.Groups, i, nil
+		}
+	}
+	return nil, nil, -1, fmt.Errorf("RenderForm: step %q not found in form %q", stepID, fd.ID)
+}
+
+// writeFields emits fields in definition order, wrapping each group's run of
+// fields in a <fieldset> headed by its <legend>.  Without groups the output
+// is exactly a sequence of writeField calls.
+func writeFields(buf *bytes.Buffer, fields []FieldDef, groups []GroupDef, prefill map[string]string) error {
+	groupOf := make(map[string]*GroupDef)
+	for i := range groups {
+		for _, name := range groups[i].Fields {
+			groupOf[name] = &groups[i]
+		}
+	}
+
+	var open *GroupDef
+	for i := range fields {
+		if g := groupOf[fields[i].Name]; g != open {
+			if open != nil {
+				buf.WriteString(`</fieldset>` + "\n")
+			}
+			if g != nil {
+				buf.WriteString(`<fieldset class="form-group" id="grp-` + html.EscapeString(g.ID) + `">` + "\n")
+				buf.WriteString(`<legend>` + html.EscapeString(g.Legend) + `</legend>` + "\n")
+			}
+			open = g
 		}
+		if err := writeField(buf, &fields[i], prefill); err != nil {
+			return err
+		}
+	}
+	if open != nil {
+		buf.WriteString(`</fieldset>` + "\n")
 	}
-	return nil, -1, fmt.Errorf("RenderForm: step %q not found in form %q", stepID, fd.ID)
+	return nil
 }
 
 // writeField emits HTML for an individual field into buf, applying prefill and
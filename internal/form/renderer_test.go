@@ -0,0 +1,150 @@
+// internal/form/renderer_test.go
+//
+// Unit-tests for fieldset grouping in the renderer and loader.
+
+package form
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const addressForm = `id: shop/checkout
+fields:
+  - name: email
+    label: Email
+    type: email
+  - name: street
+    label: Street
+    type: text
+  - name: city
+    label: City
+    type: text
+  - name: note
+    label: Note
+    type: textarea
+groups:
+  - legend: Shipping <address>
+    fields: [street, city]
+`
+
+// fieldsets maps each rendered fieldset's legend to the input names inside.
+func fieldsets(t *testing.T, out string) map[string][]string {
+	t.Helper()
+	sets := map[string][]string{}
+	re := regexp.MustCompile(`(?s)<fieldset[^>]*>\n<legend>(.*?)</legend>\n(.*?)</fieldset>`)
+	names := regexp.MustCompile(`name="([^"]+)"`)
+	for _, m := range re.FindAllStringSubmatch(out, -1) {
+		for _, n := range names.FindAllStringSubmatch(m[2], -1) {
+			sets[m[1]] = append(sets[m[1]], n[1])
+		}
+	}
+	return sets
+}
+
+func TestRender_GroupsNestFields(t *testing.T) {
+	fd, err := LoadFormDef(writeForm(t, t.TempDir(), "shop/forms/checkout.yaml", addressForm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fd.Groups[0].ID != "group1" {
+		t.Fatalf("derived group id = %q", fd.Groups[0].ID)
+	}
+
+	var buf bytes.Buffer
+	if err := writeFields(&buf, fd.Fields, fd.Groups, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	sets := fieldsets(t, out)
+	if got := strings.Join(sets["Shipping &lt;address&gt;"], ","); len(sets) != 1 || got != "street,city" {
+		t.Fatalf("fieldsets = %v\n%s", sets, out)
+	}
+	if !strings.Contains(out, `<fieldset class="form-group" id="grp-group1">`+"\n<legend>") {
+		t.Fatalf("fieldset must open with its legend:\n%s", out)
+	}
+	// Ungrouped fields stay outside, in definition order, names untouched.
+	iEmail, iSet, iNote := strings.Index(out, `name="email"`), strings.Index(out, "<fieldset"), strings.Index(out, `name="note"`)
+	if !(iEmail < iSet && iSet < strings.Index(out, "</fieldset>") && strings.Index(out, "</fieldset>") < iNote) {
+		t.Fatalf("field order broken:\n%s", out)
+	}
+}
+
+func TestRender_StepGroups(t *testing.T) {
+	fd := &FormDef{ID: "t/steps", Steps: []StepDef{
+		{ID: "one", Fields: []FieldDef{{Name: "a", Label: "A", Type: "text"}}},
+		{ID: "two", Fields: []FieldDef{{Name: "b", Label: "B", Type: "text"}},
+			Groups: []GroupDef{{ID: "g", Legend: "Bee", Fields: []string{"b"}}}},
+	}}
+	fields, groups, _, err := selectFields(fd, "two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeFields(&buf, fields, groups, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sets := fieldsets(t, buf.String()); len(sets["Bee"]) != 1 || sets["Bee"][0] != "b" {
+		t.Fatalf("step fieldsets = %v", sets)
+	}
+}
+
+func TestRender_UngroupedUnchanged(t *testing.T) {
+	fields := []FieldDef{
+		{Name: "email", Label: "Email", Type: "email", Required: true},
+		{Name: "color", Label: "Color", Type: "select", Options: []string{"red", "blue"}},
+	}
+	prefill := map[string]string{"color": "blue"}
+
+	var want bytes.Buffer
+	for i := range fields {
+		if err := writeField(&want, &fields[i], prefill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got bytes.Buffer
+	if err := writeFields(&got, fields, nil, prefill); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Fatalf("ungrouped output changed:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
+
+func TestLoadFormDef_GroupErrors(t *testing.T) {
+	base := `id: t/g
+fields:
+  - {name: a, label: A, type: text}
+  - {name: b, label: B, type: text}
+  - {name: c, label: C, type: text}
+groups:
+`
+	cases := map[string]struct {
+		groups string
+		line   int
+		msg    string
+	}{
+		"legend":  {"  - {fields: [a]}\n", 7, "group 'group1' missing 'legend'"},
+		"unknown": {"  - {legend: L, fields: [z]}\n", 7, "group 'group1' names unknown field 'z'"},
+		"gap":     {"  - {legend: L, fields: [a, c]}\n", 7, "group 'group1' fields must be consecutive in 'fields'"},
+		"twice": {"  - {id: x, legend: L, fields: [a]}\n  - {id: y, legend: M, fields: [a]}\n",
+			8, "field 'a' is in groups 'x' and 'y'"},
+		"dup id": {"  - {id: x, legend: L, fields: [a]}\n  - {id: x, legend: M, fields: [b]}\n",
+			8, "duplicate group id 'x'"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := writeForm(t, t.TempDir(), "t/forms/g.yaml", base+tc.groups)
+			_, err := LoadFormDef(p)
+			var de *DefError
+			if !errors.As(err, &de) || de.Line != tc.line || de.Msg != tc.msg || filepath.Clean(de.Path) != p {
+				t.Fatalf("err = %v, want line %d %q", err, tc.line, tc.msg)
+			}
+		})
+	}
+}
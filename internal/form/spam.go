@@ -0,0 +1,242 @@
+// internal/form/spam.go
+//
+// Adept – Forms subsystem: pluggable spam scoring.
+//
+// Context
+//   CSRF, timing, and the honeypot stop naive bots.  What gets past them
+//   is scored: every registered checker looks at the clean data and the
+//   request info and returns a score, and ExecuteActions sums them.  A
+//   form with `spam_threshold` set diverts submissions at or above it to
+//   the form_quarantine table; none of its actions run.
+//
+// Workflow
+//   •  RegisterSpamChecker(name, fn) adds or replaces a checker.  Built in:
+//      "links" (0.5 per URL), "keywords" (1 per blocklisted phrase), and
+//      "disposable_email" (2 per address at a throwaway domain).
+//   •  ExecuteActions scores only forms with a threshold above zero.  The
+//      score and its per-checker parts are logged, and audited forms keep
+//      the total in form_submission_meta.spam_score.
+//   •  Quarantined rows can be reviewed and replayed by hand; nothing in
+//      Adept reads them back.
+//
+// Notes
+//   •  Checkers run on the request goroutine, so they must be fast and
+//      must not block on the network.
+//   •  A checker may return a negative score to vouch for a submission.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanizio/adept/internal/database"
+	"github.com/yanizio/adept/internal/logger"
+	"github.com/yanizio/adept/internal/requestinfo"
+)
+
+// SpamChecker scores one submission.  info is nil outside HTTP.
+type SpamChecker func(data map[string]any, info *requestinfo.RequestInfo) float64
+
+// quarantineTable receives submissions at or above the spam threshold.
+const quarantineTable = "form_quarantine"
+
+var (
+	spamMu       sync.RWMutex
+	spamCheckers = map[string]SpamChecker{
+		"links":            checkLinks,
+		"keywords":         checkKeywords,
+		"disposable_email": checkDisposableEmail,
+	}
+)
+
+// RegisterSpamChecker adds fn under name, replacing any checker already
+// registered with that name.  A nil fn removes it.
+func RegisterSpamChecker(name string, fn SpamChecker) {
+	spamMu.Lock()
+	defer spamMu.Unlock()
+	if fn == nil {
+		delete(spamCheckers, name)
+		return
+	}
+	spamCheckers[name] = fn
+}
+
+// SpamScore runs every checker and returns the total and each part.
+func SpamScore(data map[string]any, info *requestinfo.RequestInfo) (float64, map[string]float64) {
+	spamMu.RLock()
+	defer spamMu.RUnlock()
+	var total float64
+	parts := make(map[string]float64, len(spamCheckers))
+	for name, fn := range spamCheckers {
+		s := fn(data, info)
+		parts[name] = s
+		total += s
+	}
+	return total, parts
+}
+
+// screenSpam scores data for fd and reports whether it was quarantined.
+// It records the score on actx for the audit row.
+func screenSpam(fd *FormDef, data map[string]any, actx *ActionCtx) bool {
+	if fd.SpamThreshold <= 0 {
+		return false
+	}
+	score, parts := SpamScore(data, actx.Info)
+	actx.spamScore = &score
+
+	log := logger.FromContext(actx.Ctx)
+	if score < fd.SpamThreshold {
+		log.Info("form spam score",
+			"form", fd.ID, "score", score, "threshold", fd.SpamThreshold, "checks", fmtParts(parts))
+		return false
+	}
+	log.Warn("form submission quarantined",
+		"form", fd.ID, "score", score, "threshold", fd.SpamThreshold, "checks", fmtParts(parts))
+	if err := quarantine(fd, data, *actx); err != nil {
+		logErr(*actx, fd.ID, "quarantine", err)
+	}
+	return true
+}
+
+// quarantine stores data in form_quarantine instead of running actions.
+func quarantine(fd *FormDef, data map[string]any, actx ActionCtx) error {
+	db := database.Conn(actx.Ctx)
+	j, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	res, err := db.ExecContext(
+		actx.Ctx,
+		`INSERT INTO `+quarantineTable+` (form_id, submitted_at, data, spam_score) VALUES ($1,$2,$3,$4)`,
+		fd.ID,
+		time.Now().UTC(),
+		j,
+		*actx.spamScore,
+	)
+	if err != nil || !fd.Audit {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	if err := storeAudit(actx, db, quarantineTable, id); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+// fmtParts renders checker scores as "a=1 b=0.5" in name order, skipping
+// zeros.
+func fmtParts(parts map[string]float64) string {
+	names := make([]string, 0, len(parts))
+	for n, s := range parts {
+		if s != 0 {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%s=%g", n, parts[n])
+	}
+	return b.String()
+}
+
+// -----------------------------------------------------------------------------
+// Built-in checkers
+// -----------------------------------------------------------------------------
+
+// textValues returns every string value in data.
+func textValues(data map[string]any) []string {
+	var out []string
+	for _, v := range data {
+		switch x := v.(type) {
+		case string:
+			out = append(out, x)
+		case []string:
+			out = append(out, x...)
+		}
+	}
+	return out
+}
+
+var urlRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// checkLinks scores 0.5 per URL.
+func checkLinks(data map[string]any, _ *requestinfo.RequestInfo) float64 {
+	n := 0
+	for _, s := range textValues(data) {
+		n += len(urlRe.FindAllStringIndex(s, -1))
+	}
+	return 0.5 * float64(n)
+}
+
+// spamKeywords is the built-in phrase blocklist, lower case.  Replace the
+// "keywords" checker to use a different one.
+var spamKeywords = []string{
+	"backlinks",
+	"casino",
+	"crypto investment",
+	"payday loan",
+	"seo services",
+	"viagra",
+}
+
+// checkKeywords scores 1 per distinct blocklisted phrase.
+func checkKeywords(data map[string]any, _ *requestinfo.RequestInfo) float64 {
+	text := strings.ToLower(strings.Join(textValues(data), "\n"))
+	var score float64
+	for _, kw := range spamKeywords {
+		if strings.Contains(text, kw) {
+			score++
+		}
+	}
+	return score
+}
+
+// disposableDomains are common throwaway-mailbox services.
+var disposableDomains = map[string]bool{
+	"10minutemail.com":  true,
+	"dispostable.com":   true,
+	"getnada.com":       true,
+	"guerrillamail.com": true,
+	"mailinator.com":    true,
+	"maildrop.cc":       true,
+	"sharklasers.com":   true,
+	"temp-mail.org":     true,
+	"trashmail.com":     true,
+	"yopmail.com":       true,
+}
+
+// checkDisposableEmail scores 2 per value that is an address at a
+// disposable domain.
+func checkDisposableEmail(data map[string]any, _ *requestinfo.RequestInfo) float64 {
+	var score float64
+	for _, s := range textValues(data) {
+		a, err := mail.ParseAddress(strings.TrimSpace(s))
+		if err != nil {
+			continue
+		}
+		_, domain, _ := strings.Cut(a.Address, "@")
+		if disposableDomains[strings.ToLower(domain)] {
+			score += 2
+		}
+	}
+	return score
+}
@@ -0,0 +1,84 @@
+// internal/form/spam_test.go
+//
+// Unit-tests for spam scoring and quarantine.
+
+package form
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/yanizio/adept/internal/requestinfo"
+)
+
+// fakeSpam registers a checker returning *score for the test.
+func fakeSpam(t *testing.T, score *float64) {
+	t.Helper()
+	RegisterSpamChecker("fake", func(map[string]any, *requestinfo.RequestInfo) float64 { return *score })
+	t.Cleanup(func() { RegisterSpamChecker("fake", nil) })
+}
+
+func TestExecuteActions_SpamThreshold(t *testing.T) {
+	score := 0.0
+	fakeSpam(t, &score)
+	register(&FormDef{ID: "spam/contact", Audit: true, SpamThreshold: 3,
+		Actions: []ActionDef{{Type: "store"}}})
+	data := map[string]any{"msg": "hello"} // built-ins score 0
+
+	t.Run("below", func(t *testing.T) {
+		actx, mock := auditCtx(t)
+		score = 2.5
+		mock.ExpectExec("INSERT INTO form_submission ").
+			WillReturnResult(sqlmock.NewResult(7, 1))
+		mock.ExpectExec("INSERT INTO form_submission_meta").
+			WithArgs("form_submission", int64(7), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 2.5).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		ExecuteActions("spam/contact", data, actx)
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("above", func(t *testing.T) {
+		actx, mock := auditCtx(t)
+		score = 3
+		mock.ExpectExec("INSERT INTO form_quarantine").
+			WithArgs("spam/contact", sqlmock.AnyArg(), sqlmock.AnyArg(), 3.0).
+			WillReturnResult(sqlmock.NewResult(9, 1))
+		mock.ExpectExec("INSERT INTO form_submission_meta").
+			WithArgs("form_quarantine", int64(9), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 3.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		ExecuteActions("spam/contact", data, actx) // store must not run
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSpamScore_BuiltIns(t *testing.T) {
+	cases := []struct {
+		data map[string]any
+		want float64
+	}{
+		{map[string]any{"msg": "Hi, see you Tuesday."}, 0},
+		{map[string]any{"msg": "see https://a.example and www.b.example"}, 1},
+		{map[string]any{"msg": "Cheap SEO services and backlinks"}, 2},
+		{map[string]any{"email": "bot@Mailinator.com"}, 2},
+		{map[string]any{"email": "ada@example.com"}, 0},
+	}
+	for _, c := range cases {
+		if got, _ := SpamScore(c.data, nil); got != c.want {
+			t.Errorf("SpamScore(%v) = %g, want %g", c.data, got, c.want)
+		}
+	}
+}
+
+func TestFmtParts(t *testing.T) {
+	got := fmtParts(map[string]float64{"links": 1, "keywords": 0, "fake": 2.5})
+	if got != "fake=2.5 links=1" {
+		t.Errorf("fmtParts = %q", got)
+	}
+}
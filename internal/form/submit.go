@@ -6,14 +6,24 @@
 //   Most handlers want one call that: parses POST body, validates input,
 //   executes configured actions, and returns the clean map or a ValidationError.
 //   HandleSubmit provides that convenience so component code stays terse.
+//   The body is read under the limits in limits.go; an oversized POST is a
+//   validation error that IsTooLarge recognises, so handlers can answer 413.
+//   Each submission gets a key (submissionKey) that makes its email
+//   actions idempotent.
 //
 //------------------------------------------------------------------------------
 
 package form
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
+
+	"github.com/yanizio/adept/internal/requestinfo"
 )
 
 // HandleSubmit parses r, validates against formID, executes default actions,
@@ -21,19 +31,65 @@ import (
 // ValidationError (check with IsValidationError).  On unexpected system
 // failures it returns a generic error.
 func HandleSubmit(formID string, r *http.Request) (map[string]any, error) {
-	if err := r.ParseForm(); err != nil {
+	fd, _ := GetFormDef(formID)
+	if err := parseBody(fd, r); err != nil {
 		return nil, err
 	}
 
-	clean, errs := ValidateForm(formID, r.PostForm)
+	clean, errs := ValidateFormLocale(formID, r.PostForm, RequestLocale(r))
 	if len(errs) > 0 {
 		return nil, validationError{Fields: errs}
 	}
 
-	ExecuteActions(formID, clean, ActionCtx{Ctx: r.Context()})
+	ExecuteActions(formID, clean, ActionCtx{
+		Ctx:           r.Context(),
+		Info:          requestinfo.FromContext(r.Context()),
+		SubmissionKey: submissionKey(r.PostForm.Get("csrf_token"), clean),
+	})
 	return clean, nil
 }
 
+// submissionKey identifies one logical submission: the rendered form's
+// CSRF token (random per render) plus the clean data.  A double-post of
+// the same render with the same values shares a key; an edited resubmit,
+// or any other render, gets a new one.
+func submissionKey(csrfToken string, clean map[string]any) string {
+	data, _ := json.Marshal(clean) // map keys are sorted
+	h := sha256.New()
+	h.Write([]byte(csrfToken))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseBody reads r's form values under fd's body limits.  Multipart bodies
+// keep at most the memory limit in RAM; file parts beyond it go to disk.
+func parseBody(fd *FormDef, r *http.Request) error {
+	maxBytes, maxMemory := bodyLimits(fd, r)
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBytes)
+	}
+
+	var err error
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return tooLargeError(RequestLocale(r))
+	}
+	return err
+}
+
+// IsTooLarge reports whether err is the validation error for a body over
+// its size limit.  Handlers typically answer it with 413.
+func IsTooLarge(err error) bool {
+	var ve validationError
+	return errors.As(err, &ve) && ve.tooLarge
+}
+
 // IsValidationError reports whether err came from failed ValidateForm.
 func IsValidationError(err error) bool {
 	var ve validationError
@@ -0,0 +1,87 @@
+// internal/form/sync.go
+//
+// Adept – Forms subsystem: synchronous action execution for JSON APIs.
+//
+// Context
+//   ExecuteActions is fire-and-forget: webhooks may be queued and nothing
+//   comes back to the caller.  An API client creating a record needs its
+//   ID in the response, so ExecuteActionsSync runs the same pipeline (spam
+//   screen, on_error, depends_on) with every store and webhook inline and
+//   returns what each action produced.  The HTML flow (HandleSubmit) keeps
+//   the async path.
+//
+// Results
+//   The map is keyed by action name (ActionDef.Name); a later action with
+//   a name already used gets "#<n>", its 1-based position, appended.
+//   Each value is a map[string]any:
+//
+//      store     "id" (LastInsertId) and "table"
+//      webhook   "status", the response code (also on a non-2xx failure)
+//      email     "queued", the number of messages handed to the queue
+//      any       "error" (string) when it failed, "skipped" (true) when
+//                a stop or a failed dependency kept it from running
+//
+// Rules
+//   •  Results are returned alongside the error, so a caller sees which
+//      actions did succeed when another failed.
+//   •  Email stays queued even here; delivery is not worth blocking on.
+//   •  A quarantined submission returns ErrQuarantined and no results.
+//   •  ctx bounds every inline call.  Its requestinfo, when present, feeds
+//      the spam screen and audit rows.  There is no submission key, so
+//      email dedup is off.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package form
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yanizio/adept/internal/requestinfo"
+)
+
+// ErrQuarantined reports a submission diverted by the spam screen; none of
+// its actions ran.
+var ErrQuarantined = errors.New("form: submission quarantined")
+
+// ExecuteActionsSync runs formID's actions inline on data, already
+// validated, and returns each action's output.  The error is nil, an
+// *ActionsError, or ErrQuarantined.
+func ExecuteActionsSync(ctx context.Context, formID string, data map[string]any) (map[string]any, error) {
+	if _, ok := GetFormDef(formID); !ok {
+		return nil, fmt.Errorf("ExecuteActionsSync: unknown form %q", formID)
+	}
+	results, err := runActions(formID, data, ActionCtx{
+		Ctx:  ctx,
+		Info: requestinfo.FromContext(ctx),
+		sync: true,
+	})
+	if errors.Is(err, ErrQuarantined) {
+		return nil, err
+	}
+
+	out := make(map[string]any, len(results))
+	for i, r := range results {
+		v := r.Output
+		if v == nil {
+			v = make(map[string]any)
+		}
+		switch {
+		case r.Skipped:
+			v["skipped"] = true
+		case r.Err != nil:
+			v["error"] = r.Err.Error()
+		}
+		key := r.Name
+		if _, dup := out[key]; dup {
+			key = fmt.Sprintf("%s#%d", key, i+1)
+		}
+		out[key] = v
+	}
+	return out, err
+}
@@ -0,0 +1,112 @@
+// internal/form/sync_test.go
+//
+// Unit-tests for ExecuteActionsSync: inserted IDs, webhook status codes,
+// and partial results on failure.
+
+package form
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/yanizio/adept/internal/message"
+)
+
+// webhookServer answers every request with status and lets the shared
+// client reach loopback for the test.
+func webhookServer(t *testing.T, status int) (url string, hits *int) {
+	t.Helper()
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n++
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := message.NewHTTPClient(message.ClientOptions{
+		Policy: message.AddressPolicy{AllowPrivate: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message.SetHTTPClient(c)
+	t.Cleanup(func() { message.SetHTTPClient(nil) })
+	return srv.URL, &n
+}
+
+func output(t *testing.T, out map[string]any, name string) map[string]any {
+	t.Helper()
+	v, ok := out[name].(map[string]any)
+	if !ok {
+		t.Fatalf("no output for %q in %v", name, out)
+	}
+	return v
+}
+
+func TestExecuteActionsSync_ReturnsIDAndStatus(t *testing.T) {
+	url, hits := webhookServer(t, http.StatusCreated)
+	q := &captureQueue{}
+	useQueue(t, q) // the webhook must bypass it
+
+	register(&FormDef{ID: "sync/ok", Actions: []ActionDef{
+		{Type: "store", Params: map[string]any{"table": "booking"}},
+		{Type: "webhook", Params: map[string]any{"url": url}},
+	}})
+	actx, mock := auditCtx(t)
+	mock.ExpectExec("INSERT INTO booking").WillReturnResult(sqlmock.NewResult(42, 1))
+
+	out, err := ExecuteActionsSync(actx.Ctx, "sync/ok", map[string]any{"name": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := output(t, out, "store"); st["id"] != int64(42) || st["table"] != "booking" {
+		t.Fatalf("store output = %v", st)
+	}
+	if wh := output(t, out, "webhook"); wh["status"] != http.StatusCreated {
+		t.Fatalf("webhook output = %v", wh)
+	}
+	if *hits != 1 || len(q.jobs) != 0 {
+		t.Fatalf("webhook hits = %d, queued jobs = %d; want inline delivery", *hits, len(q.jobs))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteActionsSync_PartialResultsOnFailure(t *testing.T) {
+	url, _ := webhookServer(t, http.StatusBadGateway)
+	register(&FormDef{ID: "sync/fail", Actions: []ActionDef{
+		{Type: "store"},
+		{Type: "webhook", Params: map[string]any{"url": url}},
+		{Type: "webhook", Params: map[string]any{"url": url}, DependsOn: []string{"webhook"}},
+	}})
+	actx, mock := auditCtx(t)
+	mock.ExpectExec("INSERT INTO form_submission").WillReturnResult(sqlmock.NewResult(7, 1))
+
+	out, err := ExecuteActionsSync(actx.Ctx, "sync/fail", map[string]any{})
+	var ae *ActionsError
+	if !errors.As(err, &ae) {
+		t.Fatalf("err = %v, want *ActionsError", err)
+	}
+	if st := output(t, out, "store"); st["id"] != int64(7) {
+		t.Fatalf("store output = %v", st)
+	}
+	wh := output(t, out, "webhook")
+	if wh["status"] != http.StatusBadGateway || wh["error"] == nil {
+		t.Fatalf("webhook output = %v", wh)
+	}
+	if dep := output(t, out, "webhook#3"); dep["skipped"] != true {
+		t.Fatalf("dependent webhook output = %v", dep)
+	}
+}
+
+func TestExecuteActionsSync_UnknownForm(t *testing.T) {
+	actx, _ := auditCtx(t)
+	if _, err := ExecuteActionsSync(actx.Ctx, "sync/missing", nil); err == nil {
+		t.Fatal("unknown form accepted")
+	}
+}
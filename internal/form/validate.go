@@ -17,6 +17,8 @@
 //   •  On success a map[string]any of clean values is returned.
 //   •  On failure callers wrap the []ErrorField in validationError (see
 //      submit.go) and treat it as a user error, not a 500.
+//   •  Messages come from the catalog in messages.go, in the locale the
+//      caller passes; a field's error_msg overrides them.
 //
 // Style
 //   Comments follow Adept’s guide: full sentences, two space spacing, Oxford
@@ -27,7 +29,6 @@
 package form
 
 import (
-	"fmt"
 	"html"
 	"mime/multipart"
 	"net/mail"
@@ -53,7 +54,11 @@ type ErrorField struct {
 //
 // It allows callers (HandleSubmit, component handlers) to distinguish user
 // input errors from system failures via errors.As / IsValidationError.
-type validationError struct{ Fields []ErrorField }
+type validationError struct {
+	Fields []ErrorField
+
+	tooLarge bool // body exceeded its limit (limits.go)
+}
 
 func (ve validationError) Error() string { return "form validation failed" }
 
@@ -63,11 +68,18 @@ func (ve validationError) Error() string { return "form validation failed" }
 
 // ValidateForm validates posted form data (already parsed into url.Values) for
 // formID.  It returns sanitized values and any field errors.  A non-empty error
-// slice means UI re-render is required.
+// slice means UI re-render is required.  Messages are in English; see
+// ValidateFormLocale.
 func ValidateForm(formID string, posted url.Values) (map[string]any, []ErrorField) {
+	return ValidateFormLocale(formID, posted, DefaultLocale)
+}
+
+// ValidateFormLocale is ValidateForm with error messages in locale
+// (RequestLocale picks it for a request).
+func ValidateFormLocale(formID string, posted url.Values, locale string) (map[string]any, []ErrorField) {
 	fd, ok := GetFormDef(formID)
 	if !ok {
-		return nil, []ErrorField{{Name: "", Message: "Unknown form."}}
+		return nil, []ErrorField{{Name: "", Message: Msg(locale, MsgUnknownForm)}}
 	}
 
 	var errs []ErrorField
@@ -77,10 +89,10 @@ func ValidateForm(formID string, posted url.Values) (map[string]any, []ErrorFiel
 	// Form-level checks: CSRF + render timestamp
 	// -------------------------------------------------------------------------
 	if !verifyCSRF(posted.Get("csrf_token")) {
-		errs = append(errs, ErrorField{"", "Security token invalid.  Please refresh and try again."})
+		errs = append(errs, ErrorField{"", Msg(locale, MsgCSRF)})
 		return nil, errs
 	}
-	if msg := checkTiming(posted.Get("render_ts")); msg != "" {
+	if msg := checkTiming(posted.Get("render_ts"), locale); msg != "" {
 		errs = append(errs, ErrorField{"", msg})
 		return nil, errs
 	}
@@ -93,7 +105,7 @@ func ValidateForm(formID string, posted url.Values) (map[string]any, []ErrorFiel
 
 		// Required
 		if f.Required && !present {
-			errs = append(errs, ErrorField{f.Name, requiredMsg(&f)})
+			errs = append(errs, ErrorField{f.Name, requiredMsg(&f, locale)})
 			continue
 		}
 		// Empty optional – nothing more to do.
@@ -101,7 +113,7 @@ func ValidateForm(formID string, posted url.Values) (map[string]any, []ErrorFiel
 			continue
 		}
 
-		val, perr := validateAndSanitize(&f, raw)
+		val, perr := validateAndSanitize(&f, raw, locale)
 		if perr != "" {
 			errs = append(errs, ErrorField{f.Name, perr})
 			continue
@@ -122,20 +134,20 @@ func verifyCSRF(token string) bool {
 
 // checkTiming ensures the form was not submitted suspiciously fast or too late.
 // Returns empty string on success, user-visible message on failure.
-func checkTiming(tsRaw string) string {
+func checkTiming(tsRaw, loc string) string {
 	if tsRaw == "" {
-		return "Timestamp missing.  Please reload the page."
+		return Msg(loc, MsgTimestampMissing)
 	}
 	ts, err := strconv.ParseInt(tsRaw, 10, 64)
 	if err != nil {
-		return "Bad timestamp.  Please retry."
+		return Msg(loc, MsgTimestampBad)
 	}
 	delta := time.Since(time.UnixMicro(ts))
 	switch {
 	case delta < 2*time.Second:
-		return "Form submitted too quickly.  Please enter the fields manually."
+		return Msg(loc, MsgTooFast)
 	case delta > 30*time.Minute:
-		return "Form expired.  Please reload and submit again."
+		return Msg(loc, MsgExpired)
 	default:
 		return ""
 	}
@@ -170,46 +182,46 @@ func extractValue(v url.Values, f *FieldDef) (string, bool) {
 	return raw[0], true
 }
 
-func validateAndSanitize(f *FieldDef, raw string) (any, string) {
+func validateAndSanitize(f *FieldDef, raw, loc string) (any, string) {
 	val := strings.TrimSpace(raw)
 
 	switch f.Type {
 	case "text", "textarea":
-		if msg := lengthCheck(f, val); msg != "" {
+		if msg := lengthCheck(f, val, loc); msg != "" {
 			return nil, msg
 		}
 		if f.Pattern != "" && !regexMatch(f.Pattern, val) {
-			return nil, patternMsg(f)
+			return nil, patternMsg(f, loc)
 		}
 		return html.EscapeString(val), ""
 
 	case "email":
-		if msg := lengthCheck(f, val); msg != "" {
+		if msg := lengthCheck(f, val, loc); msg != "" {
 			return nil, msg
 		}
 		if _, err := mail.ParseAddress(val); err != nil {
-			return nil, invalidMsg(f)
+			return nil, invalidMsg(f, loc)
 		}
 		return val, ""
 
 	case "password":
-		if msg := lengthCheck(f, val); msg != "" {
+		if msg := lengthCheck(f, val, loc); msg != "" {
 			return nil, msg
 		}
 		return val, ""
 
 	case "number":
-		if msg := lengthCheck(f, val); msg != "" {
+		if msg := lengthCheck(f, val, loc); msg != "" {
 			return nil, msg
 		}
 		if _, err := strconv.ParseFloat(val, 64); err != nil {
-			return nil, invalidMsg(f)
+			return nil, invalidMsg(f, loc)
 		}
 		return val, ""
 
 	case "date":
 		if _, err := time.Parse("2006-01-02", val); err != nil {
-			return nil, invalidMsg(f)
+			return nil, invalidMsg(f, loc)
 		}
 		return val, ""
 
@@ -219,23 +231,23 @@ func validateAndSanitize(f *FieldDef, raw string) (any, string) {
 
 	case "select", "radio":
 		if !optionAllowed(f.Options, val) {
-			return nil, invalidMsg(f)
+			return nil, invalidMsg(f, loc)
 		}
 		return val, ""
 
 	default:
-		return nil, fmt.Sprintf("Unsupported field type %q.", f.Type)
+		return nil, Msg(loc, MsgUnsupportedType, f.Type)
 	}
 }
 
 // lengthCheck validates minlength / maxlength rules.
-func lengthCheck(f *FieldDef, s string) string {
+func lengthCheck(f *FieldDef, s, loc string) string {
 	n := len(s)
 	if f.MinLength > 0 && n < f.MinLength {
-		return fmt.Sprintf("Must be at least %d characters.", f.MinLength)
+		return Msg(loc, MsgMinLength, f.MinLength)
 	}
 	if f.MaxLength > 0 && n > f.MaxLength {
-		return fmt.Sprintf("Must be less than %d characters.", f.MaxLength)
+		return Msg(loc, MsgMaxLength, f.MaxLength)
 	}
 	return ""
 }
@@ -254,24 +266,24 @@ func optionAllowed(opts []string, v string) bool {
 	return false
 }
 
-// user-friendly default messages
-func requiredMsg(f *FieldDef) string {
+// user-friendly default messages; error_msg wins over the catalog
+func requiredMsg(f *FieldDef, loc string) string {
 	if f.ErrorMsg != "" {
 		return f.ErrorMsg
 	}
-	return "This field is required."
+	return Msg(loc, MsgRequired)
 }
-func invalidMsg(f *FieldDef) string {
+func invalidMsg(f *FieldDef, loc string) string {
 	if f.ErrorMsg != "" {
 		return f.ErrorMsg
 	}
-	return "Invalid input."
+	return Msg(loc, MsgInvalid)
 }
-func patternMsg(f *FieldDef) string {
+func patternMsg(f *FieldDef, loc string) string {
 	if f.ErrorMsg != "" {
 		return f.ErrorMsg
 	}
-	return "Input does not match required format."
+	return Msg(loc, MsgPattern)
 }
 
 // -----------------------------------------------------------------------------
@@ -6,7 +6,7 @@
 //   Adept templates embed form markup through the widget system.  The concrete
 //   widget.Widget interface expects:
 //
-//       Render(rctx any, params map[string]any) (string, int, error)
+//       Render(ctx widget.Context, params map[string]any) (string, int, error)
 //
 //   This adapter wraps RenderForm and always returns view.CacheSkip so pages
 //   never cache CSRF tokens.
@@ -34,7 +34,7 @@ func (w *formWidget) ID() string { return w.id }
 //   - "step"    string           – specific step ID in a multi-step form
 //
 // It always returns view.CacheSkip so every render gets a fresh CSRF token.
-func (w *formWidget) Render(_ any, params map[string]any) (string, int, error) {
+func (w *formWidget) Render(_ widget.Context, params map[string]any) (string, int, error) {
 	var pre map[string]string
 	var step string
 	if params != nil {
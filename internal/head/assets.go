@@ -0,0 +1,131 @@
+// internal/head/assets.go
+//
+// URL-keyed script and stylesheet helpers.
+//
+// Context
+// -------
+// Raw Script and Link calls dedup on the exact tag string, so two
+// components that spell the same jQuery include differently both load it.
+// ScriptSrc and Stylesheet key on the URL instead and build the tag
+// themselves, so every request for one URL yields exactly one tag.
+//
+// Merging rules for a repeated URL
+// --------------------------------
+//   - Ordering: lowest Weight and earliest Position win (same as ScriptP).
+//   - Attributes: the first caller's values are kept; empty string fields
+//     (Integrity, CrossOrigin, Media, Type) are filled from later callers.
+//   - Async and Defer come from the first caller only, so a later caller
+//     cannot silently change execution order.
+//
+// Notes
+// -----
+// • Attribute values are HTML-escaped; URLs are emitted as given.
+// • Oxford commas, two spaces after periods.
+
+package head
+
+import (
+	"html/template"
+	"strings"
+)
+
+// ScriptOpts describes one <script src> include.
+type ScriptOpts struct {
+	Async       bool
+	Defer       bool
+	Integrity   string // SRI hash, e.g. "sha384-…"
+	CrossOrigin string // "anonymous" or "use-credentials"
+	Type        string // e.g. "module"; empty omits the attribute
+	Weight      int
+	Position    Position // zero value means HeadEnd
+}
+
+// StyleOpts describes one <link rel="stylesheet"> include.
+type StyleOpts struct {
+	Media       string // e.g. "print"; empty omits the attribute
+	Integrity   string
+	CrossOrigin string
+	Weight      int
+}
+
+// ScriptSrc adds a script include keyed by url.
+func (b *Builder) ScriptSrc(url string, o ScriptOpts) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, dup := b.addLocked("src:"+url, &b.scripts, "", o.Weight, o.Position)
+	if dup {
+		if e.script != nil {
+			fill(&e.script.Integrity, o.Integrity)
+			fill(&e.script.CrossOrigin, o.CrossOrigin)
+			fill(&e.script.Type, o.Type)
+		}
+		return
+	}
+	e.url, e.script = url, &o
+}
+
+// Stylesheet adds a stylesheet include keyed by url.  It renders with
+// Links().
+func (b *Builder) Stylesheet(url string, o StyleOpts) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, dup := b.addLocked("css:"+url, &b.links, "", o.Weight, HeadEnd)
+	if dup {
+		if e.style != nil {
+			fill(&e.style.Media, o.Media)
+			fill(&e.style.Integrity, o.Integrity)
+			fill(&e.style.CrossOrigin, o.CrossOrigin)
+		}
+		return
+	}
+	e.url, e.style = url, &o
+}
+
+// fill sets *dst to v when *dst is empty.
+func fill(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+func scriptTag(url string, o *ScriptOpts) string {
+	var sb strings.Builder
+	sb.WriteString(`<script src="`)
+	sb.WriteString(template.HTMLEscapeString(url))
+	sb.WriteByte('"')
+	attr(&sb, "type", o.Type)
+	if o.Async {
+		sb.WriteString(" async")
+	}
+	if o.Defer {
+		sb.WriteString(" defer")
+	}
+	attr(&sb, "integrity", o.Integrity)
+	attr(&sb, "crossorigin", o.CrossOrigin)
+	sb.WriteString("></script>")
+	return sb.String()
+}
+
+func styleTag(url string, o *StyleOpts) string {
+	var sb strings.Builder
+	sb.WriteString(`<link rel="stylesheet" href="`)
+	sb.WriteString(template.HTMLEscapeString(url))
+	sb.WriteByte('"')
+	attr(&sb, "media", o.Media)
+	attr(&sb, "integrity", o.Integrity)
+	attr(&sb, "crossorigin", o.CrossOrigin)
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+// attr writes ` name="value"` when value is non-empty.
+func attr(sb *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(name)
+	sb.WriteString(`="`)
+	sb.WriteString(template.HTMLEscapeString(value))
+	sb.WriteByte('"')
+}
@@ -9,37 +9,107 @@
 // --------
 //   - SetTitle           – single <title> tag (last call wins).
 //   - Meta, Link, Script – arbitrary tags with optional deduplication.
+//   - MetaP, LinkP,      – weighted variants; lower weights render first,
+//     ScriptP              ties keep call order.  ScriptP also takes a
+//     Position so a script can land early in <head> or before </body>.
+//   - ScriptSrc,         – URL-keyed assets with attributes (async, defer,
+//     Stylesheet           integrity, media); see assets.go.
+//   - Preload,           – validated resource hints, deduped by URL and
+//     Preconnect           emitted ahead of other links; see hints.go.
+//   - InlineStyle,       – nonce-stamped critical CSS and non-blocking
+//     StylesheetAsync      stylesheet loads; see inline.go.
+//   - SetCanonical,      – typed, escaped tags keyed by property name; the
+//     SetDescription,      last caller wins (see social.go).  OpenGraph
+//     SetOpenGraph,        and TwitterCard accept free-form maps and emit
+//     SetTwitterCard       through the same Metas() / Links() slices.
 //   - JSONLD             – stores raw JSON-LD strings and wraps them in
-//     <script type="application/ld+json">…</script>.
-//   - Render helpers     – concat methods that return template.HTML.
+//     <script type="application/ld+json">…</script>.  Article,
+//     BreadcrumbList, and Organization build those strings from typed
+//     data (see jsonld.go).
+//   - Render helpers     – concat methods that return template.HTML;
+//     RenderAll emits the full head (themes call it as {{ head }}).
+//
+// Ordering
+// --------
+// A theme that registers jQuery at weight -100 is emitted before a
+// component script added earlier at the default weight 0.  Deduplication
+// ignores weight: a repeated tag is kept once, at the lowest weight and
+// earliest Position any caller asked for, so dependents still follow it.
 package head
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"html/template"
+	"sort"
 	"strings"
 	"sync"
 )
 
+// Position says where in the document a script is emitted.  Values follow
+// document order; the zero value means HeadEnd.
+type Position int
+
+const (
+	HeadStart Position = iota + 1 // first thing in <head>; see EarlyScripts
+	HeadEnd                       // default; see Scripts
+	BodyEnd                       // just before </body>; see BodyScripts
+)
+
+// entry is one tag plus its ordering keys.  Raw tags live in tag; URL
+// assets (assets.go) keep url plus options, and inline blocks (inline.go)
+// keep element plus body, and build their tag at render.
+type entry struct {
+	tag    string
+	weight int
+	pos    Position
+	seq    int // insertion order, breaks weight ties
+
+	url    string
+	script *ScriptOpts
+	style  *StyleOpts
+
+	element string // "style" or "script" for inline blocks
+	body    string // already escaped for element
+}
+
+// html returns the finished tag.  nonce stamps inline blocks.
+func (e *entry) html(nonce string) string {
+	switch {
+	case e.script != nil:
+		return scriptTag(e.url, e.script)
+	case e.style != nil:
+		return styleTag(e.url, e.style)
+	case e.element != "":
+		return inlineTag(e.element, e.body, nonce)
+	}
+	return e.tag
+}
+
 // Builder is **not** safe for concurrent writes from multiple goroutines,
 // but typical use is one goroutine per request, so a simple mutex is enough.
 type Builder struct {
 	mu sync.Mutex
 
 	// Single-value fields
-	title string
+	title       string
+	titleSuffix string              // site name appended as "Page – Site"
+	nonce       string              // CSP nonce for inline scripts (nonce.go)
+	publicPath  func(string) string // maps canonical paths (social.go)
 
 	// Multi-value slices
-	metas   []string
-	links   []string
-	scripts []string
+	metas   []*entry
+	links   []*entry
+	scripts []*entry // every Position; filtered at render time
 	jsonLD  []string
 
-	// seen tracks keys for deduplication (optional).
-	seen map[string]struct{}
+	// seen tracks keys for deduplication and points at the stored entry.
+	seen map[string]*entry
+	seq  int
 }
 
 func New() *Builder {
-	return &Builder{seen: make(map[string]struct{})}
+	return &Builder{seen: make(map[string]*entry)}
 }
 
 // ------------------------------------------------------------------
@@ -53,12 +123,32 @@ func (b *Builder) SetTitle(t string) {
 	b.mu.Unlock()
 }
 
-// Title returns a fully formed <title> tag or an empty string.
+// SetTitleSuffix sets the site-wide suffix, normally from the tenant's
+// head.title_suffix config key.
+func (b *Builder) SetTitleSuffix(s string) {
+	b.mu.Lock()
+	b.titleSuffix = s
+	b.mu.Unlock()
+}
+
+// Title returns a fully formed <title> tag or an empty string.  With a
+// suffix set it composes "Page – Site Name", or just the suffix when no
+// page title was given.
 func (b *Builder) Title() template.HTML {
-	if b.title == "" {
+	b.mu.Lock()
+	t, suffix := b.title, b.titleSuffix
+	b.mu.Unlock()
+
+	switch {
+	case t != "" && suffix != "":
+		t += " – " + suffix
+	case t == "":
+		t = suffix
+	}
+	if t == "" {
 		return ""
 	}
-	escaped := template.HTMLEscapeString(b.title)
+	escaped := template.HTMLEscapeString(t)
 	return template.HTML("<title>" + escaped + "</title>")
 }
 
@@ -66,36 +156,100 @@ func (b *Builder) Title() template.HTML {
 // Slice helpers with deduplication
 // ------------------------------------------------------------------
 
-func (b *Builder) Meta(tag string)   { b.add("meta:"+tag, &b.metas, tag) }
-func (b *Builder) Link(tag string)   { b.add("link:"+tag, &b.links, tag) }
-func (b *Builder) Script(tag string) { b.add("script:"+tag, &b.scripts, tag) }
-func (b *Builder) JSONLD(js string)  { b.add("jsonld:"+hash(js), &b.jsonLD, js) }
+// Meta, Link, and Script are weight-0 wrappers kept for compatibility.
+func (b *Builder) Meta(tag string)   { b.MetaP(tag, 0) }
+func (b *Builder) Link(tag string)   { b.LinkP(tag, 0) }
+func (b *Builder) Script(tag string) { b.ScriptP(tag, 0, HeadEnd) }
+
+// MetaP adds a <meta> tag at the given weight.
+func (b *Builder) MetaP(tag string, weight int) {
+	b.add("meta:"+tag, &b.metas, tag, weight, HeadEnd)
+}
+
+// LinkP adds a <link> tag at the given weight.
+func (b *Builder) LinkP(tag string, weight int) {
+	b.add("link:"+tag, &b.links, tag, weight, HeadEnd)
+}
 
-func (b *Builder) add(key string, tgt *[]string, tag string) {
+// ScriptP adds a <script> tag at the given weight and Position.
+func (b *Builder) ScriptP(tag string, weight int, pos Position) {
+	b.add("script:"+tag, &b.scripts, tag, weight, pos)
+}
+
+// JSONLD adds a raw JSON-LD block, deduplicated by content.  Prefer the
+// typed helpers in jsonld.go, which always produce valid JSON.
+func (b *Builder) JSONLD(js string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	key := "jsonld:" + hash(js)
 	if _, dup := b.seen[key]; dup {
 		return
 	}
-	b.seen[key] = struct{}{}
-	*tgt = append(*tgt, tag)
+	b.seen[key] = nil
+	b.jsonLD = append(b.jsonLD, js)
 }
 
-// hash creates a short, stable key for JSON-LD strings.
-func hash(s string) string {
-	if len(s) > 32 {
-		return s[:32]
+func (b *Builder) add(key string, tgt *[]*entry, tag string, weight int, pos Position) {
+	b.mu.Lock()
+	b.addLocked(key, tgt, tag, weight, pos)
+	b.mu.Unlock()
+}
+
+// set stores tag under key, replacing any earlier tag with the same key in
+// place so the last caller wins while render order stays stable.
+func (b *Builder) set(key string, tgt *[]*entry, tag string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.seen[key]; ok {
+		e.tag = tag
+		return
+	}
+	b.addLocked(key, tgt, tag, 0, HeadEnd)
+}
+
+// addLocked stores a new entry or, for a repeated key, reconciles ordering
+// and returns the existing one with dup == true.  Caller holds b.mu.
+func (b *Builder) addLocked(key string, tgt *[]*entry, tag string, weight int,
+	pos Position) (e *entry, dup bool) {
+	if pos == 0 {
+		pos = HeadEnd
 	}
-	return s
+	if e, dup := b.seen[key]; dup {
+		// Honour the most demanding caller so dependents stay behind it.
+		e.weight = min(e.weight, weight)
+		e.pos = min(e.pos, pos)
+		return e, true
+	}
+	b.seq++
+	e = &entry{tag: tag, weight: weight, pos: pos, seq: b.seq}
+	b.seen[key] = e
+	*tgt = append(*tgt, e)
+	return e, false
+}
+
+// hash creates a short, stable key for JSON-LD strings.  It covers the
+// whole content: every schema.org block starts with the same @context, so
+// a prefix would fold distinct blocks together.
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
 }
 
 // ------------------------------------------------------------------
 // Rendering helpers called from theme templates
 // ------------------------------------------------------------------
 
-func (b *Builder) Metas() template.HTML   { return concat(b.metas) }
-func (b *Builder) Links() template.HTML   { return concat(b.links) }
-func (b *Builder) Scripts() template.HTML { return concat(b.scripts) }
+func (b *Builder) Metas() template.HTML { return b.render(b.metas, HeadEnd) }
+func (b *Builder) Links() template.HTML { return b.render(b.links, HeadEnd) }
+
+// EarlyScripts returns HeadStart scripts; place it first inside <head>.
+func (b *Builder) EarlyScripts() template.HTML { return b.render(b.scripts, HeadStart) }
+
+// Scripts returns HeadEnd scripts, the default Position.
+func (b *Builder) Scripts() template.HTML { return b.render(b.scripts, HeadEnd) }
+
+// BodyScripts returns BodyEnd scripts; place it just before </body>.
+func (b *Builder) BodyScripts() template.HTML { return b.render(b.scripts, BodyEnd) }
 
 // JSON returns all JSON-LD blocks wrapped in <script> tags.
 func (b *Builder) JSON() template.HTML {
@@ -111,7 +265,39 @@ func (b *Builder) JSON() template.HTML {
 	return template.HTML(sb.String())
 }
 
-// concat joins pre-escaped tags without a separator.
-func concat(sl []string) template.HTML {
-	return template.HTML(strings.Join(sl, ""))
+// RenderAll returns the whole <head> payload in one call, in fixed order:
+// early scripts, title, metas, links, scripts, and JSON-LD.  BodyScripts is
+// not included; it belongs before </body>.  Themes needing finer control
+// keep calling the individual methods.
+func (b *Builder) RenderAll() template.HTML {
+	var sb strings.Builder
+	for _, part := range []template.HTML{
+		b.EarlyScripts(), b.Title(), b.Metas(), b.Links(), b.Scripts(), b.JSON(),
+	} {
+		sb.WriteString(string(part))
+	}
+	return template.HTML(sb.String())
+}
+
+// render joins the entries at pos, ordered by weight then insertion.
+func (b *Builder) render(sl []*entry, pos Position) template.HTML {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]*entry, 0, len(sl))
+	for _, e := range sl {
+		if e.pos == pos {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].weight != out[j].weight {
+			return out[i].weight < out[j].weight
+		}
+		return out[i].seq < out[j].seq
+	})
+	var sb strings.Builder
+	for _, e := range out {
+		sb.WriteString(e.html(b.nonce))
+	}
+	return template.HTML(sb.String())
 }
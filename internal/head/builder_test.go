@@ -0,0 +1,343 @@
+// internal/head/builder_test.go
+//
+// Unit-tests for Builder ordering and deduplication.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package head
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestScriptP_WeightBeatsCallOrder(t *testing.T) {
+	b := New()
+	b.Script(`<script src="/app.js"></script>`)
+	b.ScriptP(`<script src="/jquery.js"></script>`, -100, HeadEnd)
+	b.Script(`<script src="/late.js"></script>`)
+
+	want := `<script src="/jquery.js"></script><script src="/app.js"></script>` +
+		`<script src="/late.js"></script>`
+	if got := string(b.Scripts()); got != want {
+		t.Fatalf("Scripts() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestScriptP_Positions(t *testing.T) {
+	b := New()
+	b.ScriptP(`<script src="/body.js"></script>`, 0, BodyEnd)
+	b.ScriptP(`<script src="/early.js"></script>`, 0, HeadStart)
+	b.Script(`<script src="/head.js"></script>`)
+
+	if got := string(b.EarlyScripts()); got != `<script src="/early.js"></script>` {
+		t.Fatalf("EarlyScripts() = %s", got)
+	}
+	if got := string(b.Scripts()); got != `<script src="/head.js"></script>` {
+		t.Fatalf("Scripts() = %s", got)
+	}
+	if got := string(b.BodyScripts()); got != `<script src="/body.js"></script>` {
+		t.Fatalf("BodyScripts() = %s", got)
+	}
+}
+
+func TestDedup_AcrossPriorities(t *testing.T) {
+	b := New()
+	tag := `<script src="/lib.js"></script>`
+	b.ScriptP(tag, 10, BodyEnd)
+	b.ScriptP(`<script src="/dep.js"></script>`, 0, HeadEnd)
+	b.ScriptP(tag, -5, HeadEnd) // a dependent needs it earlier
+
+	if got := string(b.BodyScripts()); got != "" {
+		t.Fatalf("duplicate left in BodyEnd: %s", got)
+	}
+	want := tag + `<script src="/dep.js"></script>`
+	if got := string(b.Scripts()); got != want {
+		t.Fatalf("Scripts() = %s, want %s", got, want)
+	}
+
+	b.MetaP(`<meta name="a">`, 5)
+	b.Meta(`<meta name="a">`)
+	if got := string(b.Metas()); got != `<meta name="a">` {
+		t.Fatalf("Metas() = %s", got)
+	}
+}
+
+func TestScriptSrc_DedupByURL(t *testing.T) {
+	b := New()
+	b.ScriptSrc("/js/jquery.js", ScriptOpts{Defer: true, Weight: 10})
+	b.ScriptSrc("/js/app.js", ScriptOpts{})
+	b.ScriptSrc("/js/jquery.js", ScriptOpts{Integrity: "sha384-abc", Weight: -10})
+
+	want := `<script src="/js/jquery.js" defer integrity="sha384-abc"></script>` +
+		`<script src="/js/app.js"></script>`
+	if got := string(b.Scripts()); got != want {
+		t.Fatalf("Scripts() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestScriptSrc_AttributesPreserved(t *testing.T) {
+	b := New()
+	b.ScriptSrc("/m.js", ScriptOpts{Type: "module", Async: true, CrossOrigin: "anonymous",
+		Position: BodyEnd})
+	b.ScriptSrc("/m.js", ScriptOpts{Defer: true, Type: "text/javascript", Position: BodyEnd})
+
+	want := `<script src="/m.js" type="module" async crossorigin="anonymous"></script>`
+	if got := string(b.BodyScripts()); got != want {
+		t.Fatalf("BodyScripts() = %s, want %s", got, want)
+	}
+}
+
+func TestStylesheet_DedupAndMedia(t *testing.T) {
+	b := New()
+	b.Stylesheet("/css/site.css", StyleOpts{})
+	b.Stylesheet("/css/print.css", StyleOpts{Media: "print", Weight: 5})
+	b.Stylesheet("/css/site.css", StyleOpts{Media: "screen"})
+	b.Link(`<link rel="icon" href="/favicon.ico">`)
+
+	want := `<link rel="stylesheet" href="/css/site.css" media="screen">` +
+		`<link rel="icon" href="/favicon.ico">` +
+		`<link rel="stylesheet" href="/css/print.css" media="print">`
+	if got := string(b.Links()); got != want {
+		t.Fatalf("Links() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAssetURLs_Escaped(t *testing.T) {
+	b := New()
+	b.ScriptSrc(`/x.js" onload="alert(1)`, ScriptOpts{})
+	b.Stylesheet(`/x.css?a=1&b="><script>`, StyleOpts{})
+
+	wantScript := `<script src="/x.js&#34; onload=&#34;alert(1)"></script>`
+	if got := string(b.Scripts()); got != wantScript {
+		t.Fatalf("Scripts() = %s, want %s", got, wantScript)
+	}
+	wantLink := `<link rel="stylesheet" href="/x.css?a=1&amp;b=&#34;&gt;&lt;script&gt;">`
+	if got := string(b.Links()); got != wantLink {
+		t.Fatalf("Links() = %s, want %s", got, wantLink)
+	}
+}
+
+func TestSocial_LastWinsAndEscapes(t *testing.T) {
+	b := New()
+	b.Meta(`<meta charset="utf-8">`)
+	b.SetOpenGraph(OpenGraphData{Title: "First", Type: "website"})
+	b.SetDescription(`Fish & "chips"`)
+	b.SetOpenGraph(OpenGraphData{Title: "Second"})
+
+	want := `<meta charset="utf-8">` +
+		`<meta property="og:title" content="Second">` +
+		`<meta property="og:type" content="website">` +
+		`<meta name="description" content="Fish &amp; &#34;chips&#34;">`
+	if got := string(b.Metas()); got != want {
+		t.Fatalf("Metas() =\n%s\nwant\n%s", got, want)
+	}
+
+	b.SetCanonical("https://a.example/x")
+	b.SetCanonical("https://a.example/y")
+	if got := string(b.Links()); got != `<link rel="canonical" href="https://a.example/y">` {
+		t.Fatalf("Links() = %s", got)
+	}
+}
+
+func TestTitle_Suffix(t *testing.T) {
+	b := New()
+	b.SetTitleSuffix("Adept Travel")
+	if got := string(b.Title()); got != "<title>Adept Travel</title>" {
+		t.Fatalf("suffix only: %s", got)
+	}
+	b.SetTitle("Tours")
+	if got := string(b.Title()); got != "<title>Tours – Adept Travel</title>" {
+		t.Fatalf("composed: %s", got)
+	}
+}
+
+func TestOpenGraphMap_UsesPropertyAttribute(t *testing.T) {
+	b := New()
+	b.OpenGraph(map[string]string{"title": "Tours", "og:locale": "en_US"})
+	b.TwitterCard(map[string]string{"card": "summary"})
+
+	want := `<meta property="og:locale" content="en_US">` +
+		`<meta property="og:title" content="Tours">` +
+		`<meta name="twitter:card" content="summary">`
+	if got := string(b.Metas()); got != want {
+		t.Fatalf("Metas() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(string(b.Metas()), `name="og:`) {
+		t.Fatal("og tag emitted with name attribute")
+	}
+}
+
+func TestOpenGraphMap_DedupByProperty(t *testing.T) {
+	b := New()
+	b.SetOpenGraph(OpenGraphData{Title: "Struct"})
+	b.OpenGraph(map[string]string{"og:title": "Map"})
+	if got := string(b.Metas()); got != `<meta property="og:title" content="Map">` {
+		t.Fatalf("Metas() = %s", got)
+	}
+
+	b.Canonical("https://a.example/")
+	if got := string(b.Links()); got != `<link rel="canonical" href="https://a.example/">` {
+		t.Fatalf("Links() = %s", got)
+	}
+}
+
+func TestRenderAll_FixedOrder(t *testing.T) {
+	b := New()
+	b.JSONLD(`{"@type":"Organization"}`)
+	b.ScriptSrc("/app.js", ScriptOpts{})
+	b.Stylesheet("/app.css", StyleOpts{})
+	b.SetDescription("desc")
+	b.SetTitle("Tours")
+	b.ScriptSrc("/early.js", ScriptOpts{Position: HeadStart})
+	b.ScriptSrc("/late.js", ScriptOpts{Position: BodyEnd})
+
+	out := string(b.RenderAll())
+	order := []string{"/early.js", "<title>", `name="description"`, "/app.css", "/app.js", "ld+json"}
+	last := -1
+	for _, s := range order {
+		i := strings.Index(out, s)
+		if i < 0 || i < last {
+			t.Fatalf("%q out of order in %s", s, out)
+		}
+		last = i
+	}
+	if strings.Contains(out, "/late.js") {
+		t.Fatalf("BodyEnd script leaked into head: %s", out)
+	}
+	if want := string(b.EarlyScripts() + b.Title() + b.Metas() + b.Links() + b.Scripts() + b.JSON()); out != want {
+		t.Fatalf("RenderAll differs from individual methods:\n%s\n%s", out, want)
+	}
+}
+
+func TestPreload_FontGetsCrossOriginAndType(t *testing.T) {
+	b := New()
+	if err := b.Preload("/fonts/inter.woff2", "font"); err != nil {
+		t.Fatal(err)
+	}
+	_ = b.Preload("/fonts/inter.woff2", "font") // dedup
+	if err := b.Preload("/hero.jpg", "image"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(b.Links())
+	want := `<link rel="preload" href="/fonts/inter.woff2" as="font" type="font/woff2" crossorigin="anonymous">` +
+		`<link rel="preload" href="/hero.jpg" as="image">`
+	if got != want {
+		t.Fatalf("Links:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestHints_HrefEscaped(t *testing.T) {
+	b := New()
+	if err := b.Preload(`/hero.jpg" onerror="x`, "image"); err != nil {
+		t.Fatal(err)
+	}
+	b.Preconnect(`https://cdn.example"><script>`)
+
+	got := string(b.Links())
+	want := `<link rel="preload" href="/hero.jpg&#34; onerror=&#34;x" as="image">` +
+		`<link rel="preconnect" href="https://cdn.example&#34;&gt;&lt;script&gt;">`
+	if got != want {
+		t.Fatalf("Links:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestPreload_RejectsUnknownAs(t *testing.T) {
+	b := New()
+	if err := b.Preload("/x.json", "fetch"); err == nil {
+		t.Fatal("expected error for as=fetch")
+	}
+	if b.Links() != "" {
+		t.Fatalf("invalid hint emitted: %s", b.Links())
+	}
+}
+
+func TestPreconnect_DedupAndOrderBeforeStyles(t *testing.T) {
+	b := New()
+	b.Stylesheet("/app.css", StyleOpts{})
+	b.Preconnect("https://fonts.example/")
+	b.Preconnect("https://fonts.example")
+
+	got := string(b.Links())
+	want := `<link rel="preconnect" href="https://fonts.example">` +
+		`<link rel="stylesheet" href="/app.css">`
+	if got != want {
+		t.Fatalf("Links:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestInlineStyle_NonceEscapeAndDedup(t *testing.T) {
+	b := New()
+	b.SetNonce("n0nce")
+	b.InlineStyle(`body{margin:0}`)
+	b.InlineStyle(`body{margin:0}`)
+	b.InlineStyle(`a::after{content:"</style><script>x()</script>"}`)
+
+	got := string(b.Links())
+	if strings.Count(got, "<style") != 2 {
+		t.Fatalf("dedup failed: %s", got)
+	}
+	if !strings.Contains(got, `<style nonce="n0nce">body{margin:0}</style>`) {
+		t.Fatalf("missing nonce'd block: %s", got)
+	}
+	if strings.Count(got, "</style>") != 2 || strings.Contains(got, "<script>") {
+		t.Fatalf("style context not escaped: %s", got)
+	}
+}
+
+func TestStyleHash_MatchesEmittedBody(t *testing.T) {
+	css := `h1{color:red}/*<*/`
+	b := New()
+	b.InlineStyle(css)
+	out := string(b.Links())
+	body := out[strings.Index(out, ">")+1 : strings.LastIndex(out, "</style>")]
+
+	sum := sha256.Sum256([]byte(body))
+	want := "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+	if got := StyleHash(css); got != want {
+		t.Fatalf("StyleHash = %s, want %s", got, want)
+	}
+}
+
+func TestStylesheetAsync_SwapScriptOnce(t *testing.T) {
+	b := New()
+	b.SetNonce("n")
+	b.StylesheetAsync("/full.css")
+	b.StylesheetAsync("/full.css")
+	b.StylesheetAsync("/extra.css")
+
+	links := string(b.Links())
+	if strings.Count(links, `href="/full.css" media="print" data-async-css`) != 1 ||
+		!strings.Contains(links, `<noscript><link rel="stylesheet" href="/full.css"></noscript>`) {
+		t.Fatalf("links: %s", links)
+	}
+	body := string(b.BodyScripts())
+	if strings.Count(body, "<script") != 1 || !strings.HasPrefix(body, `<script nonce="n">`) {
+		t.Fatalf("swap script: %s", body)
+	}
+}
+
+func TestSetCanonical_PublicPath(t *testing.T) {
+	b := New()
+	b.SetPublicPath(func(p string) string {
+		if p == "/content/page/view/about" {
+			return "/about"
+		}
+		return p
+	})
+	b.Canonical("/content/page/view/about")
+	if got := string(b.Links()); got != `<link rel="canonical" href="/about">` {
+		t.Fatalf("Links() = %s", got)
+	}
+
+	// Absolute and protocol-relative URLs are left alone.
+	b.Canonical("//cdn.example/content/page/view/about")
+	if got := string(b.Links()); got != `<link rel="canonical" href="//cdn.example/content/page/view/about">` {
+		t.Fatalf("Links() = %s", got)
+	}
+}
@@ -0,0 +1,105 @@
+// internal/head/inline.go
+//
+// Inline critical CSS and non-blocking stylesheets.
+//
+// Context
+// -------
+// Above-the-fold rendering is fastest when the critical rules ship inline
+// and the full stylesheet loads without blocking paint.  Two helpers cover
+// the pattern:
+//
+//   - InlineStyle(css)     – one <style> block per distinct css, emitted
+//     through Links() after resource hints and before stylesheets.
+//   - StylesheetAsync(url) – <link rel="stylesheet" media="print"> that a
+//     tiny BodyEnd script flips to media="all" once parsed, with a
+//     <noscript> fallback.
+//
+// CSP
+// ---
+// middleware.Security adds the request nonce to both script-src and
+// style-src, and these blocks are stamped with it at render time, so they
+// pass a nonce policy without 'unsafe-inline'.  A policy that allows
+// 'unsafe-inline' styles gets no style nonce, and the blocks pass anyway.
+// Sites that pin a static policy via security.csp can allow a block by
+// hash instead: StyleHash returns the 'sha256-…' source for exactly the
+// bytes InlineStyle emits.
+// The async swap uses a nonce'd script, not an onload attribute, because
+// inline event handlers are blocked by nonce policies.
+//
+// Notes
+// -----
+// • CSS is escaped for the raw-text <style> context: every "<" becomes the
+//   CSS escape `\3c `, so "</style>" can never close the element early,
+//   and NUL bytes are dropped.  Legitimate CSS only uses "<" inside strings
+//   and comments, where the escape is equivalent.
+// • Oxford commas, two spaces after periods.
+
+package head
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"strings"
+)
+
+// criticalWeight places inline CSS after hints and before stylesheets.
+const criticalWeight = hintWeight / 2
+
+// asyncSwap flips every async stylesheet to media="all".
+const asyncSwap = `document.querySelectorAll('link[data-async-css]').forEach(` +
+	`function(l){l.media='all'})`
+
+var cssEscaper = strings.NewReplacer("<", `\3c `, "\x00", "")
+
+// escapeCSS makes css safe inside a <style> element.
+func escapeCSS(css string) string { return cssEscaper.Replace(css) }
+
+// InlineStyle emits css in a <style> block.  Identical css is emitted once.
+func (b *Builder) InlineStyle(css string) {
+	body := escapeCSS(css)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, dup := b.addLocked("inline-css:"+body, &b.links, "", criticalWeight, HeadEnd)
+	if !dup {
+		e.element, e.body = "style", body
+	}
+}
+
+// StyleHash returns the CSP source ('sha256-…') that allows the block
+// InlineStyle(css) emits.
+func StyleHash(css string) string {
+	sum := sha256.Sum256([]byte(escapeCSS(css)))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// StylesheetAsync loads url without blocking render.  Dedup follows
+// Stylesheet: one tag per URL.
+func (b *Builder) StylesheetAsync(url string) {
+	href := template.HTMLEscapeString(url)
+	b.add("css-async:"+url, &b.links,
+		`<link rel="stylesheet" href="`+href+`" media="print" data-async-css>`+
+			`<noscript><link rel="stylesheet" href="`+href+`"></noscript>`,
+		0, HeadEnd)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, dup := b.addLocked("inline-js:async-css", &b.scripts, "", 0, BodyEnd)
+	if !dup {
+		e.element, e.body = "script", asyncSwap
+	}
+}
+
+// inlineTag builds <element nonce="…">body</element>.
+func inlineTag(element, body, nonce string) string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	sb.WriteString(element)
+	attr(&sb, "nonce", nonce)
+	sb.WriteByte('>')
+	sb.WriteString(body)
+	sb.WriteString("</")
+	sb.WriteString(element)
+	sb.WriteByte('>')
+	return sb.String()
+}
@@ -0,0 +1,154 @@
+// internal/head/jsonld.go
+//
+// Typed schema.org JSON-LD builders.
+//
+// Context
+// -------
+// Builder.JSONLD takes a raw string, so components used to hand-assemble
+// JSON and could ship a stray comma or an unescaped quote in SEO markup.
+// These helpers marshal typed data instead and return the JSON for
+// JSONLD:
+//
+//	b.JSONLD(head.Article(head.ArticleData{Headline: p.Title, …}))
+//	b.JSONLD(head.BreadcrumbList([]head.Crumb{{"Home", "/"}, {"Blog", "/blog"}}))
+//
+// Notes
+// -----
+// • Empty fields are omitted, so callers set only what they know.
+// • encoding/json escapes <, >, and &, so a value containing "</script>"
+//   cannot end the surrounding tag.
+// • JSONLD dedups by content hash; the same block added twice renders once.
+// • Oxford commas, two spaces after periods.
+
+package head
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const schemaContext = "https://schema.org"
+
+// OrganizationData describes a schema.org Organization.
+type OrganizationData struct {
+	Name   string
+	URL    string
+	Logo   string   // absolute URL of the logo image
+	SameAs []string // social profile URLs
+}
+
+// ArticleData describes a schema.org Article.
+type ArticleData struct {
+	Headline      string
+	Description   string
+	URL           string
+	Image         []string
+	Author        string // person name
+	Publisher     *OrganizationData
+	DatePublished time.Time
+	DateModified  time.Time
+}
+
+// Crumb is one BreadcrumbList entry.  Positions follow slice order.
+type Crumb struct {
+	Name string
+	URL  string
+}
+
+// Organization returns the JSON-LD for an Organization.
+func Organization(o OrganizationData) string {
+	return marshalLD(organization(o, true))
+}
+
+// Article returns the JSON-LD for an Article.
+func Article(a ArticleData) string {
+	v := struct {
+		Context       string   `json:"@context"`
+		Type          string   `json:"@type"`
+		Headline      string   `json:"headline,omitempty"`
+		Description   string   `json:"description,omitempty"`
+		URL           string   `json:"url,omitempty"`
+		Image         []string `json:"image,omitempty"`
+		Author        *ldThing `json:"author,omitempty"`
+		Publisher     *ldOrg   `json:"publisher,omitempty"`
+		DatePublished string   `json:"datePublished,omitempty"`
+		DateModified  string   `json:"dateModified,omitempty"`
+	}{
+		Context:       schemaContext,
+		Type:          "Article",
+		Headline:      a.Headline,
+		Description:   a.Description,
+		URL:           a.URL,
+		Image:         a.Image,
+		DatePublished: isoDate(a.DatePublished),
+		DateModified:  isoDate(a.DateModified),
+	}
+	if a.Author != "" {
+		v.Author = &ldThing{Type: "Person", Name: a.Author}
+	}
+	if a.Publisher != nil {
+		v.Publisher = organization(*a.Publisher, false)
+	}
+	return marshalLD(v)
+}
+
+// BreadcrumbList returns the JSON-LD for a BreadcrumbList.
+func BreadcrumbList(items []Crumb) string {
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item,omitempty"`
+	}
+	list := make([]listItem, len(items))
+	for i, c := range items {
+		list[i] = listItem{Type: "ListItem", Position: i + 1, Name: c.Name, Item: c.URL}
+	}
+	return marshalLD(struct {
+		Context string     `json:"@context"`
+		Type    string     `json:"@type"`
+		Items   []listItem `json:"itemListElement"`
+	}{schemaContext, "BreadcrumbList", list})
+}
+
+// ldThing is a nested typed node such as an author or a logo.
+type ldThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type ldOrg struct {
+	Context string   `json:"@context,omitempty"` // top level only
+	Type    string   `json:"@type"`
+	Name    string   `json:"name,omitempty"`
+	URL     string   `json:"url,omitempty"`
+	Logo    *ldThing `json:"logo,omitempty"`
+	SameAs  []string `json:"sameAs,omitempty"`
+}
+
+func organization(o OrganizationData, top bool) *ldOrg {
+	v := &ldOrg{Type: "Organization", Name: o.Name, URL: o.URL, SameAs: o.SameAs}
+	if top {
+		v.Context = schemaContext
+	}
+	if o.Logo != "" {
+		v.Logo = &ldThing{Type: "ImageObject", URL: o.Logo}
+	}
+	return v
+}
+
+// isoDate formats t as RFC 3339, or "" for the zero time.
+func isoDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// marshalLD encodes v.  The builder types hold only strings, slices, and
+// pointers to the same, so Marshal cannot fail.
+func marshalLD(v any) string {
+	out, _ := json.Marshal(v)
+	return string(out)
+}
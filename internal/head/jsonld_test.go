@@ -0,0 +1,77 @@
+// internal/head/jsonld_test.go
+//
+// Unit-tests for the typed JSON-LD builders.
+
+package head
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseLD(t *testing.T, js string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(js), &m); err != nil {
+		t.Fatalf("invalid JSON-LD %s: %v", js, err)
+	}
+	if m["@context"] != "https://schema.org" {
+		t.Fatalf("@context = %v", m["@context"])
+	}
+	return m
+}
+
+func TestArticle(t *testing.T) {
+	pub := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	js := Article(ArticleData{
+		Headline:      `Lisbon "off-season" </script>`,
+		Author:        "Ana Silva",
+		Publisher:     &OrganizationData{Name: "Adept Travel", Logo: "https://x.example/logo.png"},
+		DatePublished: pub,
+	})
+	if strings.Contains(js, "</script>") {
+		t.Fatalf("value can close the script tag: %s", js)
+	}
+	m := parseLD(t, js)
+	if m["@type"] != "Article" || m["headline"] != `Lisbon "off-season" </script>` {
+		t.Fatalf("article = %v", m)
+	}
+	if m["datePublished"] != "2025-03-01T09:00:00Z" || m["dateModified"] != nil {
+		t.Fatalf("dates = %v / %v", m["datePublished"], m["dateModified"])
+	}
+	author := m["author"].(map[string]any)
+	pubr := m["publisher"].(map[string]any)
+	if author["@type"] != "Person" || pubr["@type"] != "Organization" || pubr["@context"] != nil {
+		t.Fatalf("author = %v, publisher = %v", author, pubr)
+	}
+}
+
+func TestBreadcrumbList(t *testing.T) {
+	m := parseLD(t, BreadcrumbList([]Crumb{{"Home", "/"}, {"Tours", "/tours"}}))
+	items := m["itemListElement"].([]any)
+	if m["@type"] != "BreadcrumbList" || len(items) != 2 {
+		t.Fatalf("breadcrumbs = %v", m)
+	}
+	second := items[1].(map[string]any)
+	if second["@type"] != "ListItem" || second["position"] != 2.0 || second["item"] != "/tours" {
+		t.Fatalf("item 2 = %v", second)
+	}
+}
+
+func TestOrganization_EmbedsAndDedups(t *testing.T) {
+	org := Organization(OrganizationData{Name: "Adept", URL: "https://adept.example"})
+	if m := parseLD(t, org); m["@type"] != "Organization" || m["logo"] != nil {
+		t.Fatalf("organization = %v", m)
+	}
+
+	b := New()
+	b.JSONLD(org)
+	b.JSONLD(BreadcrumbList([]Crumb{{"Home", "/"}})) // same @context prefix, kept
+	b.JSONLD(org)
+	out := string(b.JSON())
+	if n := strings.Count(out, "ld+json"); n != 2 {
+		t.Fatalf("got %d blocks, want 2: %s", n, out)
+	}
+}
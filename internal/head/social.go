@@ -0,0 +1,151 @@
+// internal/head/social.go
+//
+// Canonical, description, Open Graph, and Twitter Card helpers.
+//
+// Context
+// -------
+// Components used to hand-assemble strings such as
+// `<meta property="og:title" content="…">`, which invites unescaped quotes
+// and two components emitting conflicting values.  These helpers build the
+// tag, escape every value, and key it by property name.  A later call for
+// the same property replaces the earlier value in place, so the last
+// component to set og:title wins deterministically and render order does
+// not change.
+//
+// Notes
+// -----
+// • Open Graph uses the `property` attribute; Twitter Cards use `name`.
+// • Empty struct fields are skipped, so callers set only what they know.
+// • OpenGraph and TwitterCard take free-form maps for properties the structs
+//   do not cover (og:locale, og:image:width, …).  Keys may omit the prefix;
+//   tags are emitted in sorted key order so output is stable.
+// • Raw tags pushed through Meta() and Link() are untouched.
+// • A root-relative canonical path is mapped to its public alias through
+//   SetPublicPath, the same function {{ url }} uses.
+// • Oxford commas, two spaces after periods.
+
+package head
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// OpenGraphData holds the common og:* properties.
+type OpenGraphData struct {
+	Title       string
+	Type        string // "website", "article", …
+	Image       string
+	URL         string
+	Description string
+	SiteName    string
+}
+
+// TwitterCardData holds the common twitter:* properties.
+type TwitterCardData struct {
+	Card        string // "summary", "summary_large_image", …
+	Site        string // @handle of the site
+	Creator     string // @handle of the author
+	Title       string
+	Description string
+	Image       string
+}
+
+// SetCanonical emits <link rel="canonical">.  The last caller wins.  A
+// root-relative url ("/content/page/view/about") goes through the mapper
+// set by SetPublicPath, so it is printed as the tenant's alias.
+func (b *Builder) SetCanonical(url string) {
+	b.mu.Lock()
+	fn := b.publicPath
+	b.mu.Unlock()
+	if fn != nil && strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") {
+		url = fn(url)
+	}
+	b.set("canonical", &b.links,
+		`<link rel="canonical" href="`+template.HTMLEscapeString(url)+`">`)
+}
+
+// SetPublicPath installs the path mapper SetCanonical applies; the tenant
+// package passes routing.PublicPath bound to the request's tenant.
+func (b *Builder) SetPublicPath(fn func(string) string) {
+	b.mu.Lock()
+	b.publicPath = fn
+	b.mu.Unlock()
+}
+
+// Canonical is shorthand for SetCanonical.
+func (b *Builder) Canonical(url string) { b.SetCanonical(url) }
+
+// SetDescription emits <meta name="description">.  The last caller wins.
+func (b *Builder) SetDescription(s string) { b.nameMeta("description", s) }
+
+// SetOpenGraph emits one og:* tag per non-empty field.
+func (b *Builder) SetOpenGraph(og OpenGraphData) {
+	b.propertyMeta("og:title", og.Title)
+	b.propertyMeta("og:type", og.Type)
+	b.propertyMeta("og:image", og.Image)
+	b.propertyMeta("og:url", og.URL)
+	b.propertyMeta("og:description", og.Description)
+	b.propertyMeta("og:site_name", og.SiteName)
+}
+
+// SetTwitterCard emits one twitter:* tag per non-empty field.
+func (b *Builder) SetTwitterCard(tc TwitterCardData) {
+	b.nameMeta("twitter:card", tc.Card)
+	b.nameMeta("twitter:site", tc.Site)
+	b.nameMeta("twitter:creator", tc.Creator)
+	b.nameMeta("twitter:title", tc.Title)
+	b.nameMeta("twitter:description", tc.Description)
+	b.nameMeta("twitter:image", tc.Image)
+}
+
+// OpenGraph emits <meta property="og:…"> for every entry in m, e.g.
+// {"title": "Tours", "og:locale": "en_US"}.
+func (b *Builder) OpenGraph(m map[string]string) {
+	for _, k := range sortedKeys(m) {
+		b.propertyMeta(withPrefix("og:", k), m[k])
+	}
+}
+
+// TwitterCard emits <meta name="twitter:…"> for every entry in m, e.g.
+// {"card": "summary_large_image"}.
+func (b *Builder) TwitterCard(m map[string]string) {
+	for _, k := range sortedKeys(m) {
+		b.nameMeta(withPrefix("twitter:", k), m[k])
+	}
+}
+
+func withPrefix(prefix, k string) string {
+	if strings.HasPrefix(k, prefix) {
+		return k
+	}
+	return prefix + k
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// propertyMeta writes <meta property="p" content="v"> keyed by p.
+func (b *Builder) propertyMeta(p, v string) {
+	if v == "" {
+		return
+	}
+	b.set("prop:"+p, &b.metas, `<meta property="`+template.HTMLEscapeString(p)+
+		`" content="`+template.HTMLEscapeString(v)+`">`)
+}
+
+// nameMeta writes <meta name="n" content="v"> keyed by n.
+func (b *Builder) nameMeta(n, v string) {
+	if v == "" {
+		return
+	}
+	b.set("name:"+n, &b.metas, `<meta name="`+template.HTMLEscapeString(n)+
+		`" content="`+template.HTMLEscapeString(v)+`">`)
+}
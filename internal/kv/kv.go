@@ -0,0 +1,143 @@
+// internal/kv/kv.go
+//
+// Tenant-scoped key-value store for Components.
+//
+// Context
+// -------
+// Components keep small bits of per-tenant state: feature flags, counters,
+// one-off tokens.  Rather than each inventing a table, they call Get, Set,
+// Delete, and Incr here.  Every call is scoped to the tenant in ctx
+// (database.WithTenant); a context without one is refused, so state can
+// never land in, or be read from, the global database by accident.
+//
+// Backends
+// --------
+//   - SQL (default): the kv_store table in each tenant's own database,
+//     reached through database.Conn.  Tenants are isolated by schema.
+//   - Memory: a per-process map keyed by tenant, for tests and single-node
+//     development.  Install it with SetStore(NewMemory()).
+//
+// Another backend (Redis, say) only has to implement Store and prefix its
+// keys with the tenant ID.
+//
+// Rules
+// -----
+//   - Keys are 1 to MaxKeyLen bytes.  Prefix them with the Component name,
+//     "shop:cart_seq", so Components cannot collide.
+//   - ttl <= 0 means no expiry.  An expired key is gone for every call:
+//     Get reports ErrNotFound and Incr starts over from zero.  Expired rows
+//     are deleted lazily.
+//   - Incr treats the value as a base-10 int64.  A missing key starts at 0
+//     and takes ttl; an existing key keeps its expiry.  A non-numeric value
+//     is ErrNotInteger.
+//
+// Notes
+// -----
+//   - Oxford commas, two spaces after periods.
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanizio/adept/internal/database"
+)
+
+// MaxKeyLen is the longest key, in bytes (kv_store.kv_key).
+const MaxKeyLen = 191
+
+var (
+	// ErrNotFound means the key is absent or expired.
+	ErrNotFound = errors.New("kv: not found")
+	// ErrNoTenant means ctx carries no tenant.
+	ErrNoTenant = errors.New("kv: context has no tenant")
+	// ErrNotInteger means Incr met a value that is not an int64.
+	ErrNotInteger = errors.New("kv: value is not an integer")
+)
+
+// Store is a backend.  tenant is never empty and key is already checked.
+type Store interface {
+	Get(ctx context.Context, tenant, key string) ([]byte, error)
+	Set(ctx context.Context, tenant, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, tenant, key string) error
+	Incr(ctx context.Context, tenant, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+var store atomic.Pointer[Store]
+
+// SetStore installs s for every call.  nil restores the SQL backend.
+func SetStore(s Store) {
+	if s == nil {
+		store.Store(nil)
+		return
+	}
+	store.Store(&s)
+}
+
+var sqlStore = NewSQL()
+
+func current() Store {
+	if s := store.Load(); s != nil {
+		return *s
+	}
+	return sqlStore
+}
+
+// scope returns ctx's tenant after checking key.
+func scope(ctx context.Context, key string) (string, error) {
+	if key == "" || len(key) > MaxKeyLen {
+		return "", fmt.Errorf("kv: key length %d outside 1..%d", len(key), MaxKeyLen)
+	}
+	tenant, ok := database.TenantID(ctx)
+	if !ok || tenant == "" {
+		return "", ErrNoTenant
+	}
+	return tenant, nil
+}
+
+// Get returns the value of key, or ErrNotFound.
+func Get(ctx context.Context, key string) ([]byte, error) {
+	tenant, err := scope(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return current().Get(ctx, tenant, key)
+}
+
+// Set stores value under key, replacing any previous value and expiry.
+func Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	tenant, err := scope(ctx, key)
+	if err != nil {
+		return err
+	}
+	return current().Set(ctx, tenant, key, value, ttl)
+}
+
+// Delete removes key.  A missing key is not an error.
+func Delete(ctx context.Context, key string) error {
+	tenant, err := scope(ctx, key)
+	if err != nil {
+		return err
+	}
+	return current().Delete(ctx, tenant, key)
+}
+
+// Incr adds delta to the counter at key and returns the new value.
+func Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	tenant, err := scope(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return current().Incr(ctx, tenant, key, delta, ttl)
+}
+
+// expiry returns the deadline for ttl from now, or zero for none.
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
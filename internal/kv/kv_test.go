@@ -0,0 +1,108 @@
+// internal/kv/kv_test.go
+//
+// Unit-tests for the kv API on the Memory backend: tenant isolation,
+// expiry, Incr, and the context scope check.
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/database"
+)
+
+// memStore installs a Memory store with a settable clock for the test.
+func memStore(t *testing.T) (*Memory, *time.Time) {
+	t.Helper()
+	m := NewMemory()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	SetStore(m)
+	t.Cleanup(func() { SetStore(nil) })
+	return m, &now
+}
+
+func TestMemory_TenantIsolation(t *testing.T) {
+	memStore(t)
+	a := database.WithTenant(context.Background(), "a.example")
+	b := database.WithTenant(context.Background(), "b.example")
+
+	if err := Set(a, "shop:flag", []byte("on"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Get(a, "shop:flag"); err != nil || string(v) != "on" {
+		t.Fatalf("tenant a: %q, %v", v, err)
+	}
+	if _, err := Get(b, "shop:flag"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("tenant b saw tenant a's key: %v", err)
+	}
+
+	if err := Delete(a, "shop:flag"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get(a, "shop:flag"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("after Delete: %v", err)
+	}
+}
+
+func TestMemory_Expiry(t *testing.T) {
+	_, now := memStore(t)
+	ctx := database.WithTenant(context.Background(), "a.example")
+
+	if err := Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(59 * time.Second)
+	if _, err := Get(ctx, "k"); err != nil {
+		t.Fatalf("before expiry: %v", err)
+	}
+	*now = now.Add(time.Second)
+	if _, err := Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("at expiry: %v", err)
+	}
+}
+
+func TestMemory_Incr(t *testing.T) {
+	_, now := memStore(t)
+	ctx := database.WithTenant(context.Background(), "a.example")
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := Incr(ctx, "hits", 1, time.Minute)
+		if err != nil || n != want {
+			t.Fatalf("Incr = %d, %v; want %d", n, err, want)
+		}
+		*now = now.Add(15 * time.Second) // existing key keeps its expiry
+	}
+	*now = now.Add(15 * time.Second)
+	if n, err := Incr(ctx, "hits", 5, time.Minute); err != nil || n != 5 {
+		t.Fatalf("after expiry Incr = %d, %v; want 5", n, err)
+	}
+
+	if err := Set(ctx, "name", []byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Incr(ctx, "name", 1, 0); !errors.Is(err, ErrNotInteger) {
+		t.Fatalf("Incr on text: %v", err)
+	}
+}
+
+func TestScope(t *testing.T) {
+	memStore(t)
+	if _, err := Get(context.Background(), "k"); !errors.Is(err, ErrNoTenant) {
+		t.Fatalf("no tenant: %v", err)
+	}
+	ctx := database.WithTenant(context.Background(), "a.example")
+	if err := Set(ctx, "", nil, 0); err == nil {
+		t.Fatal("empty key accepted")
+	}
+	if err := Set(ctx, strings.Repeat("k", MaxKeyLen+1), nil, 0); err == nil {
+		t.Fatal("long key accepted")
+	}
+	if err := Set(ctx, strings.Repeat("k", MaxKeyLen), nil, 0); err != nil {
+		t.Fatalf("max key: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// internal/kv/memory.go
+//
+// In-memory kv backend for tests and single-node development.
+//
+// Notes
+// -----
+//   - One map per tenant; nothing is shared between tenants or processes,
+//     and nothing survives a restart.
+//   - Expired entries are dropped when next touched.
+//   - Oxford commas, two spaces after periods.
+package kv
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memEntry struct {
+	value   []byte
+	expires time.Time // zero: never
+}
+
+func (e memEntry) live(now time.Time) bool {
+	return e.expires.IsZero() || now.Before(e.expires)
+}
+
+// Memory is a Store held in process memory.  Safe for concurrent use.
+type Memory struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]memEntry
+	now     func() time.Time // stubbed in tests
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{tenants: make(map[string]map[string]memEntry), now: time.Now}
+}
+
+// lookup returns the live entry for tenant/key, dropping an expired one.
+// Callers hold mu.
+func (m *Memory) lookup(tenant, key string) (memEntry, bool) {
+	e, ok := m.tenants[tenant][key]
+	if ok && !e.live(m.now()) {
+		delete(m.tenants[tenant], key)
+		return memEntry{}, false
+	}
+	return e, ok
+}
+
+func (m *Memory) put(tenant, key string, e memEntry) {
+	t := m.tenants[tenant]
+	if t == nil {
+		t = make(map[string]memEntry)
+		m.tenants[tenant] = t
+	}
+	t[key] = e
+}
+
+// Get implements Store.
+func (m *Memory) Get(_ context.Context, tenant, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.lookup(tenant, key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), e.value...), nil
+}
+
+// Set implements Store.
+func (m *Memory) Set(_ context.Context, tenant, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(tenant, key, memEntry{
+		value:   append([]byte(nil), value...),
+		expires: expiry(m.now(), ttl),
+	})
+	return nil
+}
+
+// Delete implements Store.
+func (m *Memory) Delete(_ context.Context, tenant, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tenants[tenant], key)
+	return nil
+}
+
+// Incr implements Store.
+func (m *Memory) Incr(_ context.Context, tenant, key string, delta int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.lookup(tenant, key)
+	var n int64
+	if ok {
+		var err error
+		if n, err = strconv.ParseInt(string(e.value), 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	} else {
+		e.expires = expiry(m.now(), ttl)
+	}
+	n += delta
+	e.value = []byte(strconv.FormatInt(n, 10))
+	m.put(tenant, key, e)
+	return n, nil
+}
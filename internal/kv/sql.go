@@ -0,0 +1,185 @@
+// internal/kv/sql.go
+//
+// SQL kv backend: the kv_store table in each tenant database.
+//
+// Notes
+// -----
+//   - The tenant's pool comes from database.Conn(ctx); tenants are isolated
+//     because each has its own schema, so kv_store carries no tenant column.
+//   - Reads filter out expired rows, so expiry holds even before a purge.
+//     Set deletes a tenant's expired rows at most once a minute.
+//   - Incr runs in a transaction: the row is created if missing, locked
+//     with SELECT … FOR UPDATE, and rewritten, so concurrent increments on
+//     any number of nodes never lose an update.
+//   - Oxford commas, two spaces after periods.
+package kv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/database"
+)
+
+const purgeEvery = time.Minute
+
+// SQL is the Store over kv_store.  The zero value is not usable; call
+// NewSQL.
+type SQL struct {
+	now    func() time.Time // stubbed in tests
+	purged sync.Map         // tenant → *atomic.Int64, last purge (unix ns)
+}
+
+// NewSQL returns the SQL backend.
+func NewSQL() *SQL { return &SQL{now: time.Now} }
+
+func (s *SQL) conn(ctx context.Context, tenant string) (*sqlx.DB, error) {
+	db := database.Conn(ctx)
+	if db == nil {
+		return nil, fmt.Errorf("kv: no database for tenant %q", tenant)
+	}
+	return db, nil
+}
+
+// nullTime maps the zero deadline to NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// Get implements Store.
+func (s *SQL) Get(ctx context.Context, tenant, key string) ([]byte, error) {
+	db, err := s.conn(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	var v []byte
+	err = db.QueryRowContext(ctx,
+		`SELECT kv_value FROM kv_store WHERE kv_key = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		key, s.now().UTC()).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+// Set implements Store.
+func (s *SQL) Set(ctx context.Context, tenant, key string, value []byte, ttl time.Duration) error {
+	db, err := s.conn(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO kv_store (kv_key, kv_value, expires_at) VALUES (?, ?, ?)
+		 ON DUPLICATE KEY UPDATE kv_value = VALUES(kv_value), expires_at = VALUES(expires_at)`,
+		key, value, nullTime(expiry(now, ttl)))
+	if err != nil {
+		return err
+	}
+	s.maybePurge(ctx, db, tenant, now)
+	return nil
+}
+
+// Delete implements Store.
+func (s *SQL) Delete(ctx context.Context, tenant, key string) error {
+	db, err := s.conn(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `DELETE FROM kv_store WHERE kv_key = ?`, key)
+	return err
+}
+
+// Incr implements Store.
+func (s *SQL) Incr(ctx context.Context, tenant, key string, delta int64, ttl time.Duration) (int64, error) {
+	db, err := s.conn(ctx, tenant)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after Commit
+
+	now := s.now()
+	deadline := nullTime(expiry(now, ttl))
+	if _, err := tx.ExecContext(ctx,
+		`INSERT IGNORE INTO kv_store (kv_key, kv_value, expires_at) VALUES (?, '0', ?)`,
+		key, deadline); err != nil {
+		return 0, err
+	}
+
+	var (
+		raw     []byte
+		expires sql.NullTime
+	)
+	if err := tx.QueryRowContext(ctx,
+		`SELECT kv_value, expires_at FROM kv_store WHERE kv_key = ? FOR UPDATE`,
+		key).Scan(&raw, &expires); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	if expires.Valid && !now.Before(expires.Time) {
+		expires = sql.NullTime{} // expired: start over with a fresh ttl
+	} else if n, err = strconv.ParseInt(string(raw), 10, 64); err != nil {
+		return 0, ErrNotInteger
+	}
+	n += delta
+
+	var keep any = deadline
+	if expires.Valid {
+		keep = expires.Time.UTC()
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE kv_store SET kv_value = ?, expires_at = ? WHERE kv_key = ?`,
+		strconv.FormatInt(n, 10), keep, key); err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// Purge deletes ctx's tenant's expired rows and returns how many went.
+func (s *SQL) Purge(ctx context.Context) (int64, error) {
+	tenant, ok := database.TenantID(ctx)
+	if !ok {
+		return 0, ErrNoTenant
+	}
+	db, err := s.conn(ctx, tenant)
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM kv_store WHERE expires_at IS NOT NULL AND expires_at <= ?`, s.now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// maybePurge runs Purge for tenant when the last one is a minute old.
+func (s *SQL) maybePurge(ctx context.Context, db *sqlx.DB, tenant string, now time.Time) {
+	v, _ := s.purged.LoadOrStore(tenant, new(atomic.Int64))
+	last := v.(*atomic.Int64)
+	prev := last.Load()
+	if now.UnixNano()-prev < int64(purgeEvery) || !last.CompareAndSwap(prev, now.UnixNano()) {
+		return
+	}
+	if _, err := db.ExecContext(ctx,
+		`DELETE FROM kv_store WHERE expires_at IS NOT NULL AND expires_at <= ?`, now.UTC()); err != nil {
+		zap.L().Warn("kv purge failed", zap.String("tenant", tenant), zap.Error(err))
+	}
+}
@@ -0,0 +1,127 @@
+// internal/kv/sql_test.go
+//
+// Unit-tests for the SQL kv backend using sqlmock: reads, the once-a-minute
+// expiry purge, and transactional Incr.
+
+package kv
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/database"
+)
+
+var t0 = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sqlTenant registers a mock tenant DB and returns an SQL store on a fixed
+// clock, a tenant context, and the mock.
+func sqlTenant(t *testing.T) (*SQL, context.Context, sqlmock.Sqlmock) {
+	t.Helper()
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sqlx.NewDb(raw, "mysql")
+	database.RegisterTenant(t.Name(), db)
+	t.Cleanup(func() { database.UnregisterTenant(t.Name(), db) })
+
+	s := NewSQL()
+	s.now = func() time.Time { return t0 }
+	return s, database.WithTenant(context.Background(), t.Name()), mock
+}
+
+func TestSQL_Get(t *testing.T) {
+	s, ctx, mock := sqlTenant(t)
+	q := regexp.QuoteMeta("SELECT kv_value FROM kv_store WHERE kv_key = ? AND (expires_at IS NULL OR expires_at > ?)")
+	mock.ExpectQuery(q).WithArgs("k", t0).
+		WillReturnRows(sqlmock.NewRows([]string{"kv_value"}).AddRow([]byte("v")))
+	mock.ExpectQuery(q).WithArgs("gone", t0).
+		WillReturnRows(sqlmock.NewRows([]string{"kv_value"}))
+
+	if v, err := s.Get(ctx, t.Name(), "k"); err != nil || string(v) != "v" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	if _, err := s.Get(ctx, t.Name(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQL_SetPurgesOncePerMinute(t *testing.T) {
+	s, ctx, mock := sqlTenant(t)
+	purge := regexp.QuoteMeta("DELETE FROM kv_store WHERE expires_at IS NOT NULL AND expires_at <= ?")
+
+	mock.ExpectExec("INSERT INTO kv_store").
+		WithArgs("k", []byte("v"), t0.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(purge).WithArgs(t0).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO kv_store").
+		WithArgs("k", []byte("w"), nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := s.Set(ctx, t.Name(), "k", []byte("v"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, t.Name(), "k", []byte("w"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQL_Incr(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		expires driver.Value
+		want    int64
+		keep    driver.Value
+		err     error
+	}{
+		{"live", "41", t0.Add(time.Minute), 42, t0.Add(time.Minute), nil},
+		{"no expiry", "7", nil, 8, t0.Add(time.Hour), nil},
+		{"expired", "41", t0, 1, t0.Add(time.Hour), nil},
+		{"text", "abc", nil, 0, nil, ErrNotInteger},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, ctx, mock := sqlTenant(t)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("INSERT IGNORE INTO kv_store")).
+				WithArgs("n", t0.Add(time.Hour)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT kv_value, expires_at FROM kv_store WHERE kv_key = ? FOR UPDATE")).
+				WithArgs("n").
+				WillReturnRows(sqlmock.NewRows([]string{"kv_value", "expires_at"}).
+					AddRow([]byte(tc.value), tc.expires))
+			if tc.err != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE kv_store SET kv_value = ?, expires_at = ? WHERE kv_key = ?")).
+					WithArgs(strconv.FormatInt(tc.want, 10), tc.keep, "n").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			n, err := s.Incr(ctx, t.Name(), "n", 1, time.Hour)
+			if !errors.Is(err, tc.err) || n != tc.want {
+				t.Fatalf("Incr = %d, %v; want %d, %v", n, err, tc.want, tc.err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -8,6 +8,10 @@
 //   • Colored tee to stdout when running in an interactive TTY.
 //   • logger.WithContext(ctx, l) embeds a request-scoped logger.
 //   • logger.FromContext(ctx) fetches that or falls back to the global.
+//   • The root handler embeds the tenant's child logger (Wrap), so entries
+//     logged through FromContext carry "tenant"=host automatically.
+//   • Both cores share one atomic level; SetLevel changes it at runtime
+//     (config reload), no rebuild needed.
 //
 // Two-space sentence spacing, Oxford comma per style guide.
 //
@@ -30,6 +34,21 @@ import (
 // Core setup (unchanged)
 //
 
+// level gates every core New builds.  Info until SetLevel says otherwise.
+var level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+// SetLevel changes the minimum level ("debug", "info", "warn", "error")
+// of the loggers New built.  "" means info.
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+	return level.UnmarshalText([]byte(name))
+}
+
+// Level returns the current minimum level.
+func Level() zapcore.Level { return level.Level() }
+
 func New(rootDir string, tee bool) (*zap.SugaredLogger, error) {
 	logDir := filepath.Join(rootDir, "logs")
 	if err := os.MkdirAll(logDir, 0o755); err != nil {
@@ -58,7 +77,7 @@ func New(rootDir string, tee bool) (*zap.SugaredLogger, error) {
 	jsonCore := zapcore.NewCore(
 		zapcore.NewJSONEncoder(encCfg),
 		zapcore.AddSync(fileSink),
-		zap.InfoLevel,
+		level,
 	)
 
 	cores := []zapcore.Core{jsonCore}
@@ -66,7 +85,7 @@ func New(rootDir string, tee bool) (*zap.SugaredLogger, error) {
 		consoleCore := zapcore.NewCore(
 			zapcore.NewConsoleEncoder(encCfg),
 			zapcore.AddSync(os.Stdout),
-			zap.InfoLevel,
+			level,
 		)
 		cores = append(cores, consoleCore)
 	}
@@ -90,6 +109,7 @@ func New(rootDir string, tee bool) (*zap.SugaredLogger, error) {
 type Logger interface {
 	Error(msg string, kv ...any)
 	Warn(msg string, kv ...any)
+	Info(msg string, kv ...any)
 }
 
 // zapAdapter wraps *zap.SugaredLogger to satisfy Logger.
@@ -97,6 +117,16 @@ type zapAdapter struct{ *zap.SugaredLogger }
 
 func (l zapAdapter) Error(msg string, kv ...any) { l.Errorw(msg, kv...) }
 func (l zapAdapter) Warn(msg string, kv ...any)  { l.Warnw(msg, kv...) }
+func (l zapAdapter) Info(msg string, kv ...any)  { l.Infow(msg, kv...) }
+
+// Wrap adapts a *zap.SugaredLogger (for example a tenant's child logger) to
+// Logger so it can be embedded with WithContext.  nil yields the global.
+func Wrap(z *zap.SugaredLogger) Logger {
+	if z == nil {
+		z = zap.S()
+	}
+	return zapAdapter{z}
+}
 
 type ctxKey struct{}
 
@@ -0,0 +1,77 @@
+// internal/logger/logger_test.go
+//
+// Unit-tests for the adapter layer (Wrap, WithContext, and FromContext)
+// and the runtime level.
+//
+// Notes
+// -----
+// • Entries are captured with zap's in-memory observer core.
+// • Oxford commas, two spaces after periods.
+
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext_CarriesChildFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	child := zap.New(core).Sugar().With("tenant", "a.example")
+
+	ctx := WithContext(context.Background(), Wrap(child))
+	l := FromContext(ctx)
+	l.Info("form submitted", "form", "contact")
+	l.Warn("slow action")
+	l.Error("action failed", "err", "boom")
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	wantLevels := []zapcore.Level{zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel}
+	for i, e := range entries {
+		if e.Level != wantLevels[i] {
+			t.Errorf("entry %d level = %v, want %v", i, e.Level, wantLevels[i])
+		}
+		if got := e.ContextMap()["tenant"]; got != "a.example" {
+			t.Errorf("entry %d tenant = %v, want a.example", i, got)
+		}
+	}
+	if got := entries[0].ContextMap()["form"]; got != "contact" {
+		t.Errorf("form = %v, want contact", got)
+	}
+}
+
+func TestFromContext_FallsBackToGlobal(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	FromContext(context.Background()).Info("no request logger")
+	Wrap(nil).Warn("nil child")
+
+	if n := logs.Len(); n != 2 {
+		t.Fatalf("global logger got %d entries, want 2", n)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	t.Cleanup(func() { _ = SetLevel("") })
+
+	if err := SetLevel("debug"); err != nil || Level() != zapcore.DebugLevel {
+		t.Fatalf("SetLevel(debug): level %v, err %v", Level(), err)
+	}
+	if err := SetLevel("loud"); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+	if Level() != zapcore.DebugLevel {
+		t.Fatalf("bad level changed it to %v", Level())
+	}
+	if err := SetLevel(""); err != nil || Level() != zapcore.InfoLevel {
+		t.Fatalf(`SetLevel(""): level %v, err %v`, Level(), err)
+	}
+}
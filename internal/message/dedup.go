@@ -0,0 +1,206 @@
+// internal/message/dedup.go
+//
+// Adept – Messaging: idempotent email delivery.
+//
+// Context
+//   A retried email job (queue redelivery, fallback, or an action retry)
+//   must not reach the recipient twice.  An Email may carry a DedupKey;
+//   the deliverer skips it when the key is already marked sent and marks
+//   it after a successful send.  Keys are marked only once the transport
+//   accepted the message, so a send that failed is retried for real.
+//
+// Rules
+//   •  No DedupKey, no dedup.  Callers derive keys from something unique to
+//      one logical message (form id, submission key, and action), never
+//      from the content alone, so distinct submissions never collide.
+//   •  Marks expire after the dedup window (DefaultDedupWindow unless
+//      SetDedupWindow says otherwise).  A retry later than that sends again.
+//   •  The default store is per-process and bounded.  Multi-replica
+//      deployments install SQLDedup on the global DB so a retry picked up
+//      by another node still sees the mark.
+//   •  A store error fails open: the email is sent and the error logged.
+//      A duplicate beats a lost message.
+//   •  Two deliveries of one key racing each other can both send; the
+//      store narrows the window, it does not lock.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	lru "github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/metrics"
+)
+
+// Dedup defaults.
+const (
+	DefaultDedupWindow  = 24 * time.Hour
+	DefaultDedupEntries = 10000 // memory store capacity
+	dedupPurgeEvery     = time.Minute
+)
+
+// DedupStore remembers which dedup keys were sent.
+type DedupStore interface {
+	// Sent reports whether key was marked and has not expired.
+	Sent(ctx context.Context, key string) (bool, error)
+	// MarkSent records key for window.
+	MarkSent(ctx context.Context, key string, window time.Duration) error
+}
+
+var (
+	dedupStore  atomic.Pointer[DedupStore]
+	dedupWindow atomic.Int64 // time.Duration
+	memDedup    DedupStore   = newMemoryDedup(DefaultDedupEntries)
+)
+
+func init() { dedupWindow.Store(int64(DefaultDedupWindow)) }
+
+// SetDedupStore installs the store that marks sent emails.  nil restores
+// the per-process default.
+func SetDedupStore(s DedupStore) {
+	if s == nil {
+		dedupStore.Store(nil)
+		return
+	}
+	dedupStore.Store(&s)
+}
+
+// SetDedupWindow sets how long a sent key suppresses retries.  d <= 0
+// restores DefaultDedupWindow.
+func SetDedupWindow(d time.Duration) {
+	if d <= 0 {
+		d = DefaultDedupWindow
+	}
+	dedupWindow.Store(int64(d))
+}
+
+func currentDedup() DedupStore {
+	if p := dedupStore.Load(); p != nil {
+		return *p
+	}
+	return memDedup
+}
+
+// deliverEmail sends msg unless its DedupKey was already sent.
+func deliverEmail(ctx context.Context, msg Email) error {
+	if msg.DedupKey == "" {
+		return sendEmail(ctx, msg)
+	}
+	store := currentDedup()
+	sent, err := store.Sent(ctx, msg.DedupKey)
+	switch {
+	case err != nil:
+		zap.L().Warn("email dedup lookup failed – sending anyway",
+			zap.String("key", msg.DedupKey), zap.Error(err))
+	case sent:
+		metrics.MessageDedupSuppressedTotal.Inc()
+		zap.L().Info("email already sent – duplicate suppressed",
+			zap.String("key", msg.DedupKey))
+		return nil
+	}
+
+	if err := sendEmail(ctx, msg); err != nil {
+		return err
+	}
+	if err := store.MarkSent(ctx, msg.DedupKey, time.Duration(dedupWindow.Load())); err != nil {
+		zap.L().Warn("email dedup mark failed – a retry may resend",
+			zap.String("key", msg.DedupKey), zap.Error(err))
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Memory store
+// -----------------------------------------------------------------------------
+
+// memoryDedup keeps marks in a bounded LRU.  Past capacity the oldest
+// marks go first, which only shortens their window.
+type memoryDedup struct {
+	mu   sync.Mutex
+	keys *lru.LRU // key → expiry time.Time
+}
+
+func newMemoryDedup(capacity int) *memoryDedup {
+	return &memoryDedup{keys: lru.New(capacity)}
+}
+
+func (m *memoryDedup) Sent(_ context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.keys.Get(key)
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(v.(time.Time)) {
+		m.keys.Remove(key)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *memoryDedup) MarkSent(_ context.Context, key string, window time.Duration) error {
+	m.mu.Lock()
+	m.keys.Add(key, time.Now().Add(window))
+	m.mu.Unlock()
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// SQL store
+// -----------------------------------------------------------------------------
+
+// SQLDedup keeps marks in the message_dedup table so every replica sees
+// them.  Expired rows are deleted at most once a minute, on MarkSent.
+type SQLDedup struct {
+	db        *sqlx.DB
+	lastPurge atomic.Int64 // unix nanoseconds
+}
+
+// NewSQLDedup returns a store backed by db, normally the global DB.
+func NewSQLDedup(db *sqlx.DB) *SQLDedup { return &SQLDedup{db: db} }
+
+// Sent implements DedupStore.
+func (s *SQLDedup) Sent(ctx context.Context, key string) (bool, error) {
+	var one int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT 1 FROM message_dedup WHERE dedup_key = ? AND expires_at > ?`,
+		key, time.Now().UTC()).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MarkSent implements DedupStore.
+func (s *SQLDedup) MarkSent(ctx context.Context, key string, window time.Duration) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO message_dedup (dedup_key, expires_at) VALUES (?, ?)
+		 ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)`,
+		key, now.Add(window))
+	if err != nil {
+		return err
+	}
+
+	last := s.lastPurge.Load()
+	if now.UnixNano()-last >= int64(dedupPurgeEvery) && s.lastPurge.CompareAndSwap(last, now.UnixNano()) {
+		if _, err := s.db.ExecContext(ctx,
+			`DELETE FROM message_dedup WHERE expires_at <= ?`, now); err != nil {
+			zap.L().Warn("email dedup purge failed", zap.Error(err))
+		}
+	}
+	return nil
+}
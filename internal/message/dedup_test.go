@@ -0,0 +1,139 @@
+// internal/message/dedup_test.go
+//
+// Unit-tests for idempotent email delivery.
+
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// countSends swaps the transport for one that counts calls and fails
+// while *fail is true.
+func countSends(t *testing.T, fail *bool) *int {
+	t.Helper()
+	n := 0
+	orig := sendEmail
+	sendEmail = func(context.Context, Email) error {
+		if fail != nil && *fail {
+			return errors.New("smtp down")
+		}
+		n++
+		return nil
+	}
+	t.Cleanup(func() { sendEmail = orig })
+	return &n
+}
+
+// useDedup installs s for the test.
+func useDedup(t *testing.T, s DedupStore) {
+	t.Helper()
+	SetDedupStore(s)
+	t.Cleanup(func() { SetDedupStore(nil); SetDedupWindow(0) })
+}
+
+func TestDeliverEmail_RetrySuppressed(t *testing.T) {
+	fail := true
+	sends := countSends(t, &fail)
+	useDedup(t, newMemoryDedup(8))
+	ctx := context.Background()
+	msg := Email{To: []string{"a@example.com"}, DedupKey: "k1"}
+
+	if err := deliverEmail(ctx, msg); err == nil {
+		t.Fatal("failed send returned nil")
+	}
+	fail = false
+	for i := 0; i < 3; i++ { // first real send, then two retries
+		if err := deliverEmail(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *sends != 1 {
+		t.Fatalf("sends = %d, want 1", *sends)
+	}
+
+	// Other keys and keyless email are unaffected.
+	_ = deliverEmail(ctx, Email{DedupKey: "k2"})
+	_ = deliverEmail(ctx, Email{})
+	_ = deliverEmail(ctx, Email{})
+	if *sends != 4 {
+		t.Fatalf("sends = %d, want 4", *sends)
+	}
+}
+
+func TestDeliverEmail_WindowExpires(t *testing.T) {
+	sends := countSends(t, nil)
+	useDedup(t, newMemoryDedup(8))
+	SetDedupWindow(time.Millisecond)
+
+	msg := Email{DedupKey: "k"}
+	_ = deliverEmail(context.Background(), msg)
+	time.Sleep(5 * time.Millisecond)
+	_ = deliverEmail(context.Background(), msg)
+	if *sends != 2 {
+		t.Fatalf("sends = %d, want 2 after the window", *sends)
+	}
+}
+
+// brokenDedup fails every call.
+type brokenDedup struct{}
+
+func (brokenDedup) Sent(context.Context, string) (bool, error) { return false, errors.New("db down") }
+func (brokenDedup) MarkSent(context.Context, string, time.Duration) error {
+	return errors.New("db down")
+}
+
+func TestDeliverEmail_StoreErrorFailsOpen(t *testing.T) {
+	sends := countSends(t, nil)
+	useDedup(t, brokenDedup{})
+	if err := deliverEmail(context.Background(), Email{DedupKey: "k"}); err != nil || *sends != 1 {
+		t.Fatalf("err = %v, sends = %d; want sent", err, *sends)
+	}
+}
+
+func TestSQLDedup(t *testing.T) {
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSQLDedup(sqlx.NewDb(raw, "mysql"))
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT 1 FROM message_dedup").
+		WithArgs("k", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}))
+	if sent, err := s.Sent(ctx, "k"); err != nil || sent {
+		t.Fatalf("Sent = %v, %v; want false", sent, err)
+	}
+
+	mock.ExpectExec("INSERT INTO message_dedup").
+		WithArgs("k", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM message_dedup WHERE expires_at").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	if err := s.MarkSent(ctx, "k", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second mark inside the purge interval skips the DELETE.
+	mock.ExpectExec("INSERT INTO message_dedup").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := s.MarkSent(ctx, "k2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT 1 FROM message_dedup").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	if sent, err := s.Sent(ctx, "k"); err != nil || !sent {
+		t.Fatalf("Sent = %v, %v; want true", sent, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
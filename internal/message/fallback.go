@@ -0,0 +1,119 @@
+// internal/message/fallback.go
+//
+// Adept – Messaging: in-memory fallback queue.
+//
+// Context
+//   When the primary Queue rejects a publish, the job is parked here and a
+//   single local worker runs Deliver on it.  This is a degraded mode, not a
+//   queue: nothing is persisted, nothing is retried, and a restart loses
+//   whatever is waiting.  It exists so a queue outage delays email instead
+//   of dropping every form action on the floor.
+//
+// Rules
+//   •  At most DefaultFallbackCapacity jobs wait.  When full, the oldest is
+//      dropped and message_fallback_dropped_total counts it.
+//   •  The worker starts on the first push and lives for the process.
+//   •  Each delivery gets FallbackJobTimeout; a failure is logged and
+//      counted, then the job is gone.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/metrics"
+)
+
+// Fallback bounds.
+const (
+	DefaultFallbackCapacity = 1000
+	FallbackJobTimeout      = 30 * time.Second
+)
+
+// fallbackQueue is a bounded FIFO drained by one worker.
+type fallbackQueue struct {
+	mu      sync.Mutex
+	jobs    []Job
+	cap     int
+	wake    chan struct{}
+	start   sync.Once
+	deliver func(context.Context, Job) error
+}
+
+var fallback = newFallbackQueue(DefaultFallbackCapacity, Deliver)
+
+func newFallbackQueue(capacity int, deliver func(context.Context, Job) error) *fallbackQueue {
+	return &fallbackQueue{cap: capacity, wake: make(chan struct{}, 1), deliver: deliver}
+}
+
+// push appends j, dropping the oldest job when full, and wakes the worker.
+func (q *fallbackQueue) push(j Job) {
+	q.start.Do(func() { go q.run() })
+
+	q.mu.Lock()
+	if len(q.jobs) >= q.cap {
+		dropped := q.jobs[0]
+		q.jobs = q.jobs[1:]
+		metrics.MessageFallbackDroppedTotal.Inc()
+		zap.L().Warn("message fallback full – oldest job dropped",
+			zap.String("kind", dropped.Kind()))
+	}
+	q.jobs = append(q.jobs, j)
+	metrics.MessageFallbackDepth.Set(float64(len(q.jobs)))
+	q.mu.Unlock()
+	metrics.MessageFallbackTotal.WithLabelValues(j.Kind()).Inc()
+
+	select {
+	case q.wake <- struct{}{}:
+	default: // worker already signalled
+	}
+}
+
+// pop removes the oldest job.
+func (q *fallbackQueue) pop() (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.jobs) == 0 {
+		return Job{}, false
+	}
+	j := q.jobs[0]
+	q.jobs[0] = Job{} // release for GC
+	q.jobs = q.jobs[1:]
+	metrics.MessageFallbackDepth.Set(float64(len(q.jobs)))
+	return j, true
+}
+
+// len reports the jobs waiting.
+func (q *fallbackQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// run delivers jobs until the process exits.
+func (q *fallbackQueue) run() {
+	for range q.wake {
+		for {
+			j, ok := q.pop()
+			if !ok {
+				break
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), FallbackJobTimeout)
+			if err := q.deliver(ctx, j); err != nil {
+				metrics.MessageFallbackFailedTotal.Inc()
+				zap.L().Warn("message fallback delivery failed",
+					zap.String("kind", j.Kind()), zap.Error(err))
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// internal/message/fallback_test.go
+//
+// Unit-tests for the primary-queue hook and the in-memory fallback.
+
+package message
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/yanizio/adept/internal/metrics"
+)
+
+func TestFallbackQueue_DropsOldest(t *testing.T) {
+	q := newFallbackQueue(2, nil)
+	q.start.Do(func() {}) // no worker: inspect the queue directly
+
+	dropped := testutil.ToFloat64(metrics.MessageFallbackDroppedTotal)
+	for _, subj := range []string{"a", "b", "c"} {
+		q.push(Job{Email: &Email{Subject: subj}})
+	}
+	if d := testutil.ToFloat64(metrics.MessageFallbackDroppedTotal) - dropped; d != 1 {
+		t.Fatalf("dropped = %v, want 1", d)
+	}
+	var got []string
+	for j, ok := q.pop(); ok; j, ok = q.pop() {
+		got = append(got, j.Email.Subject)
+	}
+	if strings.Join(got, ",") != "b,c" {
+		t.Fatalf("queue = %v, want [b c]", got)
+	}
+}
+
+// stubQueue fails Publish while err is set.
+type stubQueue struct {
+	err  error
+	jobs []Job
+}
+
+func (s *stubQueue) Publish(_ context.Context, j Job) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.jobs = append(s.jobs, j)
+	return nil
+}
+
+func TestEnqueue_FallsBackWhileQueueDown(t *testing.T) {
+	delivered := make(chan Job, 4)
+	orig := fallback
+	fallback = newFallbackQueue(10, func(_ context.Context, j Job) error {
+		delivered <- j
+		return nil
+	})
+	q := &stubQueue{err: errors.New("connection refused")}
+	SetQueue(q)
+	t.Cleanup(func() { fallback = orig; SetQueue(nil); degraded.Store(false) })
+
+	ctx := context.Background()
+	if err := EnqueueEmail(ctx, Email{To: []string{"a@example.com"}, Subject: "hi"}); err != nil {
+		t.Fatalf("EnqueueEmail: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com/x", strings.NewReader(`{"a":1}`))
+	req.Header.Set("X-Sig", "s")
+	if err := EnqueueWebhook(ctx, req); err != nil {
+		t.Fatalf("EnqueueWebhook: %v", err)
+	}
+	if !Degraded() || testutil.ToFloat64(metrics.MessageQueueDegraded) != 1 {
+		t.Fatal("not degraded after a failed publish")
+	}
+
+	for _, want := range []string{"email", "webhook"} {
+		select {
+		case j := <-delivered:
+			if j.Kind() != want {
+				t.Fatalf("delivered %s, want %s", j.Kind(), want)
+			}
+			if j.Webhook != nil && (string(j.Webhook.Body) != `{"a":1}` || j.Webhook.Header.Get("X-Sig") != "s") {
+				t.Fatalf("webhook = %+v", j.Webhook)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s never delivered by the fallback worker", want)
+		}
+	}
+
+	q.err = nil
+	if err := EnqueueEmail(ctx, Email{Subject: "back"}); err != nil || len(q.jobs) != 1 {
+		t.Fatalf("recovered publish = %v, %d jobs", err, len(q.jobs))
+	}
+	if Degraded() {
+		t.Fatal("still degraded after a successful publish")
+	}
+}
@@ -0,0 +1,173 @@
+// internal/message/httpclient.go
+//
+// Adept – Messaging: shared outbound HTTP client.
+//
+// Context
+//   Webhook, forward, and Slack-style actions all make outbound HTTP calls
+//   to URLs taken from form definitions.  http.DefaultClient has no overall
+//   timeout, unbounded idle pools, and no TLS floor, so one slow endpoint
+//   can pin goroutines and sockets indefinitely.  Every outbound call goes
+//   through HTTPClient() instead, built once from ClientOptions.
+//
+// Workflow
+//   •  cmd/web calls SetHTTPClient(NewHTTPClient(opts)) at boot, with opts
+//      from the `outbound` config block.  Until then, HTTPClient() returns
+//      a client built from DefaultClientOptions.
+//   •  Timeout is an upper bound.  Requests carry their caller's context,
+//      and a shorter context deadline always wins.
+//   •  ClientOptions.Policy (ssrf.go) vets every URL and every resolved
+//      address before a connection is made.  Its zero value blocks
+//      internal ranges.
+//   •  ClientOptions.Control runs on every dial after DNS resolution, for
+//      checks the policy does not cover.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ClientOptions tunes the shared outbound client.  Zero fields take the
+// matching DefaultClientOptions value.
+type ClientOptions struct {
+	Timeout             time.Duration // whole exchange, including body
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	IdleConnTimeout     time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxRedirects        int    // -1 disables redirects
+	Proxy               string // "" connects directly
+	TLSMinVersion       uint16 // tls.VersionTLS12 and up
+	Policy              AddressPolicy
+
+	// Control, when set, runs before each connection is made, with the
+	// resolved "ip:port".  Returning an error aborts that dial.
+	Control func(network, address string, c syscall.RawConn) error
+}
+
+// DefaultClientOptions are the values used for zero fields.
+var DefaultClientOptions = ClientOptions{
+	Timeout:             10 * time.Second,
+	DialTimeout:         5 * time.Second,
+	TLSHandshakeTimeout: 5 * time.Second,
+	IdleConnTimeout:     90 * time.Second,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	MaxRedirects:        5,
+	TLSMinVersion:       tls.VersionTLS12,
+}
+
+// merge fills zero fields from DefaultClientOptions.
+func (o *ClientOptions) merge() {
+	d := DefaultClientOptions
+	if o.Timeout == 0 {
+		o.Timeout = d.Timeout
+	}
+	if o.DialTimeout == 0 {
+		o.DialTimeout = d.DialTimeout
+	}
+	if o.TLSHandshakeTimeout == 0 {
+		o.TLSHandshakeTimeout = d.TLSHandshakeTimeout
+	}
+	if o.IdleConnTimeout == 0 {
+		o.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if o.MaxIdleConns == 0 {
+		o.MaxIdleConns = d.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost == 0 {
+		o.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if o.MaxRedirects == 0 {
+		o.MaxRedirects = d.MaxRedirects
+	}
+	if o.TLSMinVersion == 0 {
+		o.TLSMinVersion = d.TLSMinVersion
+	}
+}
+
+// NewHTTPClient builds a client from opts.  A Proxy or Policy that does
+// not parse, or a TLS floor below 1.2, is an error.
+func NewHTTPClient(opts ClientOptions) (*http.Client, error) {
+	opts.merge()
+	if opts.TLSMinVersion < tls.VersionTLS12 {
+		return nil, fmt.Errorf("outbound client: TLS min version %#x below 1.2", opts.TLSMinVersion)
+	}
+
+	guard, err := opts.Policy.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	var proxy func(*http.Request) (*url.URL, error) // nil: env proxies bypass the policy
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("outbound client: invalid proxy %q", opts.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+		guard.proxyAddr = proxyHostPort(u)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   opts.Control,
+	}
+	guard.dial = dialer.DialContext
+	tr := &http.Transport{
+		Proxy:                 guard.proxy(proxy),
+		DialContext:           guard.dialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig:       &tls.Config{MinVersion: opts.TLSMinVersion},
+	}
+
+	maxRedirects := opts.MaxRedirects
+	return &http.Client{
+		Transport: tr,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if maxRedirects < 0 {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
+
+var sharedClient atomic.Pointer[http.Client]
+
+// SetHTTPClient replaces the shared outbound client.  nil restores the
+// default.
+func SetHTTPClient(c *http.Client) { sharedClient.Store(c) }
+
+// HTTPClient returns the shared outbound client.
+func HTTPClient() *http.Client {
+	if c := sharedClient.Load(); c != nil {
+		return c
+	}
+	c, _ := NewHTTPClient(ClientOptions{}) // defaults always build
+	sharedClient.CompareAndSwap(nil, c)
+	return sharedClient.Load()
+}
@@ -0,0 +1,176 @@
+// internal/message/httpclient_test.go
+//
+// Unit-tests for the shared outbound client and webhook delivery.
+
+package message
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// loopbackOK lets test clients reach httptest servers on 127.0.0.1.
+var loopbackOK = AddressPolicy{AllowCIDRs: []string{"127.0.0.0/8"}}
+
+func TestHTTPClient_ContextDeadlineWins(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, err := NewHTTPClient(ClientOptions{Timeout: time.Minute, Policy: loopbackOK})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	start := time.Now()
+	_, err = c.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("request ran %v past its deadline", d)
+	}
+}
+
+func TestHTTPClient_ControlVetoesDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	var seen string
+	veto := errors.New("address refused")
+	c, err := NewHTTPClient(ClientOptions{
+		Policy: loopbackOK,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			seen = address
+			return veto
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(srv.URL); !errors.Is(err, veto) {
+		t.Fatalf("err = %v, want the Control error", err)
+	}
+	if seen != srv.Listener.Addr().String() {
+		t.Fatalf("Control saw %q, want %q", seen, srv.Listener.Addr())
+	}
+}
+
+func TestNewHTTPClient_Options(t *testing.T) {
+	if _, err := NewHTTPClient(ClientOptions{TLSMinVersion: tls.VersionTLS10}); err == nil {
+		t.Fatal("TLS 1.0 floor accepted")
+	}
+	if _, err := NewHTTPClient(ClientOptions{Proxy: "::not a url"}); err == nil {
+		t.Fatal("bad proxy accepted")
+	}
+
+	c, err := NewHTTPClient(ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := c.Transport.(*http.Transport)
+	if c.Timeout != DefaultClientOptions.Timeout ||
+		tr.MaxIdleConnsPerHost != DefaultClientOptions.MaxIdleConnsPerHost ||
+		tr.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("defaults not applied: timeout %v, idle/host %d, tls %#x",
+			c.Timeout, tr.MaxIdleConnsPerHost, tr.TLSClientConfig.MinVersion)
+	}
+}
+
+func TestHTTPClient_RedirectCap(t *testing.T) {
+	hops := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops++
+		http.Redirect(w, r, "/next", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	c, _ := NewHTTPClient(ClientOptions{MaxRedirects: 2, Policy: loopbackOK})
+	if _, err := c.Get(srv.URL); err == nil || !strings.Contains(err.Error(), "2 redirects") {
+		t.Fatalf("err = %v", err)
+	}
+	if hops != 3 {
+		t.Fatalf("hops = %d, want 3", hops)
+	}
+
+	c, _ = NewHTTPClient(ClientOptions{MaxRedirects: -1, Policy: loopbackOK})
+	resp, err := c.Get(srv.URL)
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("no-follow = %v, %v", resp, err)
+	}
+	resp.Body.Close()
+}
+
+func TestEnqueueWebhook_DoesNotWait(t *testing.T) {
+	release, got := make(chan struct{}), make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // a slow endpoint
+		got <- r.URL.Path
+	}))
+	defer srv.Close()
+	defer close(release)
+	SetHTTPClient(srv.Client())
+	t.Cleanup(func() { SetHTTPClient(nil) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/hook", strings.NewReader("{}"))
+	done := make(chan error, 1)
+	go func() { done <- EnqueueWebhook(ctx, req) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("EnqueueWebhook: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("EnqueueWebhook waited on the endpoint")
+	}
+
+	cancel() // the caller's request ends; the send carries on
+	release <- struct{}{}
+	select {
+	case p := <-got:
+		if p != "/hook" {
+			t.Fatalf("path = %q", p)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook never delivered")
+	}
+}
+
+func TestSendWebhook_Status(t *testing.T) {
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	SetHTTPClient(srv.Client())
+	t.Cleanup(func() { SetHTTPClient(nil) })
+
+	send := func() (int, error) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/hook", strings.NewReader("{}"))
+		return SendWebhook(context.Background(), req)
+	}
+	if code, err := send(); err != nil || code != http.StatusNoContent {
+		t.Fatalf("204: %d, %v", code, err)
+	}
+	status = http.StatusBadGateway
+	if code, err := send(); err == nil || code != http.StatusBadGateway || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("502: %d, %v", code, err)
+	}
+}
@@ -4,12 +4,13 @@
 //
 // Context
 //   The forms subsystem (and other parts of Adept) enqueue outbound messages
-//   such as emails and webhooks.  Until the real queue/worker pool is
-//   finished, this stub logs the payload and returns nil so callers proceed
-//   without blocking.
-//
-//   Replace the body of each Enqueue* function with code that publishes to
-//   your queue of choice (e.g., Redis, NATS, SQS) when ready.
+//   such as emails and webhooks.  With a primary Queue installed (queue.go)
+//   they are published to it, falling back to memory when it is down.
+//   Without one, the email deliverer is still a stub that logs the payload,
+//   and webhooks are sent in the background through the shared client
+//   (httpclient.go), so a slow endpoint never holds up the caller.  Emails with a DedupKey are delivered at most once
+//   per dedup window (dedup.go).  Tests and dev servers can install an
+//   EmailSink that captures or files mail instead (sink.go).
 //
 // Style
 //   Two-space sentence spacing, Oxford comma, concise inline notes.
@@ -20,30 +21,119 @@ package message
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/mail"
+
+	"go.uber.org/zap"
 )
 
-// Email represents a basic outbound email job.
+// Email represents a basic outbound email job.  Text is the plaintext
+// body, HTML the rich one; with both set the message is sent as
+// multipart/alternative (BuildMIME, mime.go).
 type Email struct {
-	To      []string
-	Subject string
-	Text    string
-	HTML    string // optional – not used by stub
+	From         string // header From; "" uses the transport default
+	EnvelopeFrom string // SMTP MAIL FROM (bounces); "" follows From
+	To           []string
+	Subject      string
+	Text         string
+	HTML         string
+	Attachments  []Attachment
+
+	// Unsubscribe marks bulk mail and adds List-Unsubscribe headers
+	// (unsubscribe.go).  nil for transactional mail.
+	Unsubscribe *Unsubscribe
+
+	// DedupKey, when set, makes delivery idempotent: a retry after a
+	// successful send is suppressed (dedup.go).
+	DedupKey string
 }
 
-// EnqueueEmail logs the email payload.  Swap with real queue publisher later.
+// Envelope returns the SMTP envelope sender: EnvelopeFrom, else the
+// address in From, else def.
+func (e Email) Envelope(def string) string {
+	if e.EnvelopeFrom != "" {
+		return e.EnvelopeFrom
+	}
+	if a, err := mail.ParseAddress(e.From); err == nil {
+		return a.Address
+	}
+	return def
+}
+
+// EnqueueEmail publishes msg to the primary queue, or delivers it locally
+// when none is installed.
 func EnqueueEmail(ctx context.Context, msg Email) error {
-	log.Printf("[Adept] QUEUE Email → to=%v subject=%q len(text)=%d\n",
-		msg.To, msg.Subject, len(msg.Text))
+	if q := primaryQueue(); q != nil {
+		return publish(ctx, q, Job{Email: &msg})
+	}
+	return deliverEmail(ctx, msg)
+}
+
+// sendEmail is the transport: an installed EmailSink (sink.go) gets the
+// message, else for now it logs the email payload.  Swap with a real
+// sender later; tests swap it to count sends.
+var sendEmail = func(ctx context.Context, msg Email) error {
+	if s := emailSink.Load(); s != nil {
+		return (*s).Send(ctx, msg)
+	}
+	log.Printf("[Adept] QUEUE Email → to=%v subject=%q len(text)=%d len(html)=%d attachments=%d\n",
+		msg.To, msg.Subject, len(msg.Text), len(msg.HTML), len(msg.Attachments))
 	return nil
 }
 
-// EnqueueWebhook logs the HTTP request details.  Swap with real queue later.
+// EnqueueWebhook publishes req to the primary queue.  Without one it is
+// sent in a goroutine through HTTPClient and EnqueueWebhook returns at
+// once; the client timeout bounds the send, which outlives ctx's
+// cancellation, and a failure is logged.  Use SendWebhook to wait for the
+// status.
 //
 // Caller constructs the *http.Request with full context (headers, JSON body).
 func EnqueueWebhook(ctx context.Context, req *http.Request) error {
-	log.Printf("[Adept] QUEUE Webhook → %s %s (headers=%d)\n",
-		req.Method, req.URL.String(), len(req.Header))
-	return nil
+	q := primaryQueue()
+	if q == nil {
+		go sendWebhookAsync(context.WithoutCancel(ctx), req)
+		return nil
+	}
+	w, err := bufferWebhook(req)
+	if err != nil {
+		return err
+	}
+	return publish(ctx, q, Job{Webhook: w})
+}
+
+// SendWebhook sends req inline through HTTPClient, bypassing any queue,
+// and returns the response status.  A non-2xx status is also an error;
+// status is 0 when no response arrived.
+func SendWebhook(ctx context.Context, req *http.Request) (int, error) {
+	resp, err := HTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // reuse the conn
+
+	log.Printf("[Adept] Webhook → %s %s (status=%d)\n",
+		req.Method, req.URL.Redacted(), resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook %s: status %d", req.URL.Host, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// sendWebhookAsync sends req and logs the outcome; EnqueueWebhook runs it
+// in its own goroutine.
+func sendWebhookAsync(ctx context.Context, req *http.Request) {
+	if err := sendWebhook(ctx, req); err != nil {
+		zap.L().Warn("webhook delivery failed",
+			zap.String("url", req.URL.Redacted()), zap.Error(err))
+	}
+}
+
+// sendWebhook performs one webhook request.
+func sendWebhook(ctx context.Context, req *http.Request) error {
+	_, err := SendWebhook(ctx, req)
+	return err
 }
@@ -0,0 +1,276 @@
+// internal/message/mime.go
+//
+// Adept – Messaging: MIME builder for outbound email.
+//
+// Context
+//   BuildMIME turns an Email into the RFC 5322 bytes an SMTP transport
+//   sends after DATA.  The part tree depends on what the Email carries:
+//
+//      Text only            text/plain
+//      HTML only            text/html
+//      Text and HTML        multipart/alternative (plain first, HTML last)
+//      + attachments        multipart/mixed wrapping the above, then one
+//                           part per attachment
+//
+//   Clients show the last alternative they understand, so HTML goes last
+//   and plain text is the fallback.
+//
+// Rules
+//   •  Subjects and display names outside ASCII are RFC 2047 encoded;
+//      attachment filenames use RFC 2231 parameters.
+//   •  Text parts are quoted-printable UTF-8; attachments are base64 in
+//      76-column lines.
+//   •  Addresses must parse (net/mail), so CR or LF in a header value is
+//      rejected rather than written.
+//   •  Bulk mail (Unsubscribe set) also gets the List-Unsubscribe headers
+//      from unsubscribe.go.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent with an Email.
+type Attachment struct {
+	Filename    string
+	ContentType string // "" → from Filename's extension, else octet-stream
+	Data        []byte
+}
+
+// mediaType returns a's Content-Type.
+func (a Attachment) mediaType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(a.Filename)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// BuildMIME renders msg as a complete message.  from is the default
+// sender, used when msg.From is empty.
+func BuildMIME(from string, msg Email) ([]byte, error) {
+	if msg.Text == "" && msg.HTML == "" && len(msg.Attachments) == 0 {
+		return nil, errors.New("email: no body")
+	}
+	if msg.From != "" {
+		from = msg.From
+	}
+	if msg.Unsubscribe != nil {
+		if err := msg.Unsubscribe.validate(); err != nil {
+			return nil, err
+		}
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("email from %q: %w", from, err)
+	}
+	to, err := formatAddrs(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", fromAddr.String())
+	writeHeader(&buf, "To", to)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(fromAddr.Address))
+	if msg.Unsubscribe != nil {
+		for _, h := range msg.Unsubscribe.headers() {
+			writeHeader(&buf, h[0], h[1])
+		}
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		if err := writeBody(&buf, msg, nil); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", multipartType("mixed", mixed))
+	buf.WriteString("\r\n")
+	if msg.Text != "" || msg.HTML != "" {
+		if err := writeBody(&buf, msg, mixed); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range msg.Attachments {
+		if err := writeAttachment(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the text and HTML parts: as the top-level body when
+// parent is nil, else as one part of parent.
+func writeBody(buf *bytes.Buffer, msg Email, parent *multipart.Writer) error {
+	var parts []textPart
+	if msg.Text != "" {
+		parts = append(parts, textPart{"text/plain; charset=utf-8", msg.Text})
+	}
+	if msg.HTML != "" {
+		parts = append(parts, textPart{"text/html; charset=utf-8", msg.HTML})
+	}
+
+	if len(parts) == 1 {
+		h := parts[0].header()
+		if parent != nil {
+			w, err := parent.CreatePart(h)
+			if err != nil {
+				return err
+			}
+			return parts[0].write(w)
+		}
+		writeMIMEHeader(buf, h)
+		buf.WriteString("\r\n")
+		return parts[0].write(buf)
+	}
+
+	var alt bytes.Buffer
+	aw := multipart.NewWriter(&alt)
+	for _, p := range parts {
+		w, err := aw.CreatePart(p.header())
+		if err != nil {
+			return err
+		}
+		if err := p.write(w); err != nil {
+			return err
+		}
+	}
+	if err := aw.Close(); err != nil {
+		return err
+	}
+
+	h := textproto.MIMEHeader{"Content-Type": {multipartType("alternative", aw)}}
+	if parent == nil {
+		writeMIMEHeader(buf, h)
+		buf.WriteString("\r\n")
+		_, err := buf.Write(alt.Bytes())
+		return err
+	}
+	w, err := parent.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(alt.Bytes())
+	return err
+}
+
+// textPart is one quoted-printable body part.
+type textPart struct {
+	contentType string
+	body        string
+}
+
+func (p textPart) header() textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Type":              {p.contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+}
+
+func (p textPart) write(w io.Writer) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, p.body); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeAttachment adds a as a base64 part of mixed.
+func writeAttachment(mixed *multipart.Writer, a Attachment) error {
+	name := filepath.Base(a.Filename)
+	if a.Filename == "" {
+		name = "attachment"
+	}
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {a.mediaType()},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+	})
+	if err != nil {
+		return err
+	}
+	enc := base64.StdEncoding.EncodeToString(a.Data)
+	for len(enc) > 76 {
+		if _, err := io.WriteString(w, enc[:76]+"\r\n"); err != nil {
+			return err
+		}
+		enc = enc[76:]
+	}
+	_, err = io.WriteString(w, enc+"\r\n")
+	return err
+}
+
+// formatAddrs parses and re-encodes a recipient list.
+func formatAddrs(list []string) (string, error) {
+	if len(list) == 0 {
+		return "", errors.New("email: no recipients")
+	}
+	out := make([]string, len(list))
+	for i, s := range list {
+		a, err := mail.ParseAddress(s)
+		if err != nil {
+			return "", fmt.Errorf("email to %q: %w", s, err)
+		}
+		out[i] = a.String()
+	}
+	return strings.Join(out, ", "), nil
+}
+
+func multipartType(sub string, w *multipart.Writer) string {
+	return mime.FormatMediaType("multipart/"+sub, map[string]string{"boundary": w.Boundary()})
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+// writeMIMEHeader writes h in a stable order.
+func writeMIMEHeader(buf *bytes.Buffer, h textproto.MIMEHeader) {
+	for _, k := range []string{"Content-Type", "Content-Transfer-Encoding", "Content-Disposition"} {
+		if v := h.Get(k); v != "" {
+			writeHeader(buf, k, v)
+		}
+	}
+}
+
+// messageID returns a random Message-ID in the sender's domain.
+func messageID(from string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok && d != "" {
+		domain = d
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,175 @@
+// internal/message/mime_test.go
+//
+// Unit-tests for BuildMIME: part structure, encodings, and headers.
+
+package message
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// parse reads raw back and returns its top-level message.
+func parse(t *testing.T, raw []byte) *mail.Message {
+	t.Helper()
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v\n%s", err, raw)
+	}
+	return m
+}
+
+// parts returns the parts of a multipart body with the given subtype.
+func parts(t *testing.T, ct string, body io.Reader, sub string) []*multipart.Part {
+	t.Helper()
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || mt != "multipart/"+sub {
+		t.Fatalf("Content-Type = %q, want multipart/%s", ct, sub)
+	}
+	r := multipart.NewReader(body, params["boundary"])
+	var out []*multipart.Part
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(p) // parts are only valid until NextPart
+		p.Header.Set("X-Test-Body", string(data))
+		out = append(out, p)
+	}
+}
+
+func qp(t *testing.T, s string) string {
+	t.Helper()
+	b, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(s)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestBuildMIME_Alternative(t *testing.T) {
+	raw, err := BuildMIME("Adept <noreply@example.com>", Email{
+		To:      []string{"José Núñez <jose@example.com>", "b@example.com"},
+		Subject: "Réservation confirmée",
+		Text:    "Merci !",
+		HTML:    "<p>Merci&nbsp;!</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := parse(t, raw)
+
+	subj, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || subj != "Réservation confirmée" {
+		t.Errorf("Subject = %q (%v)", subj, err)
+	}
+	if strings.ContainsAny(m.Header.Get("Subject")+m.Header.Get("To"), "éú") {
+		t.Error("non-ASCII header left unencoded")
+	}
+	to, err := m.Header.AddressList("To")
+	if err != nil || len(to) != 2 || to[0].Name != "José Núñez" {
+		t.Errorf("To = %v (%v)", to, err)
+	}
+	if m.Header.Get("MIME-Version") != "1.0" || m.Header.Get("Message-Id") == "" {
+		t.Error("MIME-Version or Message-ID missing")
+	}
+
+	ps := parts(t, m.Header.Get("Content-Type"), m.Body, "alternative")
+	if len(ps) != 2 {
+		t.Fatalf("parts = %d, want 2", len(ps))
+	}
+	if ct := ps[0].Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("first part = %q, want text/plain", ct)
+	}
+	if ct := ps[1].Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("last part = %q, want text/html", ct)
+	}
+	if got := qp(t, ps[1].Header.Get("X-Test-Body")); got != "<p>Merci&nbsp;!</p>" {
+		t.Errorf("html = %q", got)
+	}
+}
+
+func TestBuildMIME_Attachments(t *testing.T) {
+	pdf := bytes.Repeat([]byte("%PDF-1.7 "), 40)
+	raw, err := BuildMIME("noreply@example.com", Email{
+		To:          []string{"a@example.com"},
+		Subject:     "Invoice",
+		Text:        "See attached.",
+		HTML:        "<p>See attached.</p>",
+		Attachments: []Attachment{{Filename: "facture-été.pdf", Data: pdf}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := parse(t, raw)
+	top := parts(t, m.Header.Get("Content-Type"), m.Body, "mixed")
+	if len(top) != 2 {
+		t.Fatalf("mixed parts = %d, want 2", len(top))
+	}
+	alt := parts(t, top[0].Header.Get("Content-Type"),
+		strings.NewReader(top[0].Header.Get("X-Test-Body")), "alternative")
+	if len(alt) != 2 {
+		t.Errorf("alternative parts = %d, want 2", len(alt))
+	}
+
+	att := top[1]
+	if ct := att.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("attachment type = %q", ct)
+	}
+	if att.FileName() != "facture-été.pdf" {
+		t.Errorf("filename = %q", att.FileName())
+	}
+	body := att.Header.Get("X-Test-Body")
+	for _, line := range strings.Split(strings.TrimSpace(body), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("base64 line of %d chars", len(line))
+		}
+	}
+	got, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if err != nil || !bytes.Equal(got, pdf) {
+		t.Errorf("attachment round-trip failed (%v)", err)
+	}
+}
+
+func TestBuildMIME_TextOnly(t *testing.T) {
+	raw, err := BuildMIME("noreply@example.com", Email{To: []string{"a@example.com"}, Text: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := parse(t, raw)
+	if ct := m.Header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestBuildMIME_RejectsHeaderInjection(t *testing.T) {
+	_, err := BuildMIME("noreply@example.com", Email{
+		To:   []string{"a@example.com\r\nBcc: victim@example.com"},
+		Text: "x",
+	})
+	if err == nil {
+		t.Fatal("CRLF in recipient accepted")
+	}
+	raw, err := BuildMIME("noreply@example.com", Email{
+		To:      []string{"a@example.com"},
+		Subject: "hi\r\nBcc: victim@example.com",
+		Text:    "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parse(t, raw).Header.Get("Bcc") != "" {
+		t.Fatal("CRLF in subject injected a header")
+	}
+}
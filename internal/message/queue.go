@@ -0,0 +1,150 @@
+// internal/message/queue.go
+//
+// Adept – Messaging: primary queue hook and local delivery.
+//
+// Context
+//   Enqueue* publish to the primary Queue when one is installed with
+//   SetQueue (Redis, NATS, SQS, ...).  Its workers hand each Job back to
+//   Deliver.  Without a Queue, email goes to the local deliverer and
+//   webhooks are sent inline, as before.
+//
+// Workflow
+//   •  Enqueue* build a Job and call publish.
+//   •  publish tries the Queue.  On error the Job goes to the in-memory
+//      fallback (fallback.go) and the caller still gets nil: the job was
+//      accepted, best-effort.
+//   •  The first failure flips the subsystem into degraded mode (WARN log,
+//      message_queue_degraded = 1); the next successful publish flips it
+//      back (INFO log).
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/metrics"
+)
+
+// Job is one queued message.  Exactly one of Email and Webhook is set.
+type Job struct {
+	Email   *Email
+	Webhook *Webhook
+}
+
+// Kind returns "email" or "webhook".
+func (j Job) Kind() string {
+	if j.Webhook != nil {
+		return "webhook"
+	}
+	return "email"
+}
+
+// Webhook is an outbound request with its body buffered, so it can be
+// serialised to a queue and sent more than once.
+type Webhook struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// bufferWebhook copies req into a Webhook, consuming its body.
+func bufferWebhook(req *http.Request) (*Webhook, error) {
+	w := &Webhook{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone()}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("webhook body: %w", err)
+		}
+		w.Body = body
+	}
+	return w, nil
+}
+
+// request rebuilds the *http.Request for one delivery attempt.
+func (w *Webhook) request(ctx context.Context) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, w.Method, w.URL, bytes.NewReader(w.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = w.Header.Clone()
+	return req, nil
+}
+
+// Queue is the primary, usually external, message queue.
+type Queue interface {
+	Publish(ctx context.Context, j Job) error
+}
+
+var (
+	primary  atomic.Pointer[Queue]
+	degraded atomic.Bool
+)
+
+// SetQueue installs the primary queue.  nil removes it.
+func SetQueue(q Queue) {
+	if q == nil {
+		primary.Store(nil)
+		return
+	}
+	primary.Store(&q)
+}
+
+func primaryQueue() Queue {
+	if p := primary.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Degraded reports whether jobs are currently going to the in-memory
+// fallback because the primary queue is failing.
+func Degraded() bool { return degraded.Load() }
+
+// Deliver performs j locally.  Queue workers call it for each job they
+// consume; the in-memory fallback does the same.
+func Deliver(ctx context.Context, j Job) error {
+	switch {
+	case j.Webhook != nil:
+		req, err := j.Webhook.request(ctx)
+		if err != nil {
+			return err
+		}
+		return sendWebhook(ctx, req)
+	case j.Email != nil:
+		return deliverEmail(ctx, *j.Email)
+	}
+	return fmt.Errorf("message: empty job")
+}
+
+// publish hands j to q, falling back to memory when q fails.
+func publish(ctx context.Context, q Queue, j Job) error {
+	err := q.Publish(ctx, j)
+	if err == nil {
+		if degraded.CompareAndSwap(true, false) {
+			metrics.MessageQueueDegraded.Set(0)
+			zap.L().Info("message queue recovered – leaving in-memory fallback")
+		}
+		return nil
+	}
+	if degraded.CompareAndSwap(false, true) {
+		metrics.MessageQueueDegraded.Set(1)
+		zap.L().Warn("message queue unavailable – DEGRADED: jobs held in memory and lost on restart",
+			zap.Error(err))
+	}
+	fallback.push(j)
+	return nil
+}
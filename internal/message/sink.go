@@ -0,0 +1,208 @@
+// internal/message/sink.go
+//
+// Adept – Messaging: email sinks for tests and development.
+//
+// Context
+//   Exercising an email action should not need an SMTP server.  An
+//   EmailSink installed with SetEmailSink receives every email in place
+//   of the transport:
+//
+//      CaptureSink   keeps messages in memory; tests read them back with
+//                    Emails.
+//      MaildirSink   writes each message as a complete RFC 5322 file into
+//                    a Maildir (tmp/ → new/), readable by mutt, Thunderbird,
+//                    or any text editor.
+//
+// Workflow
+//   •  Tests: s := NewCaptureSink(); SetEmailSink(s); defer SetEmailSink(nil).
+//   •  Servers: mail.sink in config ("capture" or "maildir") goes through
+//      SinkFromConfig, which main installs at boot.
+//
+// Rules
+//   •  SinkFromConfig refuses either sink unless ADEPT_ENV is dev, test, or
+//      local, so a config copied from a laptop cannot swallow production
+//      mail.  An unset ADEPT_ENV counts as production.
+//   •  Dedup still applies: a sink sees what the transport would have.
+//   •  With a primary queue installed, messages reach the sink when the
+//      consumer delivers them, not when they are enqueued.
+//   •  CaptureSink keeps at most CaptureLimit messages, dropping the
+//      oldest, so a long-running dev server cannot grow without bound.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EmailSink receives emails in place of the transport.
+type EmailSink interface {
+	Send(ctx context.Context, msg Email) error
+}
+
+var emailSink atomic.Pointer[EmailSink]
+
+// SetEmailSink routes every delivered email to s.  nil restores the
+// transport.
+func SetEmailSink(s EmailSink) {
+	if s == nil {
+		emailSink.Store(nil)
+		return
+	}
+	emailSink.Store(&s)
+}
+
+// sinkEnvs are the ADEPT_ENV values under which SinkFromConfig installs a
+// sink.
+var sinkEnvs = map[string]bool{"dev": true, "test": true, "local": true}
+
+// SinkFromConfig builds the sink named by mail.sink: "" or "log" yields
+// nil (use the transport), "capture" a CaptureSink, and "maildir" a
+// MaildirSink in dir.  env is ADEPT_ENV; see Rules.
+func SinkFromConfig(kind, dir, from, env string) (EmailSink, error) {
+	switch kind {
+	case "", "log":
+		return nil, nil
+	case "capture", "maildir":
+	default:
+		return nil, fmt.Errorf("message: unknown email sink %q", kind)
+	}
+	if !sinkEnvs[env] {
+		return nil, fmt.Errorf("message: email sink %q refused: ADEPT_ENV %q is not dev, test, or local", kind, env)
+	}
+	if kind == "capture" {
+		return NewCaptureSink(), nil
+	}
+	md, err := NewMaildirSink(dir, from)
+	if err != nil {
+		return nil, err // not a typed-nil sink
+	}
+	return md, nil
+}
+
+/*──────────────────────────── capture ─────────────────────────────────────*/
+
+// CaptureLimit bounds the messages a CaptureSink keeps.
+const CaptureLimit = 1000
+
+// CaptureSink records emails in memory.  It is safe for concurrent use.
+type CaptureSink struct {
+	mu   sync.Mutex
+	msgs []Email
+}
+
+// NewCaptureSink returns an empty CaptureSink.
+func NewCaptureSink() *CaptureSink { return &CaptureSink{} }
+
+// Send records a copy of msg.
+func (s *CaptureSink) Send(_ context.Context, msg Email) error {
+	msg.To = append([]string(nil), msg.To...)
+	msg.Attachments = append([]Attachment(nil), msg.Attachments...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.msgs) >= CaptureLimit {
+		s.msgs = append(s.msgs[:0:0], s.msgs[len(s.msgs)-CaptureLimit+1:]...)
+	}
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+// Emails returns the captured messages, oldest first.
+func (s *CaptureSink) Emails() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Email(nil), s.msgs...)
+}
+
+// Len returns how many messages are held.
+func (s *CaptureSink) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.msgs)
+}
+
+// Reset drops every captured message.
+func (s *CaptureSink) Reset() {
+	s.mu.Lock()
+	s.msgs = nil
+	s.mu.Unlock()
+}
+
+/*──────────────────────────── maildir ─────────────────────────────────────*/
+
+// DefaultSinkFrom is the sender MaildirSink renders when neither the
+// Email nor the sink names one.
+const DefaultSinkFrom = "adept@localhost"
+
+// MaildirSink writes each email as one file in a Maildir.
+type MaildirSink struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+// NewMaildirSink creates dir/tmp, dir/new, and dir/cur as needed.  from is
+// the default sender ("" → DefaultSinkFrom).
+func NewMaildirSink(dir, from string) (*MaildirSink, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("message: maildir sink needs a directory")
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	if from == "" {
+		from = DefaultSinkFrom
+	}
+	return &MaildirSink{dir: dir, from: from}, nil
+}
+
+// Dir returns the Maildir root.
+func (s *MaildirSink) Dir() string { return s.dir }
+
+// Send renders msg and delivers it to new/.  The file is written under
+// tmp/ and renamed, so readers never see a partial message.
+func (s *MaildirSink) Send(_ context.Context, msg Email) error {
+	raw, err := BuildMIME(s.from, msg)
+	if err != nil {
+		return err
+	}
+	name := s.uniqueName()
+	tmp := filepath.Join(s.dir, "tmp", name)
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, "new", name)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// uniqueName follows the Maildir convention time.unique.host.
+func (s *MaildirSink) uniqueName() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	host = strings.NewReplacer("/", `\057`, ":", `\072`).Replace(host)
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return strconv.FormatInt(time.Now().Unix(), 10) + ".P" + strconv.Itoa(os.Getpid()) +
+		"Q" + strconv.FormatUint(s.seq.Add(1), 10) + "R" + hex.EncodeToString(b) + "." + host
+}
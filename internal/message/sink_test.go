@@ -0,0 +1,115 @@
+// internal/message/sink_test.go
+//
+// Unit-tests for the capture and maildir email sinks.
+
+package message
+
+import (
+	"context"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCaptureSink_ReceivesEnqueuedEmail(t *testing.T) {
+	s := NewCaptureSink()
+	SetEmailSink(s)
+	t.Cleanup(func() { SetEmailSink(nil) })
+
+	to := []string{"a@example.com"}
+	if err := EnqueueEmail(context.Background(), Email{To: to, Subject: "Hi", Text: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	to[0] = "changed@example.com" // the sink holds its own copy
+
+	got := s.Emails()
+	if len(got) != 1 || got[0].Subject != "Hi" || got[0].To[0] != "a@example.com" {
+		t.Fatalf("captured %+v", got)
+	}
+	s.Reset()
+	if s.Len() != 0 {
+		t.Fatalf("Len after Reset = %d", s.Len())
+	}
+}
+
+func TestCaptureSink_ConcurrentAndBounded(t *testing.T) {
+	s := NewCaptureSink()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < CaptureLimit/4; i++ {
+				_ = s.Send(context.Background(), Email{Subject: "s"})
+				_ = s.Emails()
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != CaptureLimit {
+		t.Fatalf("Len = %d, want cap %d", s.Len(), CaptureLimit)
+	}
+
+	_ = s.Send(context.Background(), Email{Subject: "newest"})
+	got := s.Emails()
+	if len(got) != CaptureLimit || got[len(got)-1].Subject != "newest" {
+		t.Fatalf("oldest not dropped: len %d, last %q", len(got), got[len(got)-1].Subject)
+	}
+}
+
+func TestMaildirSink_WritesParsableMessage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	s, err := NewMaildirSink(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := Email{To: []string{"b@example.com"}, Subject: "Report", Text: "body text"}
+	if err := s.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := os.ReadDir(filepath.Join(dir, "new"))
+	if len(files) != 1 {
+		t.Fatalf("new/ holds %d files", len(files))
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Fatalf("tmp/ not emptied: %d files", len(tmp))
+	}
+	f, err := os.Open(filepath.Join(dir, "new", files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Get("From") != "<"+DefaultSinkFrom+">" || m.Header.Get("Subject") != "Report" {
+		t.Fatalf("headers %v", m.Header)
+	}
+}
+
+func TestSinkFromConfig_RefusedOutsideDev(t *testing.T) {
+	for _, env := range []string{"", "prod", "staging"} {
+		if _, err := SinkFromConfig("capture", "", "", env); err == nil {
+			t.Errorf("capture sink allowed under ADEPT_ENV %q", env)
+		}
+	}
+	if s, err := SinkFromConfig("", "", "", "prod"); err != nil || s != nil {
+		t.Fatalf("default sink: %v, %v", s, err)
+	}
+	if s, err := SinkFromConfig("capture", "", "", "test"); err != nil {
+		t.Fatal(err)
+	} else if _, ok := s.(*CaptureSink); !ok {
+		t.Fatalf("capture sink is %T", s)
+	}
+	if s, err := SinkFromConfig("maildir", "", "", "dev"); err == nil || s != nil {
+		t.Fatalf("maildir without dir: %v, %v", s, err)
+	}
+	if _, err := SinkFromConfig("smtp", "", "", "dev"); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("unknown sink: %v", err)
+	}
+}
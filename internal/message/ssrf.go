@@ -0,0 +1,266 @@
+// internal/message/ssrf.go
+//
+// Adept – Messaging: outbound address policy (SSRF protection).
+//
+// Context
+//   Webhook URLs come from form YAML, and later URL-fetching features will
+//   take them from tenant data.  Without a policy, a form could POST to
+//   Vault, the cloud metadata endpoint, or any service on the private
+//   network.  AddressPolicy is enforced inside the shared client, so every
+//   caller of HTTPClient() is covered, redirects included.
+//
+// Workflow
+//   •  Before each request (and each redirect hop) the URL is checked:
+//      scheme http or https, host on AllowHosts when that list is set, and
+//      a literal IP host against the ranges below.
+//   •  The dialer resolves the host itself, checks every returned address,
+//      and then connects to a checked IP.  A name that resolves to any
+//      blocked address is refused outright, so DNS rebinding cannot swap
+//      in an internal address between check and connect.
+//
+// Rules
+//   •  Blocked unless AllowPrivate: loopback, private (RFC 1918, ULA),
+//      link-local (incl. 169.254.169.254), CGNAT, unspecified, multicast,
+//      reserved, and the NAT64 / 6to4 prefixes that can embed them.
+//   •  DenyCIDRs are always blocked.  AllowCIDRs punch holes in the
+//      default ranges (e.g. an internal webhook relay), never in DenyCIDRs.
+//   •  AllowHosts entries are exact names, IPs, or "*.example.com"
+//      (subdomains only).  Being listed does not exempt a host from the
+//      address checks.
+//   •  HTTP(S)_PROXY from the environment is not consulted, since the
+//      dialer could not see past it.  An explicit ClientOptions.Proxy is
+//      exempt from the address checks; the URL checks still apply, and the
+//      proxy must police addresses itself.
+//
+// Style
+//   Two-space sentence spacing, Oxford comma, concise inline notes.
+//
+//------------------------------------------------------------------------------
+
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// ErrBlockedAddress is wrapped by every error the policy returns.
+var ErrBlockedAddress = errors.New("outbound address blocked")
+
+// AddressPolicy restricts where the shared client may connect.  The zero
+// value blocks internal ranges and allows every public host.
+type AddressPolicy struct {
+	AllowPrivate bool     // permit the internal ranges (development only)
+	AllowHosts   []string // non-empty: only these hosts may be requested
+	AllowCIDRs   []string // exceptions to the internal ranges
+	DenyCIDRs    []string // always blocked, even with AllowPrivate
+}
+
+// internalRanges are blocked unless AllowPrivate or AllowCIDRs say
+// otherwise.  IPv4-mapped IPv6 addresses are unmapped before matching.
+var internalRanges = mustPrefixes(
+	"0.0.0.0/8",      // "this network", incl. 0.0.0.0
+	"10.0.0.0/8",     // RFC 1918
+	"100.64.0.0/10",  // CGNAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local, cloud metadata
+	"172.16.0.0/12",  // RFC 1918
+	"192.0.0.0/24",   // IETF protocol assignments
+	"192.168.0.0/16", // RFC 1918
+	"198.18.0.0/15",  // benchmarking
+	"224.0.0.0/4",    // multicast
+	"240.0.0.0/4",    // reserved, incl. broadcast
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"64:ff9b::/96",   // NAT64, embeds IPv4
+	"2002::/16",      // 6to4, embeds IPv4
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+	"ff00::/8",       // multicast
+)
+
+func mustPrefixes(ss ...string) []netip.Prefix {
+	out := make([]netip.Prefix, len(ss))
+	for i, s := range ss {
+		out[i] = netip.MustParsePrefix(s)
+	}
+	return out
+}
+
+// lookupNetIP resolves host names for the guarded dialer; tests swap it.
+var lookupNetIP = net.DefaultResolver.LookupNetIP
+
+// addressGuard is the compiled form of an AddressPolicy.
+type addressGuard struct {
+	allowPrivate bool
+	hosts        map[string]bool
+	suffixes     []string // "*.example.com" → ".example.com"
+	allow, deny  []netip.Prefix
+	proxyAddr    string // exempt from address checks
+	dial         func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// compile validates p.  A CIDR or host entry that does not parse is an
+// error.
+func (p AddressPolicy) compile() (*addressGuard, error) {
+	g := &addressGuard{allowPrivate: p.AllowPrivate}
+	var err error
+	if g.allow, err = parsePrefixes(p.AllowCIDRs); err != nil {
+		return nil, err
+	}
+	if g.deny, err = parsePrefixes(p.DenyCIDRs); err != nil {
+		return nil, err
+	}
+	for _, h := range p.AllowHosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		switch {
+		case strings.HasPrefix(h, "*.") && len(h) > 2:
+			g.suffixes = append(g.suffixes, h[1:])
+		case h == "" || strings.ContainsAny(h, "*/:@ "):
+			return nil, fmt.Errorf("outbound policy: invalid allow host %q", h)
+		default:
+			if g.hosts == nil {
+				g.hosts = make(map[string]bool)
+			}
+			g.hosts[h] = true
+		}
+	}
+	return g, nil
+}
+
+func parsePrefixes(ss []string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, s := range ss {
+		p, err := netip.ParsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("outbound policy: invalid CIDR %q", s)
+		}
+		out = append(out, p.Masked())
+	}
+	return out, nil
+}
+
+// checkIP reports whether the client may connect to ip.
+func (g *addressGuard) checkIP(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if !ip.IsValid() || contains(g.deny, ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	if g.allowPrivate || contains(g.allow, ip) || !contains(internalRanges, ip) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is an internal address", ErrBlockedAddress, ip)
+}
+
+func contains(ps []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range ps {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL vets a request URL before any connection is made.
+func (g *addressGuard) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrBlockedAddress, u.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrBlockedAddress)
+	}
+	if !g.hostAllowed(host) {
+		return fmt.Errorf("%w: host %q not on allow list", ErrBlockedAddress, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return g.checkIP(ip)
+	}
+	return nil
+}
+
+func (g *addressGuard) hostAllowed(host string) bool {
+	if g.hosts == nil && g.suffixes == nil {
+		return true
+	}
+	if g.hosts[host] {
+		return true
+	}
+	for _, s := range g.suffixes {
+		if strings.HasSuffix(host, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxy wraps the transport's proxy selector so checkURL runs on every
+// request, redirects included, before the transport dials.
+func (g *addressGuard) proxy(next func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(r *http.Request) (*url.URL, error) {
+		if err := g.checkURL(r.URL); err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, nil
+		}
+		return next(r)
+	}
+}
+
+// dialContext resolves addr, checks every address, and connects to the
+// first checked address that answers.
+func (g *addressGuard) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if g.proxyAddr != "" && addr == g.proxyAddr {
+		return g.dial(ctx, network, addr)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := lookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("outbound dial %s: no addresses", host)
+	}
+	for _, ip := range ips {
+		if err := g.checkIP(ip); err != nil {
+			return nil, fmt.Errorf("%s: %w", host, err)
+		}
+	}
+
+	var firstErr error
+	for _, ip := range ips {
+		conn, err := g.dial(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// proxyHostPort returns u's host with the scheme's default port filled in.
+func proxyHostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
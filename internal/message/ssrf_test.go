@@ -0,0 +1,156 @@
+// internal/message/ssrf_test.go
+//
+// Unit-tests for the outbound address policy.
+
+package message
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestAddressPolicy_CheckIP(t *testing.T) {
+	g, err := AddressPolicy{
+		AllowCIDRs: []string{"10.1.2.0/24"},
+		DenyCIDRs:  []string{"203.0.113.0/24"},
+	}.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{ // ip → allowed
+		"93.184.216.34":        true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"10.0.0.5":             false,
+		"10.1.2.3":             true, // AllowCIDRs hole
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"::ffff:127.0.0.1":     false, // mapped loopback
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"64:ff9b::a9fe:a9fe":   false, // NAT64 metadata
+		"203.0.113.7":          false, // DenyCIDRs
+		"::ffff:93.184.216.34": true,
+	}
+	for s, want := range cases {
+		if err := g.checkIP(netip.MustParseAddr(s)); (err == nil) != want {
+			t.Errorf("checkIP(%s) = %v, want allowed=%v", s, err, want)
+		}
+	}
+
+	open, _ := AddressPolicy{AllowPrivate: true, DenyCIDRs: []string{"127.0.0.0/8"}}.compile()
+	if open.checkIP(netip.MustParseAddr("10.0.0.5")) != nil {
+		t.Error("AllowPrivate did not allow 10.0.0.5")
+	}
+	if !errors.Is(open.checkIP(netip.MustParseAddr("127.0.0.1")), ErrBlockedAddress) {
+		t.Error("DenyCIDRs did not beat AllowPrivate")
+	}
+}
+
+func TestAddressPolicy_CheckURL(t *testing.T) {
+	g, err := AddressPolicy{AllowHosts: []string{"hooks.example.com", "*.partner.test"}}.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{
+		"https://hooks.example.com/x":   true,
+		"https://HOOKS.example.com./x":  true,
+		"https://a.b.partner.test/":     true,
+		"https://partner.test/":         false, // wildcard is subdomains only
+		"https://evil.example.com/":     false,
+		"ftp://hooks.example.com/":      false,
+		"http://169.254.169.254/latest": false,
+	}
+	for raw, want := range cases {
+		u, _ := url.Parse(raw)
+		if err := g.checkURL(u); (err == nil) != want {
+			t.Errorf("checkURL(%s) = %v, want allowed=%v", raw, err, want)
+		}
+	}
+
+	for _, bad := range []AddressPolicy{
+		{AllowCIDRs: []string{"10.0.0.0/33"}},
+		{AllowHosts: []string{"https://x.example"}},
+	} {
+		if _, err := bad.compile(); err == nil {
+			t.Errorf("compile(%+v) accepted", bad)
+		}
+	}
+}
+
+// fakeDNS points every name in m at its address for the test's duration.
+func fakeDNS(t *testing.T, m map[string][]string) {
+	t.Helper()
+	orig := lookupNetIP
+	lookupNetIP = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		if ip, err := netip.ParseAddr(host); err == nil {
+			return []netip.Addr{ip}, nil
+		}
+		var out []netip.Addr
+		for _, s := range m[host] {
+			out = append(out, netip.MustParseAddr(s))
+		}
+		return out, nil
+	}
+	t.Cleanup(func() { lookupNetIP = orig })
+}
+
+func TestHTTPClient_PolicyEnforcedAtDial(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/bounce" {
+			http.Redirect(w, r, "http://127.0.0.1:1/admin", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+	at := func(host, path string) string { return "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + path }
+
+	// relay.test resolves to loopback, which the policy lets through;
+	// rebind.test mixes in a blocked address.
+	fakeDNS(t, map[string][]string{
+		"relay.test":  {"127.0.0.1"},
+		"rebind.test": {"93.184.216.34", "10.0.0.1"},
+	})
+	c, err := NewHTTPClient(ClientOptions{Policy: AddressPolicy{AllowCIDRs: []string{"127.0.0.0/8"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(at("relay.test", "/"))
+	if err != nil {
+		t.Fatalf("allowed host: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := c.Get(at("rebind.test", "/")); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("rebind err = %v, want ErrBlockedAddress", err)
+	}
+
+	// Default policy: loopback is refused before any connection.
+	strict, _ := NewHTTPClient(ClientOptions{})
+	before := hits
+	if _, err := strict.Get(at("relay.test", "/")); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("default policy err = %v", err)
+	}
+	if hits != before {
+		t.Fatal("blocked request reached the server")
+	}
+
+	// Redirect targets are checked too.
+	narrow, _ := NewHTTPClient(ClientOptions{Policy: AddressPolicy{
+		AllowHosts: []string{"relay.test"}, AllowCIDRs: []string{"127.0.0.0/8"}}})
+	if _, err := narrow.Get(at("relay.test", "/bounce")); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("redirect err = %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+// internal/message/unsubscribe_test.go
+//
+// Unit-tests for unsubscribe tokens and bulk-mail headers.
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// withUnsubscribeKey installs a fixed signing key (nil: none).
+func withUnsubscribeKey(t *testing.T, key []byte) {
+	t.Helper()
+	orig := unsubscribeKey
+	unsubscribeKey = func() []byte { return key }
+	t.Cleanup(func() { unsubscribeKey = orig })
+}
+
+func TestUnsubscribeURL_RoundTrip(t *testing.T) {
+	withUnsubscribeKey(t, bytes.Repeat([]byte{7}, 32))
+
+	link, err := UnsubscribeURL("https://example.com/unsubscribe?src=mail", "Ada@Example.com", "digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(link)
+	if u.Query().Get("src") != "mail" {
+		t.Errorf("base query lost: %s", link)
+	}
+	tok := u.Query().Get("token")
+	rcpt, list, err := ParseUnsubscribeToken(tok)
+	if err != nil || rcpt != "ada@example.com" || list != "digest" {
+		t.Fatalf("Parse = %q, %q, %v", rcpt, list, err)
+	}
+
+	// Swap the recipient but keep the signature.
+	p64, sig, _ := strings.Cut(tok, ".")
+	forged := strings.Replace(p64, p64[:4], "ZXZl", 1) + "." + sig
+	if _, _, err := ParseUnsubscribeToken(forged); !errors.Is(err, ErrBadUnsubscribeToken) {
+		t.Errorf("forged token: err = %v", err)
+	}
+	if _, err := UnsubscribeURL("http://example.com/u", "a@example.com", "x"); err == nil {
+		t.Error("plain-http base accepted")
+	}
+}
+
+func TestUnsubscribeURL_NeedsKey(t *testing.T) {
+	withUnsubscribeKey(t, nil)
+	if _, err := UnsubscribeURL("https://example.com/u", "a@example.com", "x"); !errors.Is(err, ErrNoUnsubscribeKey) {
+		t.Fatalf("err = %v, want ErrNoUnsubscribeKey", err)
+	}
+}
+
+func TestBuildMIME_BulkHeaders(t *testing.T) {
+	msg := Email{
+		From:    "News <news@example.com>",
+		To:      []string{"a@example.com"},
+		Subject: "Weekly digest",
+		Text:    "hi",
+		Unsubscribe: &Unsubscribe{
+			URL:      "https://example.com/u?token=abc",
+			Mailto:   "unsub@example.com",
+			OneClick: true,
+		},
+	}
+	raw, err := BuildMIME("noreply@example.com", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := parse(t, raw).Header
+	if got := h.Get("List-Unsubscribe"); got != "<https://example.com/u?token=abc>, <mailto:unsub@example.com>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if h.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" || h.Get("Precedence") != "bulk" {
+		t.Error("one-click or Precedence header missing")
+	}
+	if from, _ := h.AddressList("From"); len(from) != 1 || from[0].Address != "news@example.com" {
+		t.Errorf("From = %v, want the Email's own sender", from)
+	}
+	if msg.Envelope("bounce@example.com") != "news@example.com" {
+		t.Errorf("Envelope = %q", msg.Envelope("bounce@example.com"))
+	}
+
+	// Transactional mail carries none of them.
+	msg.Unsubscribe = nil
+	raw, _ = BuildMIME("noreply@example.com", msg)
+	h = parse(t, raw).Header
+	if h.Get("List-Unsubscribe") != "" || h.Get("Precedence") != "" {
+		t.Error("transactional email got bulk headers")
+	}
+}
+
+func TestBuildMIME_BadUnsubscribe(t *testing.T) {
+	bad := []*Unsubscribe{
+		{},
+		{URL: "http://example.com/u"},
+		{URL: "https://example.com/u>, <https://evil.example"},
+		{Mailto: "Unsub <u@example.com>"},
+		{Mailto: "u@example.com", OneClick: true},
+	}
+	for _, u := range bad {
+		msg := Email{To: []string{"a@example.com"}, Text: "x", Unsubscribe: u}
+		if _, err := BuildMIME("noreply@example.com", msg); err == nil {
+			t.Errorf("accepted %+v", *u)
+		}
+	}
+}
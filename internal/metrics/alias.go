@@ -0,0 +1,71 @@
+// internal/metrics/alias.go
+//
+// Alias-cache instruments (internal/routing).
+//
+// Context
+// -------
+// Every request to an ALIAS or BOTH tenant consults the alias cache, and a
+// miss used to cost one or two SQL queries.  These counters show how much
+// traffic the in-memory map absorbs, how often the SQL fallback still runs,
+// and how often maps are rebuilt.  They carry no tenant label: the alias
+// cache is per tenant, but the question they answer is per process.
+//
+// Notes
+// -----
+// • AliasCacheMissesTotal counts every lookup that ended without an alias;
+//   AliasNegativeHitsTotal is the subset answered from the negative cache
+//   without touching SQL.
+// • Oxford commas, two spaces after periods.
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	AliasCacheHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_cache_hits_total",
+			Help: "Alias lookups answered from memory with an alias.",
+		})
+
+	AliasCacheMissesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_cache_misses_total",
+			Help: "Alias lookups that found no alias.",
+		})
+
+	AliasNegativeHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_cache_negative_hits_total",
+			Help: "Alias misses answered from the negative cache, without SQL.",
+		})
+
+	AliasSQLFallbackTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_sql_fallback_total",
+			Help: "Alias lookups that fell back to a route_alias query.",
+		})
+
+	AliasReloadsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_cache_reloads_total",
+			Help: "Full alias-map loads from route_alias.",
+		})
+
+	AliasReloadErrorsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "alias_cache_reload_errors_total",
+			Help: "Alias-map loads that failed; the previous map kept serving.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(
+		AliasCacheHitsTotal,
+		AliasCacheMissesTotal,
+		AliasNegativeHitsTotal,
+		AliasSQLFallbackTotal,
+		AliasReloadsTotal,
+		AliasReloadErrorsTotal,
+	)
+}
@@ -0,0 +1,143 @@
+// internal/metrics/http.go
+//
+// Per-tenant HTTP request instruments and the middleware that feeds them.
+//
+// Context
+// -------
+// The tenant-cache series say which site reloads; they do not say which
+// site is slow or hot.  InstrumentHTTP wraps the root handler and records,
+// per request:
+//
+//   - tenant_http_requests_total{host, code}       responses by status class
+//   - tenant_http_request_duration_seconds{host}   latency histogram
+//   - http_requests_in_flight                       requests being served now
+//
+// Workflow
+// --------
+//  1. InstrumentHTTP puts a label slot on the request context, defaulting
+//     to UnknownHost, and wraps the ResponseWriter to see the status.
+//  2. The root handler resolves the tenant and calls SetRequestHost with
+//     its canonical host.
+//  3. When the handler returns, the slot's value labels both series.
+//
+// Notes
+// -----
+// • Host labels go through HostLabel, so the cap shared with the tenant
+//   series (MaxHostLabels, then "other") bounds these too, and hosts that
+//   never resolve stay under "unknown".
+// • code is the class ("2xx" … "5xx"), not the exact status, to keep the
+//   series count at hosts × 5.
+// • Oxford commas, two spaces after periods.
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_http_requests_total",
+			Help: "HTTP responses by tenant host and status class.",
+		}, []string{"host", "code"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "tenant_http_request_duration_seconds",
+			Help:    "HTTP request latency by tenant host.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"host"})
+
+	HTTPInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests currently being served.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(HTTPRequestsTotal, HTTPRequestDuration, HTTPInFlight)
+}
+
+type hostSlotKey struct{}
+
+// hostSlot is filled in by SetRequestHost and read after the handler.
+type hostSlot struct{ label string }
+
+// SetRequestHost labels the current request with the tenant's canonical
+// host.  It is a no-op outside InstrumentHTTP.
+func SetRequestHost(ctx context.Context, host string) {
+	if s, ok := ctx.Value(hostSlotKey{}).(*hostSlot); ok {
+		s.label = HostLabel(host)
+	}
+}
+
+// InstrumentHTTP records request count, latency, and in-flight requests.
+func InstrumentHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HTTPInFlight.Inc()
+		defer HTTPInFlight.Dec()
+
+		slot := &hostSlot{label: UnknownHost}
+		r = r.WithContext(context.WithValue(r.Context(), hostSlotKey{}, slot))
+		sw := &statusWriter{ResponseWriter: w}
+		start := time.Now()
+
+		next.ServeHTTP(sw, r)
+
+		HTTPRequestDuration.WithLabelValues(slot.label).Observe(time.Since(start).Seconds())
+		HTTPRequestsTotal.WithLabelValues(slot.label, statusClass(sw.status())).Inc()
+	})
+}
+
+// statusClass maps 404 → "4xx".  Out-of-range codes count as "5xx".
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "5xx"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// statusWriter remembers the first status written.  Unwrap keeps
+// http.ResponseController (Flush, Hijack, deadlines) working through it.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying writer when it can flush.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// status is the recorded code; a handler that wrote nothing sent 200.
+func (w *statusWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
@@ -0,0 +1,60 @@
+// internal/metrics/http_test.go
+//
+// Unit-tests for InstrumentHTTP and its host label slot.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentHTTP_LabelsByHostAndClass(t *testing.T) {
+	resetHosts(t, 10)
+	var inFlight float64
+	h := InstrumentHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = testutil.ToFloat64(HTTPInFlight)
+		if r.URL.Path == "/tenant" {
+			SetRequestHost(r.Context(), "hot.example")
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	ok := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues("hot.example", "2xx"))
+	miss := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues(UnknownHost, "4xx"))
+	before := testutil.ToFloat64(HTTPInFlight)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tenant", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if got := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues("hot.example", "2xx")); got != ok+1 {
+		t.Fatalf("hot.example 2xx = %v, want %v", got, ok+1)
+	}
+	if got := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues(UnknownHost, "4xx")); got != miss+1 {
+		t.Fatalf("unknown 4xx = %v, want %v", got, miss+1)
+	}
+	if inFlight != before+1 {
+		t.Fatalf("in-flight during request = %v, want %v", inFlight, before+1)
+	}
+	if got := testutil.ToFloat64(HTTPInFlight); got != before {
+		t.Fatalf("in-flight after = %v, want %v", got, before)
+	}
+	if n := testutil.CollectAndCount(HTTPRequestDuration, "tenant_http_request_duration_seconds"); n < 2 {
+		t.Fatalf("duration series = %d, want hot.example and unknown", n)
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	for code, want := range map[int]string{200: "2xx", 301: "3xx", 404: "4xx", 503: "5xx", 0: "5xx", 700: "5xx"} {
+		if got := statusClass(code); got != want {
+			t.Errorf("statusClass(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
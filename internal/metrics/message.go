@@ -0,0 +1,73 @@
+// internal/metrics/message.go
+//
+// Message-queue instruments (internal/message).
+//
+// Context
+// -------
+// When the primary queue rejects a publish, internal/message parks the job
+// in a bounded in-memory fallback and delivers it from a local worker.
+// That is a degraded mode: jobs there die with the process.  These series
+// make it visible on a dashboard instead of only in logs.
+//
+// Notes
+// -----
+// • message_queue_degraded is 1 from the first failed publish until a
+//   publish succeeds again.
+// • A non-zero message_fallback_dropped_total means jobs were lost: the
+//   fallback was full and its oldest job was discarded.
+// • message_dedup_suppressed_total counts emails skipped because their
+//   dedup key was already marked sent, i.e. retries that did not resend.
+// • Oxford commas, two spaces after periods.
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	MessageQueueDegraded = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "message_queue_degraded",
+			Help: "1 while the primary message queue is failing and the in-memory fallback is in use.",
+		})
+
+	MessageFallbackTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "message_fallback_total",
+			Help: "Jobs routed to the in-memory fallback queue, by kind.",
+		}, []string{"kind"})
+
+	MessageFallbackDroppedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "message_fallback_dropped_total",
+			Help: "Oldest fallback jobs discarded because the fallback queue was full.",
+		})
+
+	MessageFallbackFailedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "message_fallback_failed_total",
+			Help: "Fallback jobs whose local delivery failed.",
+		})
+
+	MessageFallbackDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "message_fallback_depth",
+			Help: "Jobs waiting in the in-memory fallback queue.",
+		})
+
+	MessageDedupSuppressedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "message_dedup_suppressed_total",
+			Help: "Emails not sent because their dedup key was already marked sent.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(
+		MessageQueueDegraded,
+		MessageFallbackTotal,
+		MessageFallbackDroppedTotal,
+		MessageFallbackFailedTotal,
+		MessageFallbackDepth,
+		MessageDedupSuppressedTotal,
+	)
+}
@@ -31,6 +31,24 @@ var (
 			Name: "tenant_evict_total",
 			Help: "Cumulative number of tenants evicted from the cache.",
 		})
+
+	WidgetCacheHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "widget_cache_hits_total",
+			Help: "Widget renders served from the fragment cache.",
+		})
+
+	WidgetCacheMissesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "widget_cache_misses_total",
+			Help: "Cacheable widget renders that missed the fragment cache and were stored.",
+		})
+
+	WidgetCacheEntries = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "widget_cache_entries",
+			Help: "Rendered widget fragments currently held in the cache.",
+		})
 )
 
 func init() {
@@ -39,5 +57,8 @@ func init() {
 		TenantLoadTotal,
 		TenantLoadErrorsTotal,
 		TenantEvictTotal,
+		WidgetCacheHitsTotal,
+		WidgetCacheMissesTotal,
+		WidgetCacheEntries,
 	)
 }
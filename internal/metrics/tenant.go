@@ -0,0 +1,161 @@
+// internal/metrics/tenant.go
+//
+// Tenant-cache instruments: per-host series with a bounded label, load
+// latency, and single-flight coalescing.
+//
+// Context
+// -------
+// The global tenant counters in metrics.go say how much churn there is,
+// not where.  The *_host_* vectors below repeat them with a `host` label so
+// a dashboard can single out the tenant that keeps reloading or failing.
+//
+// Cardinality guard
+// -----------------
+// Every label value is a separate time series held in Prometheus memory,
+// and the Host header is attacker-controlled.  HostLabel therefore admits
+// at most MaxHostLabels distinct hosts per process; later hosts share the
+// "other" series.  Only canonical hosts of resolved sites are admitted;
+// requests that never resolve to a site (404s and lookup errors) are
+// reported under "unknown", so a Host-header flood cannot crowd real
+// tenants out of their labels.
+//
+// Notes
+// -----
+// • Admission is first come, first served and lasts for the process; a
+//   restart re-admits whichever tenants load first (normally the warm set).
+// • The Tenant* helpers update the global and the labelled instruments
+//   together, so call sites never drift apart.
+// • TenantLoadDuration and TenantLoadFlights carry no host label; they
+//   describe the loader, not a tenant.
+// • Oxford commas, two spaces after periods.
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MaxHostLabels caps the distinct host label values.  Set before serving.
+var MaxHostLabels = 500
+
+const (
+	OtherHost   = "other"   // overflow bucket once MaxHostLabels is reached
+	UnknownHost = "unknown" // hosts that resolved to no site
+)
+
+var (
+	TenantHostLoadTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_host_load_total",
+			Help: "Tenants successfully loaded, by host.",
+		}, []string{"host"})
+
+	TenantHostLoadErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_host_load_errors_total",
+			Help: "Tenant load errors, by host.",
+		}, []string{"host"})
+
+	TenantHostEvictTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_host_evict_total",
+			Help: "Tenants evicted from the cache, by host.",
+		}, []string{"host"})
+
+	TenantHostActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "tenant_host_active",
+			Help: "Tenants currently loaded in memory, by host (1 or 0; other sums).",
+		}, []string{"host"})
+
+	// TenantLoadDuration times every cold load, failed ones included, from
+	// a warm DB round-trip (a few ms) to a slow Vault or theme parse.
+	TenantLoadDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "tenant_load_duration_seconds",
+			Help:    "Time spent building a tenant on a cache miss.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		})
+
+	// TenantLoadFlights splits cache misses into the caller that ran the
+	// load (result="load") and callers that waited on it
+	// (result="coalesced").  A high coalesced rate is a stampede the
+	// single-flight absorbed.
+	TenantLoadFlights = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_load_singleflight_total",
+			Help: "Tenant cache misses by single-flight role (load or coalesced).",
+		}, []string{"result"})
+)
+
+// Single-flight roles for TenantLoadFlights.
+const (
+	FlightLoad      = "load"
+	FlightCoalesced = "coalesced"
+)
+
+var (
+	hostMu   sync.Mutex
+	hostSeen = make(map[string]struct{})
+)
+
+func init() {
+	prometheus.MustRegister(
+		TenantHostLoadTotal,
+		TenantHostLoadErrorsTotal,
+		TenantHostEvictTotal,
+		TenantHostActive,
+		TenantLoadDuration,
+		TenantLoadFlights,
+	)
+}
+
+// HostLabel returns the label value for host: host itself while fewer than
+// MaxHostLabels hosts have been admitted, OtherHost after that.
+func HostLabel(host string) string {
+	hostMu.Lock()
+	defer hostMu.Unlock()
+	if _, ok := hostSeen[host]; ok {
+		return host
+	}
+	if len(hostSeen) >= MaxHostLabels {
+		return OtherHost
+	}
+	hostSeen[host] = struct{}{}
+	return host
+}
+
+// TenantLoaded records a successful load of host.
+func TenantLoaded(host string) {
+	l := HostLabel(host)
+	TenantLoadTotal.Inc()
+	TenantHostLoadTotal.WithLabelValues(l).Inc()
+	ActiveTenants.Inc()
+	TenantHostActive.WithLabelValues(l).Inc()
+}
+
+// TenantLoadFailed records a load error for host.  Pass UnknownHost when
+// the request never resolved to a site, so it never takes a label slot.
+func TenantLoadFailed(host string) {
+	l := UnknownHost
+	if host != UnknownHost {
+		l = HostLabel(host)
+	}
+	TenantLoadErrorsTotal.Inc()
+	TenantHostLoadErrorsTotal.WithLabelValues(l).Inc()
+}
+
+// TenantEvicted records an idle or LRU eviction of host.
+func TenantEvicted(host string) {
+	TenantEvictTotal.Inc()
+	TenantHostEvictTotal.WithLabelValues(HostLabel(host)).Inc()
+	TenantUnloaded(host)
+}
+
+// TenantUnloaded records host leaving memory for any reason.
+func TenantUnloaded(host string) {
+	ActiveTenants.Dec()
+	TenantHostActive.WithLabelValues(HostLabel(host)).Dec()
+}
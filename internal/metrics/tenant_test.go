@@ -0,0 +1,63 @@
+// internal/metrics/tenant_test.go
+//
+// Unit-tests for the host label guard and the Tenant* helpers.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// resetHosts empties the admitted set and caps it at max for one test.
+func resetHosts(t *testing.T, max int) {
+	old := MaxHostLabels
+	hostMu.Lock()
+	hostSeen = make(map[string]struct{})
+	hostMu.Unlock()
+	MaxHostLabels = max
+	t.Cleanup(func() { MaxHostLabels = old })
+}
+
+func TestHostLabel_OverflowsToOther(t *testing.T) {
+	resetHosts(t, 2)
+	for _, h := range []string{"a.example", "b.example", "a.example"} {
+		if got := HostLabel(h); got != h {
+			t.Fatalf("HostLabel(%q) = %q", h, got)
+		}
+	}
+	if got := HostLabel("c.example"); got != OtherHost {
+		t.Fatalf("HostLabel past cap = %q, want %q", got, OtherHost)
+	}
+}
+
+func TestTenantHelpers_LabelHost(t *testing.T) {
+	resetHosts(t, 10)
+	load := testutil.ToFloat64(TenantHostLoadTotal.WithLabelValues("m.example"))
+	evict := testutil.ToFloat64(TenantHostEvictTotal.WithLabelValues("m.example"))
+
+	TenantLoaded("m.example")
+	if got := testutil.ToFloat64(TenantHostLoadTotal.WithLabelValues("m.example")); got != load+1 {
+		t.Fatalf("load counter = %v, want %v", got, load+1)
+	}
+	if got := testutil.ToFloat64(TenantHostActive.WithLabelValues("m.example")); got != 1 {
+		t.Fatalf("active gauge = %v, want 1", got)
+	}
+
+	TenantEvicted("m.example")
+	if got := testutil.ToFloat64(TenantHostEvictTotal.WithLabelValues("m.example")); got != evict+1 {
+		t.Fatalf("evict counter = %v, want %v", got, evict+1)
+	}
+	if got := testutil.ToFloat64(TenantHostActive.WithLabelValues("m.example")); got != 0 {
+		t.Fatalf("active gauge after evict = %v, want 0", got)
+	}
+}
+
+func TestTenantLoadFailed_UnknownTakesNoSlot(t *testing.T) {
+	resetHosts(t, 1)
+	TenantLoadFailed(UnknownHost)
+	if got := HostLabel("real.example"); got != "real.example" {
+		t.Fatalf("unknown host consumed a label slot: %q", got)
+	}
+}
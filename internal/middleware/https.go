@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -20,11 +21,19 @@ func ForceHTTPS(cache *tenant.Cache, h http.Handler) http.Handler {
 			return
 		}
 
-		// Only redirect if the host exists in the site table.
-		if _, err := cache.Get(stripPort(r.Host)); err == nil {
+		// Only redirect if the host exists in the site table.  A redirect
+		// alias goes straight to its canonical host, saving a hop.
+		_, err := cache.Get(stripPort(r.Host))
+		var alias *tenant.Redirect
+		switch {
+		case err == nil:
 			target := "https://" + r.Host + r.URL.RequestURI()
 			http.Redirect(w, r, target, http.StatusPermanentRedirect)
 			return
+		case errors.As(err, &alias):
+			target := "https://" + alias.Host + r.URL.RequestURI()
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
 		}
 
 		// Unknown host → keep normal flow (likely 404 later).
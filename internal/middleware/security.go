@@ -5,58 +5,190 @@
 // Injects industry-standard headers on every response:
 //
 //   • Strict-Transport-Security  –  forces HTTPS (2 years + preload)
-//   • Content-Security-Policy   –  sane default self-only policy
+//   • Content-Security-Policy   –  self-only policy plus a per-request
+//                                  script and style nonce (see
+//                                  head.WithNonce)
 //   • X-Frame-Options           –  click-jacking defence
 //   • X-Content-Type-Options    –  MIME-sniffing defence
 //   • Referrer-Policy           –  drops path/query from Referer
 //   • Permissions-Policy        –  disables powerful features by default
 //
+// Per-tenant overrides (site_config)
+// ----------------------------------
+//   - security.csp                 full CSP replacing the default; the
+//                                  request nonce is still added to
+//                                  script-src, and to style-src unless
+//                                  styles allow 'unsafe-inline'
+//   - security.frame_ancestors     frame-ancestors sources, e.g.
+//                                  "'self' https://maps.example"; anything
+//                                  other than 'none' drops X-Frame-Options
+//   - security.permissions_policy  full Permissions-Policy value
+//
+// Unset keys fall back to the secure defaults.  The tenant is read from the
+// request context, so Security must run after tenant.WithContext.
+//
 // Notes
 // -----
-// • Headers are added *after* next.ServeHTTP so handlers may set Content-Type
-//   first; the middleware never overwrites an existing value.
+// • Headers are set *before* next.ServeHTTP; once a handler writes the body
+//   they can no longer change.  A header already present (set by an outer
+//   wrapper) is never overwritten, and handlers may still replace any of
+//   them with w.Header().Set.
+// • The nonce rides on the request context, so the CSP header and every
+//   <script nonce> and <style nonce> in the body carry the same value.
+// • Browsers ignore 'unsafe-inline' once a nonce is listed, so a tenant
+//   policy whose style-src (or default-src) allows inline styles gets no
+//   style nonce; its style="" attributes keep working.
 // • If Adept is running behind a TLS-terminating proxy, HSTS is still useful
 //   because browsers see the tenant’s domain as HTTPS.
 // • Oxford commas, two spaces after periods.
 
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/head"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+const (
+	defaultCSP = "default-src 'self'; img-src 'self' data:; object-src 'none'; " +
+		"base-uri 'self'; frame-ancestors 'none'"
+	defaultPerm = "geolocation=(), microphone=(), camera=()"
+)
+
+func init() {
+	component.DeclareConfig(
+		component.ConfigKey{Name: "security.csp", Default: defaultCSP},
+		component.ConfigKey{Name: "security.frame_ancestors", Default: "'none'"},
+		component.ConfigKey{Name: "security.permissions_policy", Default: defaultPerm},
+	)
+}
 
 // Security sets security headers for every response.
 func Security(next http.Handler) http.Handler {
 	const (
-		hsts = "max-age=63072000; includeSubDomains; preload"
-		csp  = "default-src 'self'; img-src 'self' data:; object-src 'none'; " +
-			"base-uri 'self'; frame-ancestors 'none'"
+		hsts  = "max-age=63072000; includeSubDomains; preload"
 		xfo   = "DENY"
 		nosn  = "nosniff"
 		refer = "strict-origin-when-cross-origin"
-		perm  = "geolocation=(), microphone=(), camera=()"
 	)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		next.ServeHTTP(w, r)
-
-		add := w.Header().Add // shorthand
+		csp, perm := defaultCSP, defaultPerm
+		frame := ""
+		if t := tenant.FromContext(r.Context()); t != nil {
+			csp = t.Config.String("security.csp", csp)
+			perm = t.Config.String("security.permissions_policy", perm)
+			frame = t.Config.String("security.frame_ancestors", "")
+		}
 
-		if w.Header().Get("Strict-Transport-Security") == "" {
-			add("Strict-Transport-Security", hsts)
+		p := parseCSP(csp)
+		if frame != "" {
+			p.set("frame-ancestors", frame)
 		}
-		if w.Header().Get("Content-Security-Policy") == "" {
-			add("Content-Security-Policy", csp)
+		if nonce, err := head.NewNonce(); err == nil {
+			p.addSource("script-src", "'nonce-"+nonce+"'")
+			if !p.allows("style-src", "'unsafe-inline'") {
+				p.addSource("style-src", "'nonce-"+nonce+"'")
+			}
+			r = r.WithContext(head.WithNonce(r.Context(), nonce))
+		} else {
+			zap.L().Error("csp nonce", zap.Error(err))
 		}
-		if w.Header().Get("X-Frame-Options") == "" {
-			add("X-Frame-Options", xfo)
+
+		h := w.Header()
+		setIfAbsent(h, "Strict-Transport-Security", hsts)
+		setIfAbsent(h, "Content-Security-Policy", p.String())
+		if fa := p.get("frame-ancestors"); fa == "" || fa == "'none'" {
+			setIfAbsent(h, "X-Frame-Options", xfo)
 		}
-		if w.Header().Get("X-Content-Type-Options") == "" {
-			add("X-Content-Type-Options", nosn)
+		setIfAbsent(h, "X-Content-Type-Options", nosn)
+		setIfAbsent(h, "Referrer-Policy", refer)
+		setIfAbsent(h, "Permissions-Policy", perm)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setIfAbsent(h http.Header, k, v string) {
+	if h.Get(k) == "" {
+		h.Set(k, v)
+	}
+}
+
+/*──────────────────────────── CSP helpers ───────────────────────────────*/
+
+// csp is an ordered list of directives, each "name value…".
+type csp [][2]string
+
+func parseCSP(s string) csp {
+	var p csp
+	for _, d := range strings.Split(s, ";") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
 		}
-		if w.Header().Get("Referrer-Policy") == "" {
-			add("Referrer-Policy", refer)
+		name, val, _ := strings.Cut(d, " ")
+		p = append(p, [2]string{strings.ToLower(name), strings.TrimSpace(val)})
+	}
+	return p
+}
+
+func (p csp) get(name string) string {
+	for _, d := range p {
+		if d[0] == name {
+			return d[1]
+		}
+	}
+	return ""
+}
+
+// set replaces directive name, appending it when missing.
+func (p *csp) set(name, val string) {
+	for i := range *p {
+		if (*p)[i][0] == name {
+			(*p)[i][1] = val
+			return
 		}
-		if w.Header().Get("Permissions-Policy") == "" {
-			add("Permissions-Policy", perm)
+	}
+	*p = append(*p, [2]string{name, val})
+}
+
+// allows reports whether the sources in effect for name, falling back to
+// default-src when name is absent, include src.
+func (p csp) allows(name, src string) bool {
+	v := p.get(name)
+	if v == "" {
+		v = p.get("default-src")
+	}
+	for _, s := range strings.Fields(v) {
+		if strings.EqualFold(s, src) {
+			return true
 		}
-	})
+	}
+	return false
+}
+
+// addSource appends src to directive name.  A missing script-src or
+// style-src starts from 'self' so adding a nonce never loosens the
+// default-src fallback.
+func (p *csp) addSource(name, src string) {
+	if v := p.get(name); v != "" {
+		p.set(name, v+" "+src)
+		return
+	}
+	p.set(name, "'self' "+src)
+}
+
+func (p csp) String() string {
+	parts := make([]string, len(p))
+	for i, d := range p {
+		parts[i] = strings.TrimSpace(d[0] + " " + d[1])
+	}
+	return strings.Join(parts, "; ")
 }
@@ -0,0 +1,171 @@
+// internal/middleware/security_test.go
+//
+// Unit-tests for the per-request CSP nonce and per-tenant header overrides.
+//
+// Context
+// -------
+// The handler builds a tenant.Context exactly as components do and stamps
+// .Head.Nonce onto an inline script.  The test then checks that the CSP
+// header names that very nonce, and that two requests never share one.
+// Override tests place a tenant with security.* site_config on the context
+// and compare the emitted headers with the defaults.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+var nonceAttr = regexp.MustCompile(`<script nonce="([^"]+)">`)
+
+func serveInline(t *testing.T) (csp, nonce string) {
+	t.Helper()
+	h := Security(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vctx := tenant.NewContext(r)
+		_, _ = w.Write([]byte(`<script nonce="` + vctx.Head.Nonce() + `">boot()</script>`))
+	}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	m := nonceAttr.FindStringSubmatch(rr.Body.String())
+	if m == nil || m[1] == "" {
+		t.Fatalf("no nonce in body: %s", rr.Body.String())
+	}
+	return rr.Header().Get("Content-Security-Policy"), m[1]
+}
+
+func TestSecurity_CSPNonceMatchesBody(t *testing.T) {
+	csp, nonce := serveInline(t)
+	if !strings.Contains(csp, "script-src 'self' 'nonce-"+nonce+"'") {
+		t.Fatalf("CSP %q does not carry body nonce %q", csp, nonce)
+	}
+}
+
+func TestSecurity_StyleSrcCarriesNonce(t *testing.T) {
+	csp, nonce := serveInline(t)
+	if !strings.Contains(csp, "style-src 'self' 'nonce-"+nonce+"'") {
+		t.Fatalf("CSP %q lacks style-src nonce %q", csp, nonce)
+	}
+}
+
+func TestSecurity_NonceIsPerRequest(t *testing.T) {
+	_, a := serveInline(t)
+	_, b := serveInline(t)
+	if a == b {
+		t.Fatalf("nonce reused across requests: %s", a)
+	}
+}
+
+func headersFor(cfg tenant.SiteConfig, preset http.Header) http.Header {
+	h := Security(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cfg != nil {
+		req = req.WithContext(tenant.WithContext(context.Background(), &tenant.Tenant{Config: cfg}))
+	}
+	rr := httptest.NewRecorder()
+	for k, v := range preset {
+		rr.Header()[k] = v
+	}
+	h.ServeHTTP(rr, req)
+	return rr.Header()
+}
+
+func TestSecurity_DefaultPolicies(t *testing.T) {
+	for name, cfg := range map[string]tenant.SiteConfig{
+		"no tenant":    nil,
+		"empty config": {},
+	} {
+		h := headersFor(cfg, nil)
+		csp := h.Get("Content-Security-Policy")
+		if !strings.HasPrefix(csp, defaultCSP+"; script-src 'self' 'nonce-") {
+			t.Errorf("%s: CSP = %q", name, csp)
+		}
+		if h.Get("X-Frame-Options") != "DENY" {
+			t.Errorf("%s: X-Frame-Options = %q", name, h.Get("X-Frame-Options"))
+		}
+		if h.Get("Permissions-Policy") != defaultPerm {
+			t.Errorf("%s: Permissions-Policy = %q", name, h.Get("Permissions-Policy"))
+		}
+	}
+}
+
+func TestSecurity_TenantOverrides(t *testing.T) {
+	h := headersFor(tenant.SiteConfig{
+		"security.csp":                "default-src 'self' https://cdn.example; script-src 'self' https://js.example",
+		"security.frame_ancestors":    "'self' https://partner.example",
+		"security.permissions_policy": "geolocation=(self)",
+	}, nil)
+
+	csp := h.Get("Content-Security-Policy")
+	for _, want := range []string{
+		"default-src 'self' https://cdn.example",
+		"script-src 'self' https://js.example 'nonce-",
+		"frame-ancestors 'self' https://partner.example",
+	} {
+		if !strings.Contains(csp, want) {
+			t.Errorf("CSP %q missing %q", csp, want)
+		}
+	}
+	if xfo := h.Get("X-Frame-Options"); xfo != "" {
+		t.Errorf("X-Frame-Options = %q; framing was allowed by frame_ancestors", xfo)
+	}
+	if pp := h.Get("Permissions-Policy"); pp != "geolocation=(self)" {
+		t.Errorf("Permissions-Policy = %q", pp)
+	}
+}
+
+func TestSecurity_UnsafeInlineStylesGetNoNonce(t *testing.T) {
+	for name, policy := range map[string]string{
+		"style-src":   "default-src 'self'; style-src 'self' 'unsafe-inline'",
+		"default-src": "default-src 'self' 'unsafe-inline'",
+	} {
+		csp := headersFor(tenant.SiteConfig{"security.csp": policy}, nil).Get("Content-Security-Policy")
+		style := ""
+		for _, d := range parseCSP(csp) {
+			if d[0] == "style-src" {
+				style = d[1]
+			}
+		}
+		if strings.Contains(style, "'nonce-") {
+			t.Errorf("%s: CSP %q adds a style nonce, which disables 'unsafe-inline'", name, csp)
+		}
+		if !strings.Contains(csp, "script-src 'self' 'nonce-") {
+			t.Errorf("%s: CSP %q lost the script nonce", name, csp)
+		}
+	}
+}
+
+func TestSecurity_FrameAncestorsNoneKeepsXFO(t *testing.T) {
+	h := headersFor(tenant.SiteConfig{"security.frame_ancestors": "'none'"}, nil)
+	if h.Get("X-Frame-Options") != "DENY" {
+		t.Fatalf("X-Frame-Options = %q", h.Get("X-Frame-Options"))
+	}
+}
+
+func TestSecurity_NeverOverwritesExistingHeader(t *testing.T) {
+	preset := http.Header{}
+	preset.Set("Content-Security-Policy", "default-src *")
+	preset.Set("Referrer-Policy", "no-referrer")
+
+	h := headersFor(tenant.SiteConfig{"security.csp": "default-src 'none'"}, preset)
+	if got := h.Get("Content-Security-Policy"); got != "default-src *" {
+		t.Errorf("CSP overwritten: %q", got)
+	}
+	if got := h.Get("Referrer-Policy"); got != "no-referrer" {
+		t.Errorf("Referrer-Policy overwritten: %q", got)
+	}
+	if h.Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("unset headers should still get defaults")
+	}
+}
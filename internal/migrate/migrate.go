@@ -0,0 +1,182 @@
+// internal/migrate/migrate.go
+//
+// Per-tenant Component migrations.
+//
+// Context
+// -------
+// component.Component declares Migrations() []string: an append-only list of
+// SQL statements that build the Component's tables in the tenant schema.
+// Run applies the ones a tenant has not seen yet and records each in
+// `schema_migrations`, so Components can own their schema instead of
+// relying on the install scripts.
+//
+// Workflow
+// --------
+//  1. Pin one connection and take a MySQL advisory lock named after the
+//     current schema (GET_LOCK), so concurrent cold-loads of the same tenant
+//     in several processes migrate it once.  The others wait, then find
+//     nothing pending.
+//  2. Create `schema_migrations` when missing and read what is applied.
+//  3. Walk Components by name and, for each pending statement in list
+//     order, run it and insert its row in one transaction.
+//  4. Release the lock, also on error.
+//
+// Versions
+// --------
+// A migration's version is its 1-based position in Migrations().  Never
+// reorder or edit a shipped entry; append a new one instead.  Each applied
+// row stores a SHA-256 of its statement, and an edited entry is logged at
+// WARN rather than re-run.
+//
+// Notes
+// -----
+// • MySQL commits DDL implicitly, so a failed CREATE/ALTER cannot be rolled
+//   back; the transaction still keeps DML migrations atomic with their
+//   version row.  Write DDL idempotently (IF NOT EXISTS) so a retry after a
+//   crash between statement and record is harmless.
+// • Oxford commas, two spaces after periods.
+
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+// LockTimeout bounds the wait for another process's migration run.
+var LockTimeout = 30 * time.Second
+
+// ErrLocked is returned when the advisory lock is not granted in time.
+var ErrLocked = errors.New("migrate: lock timeout")
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    component   VARCHAR(64)  NOT NULL,
+    version     INT          NOT NULL,
+    checksum    CHAR(64)     NOT NULL,
+    applied_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (component, version)
+)`
+
+// lockName is scoped to the schema: GET_LOCK names are server-wide and
+// several tenants may share one MySQL server.
+const lockName = `CONCAT('adept_migrate.', DATABASE())`
+
+type key struct {
+	component string
+	version   int
+}
+
+// Run applies every pending migration of comps to db and returns how many
+// it applied.  It is idempotent and safe to call from several processes.
+func Run(ctx context.Context, db *sqlx.DB, comps []component.Component, log *zap.SugaredLogger) (int, error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// 1. advisory lock on this connection
+	var got sql.NullInt64
+	err = conn.QueryRowxContext(ctx,
+		`SELECT GET_LOCK(`+lockName+`, ?)`, int(LockTimeout.Seconds())).Scan(&got)
+	if err != nil {
+		return 0, fmt.Errorf("migrate: lock: %w", err)
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return 0, ErrLocked
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(`+lockName+`)`)
+	}()
+
+	// 2. bookkeeping table and applied set
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return 0, fmt.Errorf("migrate: create schema_migrations: %w", err)
+	}
+	applied, err := appliedSet(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	// 3. pending statements, Components in name order
+	sorted := append([]component.Component(nil), comps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	var n int
+	for _, c := range sorted {
+		for i, stmt := range c.Migrations() {
+			k := key{c.Name(), i + 1}
+			sum := checksum(stmt)
+			if prev, ok := applied[k]; ok {
+				if prev != sum {
+					log.Warnw("migration changed after it was applied – not re-run",
+						"component", k.component, "version", k.version)
+				}
+				continue
+			}
+			if err := apply(ctx, conn, k, stmt, sum); err != nil {
+				return n, fmt.Errorf("migrate %s #%d: %w", k.component, k.version, err)
+			}
+			log.Infow("migration applied", "component", k.component, "version", k.version)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// appliedSet reads schema_migrations into a (component, version) → checksum
+// map.
+func appliedSet(ctx context.Context, conn *sqlx.Conn) (map[key]string, error) {
+	rows, err := conn.QueryxContext(ctx,
+		`SELECT component, version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[key]string)
+	for rows.Next() {
+		var k key
+		var sum string
+		if err := rows.Scan(&k.component, &k.version, &sum); err != nil {
+			return nil, err
+		}
+		out[k] = sum
+	}
+	return out, rows.Err()
+}
+
+// apply runs stmt and records it in one transaction.
+func apply(ctx context.Context, conn *sqlx.Conn, k key, stmt, sum string) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (component, version, checksum) VALUES (?, ?, ?)`,
+		k.component, k.version, sum); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func checksum(stmt string) string {
+	h := sha256.Sum256([]byte(stmt))
+	return hex.EncodeToString(h[:])
+}
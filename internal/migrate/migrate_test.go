@@ -0,0 +1,136 @@
+// internal/migrate/migrate_test.go
+//
+// Unit-tests for the migration runner against sqlmock.
+//
+// Notes
+// -----
+// • fakeComp is a minimal component.Component with a fixed migration list.
+// • Oxford commas, two spaces after periods.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+type fakeComp struct {
+	name  string
+	stmts []string
+}
+
+func (f fakeComp) Name() string                    { return f.name }
+func (f fakeComp) Routes() chi.Router              { return chi.NewRouter() }
+func (f fakeComp) Migrations() []string            { return f.stmts }
+func (f fakeComp) Init(component.TenantInfo) error { return nil }
+
+func mockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return sqlx.NewDb(db, "mysql"), mock
+}
+
+func expectLock(mock sqlmock.Sqlmock, granted int) {
+	mock.ExpectQuery(`SELECT GET_LOCK`).WithArgs(int(LockTimeout.Seconds())).
+		WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(granted))
+}
+
+func expectApply(mock sqlmock.Sqlmock, stmt, comp string, version int) {
+	mock.ExpectBegin()
+	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).
+		WithArgs(comp, version, checksum(stmtText[stmt])).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+}
+
+// stmtText maps the regexps used in expectations to the statements.
+var stmtText = map[string]string{
+	`CREATE TABLE a1`: "CREATE TABLE a1 (id INT)",
+	`CREATE TABLE a2`: "CREATE TABLE a2 (id INT)",
+	`CREATE TABLE b1`: "CREATE TABLE b1 (id INT)",
+}
+
+func TestRun_AppliesPendingInOrder(t *testing.T) {
+	db, mock := mockDB(t)
+	comps := []component.Component{
+		fakeComp{"beta", []string{stmtText[`CREATE TABLE b1`]}},
+		fakeComp{"alpha", []string{stmtText[`CREATE TABLE a1`], stmtText[`CREATE TABLE a2`]}},
+	}
+
+	expectLock(mock, 1)
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT component, version, checksum FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"component", "version", "checksum"}).
+			AddRow("alpha", 1, checksum(stmtText[`CREATE TABLE a1`])))
+	expectApply(mock, `CREATE TABLE a2`, "alpha", 2)
+	expectApply(mock, `CREATE TABLE b1`, "beta", 1)
+	mock.ExpectExec(`SELECT RELEASE_LOCK`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	n, err := Run(context.Background(), db, comps, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("applied = %d, want 2", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_FailureRollsBackAndStops(t *testing.T) {
+	db, mock := mockDB(t)
+	comps := []component.Component{
+		fakeComp{"alpha", []string{stmtText[`CREATE TABLE a1`], stmtText[`CREATE TABLE a2`]}},
+	}
+	boom := errors.New("boom")
+
+	expectLock(mock, 1)
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT component, version, checksum`).
+		WillReturnRows(sqlmock.NewRows([]string{"component", "version", "checksum"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE a1`).WillReturnError(boom)
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT RELEASE_LOCK`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	n, err := Run(context.Background(), db, comps, zap.NewNop().Sugar())
+	if !errors.Is(err, boom) || n != 0 {
+		t.Fatalf("Run = %d, %v; want 0, boom", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_LockTimeout(t *testing.T) {
+	db, mock := mockDB(t)
+	defer func(d time.Duration) { LockTimeout = d }(LockTimeout)
+	LockTimeout = time.Second
+
+	expectLock(mock, 0)
+
+	_, err := Run(context.Background(), db, nil, zap.NewNop().Sugar())
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("err = %v, want ErrLocked", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -27,7 +27,8 @@
 // -----
 //   - All look‑ups are read‑only and pool‑based, so the middleware is safe
 //     under heavy concurrency.
-//   - UA parse ≈ 40 ns (uasurfer), Geo lookup ≈ 50 µs (cached).
+//   - UA parse ≈ 60 ns when memoised (≈ 1.5 µs on a miss), Geo lookup
+//     ≈ 50 µs (cached).
 package requestinfo
 
 import (
@@ -15,10 +15,77 @@
 //   1. Tenant cold-loads an AliasCache via routing.NewAliasCache().
 //   2. tenant.Router() inserts routing.Middleware(t) high in the chain.
 //   3. Each request looks up r.URL.Path in the in-memory map.
-//      • On hit  → rewrite and continue.
-//      • On miss → one-shot SQL lookup; if found, store + rewrite.
+//      • On hit  → rewrite and continue, or redirect (see Kinds).
+//      • On miss → one-shot SQL lookup, exact then pattern; if found,
+//        store + act on it, else remember the miss (see Bounds).
 //   4. The cache is refreshed when its TTL expires **or** when
-//      site.route_version increments.
+//      site.route_version increments.  The tenant cache keeps the live
+//      Tenant's route_version current (on-hit recheck and the site poller),
+//      so an alias edit plus a version bump reaches running processes
+//      without eviction.
+//
+// Reverse lookup
+// --------------
+// Load also builds target→alias for exact rewrite aliases, so links can be
+// printed in their public form (PublicPath, reverse.go).  When several
+// aliases share a target, the row with canonical = 1 wins, then the
+// shortest alias, then the first in byte order.  Redirect and pattern
+// aliases are never reversed.
+//
+// Bounds
+// -------
+// Load's map mirrors route_alias and is the source of truth.  Everything
+// learned between loads is bounded:
+//
+//   - SQL-fallback hits go to an LRU of DefaultAliasFallbackEntries, not
+//     into the loaded map.
+//   - Paths that SQL did not find (scanner noise such as /wp-login.php) go
+//     to a negative LRU of DefaultAliasMissEntries for the negative TTL,
+//     so repeats cost a map lookup instead of two queries.  A fallback
+//     error is never remembered.
+//   - Every successful Load empties both, so a deleted alias cannot
+//     outlive a reload and a newly added one is seen at once.
+//
+// metrics.Alias* count hits, misses, negative hits, SQL fallbacks, and
+// reloads.
+//
+// Patterns
+// --------
+// alias_path may also be a wildcard ("/blog/*") or carry {params}
+// ("/docs/{slug}"); see aliaspattern.go for syntax and precedence.  Exact
+// aliases always win and stay a single map lookup.
+//
+// Kinds
+// -----
+// route_alias.kind decides what a hit does:
+//
+//   - rewrite             → the request continues at target_path (the
+//                           default).  A target may carry a query, as in
+//                           "/content/page/view?id=42"; it is merged with
+//                           the request's, and the request wins on a key
+//                           both set.
+//   - redirect_permanent  → 301 to target_path, chain stops.
+//   - redirect_temporary  → 302 to target_path, chain stops.
+//
+// Redirects keep the request's query string, appended after any query the
+// target already has.  A redirect whose target is itself a redirect alias
+// is collapsed to the final hop, so clients see one redirect; a cycle (or
+// more than maxRedirectHops) answers 508 and logs an ERROR instead of
+// bouncing the browser forever.  A redirect onto a rewrite alias needs no
+// collapsing: the next request is rewritten as usual.
+//
+// Notes
+// -----
+// • Load reads route_alias without a site filter: the table lives in the
+//   tenant's own database.
+// • A failed reload keeps serving the last loaded map; stale aliases beat
+//   404s.
+// • An unknown kind is treated as rewrite and logged at WARN.  A tenant
+//   database from before kinds, with no kind column at all, reads every
+//   row as a rewrite (aliascols.go).
+// • In ALIAS-only mode a miss on a path reserved by the framework
+//   (reserved.go, e.g. /sitemap.xml) reaches the router instead of 404.
+// • Oxford commas, two spaces after periods.
 //
 
 package routing
@@ -27,80 +94,401 @@ import (
 	"context"
 	"database/sql"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
+
+	lru "github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/metrics"
 )
 
+//
+// Alias kinds
+//
+
+// AliasKind is route_alias.kind.
+type AliasKind string
+
+const (
+	KindRewrite           AliasKind = "rewrite"
+	KindRedirectPermanent AliasKind = "redirect_permanent"
+	KindRedirectTemporary AliasKind = "redirect_temporary"
+)
+
+// maxRedirectHops bounds how far a redirect chain is collapsed.
+const maxRedirectHops = 8
+
+// Bounds on what the cache learns between loads (see Bounds).
+const (
+	DefaultAliasFallbackEntries = 1024
+	DefaultAliasMissEntries     = 4096
+	DefaultAliasNegativeTTL     = time.Minute
+)
+
+// parseKind maps a column value to an AliasKind; "" and unknown values are
+// rewrites.
+func parseKind(alias, s string) AliasKind {
+	switch k := AliasKind(s); k {
+	case KindRewrite, KindRedirectPermanent, KindRedirectTemporary:
+		return k
+	case "":
+		return KindRewrite
+	}
+	zap.L().Warn("unknown route_alias kind – treated as rewrite",
+		zap.String("alias", alias), zap.String("kind", s))
+	return KindRewrite
+}
+
+// status is the redirect code for k, or 0 for a rewrite.
+func (k AliasKind) status() int {
+	switch k {
+	case KindRedirectPermanent:
+		return http.StatusMovedPermanently
+	case KindRedirectTemporary:
+		return http.StatusFound
+	}
+	return 0
+}
+
+// Alias is one route_alias row.
+type Alias struct {
+	Target string
+	Kind   AliasKind
+}
+
 //
 // AliasCache
 //
 
-// AliasCache stores alias→target pairs plus TTL and route-version state.
+// AliasCache stores alias→Alias pairs plus TTL and route-version state.
+// Exact aliases live in data; wildcard and {param} aliases in patterns
+// (aliaspattern.go).  SQL-fallback results live in the bounded fallback
+// and misses LRUs, guarded by lmu so map hits never wait on them.
 type AliasCache struct {
 	mu       sync.RWMutex
-	data     map[string]string
+	data     map[string]Alias
+	reverse  map[string]string // target → preferred alias (reverse.go)
+	patterns []aliasPattern    // sorted by precedence
 	loadedAt time.Time
 	ttl      time.Duration
 	version  int
+	loads    int // successful Loads, for Stats
 	db       *sql.DB
+
+	lmu      sync.Mutex
+	fallback *lru.LRU // path → Alias found by SQL fallback
+	misses   *lru.LRU // path → time.Time the miss expires
+	negTTL   time.Duration
+	gen      int // bumped by Load; stale fallbacks are dropped
+
+	cmu  sync.Mutex
+	cols aliasCols // optional columns known to exist (aliascols.go)
+}
+
+// AliasStats is a point-in-time view of an AliasCache.
+type AliasStats struct {
+	Version  int       // route_version the map was loaded for
+	LoadedAt time.Time // last successful Load; zero before the first
+	Loads    int       // successful Loads so far
+	Entries  int       // loaded aliases and patterns
+	Fallback int       // SQL-fallback hits held until the next Load
+	Misses   int       // remembered misses, expired ones included
+}
+
+// Stats reports the cache's version, last load, and size.
+func (c *AliasCache) Stats() AliasStats {
+	c.mu.RLock()
+	st := AliasStats{Version: c.version, LoadedAt: c.loadedAt, Loads: c.loads,
+		Entries: len(c.data) + len(c.patterns)}
+	c.mu.RUnlock()
+	c.lmu.Lock()
+	st.Fallback, st.Misses = c.fallback.Len(), c.misses.Len()
+	c.lmu.Unlock()
+	return st
 }
 
 // NewAliasCache returns an empty cache with the given TTL.
 func NewAliasCache(db *sql.DB, ttl time.Duration) *AliasCache {
 	return &AliasCache{
-		data: make(map[string]string),
-		db:   db,
-		ttl:  ttl,
+		data:     make(map[string]Alias),
+		reverse:  make(map[string]string),
+		db:       db,
+		ttl:      ttl,
+		fallback: lru.New(DefaultAliasFallbackEntries),
+		misses:   lru.New(DefaultAliasMissEntries),
+		negTTL:   DefaultAliasNegativeTTL,
+		cols:     allAliasCols,
 	}
 }
 
-// Load refreshes the entire map from route_alias.
+// SetNegativeTTL sets how long a path SQL did not find is answered from
+// memory.  d ≤ 0 turns the negative cache off.
+func (c *AliasCache) SetNegativeTTL(d time.Duration) {
+	c.lmu.Lock()
+	c.negTTL = d
+	c.lmu.Unlock()
+}
+
+// Load refreshes the entire map from route_alias and forgets everything
+// the SQL fallback learned since the last load.
 func (c *AliasCache) Load(ctx context.Context) error {
-	rows, err := c.db.QueryContext(ctx,
-		`SELECT alias_path, target_path FROM route_alias`)
+	if err := c.load(ctx); err != nil {
+		metrics.AliasReloadErrorsTotal.Inc()
+		return err
+	}
+	metrics.AliasReloadsTotal.Inc()
+	return nil
+}
+
+func (c *AliasCache) load(ctx context.Context) error {
+	var rows *sql.Rows
+	err := c.withAliasCols(allAliasCols, func(cols aliasCols) (err error) {
+		rows, err = c.db.QueryContext(ctx,
+			`SELECT alias_path, target_path, `+cols.kind+`, `+cols.canonical+` FROM route_alias`)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 
-	fresh := make(map[string]string)
+	fresh := make(map[string]Alias)
+	rev := reverseBuilder{}
+	var patterns []aliasPattern
 	for rows.Next() {
-		var alias, target string
-		if err := rows.Scan(&alias, &target); err != nil {
+		var alias, target, kind string
+		var canonical bool
+		if err := rows.Scan(&alias, &target, &kind, &canonical); err != nil {
 			return err
 		}
-		fresh[alias] = target
+		a := Alias{Target: target, Kind: parseKind(alias, kind)}
+		if !isPattern(alias) {
+			fresh[alias] = a
+			if a.Kind == KindRewrite {
+				rev.add(target, alias, canonical)
+			}
+			continue
+		}
+		if p, ok := compileRow(alias, a); ok {
+			patterns = append(patterns, p)
+		}
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
+	sortPatterns(patterns)
 
 	c.mu.Lock()
 	c.data = fresh
+	c.reverse = rev.build()
+	c.patterns = patterns
 	c.loadedAt = time.Now()
+	c.loads++
 	c.mu.Unlock()
 
+	c.lmu.Lock()
+	c.fallback = lru.New(DefaultAliasFallbackEntries)
+	c.misses = lru.New(DefaultAliasMissEntries)
+	c.gen++
+	c.lmu.Unlock()
+
 	zap.L().Debug("alias cache loaded",
-		zap.Int("count", len(fresh)))
+		zap.Int("count", len(fresh)),
+		zap.Int("patterns", len(patterns)))
 	return nil
 }
 
-// lookup returns (target,true) on cache hit and not-stale; otherwise ("",false).
-func (c *AliasCache) lookup(path string) (string, bool) {
+// lookup returns (alias,true) on a cache hit: a loaded exact alias, then a
+// fallback hit, then the first matching pattern.  Staleness is handled by
+// the refresh before it; when that fails the last map still answers.
+func (c *AliasCache) lookup(path string) (Alias, bool) {
 	c.mu.RLock()
-	tgt, ok := c.data[path]
-	stale := time.Since(c.loadedAt) > c.ttl
+	a, ok := c.data[path]
 	c.mu.RUnlock()
-	return tgt, ok && !stale
+	if ok {
+		return a, true
+	}
+
+	c.lmu.Lock()
+	v, ok := c.fallback.Get(path)
+	c.lmu.Unlock()
+	if ok {
+		return v.(Alias), true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return matchPatterns(c.patterns, path)
+}
+
+// store caches a single SQL-fallback hit until the next Load.
+func (c *AliasCache) store(path string, a Alias) {
+	c.lmu.Lock()
+	c.fallback.Add(path, a)
+	c.misses.Remove(path)
+	c.lmu.Unlock()
+}
+
+// generation returns the current Load generation.
+func (c *AliasCache) generation() int {
+	c.lmu.Lock()
+	defer c.lmu.Unlock()
+	return c.gen
+}
+
+// storeFrom stores a fallback hit unless a Load ran since gen, in which
+// case the fresh map already has the truth.
+func (c *AliasCache) storeFrom(gen int, path string, a Alias) {
+	c.lmu.Lock()
+	defer c.lmu.Unlock()
+	if gen == c.gen {
+		c.fallback.Add(path, a)
+	}
+}
+
+// rememberMiss records that SQL had no alias for path, unless a Load ran
+// since gen or the negative cache is off.
+func (c *AliasCache) rememberMiss(gen int, path string) {
+	c.lmu.Lock()
+	defer c.lmu.Unlock()
+	if gen == c.gen && c.negTTL > 0 {
+		c.misses.Add(path, time.Now().Add(c.negTTL))
+	}
+}
+
+// knownMiss reports whether path is a remembered, unexpired miss.
+func (c *AliasCache) knownMiss(path string) bool {
+	c.lmu.Lock()
+	defer c.lmu.Unlock()
+	v, ok := c.misses.Get(path)
+	if !ok {
+		return false
+	}
+	if time.Now().After(v.(time.Time)) {
+		c.misses.Remove(path)
+		return false
+	}
+	return true
 }
 
-// store inserts a single alias→target into the map (used after SQL fallback).
-func (c *AliasCache) store(path, target string) {
+// storePattern adds a compiled pattern, keeping precedence order.
+func (c *AliasCache) storePattern(p aliasPattern) {
 	c.mu.Lock()
-	c.data[path] = target
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+	for _, have := range c.patterns {
+		if have.alias == p.alias {
+			return
+		}
+	}
+	c.patterns = append(c.patterns, p)
+	sortPatterns(c.patterns)
+}
+
+// compileRow compiles a pattern row, logging one that does not compile.
+func compileRow(alias string, a Alias) (aliasPattern, bool) {
+	p, err := compilePattern(alias, a)
+	if err != nil {
+		zap.L().Warn("route_alias pattern skipped",
+			zap.String("alias", alias), zap.Error(err))
+		return aliasPattern{}, false
+	}
+	return p, true
+}
+
+// resolve looks path up in the map, then falls back to SQL: one exact
+// query, then one query for patterns whose literal prefix starts path.
+// Hits and misses are cached (see Bounds).  A fallback error is logged and
+// reported as a miss, but not remembered.
+func (c *AliasCache) resolve(ctx context.Context, path string) (Alias, bool) {
+	if a, ok := c.lookup(path); ok {
+		metrics.AliasCacheHitsTotal.Inc()
+		return a, true
+	}
+	if c.knownMiss(path) {
+		metrics.AliasNegativeHitsTotal.Inc()
+		metrics.AliasCacheMissesTotal.Inc()
+		return Alias{}, false
+	}
+
+	a, ok := c.fallbackSQL(ctx, path)
+	if ok {
+		metrics.AliasCacheHitsTotal.Inc()
+	} else {
+		metrics.AliasCacheMissesTotal.Inc()
+	}
+	return a, ok
+}
+
+// fallbackSQL runs the exact, then the pattern, query for path.
+func (c *AliasCache) fallbackSQL(ctx context.Context, path string) (Alias, bool) {
+	metrics.AliasSQLFallbackTotal.Inc()
+	gen := c.generation()
+
+	var target, kind string
+	err := c.withAliasCols(c.aliasColumns(), func(cols aliasCols) error {
+		return c.db.
+			QueryRowContext(ctx,
+				`SELECT target_path, `+cols.kind+` FROM route_alias
+				  WHERE alias_path = ? LIMIT 1`, path).
+			Scan(&target, &kind)
+	})
+
+	switch err {
+	case nil:
+		a := Alias{Target: target, Kind: parseKind(path, kind)}
+		c.storeFrom(gen, path, a)
+		return a, true
+	case sql.ErrNoRows:
+		a, ok, err := c.resolvePattern(ctx, path)
+		if !ok && err == nil {
+			c.rememberMiss(gen, path)
+		}
+		return a, ok
+	default:
+		zap.L().Warn("alias SQL fallback failed", zap.Error(err))
+	}
+	return Alias{}, false
+}
+
+// resolvePattern loads the pattern rows that could match path, caches
+// them, and matches path against the cache.  The LIKE compares path with
+// each pattern's literal prefix (the text before its first { or *).  err
+// reports a failed query, so the caller does not remember the miss.
+func (c *AliasCache) resolvePattern(ctx context.Context, path string) (Alias, bool, error) {
+	var rows *sql.Rows
+	err := c.withAliasCols(c.aliasColumns(), func(cols aliasCols) (err error) {
+		rows, err = c.db.QueryContext(ctx,
+			`SELECT alias_path, target_path, `+cols.kind+` FROM route_alias
+			  WHERE (alias_path LIKE '%*' OR alias_path LIKE '%{%')
+			    AND ? LIKE CONCAT(SUBSTRING_INDEX(SUBSTRING_INDEX(alias_path, '{', 1), '*', 1), '%')`,
+			path)
+		return err
+	})
+	if err != nil {
+		zap.L().Warn("alias pattern fallback failed", zap.Error(err))
+		return Alias{}, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias, target, kind string
+		if err := rows.Scan(&alias, &target, &kind); err != nil {
+			zap.L().Warn("alias pattern fallback failed", zap.Error(err))
+			return Alias{}, false, err
+		}
+		if p, ok := compileRow(alias, Alias{Target: target, Kind: parseKind(alias, kind)}); ok {
+			c.storePattern(p)
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		zap.L().Warn("alias pattern fallback failed", zap.Error(err))
+	}
+	a, ok := c.lookup(path)
+	return a, ok, err
 }
 
 // needsRefresh returns true when TTL expired or route_version changed.
@@ -131,7 +519,8 @@ const (
 	RouteModeBoth      = "both"
 )
 
-// Middleware rewrites friendly paths to absolute component paths.
+// Middleware rewrites friendly paths to absolute component paths, or
+// redirects them for redirect kinds.
 func Middleware(t AliasTenant) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -153,33 +542,18 @@ func Middleware(t AliasTenant) func(http.Handler) http.Handler {
 				}
 			}
 
-			// 1️⃣  In-memory lookup
-			if tgt, ok := cache.lookup(r.URL.Path); ok {
-				rewriteAndServe(w, r, tgt, next)
-				return
-			}
-
-			// 2️⃣  First-hit SQL fallback
-			var dbTgt string
-			err := cache.db.
-				QueryRowContext(r.Context(),
-					`SELECT target_path FROM route_alias
-					  WHERE alias_path = ? LIMIT 1`, r.URL.Path).
-				Scan(&dbTgt)
-
-			switch err {
-			case nil:
-				cache.store(r.URL.Path, dbTgt)
-				rewriteAndServe(w, r, dbTgt, next)
+			// In-memory lookup, then first-hit SQL fallback
+			if a, ok := cache.resolve(r.Context(), r.URL.Path); ok {
+				if a.Kind.status() != 0 {
+					redirect(w, r, cache, a)
+					return
+				}
+				rewriteAndServe(w, r, a.Target, next)
 				return
-			case sql.ErrNoRows:
-				// treat as miss
-			default:
-				zap.L().Warn("alias SQL fallback failed", zap.Error(err))
 			}
 
-			// Alias not found
-			if t.RoutingMode() == RouteModeAliasOnly {
+			// Alias not found; reserved paths (reserved.go) still route
+			if t.RoutingMode() == RouteModeAliasOnly && !reserved(r.URL.Path) {
 				http.NotFound(w, r)
 				return
 			}
@@ -188,13 +562,89 @@ func Middleware(t AliasTenant) func(http.Handler) http.Handler {
 	}
 }
 
-// rewriteAndServe mutates the request path then delegates to next.
+// redirect answers a redirect alias.  The chain is followed while the
+// target is itself a redirect alias; the first hop's status is kept, and a
+// loop answers 508.
+func redirect(w http.ResponseWriter, r *http.Request, cache *AliasCache, a Alias) {
+	code := a.Kind.status()
+	seen := map[string]bool{r.URL.Path: true}
+	for hops := 0; ; hops++ {
+		path, _, _ := strings.Cut(a.Target, "?")
+		if seen[path] || hops >= maxRedirectHops {
+			zap.L().Error("alias redirect loop",
+				zap.String("from", r.URL.Path),
+				zap.String("at", path))
+			http.Error(w, http.StatusText(http.StatusLoopDetected), http.StatusLoopDetected)
+			return
+		}
+		if !strings.HasPrefix(path, "/") {
+			break // absolute URL: not ours to follow
+		}
+		next, ok := cache.resolve(r.Context(), path)
+		if !ok || next.Kind.status() == 0 {
+			break
+		}
+		seen[path] = true
+		a = next
+	}
+
+	loc := a.Target
+	if r.URL.RawQuery != "" {
+		sep := "?"
+		if strings.Contains(loc, "?") {
+			sep = "&"
+		}
+		loc += sep + r.URL.RawQuery
+	}
+	zap.L().Debug("alias redirect",
+		zap.String("from", r.URL.Path),
+		zap.String("to", loc),
+		zap.Int("status", code))
+	http.Redirect(w, r, loc, code)
+}
+
+// rewriteAndServe serves a copy of r whose URL is target.  A query on the
+// target is merged with the request's; on a key both carry, the request's
+// values win.  RequestURI is kept in step with the new URL, and the
+// caller's *http.Request is left untouched.
 func rewriteAndServe(w http.ResponseWriter, r *http.Request, target string, next http.Handler) {
-	orig := r.URL.Path
-	r.URL.Path = target
-	r.RequestURI = target
+	u, err := url.Parse(target)
+	if err != nil || u.Path == "" {
+		zap.L().Error("alias target invalid",
+			zap.String("from", r.URL.Path),
+			zap.String("target", target),
+			zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	u2 := *r.URL
+	r2.URL = &u2
+	r2.URL.Path, r2.URL.RawPath = u.Path, u.RawPath
+	r2.URL.RawQuery = mergeQuery(u.RawQuery, r.URL.RawQuery)
+	r2.RequestURI = r2.URL.RequestURI()
+
 	zap.L().Debug("alias rewrite",
-		zap.String("from", orig),
-		zap.String("to", target))
-	next.ServeHTTP(w, r)
+		zap.String("from", r.URL.RequestURI()),
+		zap.String("to", r2.RequestURI))
+	next.ServeHTTP(w, r2)
+}
+
+// mergeQuery merges the target's raw query with the incoming one.  When
+// only one side is set it is kept byte for byte.
+func mergeQuery(target, incoming string) string {
+	switch {
+	case target == "":
+		return incoming
+	case incoming == "":
+		return target
+	}
+	q, _ := url.ParseQuery(target)
+	in, _ := url.ParseQuery(incoming)
+	for k, vs := range in {
+		q[k] = vs
+	}
+	return q.Encode()
 }
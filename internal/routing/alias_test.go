@@ -6,11 +6,17 @@
 // -------
 // The AliasRewrite handler rewrites friendly paths to absolute component
 // paths by consulting an in-memory AliasCache with SQL fallback.  These tests
-// verify three critical behaviours:
+// verify these behaviours:
 //
 //   • Cache-hit rewrite in BOTH mode                         → 200, path mutated
 //   • Cache-miss in ALIAS-only mode                          → 404
+//   • Reserved framework path in ALIAS-only mode             → routed
 //   • ABSOLUTE routing mode leaves path untouched            → 200
+//   • route_version bump reloads the map before lookup       → new alias
+//   • Redirect kinds                                         → 301/302, query kept
+//   • Redirect chains collapse; loops                        → 508
+//   • Rewrite targets with a query merge with the request    → request wins
+//   • SQL misses are remembered; Load forgets fallback state → no re-query
 //
 // Workflow / Structure
 // --------------------
@@ -32,12 +38,18 @@
 package routing
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/yanizio/adept/internal/metrics"
 )
 
 // fakeTenant satisfies AliasTenant with injectable fields.
@@ -54,7 +66,7 @@ func (f *fakeTenant) AliasCache() *AliasCache { return f.cache }
 func TestAliasRewrite_CacheHit(t *testing.T) {
 	db, _, _ := sqlmock.New()
 	cache := NewAliasCache(db, time.Minute)
-	cache.store("/about", "/content/page/view/about")
+	cache.store("/about", Alias{Target: "/content/page/view/about", Kind: KindRewrite})
 
 	tenant := &fakeTenant{mode: RouteModeBoth, cache: cache}
 
@@ -115,3 +127,314 @@ func TestAliasRewrite_AbsoluteMode_NoMutation(t *testing.T) {
 		t.Fatalf("status = %d, want 200", rr.Code)
 	}
 }
+
+func TestAliasRewrite_VersionBumpReloads(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	tenant := &fakeTenant{mode: RouteModeAliasOnly, version: 1, cache: cache}
+
+	cols := []string{"alias_path", "target_path", "kind", "canonical"}
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows(cols).AddRow("/about", "/content/page/view/about", "rewrite", 0))
+
+	var got string
+	h := Middleware(tenant)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+	serve := func(path string) int {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Code
+	}
+
+	if code := serve("/about"); code != http.StatusOK || got != "/content/page/view/about" {
+		t.Fatalf("first request = %d %q", code, got)
+	}
+
+	// The poller applied a new route_version; the next request reloads.
+	tenant.version = 2
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("/about", "/content/page/view/about", "rewrite", 0).
+			AddRow("/new", "/content/page/view/new", "", 0))
+
+	if code := serve("/new"); code != http.StatusOK || got != "/content/page/view/new" {
+		t.Fatalf("after bump = %d %q", code, got)
+	}
+	if st := cache.Stats(); st.Version != 2 || st.Loads != 2 || st.Entries != 2 {
+		t.Fatalf("stats = %+v", st)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAliasRedirect_KindsAndQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	tenant := &fakeTenant{mode: RouteModeBoth, cache: cache}
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/old", "/new", "redirect_permanent", 0).
+			AddRow("/promo", "/sale?src=promo", "redirect_temporary", 0))
+
+	h := Middleware(tenant)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("redirect reached the next handler")
+	}))
+
+	cases := []struct {
+		url, loc string
+		code     int
+	}{
+		{"/old?page=2", "/new?page=2", http.StatusMovedPermanently},
+		{"/old", "/new", http.StatusMovedPermanently},
+		{"/promo?utm=x", "/sale?src=promo&utm=x", http.StatusFound},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if rr.Code != tc.code || rr.Header().Get("Location") != tc.loc {
+			t.Errorf("%s = %d %q, want %d %q", tc.url, rr.Code, rr.Header().Get("Location"), tc.code, tc.loc)
+		}
+	}
+}
+
+func TestAliasRedirect_SQLFallbackCarriesKind(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now() // skip the bulk load
+	tenant := &fakeTenant{mode: RouteModeBoth, cache: cache}
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/moved").
+		WillReturnRows(sqlmock.NewRows([]string{"target_path", "kind"}).
+			AddRow("/here", "redirect_temporary"))
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/here").
+		WillReturnError(sql.ErrNoRows)
+
+	rr := httptest.NewRecorder()
+	Middleware(tenant)(http.NotFoundHandler()).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/moved", nil))
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "/here" {
+		t.Fatalf("fallback = %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if a, ok := cache.lookup("/moved"); !ok || a.Kind != KindRedirectTemporary {
+		t.Fatalf("cached = %+v, %v", a, ok)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAliasRedirect_ChainCollapsesAndLoopStops(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now()
+	cache.store("/a", Alias{Target: "/b", Kind: KindRedirectPermanent})
+	cache.store("/b", Alias{Target: "/c", Kind: KindRedirectTemporary})
+	cache.store("/c", Alias{Target: "/content/page/view/c", Kind: KindRewrite})
+	cache.store("/x", Alias{Target: "/y", Kind: KindRedirectPermanent})
+	cache.store("/y", Alias{Target: "/x?again=1", Kind: KindRedirectPermanent})
+	tenant := &fakeTenant{mode: RouteModeBoth, cache: cache}
+	h := Middleware(tenant)(http.NotFoundHandler())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/c" {
+		t.Fatalf("chain = %d %q, want 301 /c", rr.Code, rr.Header().Get("Location"))
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if rr.Code != http.StatusLoopDetected || rr.Header().Get("Location") != "" {
+		t.Fatalf("loop = %d %q, want 508", rr.Code, rr.Header().Get("Location"))
+	}
+}
+
+func TestAliasRewrite_QueryMerge(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now()
+	cache.store("/about", Alias{Target: "/content/page/view/about", Kind: KindRewrite})
+	cache.store("/page", Alias{Target: "/content/page/view?id=42&lang=en", Kind: KindRewrite})
+	tenant := &fakeTenant{mode: RouteModeBoth, cache: cache}
+
+	var gotPath, gotQuery, gotURI string
+	h := Middleware(tenant)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotURI = r.URL.Path, r.URL.RawQuery, r.RequestURI
+	}))
+
+	cases := []struct{ url, path, query, uri string }{
+		{"/page", "/content/page/view", "id=42&lang=en", "/content/page/view?id=42&lang=en"},
+		{"/about?tag=go&b=2", "/content/page/view/about", "tag=go&b=2", "/content/page/view/about?tag=go&b=2"},
+		{"/page?lang=fr&x=1", "/content/page/view", "id=42&lang=fr&x=1", "/content/page/view?id=42&lang=fr&x=1"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if gotPath != tc.path || gotQuery != tc.query || gotURI != tc.uri {
+			t.Errorf("%s → %q %q %q, want %q %q %q",
+				tc.url, gotPath, gotQuery, gotURI, tc.path, tc.query, tc.uri)
+		}
+		if req.URL.String() != tc.url || req.RequestURI != tc.url {
+			t.Errorf("%s: caller's request mutated to %s", tc.url, req.URL)
+		}
+	}
+}
+
+func TestAliasCache_MissRemembered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now()
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/wp-login.php").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT alias_path, target_path, kind FROM route_alias").
+		WithArgs("/wp-login.php").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind"}))
+
+	neg := testutil.ToFloat64(metrics.AliasNegativeHitsTotal)
+	fallbacks := testutil.ToFloat64(metrics.AliasSQLFallbackTotal)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, ok := cache.resolve(ctx, "/wp-login.php"); ok {
+			t.Fatal("miss resolved")
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err) // a second query pair would be unexpected
+	}
+	if d := testutil.ToFloat64(metrics.AliasNegativeHitsTotal) - neg; d != 2 {
+		t.Fatalf("negative hits = %v, want 2", d)
+	}
+	if d := testutil.ToFloat64(metrics.AliasSQLFallbackTotal) - fallbacks; d != 1 {
+		t.Fatalf("fallbacks = %v, want 1", d)
+	}
+
+	// Expired misses query again; a query error is never remembered.
+	cache.SetNegativeTTL(time.Nanosecond)
+	cache.rememberMiss(cache.generation(), "/gone")
+	time.Sleep(time.Millisecond)
+	if cache.knownMiss("/gone") {
+		t.Fatal("expired miss still known")
+	}
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/flaky").
+		WillReturnError(fmt.Errorf("conn reset"))
+	cache.SetNegativeTTL(time.Hour)
+	cache.resolve(ctx, "/flaky")
+	if cache.knownMiss("/flaky") {
+		t.Fatal("fallback error remembered as a miss")
+	}
+}
+
+func TestAliasCache_LoadForgetsFallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now()
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/promo").
+		WillReturnRows(sqlmock.NewRows([]string{"target_path", "kind"}).
+			AddRow("/content/page/view/promo", "rewrite"))
+	ctx := context.Background()
+	if _, ok := cache.resolve(ctx, "/promo"); !ok {
+		t.Fatal("fallback missed")
+	}
+	cache.rememberMiss(cache.generation(), "/new")
+
+	// The row was deleted; the reload is the source of truth.
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/new", "/content/page/view/new", "rewrite", 0))
+	if err := cache.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.lookup("/promo"); ok {
+		t.Fatal("deleted fallback alias survived Load")
+	}
+	if a, ok := cache.resolve(ctx, "/new"); !ok || a.Target != "/content/page/view/new" {
+		t.Fatalf("/new = %+v, %v; miss survived Load", a, ok)
+	}
+
+	// A fallback that straddles a Load is dropped.
+	gen := cache.generation()
+	cache.gen++
+	cache.storeFrom(gen, "/late", Alias{Target: "/x"})
+	if _, ok := cache.lookup("/late"); ok {
+		t.Fatal("stale fallback stored")
+	}
+}
+
+func TestAliasCache_Bounded(t *testing.T) {
+	cache := NewAliasCache(nil, time.Hour)
+	for i := 0; i < DefaultAliasFallbackEntries+10; i++ {
+		cache.store(fmt.Sprintf("/f%d", i), Alias{Target: "/x"})
+	}
+	for i := 0; i < DefaultAliasMissEntries+10; i++ {
+		cache.rememberMiss(0, fmt.Sprintf("/m%d", i))
+	}
+	st := cache.Stats()
+	if st.Fallback != DefaultAliasFallbackEntries || st.Misses != DefaultAliasMissEntries {
+		t.Fatalf("stats = %+v", st)
+	}
+	if _, ok := cache.lookup("/f0"); ok {
+		t.Fatal("oldest fallback entry not evicted")
+	}
+}
+
+// BenchmarkAliasResolve_MissHeavy resolves remembered misses against a
+// loaded map.  ns/op should not grow with the number of distinct paths.
+func BenchmarkAliasResolve_MissHeavy(b *testing.B) {
+	for _, n := range []int{10, 1000, DefaultAliasMissEntries} {
+		b.Run(fmt.Sprintf("paths=%d", n), func(b *testing.B) {
+			cache := NewAliasCache(nil, time.Hour)
+			for i := 0; i < 1000; i++ {
+				cache.data[fmt.Sprintf("/page-%d", i)] = Alias{Target: "/content/page/view/x"}
+			}
+			paths := make([]string, n)
+			for i := range paths {
+				paths[i] = fmt.Sprintf("/wp-admin/%d.php", i)
+				cache.rememberMiss(0, paths[i])
+			}
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := cache.resolve(ctx, paths[i%n]); ok {
+					b.Fatal("miss resolved")
+				}
+			}
+		})
+	}
+}
+
+func TestAliasOnly_ReservedPathRoutes(t *testing.T) {
+	ReservePath("/test-reserved.xml", "/test-reserved-*")
+	db, _, _ := sqlmock.New()
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now()
+	for _, p := range []string{"/test-reserved.xml", "/test-reserved-2.xml"} {
+		cache.rememberMiss(cache.generation(), p) // no SQL fallback
+	}
+	tenant := &fakeTenant{mode: RouteModeAliasOnly, cache: cache}
+	h := Middleware(tenant)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	for _, p := range []string{"/test-reserved.xml", "/test-reserved-2.xml"} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, p, nil))
+		if rr.Code != http.StatusTeapot {
+			t.Errorf("%s = %d, want it routed", p, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// internal/routing/aliascols.go
+//
+// route_alias columns that older tenant databases lack.
+//
+// Context
+// -------
+// route_alias.kind and route_alias.canonical arrived after tenants were
+// installed, and nothing migrates existing tenant databases.  Selecting a missing column fails the
+// whole query, so every alias would miss and an ALIAS-only site would 404
+// on every path.  Queries therefore name optional columns through
+// aliasCols, which swaps a column the database reports unknown for the
+// value a pre-upgrade row means:
+//
+//   - kind      → '' (parseKind: rewrite, the only behaviour before kinds)
+//   - canonical → 0  (no preferred alias; Reverse picks the shortest)
+//
+// The sitemap filters on kind; it uses UnknownColumn to drop that filter.
+//
+// Workflow
+// --------
+//  1. Load starts from every column, so a tenant migrated since the last
+//     load is picked up without a restart.
+//  2. A query that fails with an unknown-column error drops that column
+//     and retries; the result is remembered for the SQL fallback paths.
+//
+// Notes
+// -----
+// • Like mountPrefixes in the tenant package, the error is recognised by
+//   its MariaDB (1054) or Postgres (42703) code, not a driver type.
+// • Oxford commas, two spaces after periods.
+
+package routing
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// aliasCols names the optional route_alias columns, or their literal
+// stand-ins when a column does not exist.
+type aliasCols struct {
+	kind      string
+	canonical string
+}
+
+// allAliasCols selects every optional column.
+var allAliasCols = aliasCols{kind: "kind", canonical: "canonical"}
+
+// optionalAliasCols lists each optional column with its stand-in.
+var optionalAliasCols = []struct {
+	name, fallback string
+	field          func(*aliasCols) *string
+}{
+	{"kind", "''", func(c *aliasCols) *string { return &c.kind }},
+	{"canonical", "0", func(c *aliasCols) *string { return &c.canonical }},
+}
+
+// without returns cols minus the column err reports unknown.  ok is false
+// when err is not an unknown-column error or nothing is left to drop.  An
+// error that names no optional column drops the first one still selected,
+// so retries always make progress.
+func (cols aliasCols) without(err error) (aliasCols, bool) {
+	if !UnknownColumn(err, "") {
+		return cols, false
+	}
+	var first *string
+	var firstFallback string
+	for _, o := range optionalAliasCols {
+		f := o.field(&cols)
+		if *f != o.name {
+			continue // already dropped
+		}
+		if UnknownColumn(err, o.name) {
+			*f = o.fallback
+			return cols, true
+		}
+		if first == nil {
+			first, firstFallback = f, o.fallback
+		}
+	}
+	if first == nil {
+		return cols, false
+	}
+	*first = firstFallback
+	return cols, true
+}
+
+// UnknownColumn reports whether err is a MariaDB (1054) or Postgres (42703)
+// "column does not exist" error, naming col when col is not empty.
+func UnknownColumn(err error, col string) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "1054") && !strings.Contains(msg, "42703") {
+		return false
+	}
+	return col == "" || strings.Contains(msg, "'"+col+"'") || strings.Contains(msg, `"`+col+`"`)
+}
+
+// aliasColumns returns the columns last known to exist.
+func (c *AliasCache) aliasColumns() aliasCols {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+	return c.cols
+}
+
+// withAliasCols runs q with cols, dropping each column the database reports
+// unknown and retrying, then remembers the columns that worked.
+func (c *AliasCache) withAliasCols(cols aliasCols, q func(aliasCols) error) error {
+	for {
+		err := q(cols)
+		next, retry := cols.without(err)
+		if !retry {
+			if err == nil {
+				c.cmu.Lock()
+				c.cols = cols
+				c.cmu.Unlock()
+			}
+			return err
+		}
+		zap.L().Debug("route_alias column missing; using its default",
+			zap.Error(err))
+		cols = next
+	}
+}
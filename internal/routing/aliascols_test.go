@@ -0,0 +1,77 @@
+// internal/routing/aliascols_test.go
+//
+// Unit-tests for route_alias tables that predate the optional columns.
+
+package routing
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var (
+	errNoKind      = errors.New("Error 1054 (42S22): Unknown column 'kind' in 'field list'")
+	errNoCanonical = errors.New(`pq: column "canonical" does not exist (42703)`)
+)
+
+func TestAliasCache_LegacyKindColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	tenant := &fakeTenant{mode: RouteModeAliasOnly, cache: cache}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, kind, canonical FROM route_alias")).
+		WillReturnError(errNoKind)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, '', canonical FROM route_alias")).
+		WillReturnError(errNoCanonical)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, '', 0 FROM route_alias")).
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/about", "/content/page/view/about", "", 0))
+	// The SQL fallback goes straight to the columns that worked.
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT target_path, '' FROM route_alias")).WithArgs("/team").
+		WillReturnRows(sqlmock.NewRows([]string{"target_path", "kind"}).AddRow("/content/page/view/team", ""))
+
+	var got string
+	h := Middleware(tenant)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+	for path, want := range map[string]string{
+		"/about": "/content/page/view/about",
+		"/team":  "/content/page/view/team",
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusOK || got != want {
+			t.Fatalf("%s = %d %q, want rewrite to %s", path, rr.Code, got, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAliasCols_Without(t *testing.T) {
+	if _, ok := allAliasCols.without(sql.ErrNoRows); ok {
+		t.Fatal("dropped a column for a non-schema error")
+	}
+	cols, ok := allAliasCols.without(errNoCanonical)
+	if !ok || cols != (aliasCols{kind: "kind", canonical: "0"}) {
+		t.Fatalf("without(canonical) = %+v, %v", cols, ok)
+	}
+	cols, ok = cols.without(errNoKind)
+	if !ok || cols != (aliasCols{kind: "''", canonical: "0"}) {
+		t.Fatalf("without(kind) = %+v, %v", cols, ok)
+	}
+	if _, ok := cols.without(errNoKind); ok {
+		t.Fatal("retry with nothing left to drop")
+	}
+}
@@ -0,0 +1,172 @@
+// internal/routing/aliaspattern.go
+//
+// Wildcard and named-parameter aliases.
+//
+// Context
+// -------
+// An exact alias maps one path.  A pattern alias maps a family of them, so
+// a content-heavy tenant needs one row instead of thousands:
+//
+//	/blog/*             →  /content/article/view/*
+//	/docs/{slug}        →  /content/page/view/{slug}
+//	/{lang}/help/*      →  /help/*?lang={lang}
+//
+//   - {name} matches exactly one non-empty segment.
+//   - A trailing /* matches the rest of the path, including nothing
+//     ("/blog/" but not "/blog").  * is only legal as the last segment.
+//   - The target gets each {name} and the * replaced by what they matched.
+//
+// Precedence
+// ----------
+//  1. Exact aliases, from the map: still one O(1) lookup.
+//  2. Patterns, longest literal prefix first (the text before the first
+//     { or *), then more literal segments, then no wildcard before
+//     wildcard, then alias text.  "/docs/api/{slug}" beats "/docs/{slug}",
+//     which beats "/docs/*".
+//
+// Patterns are kept as a sorted slice and scanned in order.  Tenants have a
+// handful of them, so a trie would buy nothing.
+//
+// Notes
+// -----
+// • A row that looks like a pattern but does not compile (a * mid-path, an
+//   empty {}) is skipped with a WARN.
+// • A captured value may not start with / or \, and a filled-in target
+//   may not start with // or /\, so "/old//evil.com" against
+//   "/old/*" → "/*" cannot become a redirect to another host.
+// • Oxford commas, two spaces after periods.
+
+package routing
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// aliasPattern is one compiled pattern row.
+type aliasPattern struct {
+	alias    string
+	segs     []string // segments after the leading "/"; "{x}" or literal
+	wildcard bool     // alias ends in "/*"
+	prefix   string   // literal text before the first { or *
+	literals int      // literal segments
+	target   Alias
+}
+
+var errBadPattern = errors.New("invalid alias pattern")
+
+// isPattern reports whether alias needs compiling rather than a map entry.
+func isPattern(alias string) bool {
+	return strings.ContainsAny(alias, "*{")
+}
+
+// compilePattern parses alias.
+func compilePattern(alias string, a Alias) (aliasPattern, error) {
+	if !strings.HasPrefix(alias, "/") {
+		return aliasPattern{}, errBadPattern
+	}
+	p := aliasPattern{alias: alias, target: a}
+	if i := strings.IndexAny(alias, "*{"); i >= 0 {
+		p.prefix = alias[:i]
+	}
+
+	body := strings.TrimPrefix(alias, "/")
+	if body == "*" || strings.HasSuffix(body, "/*") {
+		p.wildcard = true
+		body = strings.TrimSuffix(strings.TrimSuffix(body, "*"), "/")
+	}
+	if body != "" {
+		p.segs = strings.Split(body, "/")
+	}
+	for _, seg := range p.segs {
+		switch {
+		case isParam(seg):
+		case strings.ContainsAny(seg, "*{}"):
+			return aliasPattern{}, errBadPattern
+		default:
+			p.literals++
+		}
+	}
+	return p, nil
+}
+
+// isParam reports whether seg is "{name}".
+func isParam(seg string) bool {
+	return len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' &&
+		!strings.ContainsAny(seg[1:len(seg)-1], "{}*")
+}
+
+// match returns the Alias for path with the target filled in.
+func (p *aliasPattern) match(path string) (Alias, bool) {
+	if !strings.HasPrefix(path, "/") || !strings.HasPrefix(path, p.prefix) {
+		return Alias{}, false
+	}
+	parts := strings.Split(path[1:], "/") // "/blog/" → ["blog", ""]
+	n := len(p.segs)
+	if p.wildcard {
+		if len(parts) <= n {
+			return Alias{}, false // "/blog" does not match "/blog/*"
+		}
+	} else if len(parts) != n {
+		return Alias{}, false
+	}
+
+	// One Replacer pass, so a matched value is never substituted again.
+	var subst []string
+	for i, seg := range p.segs {
+		switch {
+		case isParam(seg):
+			if parts[i] == "" || !safeCapture(parts[i]) {
+				return Alias{}, false
+			}
+			subst = append(subst, seg, parts[i])
+		case seg != parts[i]:
+			return Alias{}, false
+		}
+	}
+	if p.wildcard {
+		rest := strings.Join(parts[n:], "/")
+		if !safeCapture(rest) {
+			return Alias{}, false
+		}
+		subst = append(subst, "*", rest)
+	}
+	target := strings.NewReplacer(subst...).Replace(p.target.Target)
+	if strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return Alias{}, false // scheme-relative: would leave the site
+	}
+	return Alias{Target: target, Kind: p.target.Kind}, true
+}
+
+// safeCapture reports whether v may be substituted into a target: a
+// leading / or \ would turn "/{x}" or "/*" into a scheme-relative URL.
+func safeCapture(v string) bool {
+	return !strings.HasPrefix(v, "/") && !strings.HasPrefix(v, "\\")
+}
+
+// sortPatterns orders ps by precedence.
+func sortPatterns(ps []aliasPattern) {
+	sort.SliceStable(ps, func(i, j int) bool {
+		a, b := ps[i], ps[j]
+		switch {
+		case len(a.prefix) != len(b.prefix):
+			return len(a.prefix) > len(b.prefix)
+		case a.literals != b.literals:
+			return a.literals > b.literals
+		case a.wildcard != b.wildcard:
+			return !a.wildcard
+		}
+		return a.alias < b.alias
+	})
+}
+
+// matchPatterns returns the first pattern in ps that matches path.
+func matchPatterns(ps []aliasPattern, path string) (Alias, bool) {
+	for i := range ps {
+		if a, ok := ps[i].match(path); ok {
+			return a, true
+		}
+	}
+	return Alias{}, false
+}
@@ -0,0 +1,129 @@
+// internal/routing/aliaspattern_test.go
+//
+// Unit-tests for wildcard and {param} aliases.
+
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAliasPattern_MatchAndPrecedence(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/blog/*", "/content/article/view/*", "rewrite", 0).
+			AddRow("/docs/{slug}", "/content/page/view/{slug}", "rewrite", 0).
+			AddRow("/docs/*", "/content/docs/*", "rewrite", 0).
+			AddRow("/docs/api/{slug}", "/api/docs/{slug}", "rewrite", 0).
+			AddRow("/{lang}/help/*", "/help/*?lang={lang}", "rewrite", 0).
+			AddRow("/docs/intro", "/content/page/view/welcome", "rewrite", 0).
+			AddRow("/old/{id}", "/new/{id}", "redirect_permanent", 0).
+			AddRow("/bad/*/mid", "/x", "rewrite", 0))
+	if err := cache.Load(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"/blog/2025/06/hello":  "/content/article/view/2025/06/hello",
+		"/blog/":               "/content/article/view/",
+		"/docs/intro":          "/content/page/view/welcome", // exact wins
+		"/docs/setup":          "/content/page/view/setup",
+		"/docs/setup/extra":    "/content/docs/setup/extra",
+		"/docs/api/auth":       "/api/docs/auth",
+		"/fr/help/billing/faq": "/help/billing/faq?lang=fr",
+		"/old/42":              "/new/42",
+	}
+	for path, want := range cases {
+		a, ok := cache.lookup(path)
+		if !ok || a.Target != want {
+			t.Errorf("lookup(%s) = %q %v, want %q", path, a.Target, ok, want)
+		}
+	}
+	if a, _ := cache.lookup("/old/42"); a.Kind != KindRedirectPermanent {
+		t.Errorf("pattern kind = %s", a.Kind)
+	}
+	for _, path := range []string{"/blog", "/doc", "/docs", "/bad/x/mid", "/fr/help"} {
+		if a, ok := cache.lookup(path); ok {
+			t.Errorf("lookup(%s) matched %q", path, a.Target)
+		}
+	}
+	if st := cache.Stats(); st.Entries != 7 {
+		t.Errorf("entries = %d, want 7 (invalid pattern skipped)", st.Entries)
+	}
+}
+
+func TestAliasPattern_SQLFallbackOnColdMiss(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	cache.loadedAt = time.Now() // skip the bulk load
+	tenant := &fakeTenant{mode: RouteModeAliasOnly, cache: cache}
+
+	mock.ExpectQuery("SELECT target_path, kind FROM route_alias").WithArgs("/blog/hello").
+		WillReturnRows(sqlmock.NewRows([]string{"target_path", "kind"}))
+	mock.ExpectQuery(`WHERE \(alias_path LIKE '%\*' OR alias_path LIKE '%\{%'\)`).WithArgs("/blog/hello").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind"}).
+			AddRow("/blog/*", "/content/article/view/*", "rewrite"))
+
+	var got string
+	h := Middleware(tenant)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+	serve := func(path string) int {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Code
+	}
+
+	if code := serve("/blog/hello"); code != http.StatusOK || got != "/content/article/view/hello" {
+		t.Fatalf("cold miss = %d %q", code, got)
+	}
+	// The pattern is now cached: no further SQL.
+	if code := serve("/blog/other"); code != http.StatusOK || got != "/content/article/view/other" {
+		t.Fatalf("warm pattern = %d %q", code, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAliasPattern_NoSchemeRelativeTarget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/old/*", "/*", "redirect_permanent", 0).
+			AddRow("/go/{to}", "/{to}", "redirect_temporary", 0))
+	if err := cache.Load(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if a, ok := cache.lookup("/old/news/1"); !ok || a.Target != "/news/1" {
+		t.Fatalf("lookup(/old/news/1) = %q %v", a.Target, ok)
+	}
+	for _, path := range []string{
+		"/old//evil.com",
+		"/old///evil.com",
+		`/old/\evil.com`,
+		`/go/\evil.com`,
+	} {
+		if a, ok := cache.lookup(path); ok {
+			t.Errorf("lookup(%s) = %q, want no match", path, a.Target)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// internal/routing/reserved.go
+//
+// Paths the framework serves itself in ALIAS-only mode.
+//
+// Context
+// -------
+// An ALIAS-only tenant answers 404 for every path that is not an alias.
+// Framework endpoints such as /sitemap.xml have no alias, so the packages
+// that serve them reserve their paths here and the middleware lets a miss
+// on a reserved path through to the router.  An alias for the same path
+// still wins.
+//
+// Notes
+// -----
+// • A trailing "*" reserves a prefix ("/sitemap-*").
+// • Call ReservePath from init(); the set is read without locking after.
+// • Oxford commas, two spaces after periods.
+
+package routing
+
+import "strings"
+
+var (
+	reservedExact  = map[string]bool{}
+	reservedPrefix []string
+)
+
+// ReservePath lets misses on each pattern through in ALIAS-only mode.
+func ReservePath(patterns ...string) {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			reservedPrefix = append(reservedPrefix, prefix)
+			continue
+		}
+		reservedExact[p] = true
+	}
+}
+
+// reserved reports whether path was reserved.
+func reserved(path string) bool {
+	if reservedExact[path] {
+		return true
+	}
+	for _, p := range reservedPrefix {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// internal/routing/reverse.go
+//
+// Reverse alias lookup: absolute component path → public alias.
+//
+// Context
+// -------
+// Components build links from their own routes ("/content/page/view/about"),
+// but a tenant in ALIAS or BOTH mode publishes the friendly form ("/about").
+// PublicPath is the one function that converts between them; the view
+// engine's {{ url }} helper, the head builder's canonical link, and sitemap
+// generation all call it, so every surface prints the same URL.
+//
+//	<a href="{{ url "/content/page/view/about" }}">About</a>   → /about
+//
+// Rules
+// -----
+//   - Absolute-only tenants, and paths with no rewrite alias, come back
+//     unchanged.
+//   - The whole argument is tried first, so an alias whose target carries a
+//     query ("/content/page/view?id=42") reverses exactly.  Otherwise the
+//     path is reversed and the query and fragment are kept.
+//   - Only the loaded map is consulted: no SQL, so templates never wait on
+//     the database.  The alias middleware loads it before handlers run.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package routing
+
+import "strings"
+
+// reverseBuilder picks one alias per target while Load scans rows.
+type reverseBuilder map[string]reverseEntry
+
+type reverseEntry struct {
+	alias     string
+	canonical bool
+}
+
+// add offers alias for target.  A canonical row beats a plain one; among
+// equals the shorter alias, then the lower in byte order, wins.
+func (b reverseBuilder) add(target, alias string, canonical bool) {
+	have, ok := b[target]
+	switch {
+	case !ok,
+		canonical && !have.canonical,
+		canonical == have.canonical && len(alias) < len(have.alias),
+		canonical == have.canonical && len(alias) == len(have.alias) && alias < have.alias:
+		b[target] = reverseEntry{alias, canonical}
+	}
+}
+
+// build returns the target→alias map.
+func (b reverseBuilder) build() map[string]string {
+	out := make(map[string]string, len(b))
+	for target, e := range b {
+		out[target] = e.alias
+	}
+	return out
+}
+
+// Reverse returns the preferred rewrite alias for target, as of the last
+// Load.
+func (c *AliasCache) Reverse(target string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	alias, ok := c.reverse[target]
+	return alias, ok
+}
+
+// PublicPath returns the public form of the absolute path p for t: its
+// preferred alias when one exists, else p itself.
+func PublicPath(t AliasTenant, p string) string {
+	if t == nil || t.RoutingMode() == RouteModeAbsolute || !strings.HasPrefix(p, "/") {
+		return p
+	}
+	cache := t.AliasCache()
+	if cache == nil {
+		return p
+	}
+	if alias, ok := cache.Reverse(p); ok {
+		return alias
+	}
+	if i := strings.IndexAny(p, "?#"); i > 0 {
+		if alias, ok := cache.Reverse(p[:i]); ok {
+			return alias + p[i:]
+		}
+	}
+	return p
+}
@@ -0,0 +1,68 @@
+// internal/routing/reverse_test.go
+//
+// Unit-tests for AliasCache.Reverse and PublicPath.
+
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReverse_PrefersCanonical(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewAliasCache(db, time.Hour)
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/about-us", "/content/page/view/about", "rewrite", 0).
+			AddRow("/about", "/content/page/view/about", "rewrite", 0).
+			AddRow("/company/about", "/content/page/view/about", "rewrite", 1).
+			AddRow("/b", "/content/page/view/team", "", 0).
+			AddRow("/a", "/content/page/view/team", "rewrite", 0).
+			AddRow("/old-home", "/content/page/view/home", "redirect_permanent", 0).
+			AddRow("/blog/*", "/content/article/view/*", "rewrite", 0).
+			AddRow("/item", "/shop/view?id=42", "rewrite", 0))
+	if err := cache.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"/content/page/view/about": "/company/about", // canonical beats shorter
+		"/content/page/view/team":  "/a",             // tie → byte order
+		"/content/page/view/home":  "",               // redirect: never reversed
+		"/content/article/view/x":  "",               // patterns: never reversed
+		"/shop/view?id=42":         "/item",
+	}
+	for target, want := range cases {
+		got, ok := cache.Reverse(target)
+		if got != want || ok != (want != "") {
+			t.Errorf("Reverse(%q) = %q, %v; want %q", target, got, ok, want)
+		}
+	}
+
+	alias := &fakeTenant{mode: RouteModeBoth, cache: cache}
+	paths := map[string]string{
+		"/content/page/view/team":        "/a",
+		"/content/page/view/team?tab=2":  "/a?tab=2",
+		"/content/page/view/team#people": "/a#people",
+		"/shop/view?id=42":               "/item",
+		"/content/page/view/none":        "/content/page/view/none",
+		"https://x.example/a":            "https://x.example/a",
+	}
+	for p, want := range paths {
+		if got := PublicPath(alias, p); got != want {
+			t.Errorf("PublicPath(%q) = %q, want %q", p, got, want)
+		}
+	}
+
+	absolute := &fakeTenant{mode: RouteModeAbsolute, cache: cache}
+	if got := PublicPath(absolute, "/content/page/view/team"); got != "/content/page/view/team" {
+		t.Errorf("absolute tenant = %q", got)
+	}
+}
@@ -6,6 +6,8 @@
 //   to ASCII a-z, 0-9 and “-” (English-only requirement).
 // • BuildPath(parent, slug) ─ joins parent path + slug with a single “/” and
 //   guarantees exactly one leading slash.
+// • UniqueSlug / NextSlug ─ "about", "about-2", ... free under a parent
+//   (uniqueslug.go).
 //
 // Rules (MakeSlug)
 // ----------------
@@ -0,0 +1,198 @@
+// internal/routing/uniqueslug.go
+//
+// Collision-free slugs: "about", "about-2", "about-3", ...
+//
+// Context
+// -------
+// Content components turn titles into routes, and two pages titled
+// "About" under the same parent must not share a path.  UniqueSlug asks
+// the tenant DB which "<parent>/<base>" and "<parent>/<base>-N" paths are
+// taken and picks the first free one.  NextSlug is the pure variant for
+// callers that batch and already hold the taken set.
+//
+// Workflow
+// --------
+// 1. base = MakeSlug(title).
+// 2. One query loads every taken candidate under parent (exact path or
+//    "<base>-" prefix) from route_alias.alias_path, or the table and
+//    column in SlugOptions.
+// 3. base, base-2, ..., base-MaxAttempts are tried in order; when all are
+//    taken a random "-xxxxxx" suffix is the fallback.
+// 4. With SlugOptions.Insert set, the chosen path is claimed through it.
+//    A unique-constraint violation means a concurrent insert won the
+//    slug; it is marked taken and the next candidate is tried, up to
+//    MaxAttempts claims.
+//
+// Notes
+// -----
+// • Without Insert, the result is only free at the time of the query.
+//   Callers that insert later must handle the duplicate-key error
+//   themselves; pass Insert instead.
+// • Suffixed slugs are trimmed so they stay within MakeSlug's 100 bytes.
+// • Oxford commas, two spaces after periods.
+
+package routing
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DefaultSlugAttempts bounds both the numbered candidates and the
+// claims UniqueSlug makes through SlugOptions.Insert.
+const DefaultSlugAttempts = 50
+
+const maxSlugLen = 100 // MakeSlug's cap
+
+// ErrSlugExhausted is returned when every claim attempt hit a duplicate.
+var ErrSlugExhausted = errors.New("routing: no free slug")
+
+// SlugOptions tunes UniqueSlug.  The zero value checks
+// route_alias.alias_path and only reads.
+type SlugOptions struct {
+	Table       string // default "route_alias"
+	Column      string // default "alias_path"; must hold full paths
+	MaxAttempts int    // default DefaultSlugAttempts
+
+	// Insert, when set, claims the chosen path (e.g. INSERT INTO
+	// route_alias).  A MySQL duplicate-key error makes UniqueSlug retry
+	// with the next candidate; any other error is returned as is.
+	Insert func(ctx context.Context, path string) error
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (o SlugOptions) withDefaults() (SlugOptions, error) {
+	if o.Table == "" {
+		o.Table = "route_alias"
+	}
+	if o.Column == "" {
+		o.Column = "alias_path"
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultSlugAttempts
+	}
+	if !identRe.MatchString(o.Table) || !identRe.MatchString(o.Column) {
+		return o, fmt.Errorf("routing: invalid slug table %q or column %q", o.Table, o.Column)
+	}
+	return o, nil
+}
+
+// UniqueSlug returns a slug for title that is free under parentPath.
+func UniqueSlug(ctx context.Context, db *sql.DB, parentPath, title string, opts ...SlugOptions) (string, error) {
+	var o SlugOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o, err := o.withDefaults()
+	if err != nil {
+		return "", err
+	}
+
+	base := MakeSlug(title)
+	taken, err := takenSlugs(ctx, db, o, parentPath, base)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i < o.MaxAttempts; i++ {
+		slug := nextSlug(base, taken, o.MaxAttempts)
+		if o.Insert == nil {
+			return slug, nil
+		}
+		err := o.Insert(ctx, BuildPath(parentPath, slug))
+		if err == nil {
+			return slug, nil
+		}
+		if !isDuplicateKey(err) {
+			return "", err
+		}
+		taken[slug] = true // a concurrent insert won it
+	}
+	return "", ErrSlugExhausted
+}
+
+// NextSlug returns the first of base(title), base-2, base-3, ... that is
+// not in taken, or base plus a random suffix after DefaultSlugAttempts.
+// It does not modify taken; batch callers add the result themselves.
+func NextSlug(title string, taken map[string]bool) string {
+	return nextSlug(MakeSlug(title), taken, DefaultSlugAttempts)
+}
+
+func nextSlug(base string, taken map[string]bool, attempts int) string {
+	if !taken[base] {
+		return base
+	}
+	for n := 2; n <= attempts; n++ {
+		if s := suffixed(base, strconv.Itoa(n)); !taken[s] {
+			return s
+		}
+	}
+	for {
+		b := make([]byte, 3)
+		_, _ = rand.Read(b)
+		if s := suffixed(base, hex.EncodeToString(b)); !taken[s] {
+			return s
+		}
+	}
+}
+
+// suffixed appends "-suffix", trimming base to keep the 100-byte cap.
+func suffixed(base, suffix string) string {
+	if limit := maxSlugLen - len(suffix) - 1; len(base) > limit {
+		base = strings.TrimRight(base[:limit], "-")
+	}
+	return base + "-" + suffix
+}
+
+// takenSlugs loads the candidate slugs already used under parent.
+func takenSlugs(ctx context.Context, db *sql.DB, o SlugOptions, parent, base string) (map[string]bool, error) {
+	prefix := BuildPath(parent, "")
+	if prefix != "/" {
+		prefix += "/"
+	}
+	// A long base may be trimmed before its suffix; match on the shortest
+	// form a suffixed candidate can take.
+	stem := base
+	if len(stem) > maxSlugLen-8 {
+		stem = strings.TrimRight(stem[:maxSlugLen-8], "-")
+	}
+	q := fmt.Sprintf(`SELECT %[2]s FROM %[1]s WHERE %[2]s = ? OR %[2]s LIKE ?`, o.Table, o.Column)
+	rows, err := db.QueryContext(ctx, q, prefix+base, escapeLike(prefix+stem)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		if slug, ok := strings.CutPrefix(p, prefix); ok && !strings.Contains(slug, "/") {
+			taken[slug] = true
+		}
+	}
+	return taken, rows.Err()
+}
+
+// escapeLike escapes LIKE wildcards with MySQL's default "\" escape.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// isDuplicateKey reports a MySQL unique-constraint violation (1062).
+func isDuplicateKey(err error) bool {
+	var me *mysql.MySQLError
+	return errors.As(err, &me) && me.Number == 1062
+}
@@ -0,0 +1,113 @@
+// internal/routing/uniqueslug_test.go
+//
+// Unit-tests for UniqueSlug and NextSlug.
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestNextSlug(t *testing.T) {
+	taken := map[string]bool{}
+	var got []string
+	for i := 0; i < 3; i++ { // batch caller: add each result
+		s := NextSlug("About", taken)
+		taken[s] = true
+		got = append(got, s)
+	}
+	if strings.Join(got, ",") != "about,about-2,about-3" {
+		t.Fatalf("got %v", got)
+	}
+
+	for n := 4; n <= DefaultSlugAttempts; n++ {
+		taken[suffixed("about", strconv.Itoa(n))] = true
+	}
+	if s := NextSlug("About", taken); !regexp.MustCompile(`^about-[0-9a-f]{6}$`).MatchString(s) {
+		t.Fatalf("fallback = %q, want random suffix", s)
+	}
+}
+
+func TestNextSlug_LongTitleStaysWithinCap(t *testing.T) {
+	title := strings.Repeat("word ", 40)
+	base := MakeSlug(title)
+	s := NextSlug(title, map[string]bool{base: true})
+	if len(s) > maxSlugLen || !strings.HasSuffix(s, "-2") || strings.Contains(s, "--") {
+		t.Fatalf("NextSlug = %q (%d bytes)", s, len(s))
+	}
+}
+
+func TestUniqueSlug_QueriesParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(`SELECT alias_path FROM route_alias WHERE alias_path = \? OR alias_path LIKE \?`).
+		WithArgs("/company/about", "/company/about%").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path"}).
+			AddRow("/company/about").
+			AddRow("/company/about-2").
+			AddRow("/company/about-us").
+			AddRow("/company/about-3/team")) // deeper path: not a sibling
+
+	slug, err := UniqueSlug(context.Background(), db, "/company", "About")
+	if err != nil || slug != "about-3" {
+		t.Fatalf("UniqueSlug = %q, %v; want about-3", slug, err)
+	}
+}
+
+func TestUniqueSlug_RetriesOnDuplicateKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("SELECT slug_path FROM page").
+		WillReturnRows(sqlmock.NewRows([]string{"slug_path"}).AddRow("/news"))
+
+	var tried []string
+	opts := SlugOptions{Table: "page", Column: "slug_path",
+		Insert: func(_ context.Context, path string) error {
+			tried = append(tried, path)
+			if len(tried) < 3 { // two concurrent writers beat us
+				return &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+			}
+			return nil
+		}}
+	slug, err := UniqueSlug(context.Background(), db, "", "News", opts)
+	if err != nil || slug != "news-4" {
+		t.Fatalf("UniqueSlug = %q, %v; want news-4", slug, err)
+	}
+	if strings.Join(tried, ",") != "/news-2,/news-3,/news-4" {
+		t.Fatalf("claims = %v", tried)
+	}
+
+	// Other insert errors surface; exhausted retries are reported.
+	boom := errors.New("boom")
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"p"}))
+	opts.Insert = func(context.Context, string) error { return boom }
+	if _, err := UniqueSlug(context.Background(), db, "", "x", opts); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"p"}))
+	opts.MaxAttempts = 2
+	opts.Insert = func(context.Context, string) error { return &mysql.MySQLError{Number: 1062} }
+	if _, err := UniqueSlug(context.Background(), db, "", "x", opts); !errors.Is(err, ErrSlugExhausted) {
+		t.Fatalf("err = %v, want ErrSlugExhausted", err)
+	}
+}
+
+func TestUniqueSlug_RejectsBadIdentifiers(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	_, err := UniqueSlug(context.Background(), db, "", "x", SlugOptions{Table: "page; DROP TABLE x"})
+	if err == nil {
+		t.Fatal("unsafe table name accepted")
+	}
+}
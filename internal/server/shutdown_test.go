@@ -0,0 +1,54 @@
+// internal/server/shutdown_test.go
+//
+// Unit-tests for the ordered shutdown Sequence.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSequence_RunsInOrderPastFailures(t *testing.T) {
+	var got []string
+	step := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if ctx.Value(ctxKey{}) != "shutdown" {
+				t.Errorf("%s: phase did not receive the Run context", name)
+			}
+			got = append(got, name)
+			return err
+		}
+	}
+	boom := errors.New("drain timed out")
+
+	s := NewSequence(zap.NewNop().Sugar())
+	s.Add("http", step("http", boom))
+	s.Add("tenant-pools", step("tenant-pools", nil))
+	s.Add("global-db", step("global-db", nil))
+	s.Add("vault", step("vault", nil))
+	s.Add("log", step("log", nil))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "shutdown")
+	err := s.Run(ctx)
+
+	want := "http tenant-pools global-db vault log"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("order = %v, want %s", got, want)
+	}
+	if !errors.Is(err, boom) || !strings.HasPrefix(err.Error(), "http: ") {
+		t.Fatalf("err = %v, want http-prefixed drain error", err)
+	}
+
+	// A second Run is a no-op.
+	got = nil
+	if err := s.Run(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("second Run = %v, ran %v", err, got)
+	}
+}
+
+type ctxKey struct{}
@@ -19,10 +19,7 @@
 
 package session
 
-import (
-	"net/http"
-	"time"
-)
+import "net/http"
 
 const (
 	cookieName = "adept_session"
@@ -31,6 +28,8 @@ const (
 
 // LoginUser sets a session cookie containing the user’s email.
 //
+// The cookie has no Expires attribute, so it ends with the browser session.
+// Long-lived logins use the auth component's refresh tokens instead.
 // Callers typically invoke this after credential verification succeeds.
 func LoginUser(w http.ResponseWriter, r *http.Request, email string) {
 	http.SetCookie(w, &http.Cookie{
@@ -40,10 +39,22 @@ func LoginUser(w http.ResponseWriter, r *http.Request, email string) {
 		HttpOnly: true,
 		Secure:   r.TLS != nil, // only send over HTTPS
 		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Now().Add(14 * 24 * time.Hour),
 	})
 }
 
+// Inject adds the session cookie to r itself so handlers later in the same
+// request see a session that middleware has just minted.
+func Inject(r *http.Request, email string) {
+	cs := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, c := range cs {
+		if c.Name != cookieName {
+			r.AddCookie(c)
+		}
+	}
+	r.AddCookie(&http.Cookie{Name: cookieName, Value: email})
+}
+
 // LogoutUser clears the session cookie.
 func LogoutUser(w http.ResponseWriter, _ *http.Request) {
 	http.SetCookie(w, &http.Cookie{
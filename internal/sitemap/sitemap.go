@@ -0,0 +1,429 @@
+// internal/sitemap/sitemap.go
+//
+// Per-tenant /sitemap.xml.
+//
+// Context
+// -------
+// Search engines expect /sitemap.xml on every site.  The tenant router
+// mounts a Handler built from two sources:
+//
+//   - route_alias: each rewrite alias that is the public form of its
+//     target, i.e. the one AliasCache.Reverse picks (canonical = 1, else
+//     the shortest).  updated_at becomes <lastmod>.  Absolute-only
+//     tenants publish no aliases, so they list none.
+//   - Components: every enabled Component that implements
+//     component.SitemapProvider.  Paths go through routing.PublicPath, so
+//     they print as the alias a visitor would see.
+//
+// Workflow
+// --------
+//  1. The first request builds every file, plain and gzipped, and keeps
+//     them on the Handler.  Concurrent requests wait for that one build.
+//  2. Later requests serve the cached bytes until route_version changes or
+//     the TTL (site_config sitemap.ttl, 1h) passes.  The router is rebuilt
+//     on a version bump too, which drops the Handler altogether.
+//  3. Up to MaxURLsPerFile entries (and MaxFileBytes) /sitemap.xml is a
+//     plain <urlset>.  Beyond that it is a <sitemapindex> pointing at
+//     /sitemap-1.xml, /sitemap-2.xml, and so on.
+//
+// Notes
+// -----
+// • Clients that send Accept-Encoding: gzip get the precompressed body.
+// • Locs are absolute: sitemap.base_url, else https:// plus the canonical
+//   host.  Duplicate locs are merged, keeping the latest lastmod.
+// • sitemap.enabled = false leaves /sitemap.xml to the Components.
+// • A failed alias query or Provider is logged and skipped, so one bad
+//   source never takes the whole sitemap down.
+// • A route_alias table from before kinds holds only rewrites, so the
+//   kind filter is dropped when the column is missing.
+// • Oxford commas, two spaces after periods.
+
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/routing"
+)
+
+// Protocol limits per file (sitemaps.org).
+const (
+	MaxURLsPerFile = 50000
+	MaxFileBytes   = 50 << 20
+)
+
+// DefaultTTL bounds how long a built sitemap is served.
+const DefaultTTL = time.Hour
+
+// site_config keys.
+const (
+	EnabledKey = "sitemap.enabled"
+	BaseURLKey = "sitemap.base_url"
+	TTLKey     = "sitemap.ttl"
+)
+
+// Paths served by Handler.
+const (
+	Path       = "/sitemap.xml"
+	PartPrefix = "/sitemap-"
+)
+
+// buildTimeout bounds one build, alias query and Providers together.
+const buildTimeout = 10 * time.Second
+
+// maxURLs and maxBytes are the split points; tests lower them.
+var (
+	maxURLs  = MaxURLsPerFile
+	maxBytes = MaxFileBytes
+)
+
+func init() {
+	component.DeclareConfig(
+		component.ConfigKey{Name: EnabledKey, Type: component.ConfigBool, Default: "true"},
+		component.ConfigKey{Name: BaseURLKey, Type: component.ConfigString, Default: ""},
+		component.ConfigKey{Name: TTLKey, Type: component.ConfigDuration, Default: DefaultTTL.String()},
+	)
+	routing.ReservePath(Path, PartPrefix+"*")
+}
+
+// Source is the tenant a sitemap is built for.
+type Source interface {
+	routing.AliasTenant
+	component.TenantInfo
+	ReadDB() *sqlx.DB
+}
+
+// Options come from the tenant's site_config.
+type Options struct {
+	BaseURL string        // "https://example.com"; no trailing slash
+	TTL     time.Duration // 0: DefaultTTL
+}
+
+// Handler serves /sitemap.xml and its parts for one tenant.
+type Handler struct {
+	src       Source
+	providers []component.SitemapProvider
+	opts      Options
+
+	mu    sync.Mutex
+	built *built
+}
+
+// built is one generation of files.  files[0] is /sitemap.xml; parts, when
+// there is an index, follow as /sitemap-1.xml, /sitemap-2.xml, ...
+type built struct {
+	ver   int
+	at    time.Time
+	files []file
+}
+
+type file struct{ plain, gz []byte }
+
+// New returns a Handler for src.  providers are the tenant's enabled
+// Components that implement component.SitemapProvider.
+func New(src Source, providers []component.SitemapProvider, opts Options) *Handler {
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	return &Handler{src: src, providers: providers, opts: opts}
+}
+
+// ServeHTTP answers GET and HEAD for Path and PartPrefix<n>.xml.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	idx, ok := fileIndex(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	b := h.current(r.Context())
+	if idx >= len(b.files) {
+		http.NotFound(w, r)
+		return
+	}
+	f := b.files[idx]
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "application/xml; charset=utf-8")
+	hdr.Set("Vary", "Accept-Encoding")
+	hdr.Set("Last-Modified", b.at.UTC().Format(http.TimeFormat))
+	body := f.plain
+	if acceptsGzip(r) {
+		hdr.Set("Content-Encoding", "gzip")
+		body = f.gz
+	}
+	hdr.Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// fileIndex maps "/sitemap.xml" → 0 and "/sitemap-3.xml" → 3.
+func fileIndex(path string) (int, bool) {
+	if path == Path {
+		return 0, true
+	}
+	rest, ok := strings.CutPrefix(path, PartPrefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(rest, ".xml"))
+	if err != nil || n < 1 || !strings.HasSuffix(rest, ".xml") {
+		return 0, false
+	}
+	return n, true
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(name, "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the cached build, rebuilding it when stale.
+func (h *Handler) current(ctx context.Context) *built {
+	ver := h.src.RouteVersion()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if b := h.built; b != nil && b.ver == ver && time.Since(b.at) < h.opts.TTL {
+		return b
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), buildTimeout)
+	defer cancel()
+	h.built = h.build(ctx, ver)
+	return h.built
+}
+
+// build collects, merges, and renders every entry.
+func (h *Handler) build(ctx context.Context, ver int) *built {
+	log := h.src.GetLogger()
+	entries, err := h.aliasEntries(ctx)
+	if err != nil {
+		log.Warnw("sitemap: route_alias skipped", "err", err)
+	}
+	for _, p := range h.providers {
+		more, err := p.SitemapEntries(ctx, h.src)
+		if err != nil {
+			log.Warnw("sitemap: provider skipped",
+				"provider", fmt.Sprintf("%T", p), "err", err)
+			continue
+		}
+		entries = append(entries, more...)
+	}
+
+	urls := h.normalise(entries)
+	at := time.Now()
+	parts := split(urls)
+	b := &built{ver: ver, at: at}
+	if len(parts) <= 1 {
+		var only []component.SitemapEntry
+		if len(parts) == 1 {
+			only = parts[0]
+		}
+		b.files = []file{pack(renderURLSet(only))}
+		return b
+	}
+
+	idx := make([]indexEntry, len(parts))
+	b.files = make([]file, 1, len(parts)+1)
+	for i, part := range parts {
+		idx[i] = indexEntry{
+			loc:     fmt.Sprintf("%s%s%d.xml", h.opts.BaseURL, PartPrefix, i+1),
+			lastMod: latest(part),
+		}
+		b.files = append(b.files, pack(renderURLSet(part)))
+	}
+	b.files[0] = pack(renderIndex(idx))
+	return b
+}
+
+// aliasEntries lists the public alias of each rewrite target.
+func (h *Handler) aliasEntries(ctx context.Context) ([]component.SitemapEntry, error) {
+	if h.src.RoutingMode() == routing.RouteModeAbsolute {
+		return nil, nil
+	}
+	cache := h.src.AliasCache()
+	db := h.src.ReadDB()
+	if cache == nil || db == nil {
+		return nil, nil
+	}
+	if cache.Stats().Loads == 0 {
+		if err := cache.Load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	const q = `SELECT alias_path, target_path, updated_at FROM route_alias`
+	rows, err := db.QueryContext(ctx, q+` WHERE kind = 'rewrite'`)
+	if routing.UnknownColumn(err, "kind") {
+		rows, err = db.QueryContext(ctx, q) // pre-kind table: all rewrites
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []component.SitemapEntry
+	for rows.Next() {
+		var alias, target string
+		var updated sql.NullTime
+		if err := rows.Scan(&alias, &target, &updated); err != nil {
+			return nil, err
+		}
+		if pub, ok := cache.Reverse(target); !ok || pub != alias {
+			continue // not the public form, or a pattern
+		}
+		out = append(out, component.SitemapEntry{Loc: target, LastMod: updated.Time})
+	}
+	return out, rows.Err()
+}
+
+// normalise makes every Loc absolute, drops foreign URLs, merges
+// duplicates, and sorts by Loc.
+func (h *Handler) normalise(in []component.SitemapEntry) []component.SitemapEntry {
+	byLoc := make(map[string]component.SitemapEntry, len(in))
+	for _, e := range in {
+		switch {
+		case strings.HasPrefix(e.Loc, "/") && !strings.HasPrefix(e.Loc, "//"):
+			e.Loc = h.opts.BaseURL + routing.PublicPath(h.src, e.Loc)
+		case strings.HasPrefix(e.Loc, h.opts.BaseURL+"/"):
+		default:
+			continue // another host: not allowed in this sitemap
+		}
+		if have, ok := byLoc[e.Loc]; ok && !e.LastMod.After(have.LastMod) {
+			continue
+		}
+		byLoc[e.Loc] = e
+	}
+	out := make([]component.SitemapEntry, 0, len(byLoc))
+	for _, e := range byLoc {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Loc < out[j].Loc })
+	return out
+}
+
+// split cuts urls into files of at most maxURLs entries and about
+// maxBytes of XML each.
+func split(urls []component.SitemapEntry) [][]component.SitemapEntry {
+	var parts [][]component.SitemapEntry
+	start, size := 0, len(urlsetOpen)+len(urlsetClose)
+	for i, e := range urls {
+		n := entrySize(e)
+		if i > start && (i-start >= maxURLs || size+n > maxBytes) {
+			parts = append(parts, urls[start:i])
+			start, size = i, len(urlsetOpen)+len(urlsetClose)
+		}
+		size += n
+	}
+	if start < len(urls) {
+		parts = append(parts, urls[start:])
+	}
+	return parts
+}
+
+// latest returns the newest LastMod in part.
+func latest(part []component.SitemapEntry) time.Time {
+	var t time.Time
+	for _, e := range part {
+		if e.LastMod.After(t) {
+			t = e.LastMod
+		}
+	}
+	return t
+}
+
+//
+// XML
+//
+
+const (
+	urlsetOpen  = xml.Header + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	urlsetClose = "</urlset>\n"
+	indexOpen   = xml.Header + `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	indexClose  = "</sitemapindex>\n"
+)
+
+type indexEntry struct {
+	loc     string
+	lastMod time.Time
+}
+
+func renderURLSet(urls []component.SitemapEntry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(urlsetOpen)
+	for _, e := range urls {
+		writeEntry(&buf, e)
+	}
+	buf.WriteString(urlsetClose)
+	return buf.Bytes()
+}
+
+func renderIndex(idx []indexEntry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(indexOpen)
+	for _, e := range idx {
+		buf.WriteString("  <sitemap><loc>")
+		_ = xml.EscapeText(&buf, []byte(e.loc))
+		buf.WriteString("</loc>")
+		if !e.lastMod.IsZero() {
+			buf.WriteString("<lastmod>" + e.lastMod.UTC().Format(time.RFC3339) + "</lastmod>")
+		}
+		buf.WriteString("</sitemap>\n")
+	}
+	buf.WriteString(indexClose)
+	return buf.Bytes()
+}
+
+func writeEntry(buf *bytes.Buffer, e component.SitemapEntry) {
+	buf.WriteString("  <url><loc>")
+	_ = xml.EscapeText(buf, []byte(e.Loc))
+	buf.WriteString("</loc>")
+	if !e.LastMod.IsZero() {
+		buf.WriteString("<lastmod>" + e.LastMod.UTC().Format(time.RFC3339) + "</lastmod>")
+	}
+	if e.ChangeFreq != "" {
+		buf.WriteString("<changefreq>")
+		_ = xml.EscapeText(buf, []byte(e.ChangeFreq))
+		buf.WriteString("</changefreq>")
+	}
+	if e.Priority > 0 {
+		buf.WriteString("<priority>" + strconv.FormatFloat(e.Priority, 'f', 1, 64) + "</priority>")
+	}
+	buf.WriteString("</url>\n")
+}
+
+// entrySize estimates the rendered size of e, for split.
+func entrySize(e component.SitemapEntry) int {
+	return len("  <url><loc></loc><lastmod>2006-01-02T15:04:05Z</lastmod>"+
+		"<changefreq></changefreq><priority>0.0</priority></url>\n") +
+		len(e.Loc) + len(e.Loc)/4 + len(e.ChangeFreq) // room for escaping
+}
+
+// pack keeps plain and gzipped copies of body.
+func pack(body []byte) file {
+	var gz bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	_, _ = zw.Write(body)
+	_ = zw.Close()
+	return file{plain: body, gz: gz.Bytes()}
+}
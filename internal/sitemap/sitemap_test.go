@@ -0,0 +1,244 @@
+// internal/sitemap/sitemap_test.go
+//
+// Unit-tests for sitemap generation, caching, and splitting.
+
+package sitemap
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/routing"
+	"github.com/yanizio/adept/internal/theme"
+	"github.com/yanizio/adept/internal/vault"
+)
+
+// fakeSource is a tenant with an sqlmock-backed alias table.
+type fakeSource struct {
+	mode  string
+	ver   int
+	db    *sqlx.DB
+	cache *routing.AliasCache
+}
+
+func (f *fakeSource) RoutingMode() string             { return f.mode }
+func (f *fakeSource) RouteVersion() int               { return f.ver }
+func (f *fakeSource) AliasCache() *routing.AliasCache { return f.cache }
+func (f *fakeSource) GetDB() *sqlx.DB                 { return f.db }
+func (f *fakeSource) ReadDB() *sqlx.DB                { return f.db }
+func (f *fakeSource) GetConfig() map[string]string    { return nil }
+func (f *fakeSource) GetTheme() *theme.Theme          { return nil }
+func (f *fakeSource) GetVault() vault.Secrets         { return nil }
+func (f *fakeSource) GetLogger() *zap.SugaredLogger   { return zap.NewNop().Sugar() }
+
+// countingProvider returns entries and counts its calls.
+type countingProvider struct {
+	entries []component.SitemapEntry
+	err     error
+	calls   int
+}
+
+func (p *countingProvider) SitemapEntries(context.Context, component.TenantInfo) ([]component.SitemapEntry, error) {
+	p.calls++
+	return p.entries, p.err
+}
+
+var updated = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newSource expects one alias Load and one sitemap alias query.
+func newSource(t *testing.T, mode string) (*fakeSource, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	src := &fakeSource{mode: mode, db: sqlx.NewDb(db, "mysql"),
+		cache: routing.NewAliasCache(db, time.Hour)}
+	return src, mock
+}
+
+func expectAliases(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT alias_path, target_path, kind, canonical FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/about", "/content/page/view/about", "rewrite", 0).
+			AddRow("/about-us", "/content/page/view/about", "rewrite", 0).
+			AddRow("/old", "/about", "redirect_permanent", 0).
+			AddRow("/blog/*", "/content/article/view/*", "rewrite", 0))
+	mock.ExpectQuery("SELECT alias_path, target_path, updated_at FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "updated_at"}).
+			AddRow("/about", "/content/page/view/about", updated).
+			AddRow("/about-us", "/content/page/view/about", updated).
+			AddRow("/blog/*", "/content/article/view/*", updated))
+}
+
+type urlset struct {
+	URLs []struct {
+		Loc      string `xml:"loc"`
+		LastMod  string `xml:"lastmod"`
+		Priority string `xml:"priority"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+func get(t *testing.T, h http.Handler, path string, gz bool) (int, []byte) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if gz {
+		r.Header.Set("Accept-Encoding", "br, gzip")
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	body := rr.Body.Bytes()
+	if rr.Header().Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ = io.ReadAll(zr)
+	} else if gz && rr.Code == http.StatusOK {
+		t.Fatal("gzip accepted but not used")
+	}
+	return rr.Code, body
+}
+
+func TestHandler_AliasesAndProviders(t *testing.T) {
+	src, mock := newSource(t, routing.RouteModeBoth)
+	expectAliases(mock)
+	prov := &countingProvider{entries: []component.SitemapEntry{
+		{Loc: "/content/page/view/about", Priority: 0.8}, // duplicate of /about
+		{Loc: "/shop/item/7", LastMod: updated, Priority: 0.5},
+		{Loc: "https://other.example/x"}, // foreign host: dropped
+	}}
+	bad := &countingProvider{err: errors.New("boom")}
+	h := New(src, []component.SitemapProvider{prov, bad}, Options{BaseURL: "https://example.com/"})
+
+	code, body := get(t, h, Path, true)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	var set urlset
+	if err := xml.Unmarshal(body, &set); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, body)
+	}
+	got := map[string]string{}
+	for _, u := range set.URLs {
+		got[u.Loc] = u.LastMod
+	}
+	want := map[string]string{
+		"https://example.com/about":       "2026-03-01T12:00:00Z",
+		"https://example.com/shop/item/7": "2026-03-01T12:00:00Z",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("urls = %v, want %v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cached until route_version moves.
+	get(t, h, Path, false)
+	if prov.calls != 1 {
+		t.Fatalf("provider calls = %d, want 1 (cached)", prov.calls)
+	}
+	src.ver++
+	mock.ExpectQuery("SELECT alias_path, target_path, updated_at FROM route_alias").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "updated_at"}))
+	get(t, h, Path, false)
+	if prov.calls != 2 {
+		t.Fatalf("provider calls = %d, want 2 after a version bump", prov.calls)
+	}
+}
+
+func TestHandler_IndexWhenLarge(t *testing.T) {
+	maxURLs = 2
+	t.Cleanup(func() { maxURLs = MaxURLsPerFile })
+
+	src, _ := newSource(t, routing.RouteModeAbsolute) // no alias queries
+	var entries []component.SitemapEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, component.SitemapEntry{Loc: fmt.Sprintf("/p/%d", i)})
+	}
+	h := New(src, []component.SitemapProvider{&countingProvider{entries: entries}},
+		Options{BaseURL: "https://example.com"})
+
+	_, body := get(t, h, Path, false)
+	var idx sitemapIndex
+	if err := xml.Unmarshal(body, &idx); err != nil || len(idx.Sitemaps) != 3 {
+		t.Fatalf("index = %+v, %v\n%s", idx, err, body)
+	}
+	if idx.Sitemaps[1].Loc != "https://example.com/sitemap-2.xml" {
+		t.Fatalf("part loc = %q", idx.Sitemaps[1].Loc)
+	}
+
+	total := 0
+	for i := 1; i <= 3; i++ {
+		code, body := get(t, h, fmt.Sprintf("/sitemap-%d.xml", i), true)
+		var set urlset
+		if code != http.StatusOK || xml.Unmarshal(body, &set) != nil {
+			t.Fatalf("part %d = %d\n%s", i, code, body)
+		}
+		total += len(set.URLs)
+	}
+	if total != 5 {
+		t.Fatalf("parts hold %d urls, want 5", total)
+	}
+	for _, p := range []string{"/sitemap-4.xml", "/sitemap-0.xml", "/sitemap-x.xml"} {
+		if code, _ := get(t, h, p, false); code != http.StatusNotFound {
+			t.Errorf("%s = %d, want 404", p, code)
+		}
+	}
+}
+
+func TestHandler_LegacyAliasColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &fakeSource{mode: routing.RouteModeAliasOnly, db: sqlx.NewDb(db, "mysql"),
+		cache: routing.NewAliasCache(db, time.Hour)}
+	noKind := errors.New("Error 1054 (42S22): Unknown column 'kind' in 'field list'")
+
+	// A tenant installed before kind and canonical existed.
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, kind, canonical FROM route_alias")).
+		WillReturnError(noKind)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, '', canonical FROM route_alias")).
+		WillReturnError(errors.New("Error 1054 (42S22): Unknown column 'canonical' in 'field list'"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, '', 0 FROM route_alias")).
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "kind", "canonical"}).
+			AddRow("/about", "/content/page/view/about", "", 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM route_alias WHERE kind = 'rewrite'")).
+		WillReturnError(noKind)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT alias_path, target_path, updated_at FROM route_alias") + "$").
+		WillReturnRows(sqlmock.NewRows([]string{"alias_path", "target_path", "updated_at"}).
+			AddRow("/about", "/content/page/view/about", updated))
+
+	code, body := get(t, New(src, nil, Options{BaseURL: "https://example.com"}), Path, false)
+	var set urlset
+	if code != http.StatusOK || xml.Unmarshal(body, &set) != nil ||
+		len(set.URLs) != 1 || set.URLs[0].Loc != "https://example.com/about" {
+		t.Fatalf("sitemap = %d\n%s", code, body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
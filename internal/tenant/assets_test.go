@@ -0,0 +1,128 @@
+// internal/tenant/assets_test.go
+//
+// Unit-tests for /assets/* serving.
+//
+// Context
+// -------
+// Each test runs in a temp directory holding themes/t/assets and
+// sites/a.example/assets, then drives ServeAsset through a bare chi router
+// so the "*" URL param is populated exactly as in production.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yanizio/adept/internal/tenant/meta"
+	"github.com/yanizio/adept/internal/theme"
+)
+
+func writeAsset(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assetRouter(t *testing.T) http.Handler {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	writeAsset(t, "themes/t/assets/css/app.css", "theme-css")
+	writeAsset(t, "themes/t/assets/logo.png", "theme-logo")
+	writeAsset(t, "sites/a.example/assets/logo.png", "site-logo")
+	writeAsset(t, "themes/t/secret.txt", "nope")
+
+	ten := &Tenant{Meta: meta.Record{Theme: "t"}, host: "a.example"}
+	r := chi.NewRouter()
+	r.Get(assetPrefix+"*", ten.ServeAsset)
+	return r
+}
+
+func getAsset(h http.Handler, target string, hdr map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestServeAsset_OverrideChain(t *testing.T) {
+	h := assetRouter(t)
+
+	if rr := getAsset(h, "/assets/logo.png", nil); rr.Body.String() != "site-logo" {
+		t.Fatalf("site override not preferred: %q", rr.Body.String())
+	}
+	rr := getAsset(h, "/assets/css/app.css", nil)
+	if rr.Code != http.StatusOK || rr.Body.String() != "theme-css" {
+		t.Fatalf("theme fallback: %d %q", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != cacheRevalidate {
+		t.Fatalf("Cache-Control without fingerprint = %q", cc)
+	}
+}
+
+func TestServeAsset_FingerprintAndETag(t *testing.T) {
+	h := assetRouter(t)
+
+	rr := getAsset(h, "/assets/css/app.css?v=abc123", nil)
+	if cc := rr.Header().Get("Cache-Control"); cc != cacheImmutable {
+		t.Fatalf("Cache-Control = %q", cc)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	rr = getAsset(h, "/assets/css/app.css", map[string]string{"If-None-Match": etag})
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: status %d", rr.Code)
+	}
+}
+
+func TestServeAsset_RefusesTraversalAndDirectories(t *testing.T) {
+	h := assetRouter(t)
+
+	for _, p := range []string{
+		"/assets/../secret.txt",
+		"/assets/%2e%2e/secret.txt",
+		"/assets/css/..%2f..%2fsecret.txt",
+		"/assets/css",
+		"/assets/css/",
+		"/assets/",
+	} {
+		if rr := getAsset(h, p, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("%s: status %d, body %q", p, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestServeAsset_ThemeAssetFuncAgrees(t *testing.T) {
+	h := assetRouter(t)
+	ten := &Tenant{Meta: meta.Record{Theme: "t"}, host: "a.example"}
+	th := theme.New("t", filepath.Join(themeBaseDir, "t"), nil)
+	ten.useAssets(th)
+
+	url := th.AssetFunc("logo.png") // site override wins here too
+	rr := getAsset(h, url, nil)
+	if rr.Body.String() != "site-logo" || rr.Header().Get("Cache-Control") != cacheImmutable {
+		t.Fatalf("GET %s = %q, Cache-Control %q", url, rr.Body, rr.Header().Get("Cache-Control"))
+	}
+}
@@ -13,14 +13,18 @@
 // ---------------
 // Structured Zap spans are emitted at DEBUG / INFO / WARN / ERROR levels:
 //
-//   • cache hit / miss
+//   • cache hit / miss, keyed by canonical host (aliases share entries)
 //   • DB-backed load start / success / failure
-//   • host not found
+//   • host not found, and negative-cache hits for hosts recently not found
 //   • idle and LRU evictions (see evictor.go)
 //
 // These JSON lines appear in `/logs/YYYY-MM-DD.log` and, when running in a
 // TTY, on stdout.
 //
+// Prometheus records load latency (tenant_load_duration_seconds) and how
+// many misses ran a load versus waited on one already in flight
+// (tenant_load_singleflight_total), alongside the per-host series.
+//
 // Notes
 // -----
 // • All public methods are concurrency-safe.
@@ -31,6 +35,8 @@ package tenant
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -39,31 +45,103 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/singleflight"
 
+	lru "github.com/yanizio/adept/internal/cache"
 	"github.com/yanizio/adept/internal/metrics"
+	"github.com/yanizio/adept/internal/tenant/meta"
 	"github.com/yanizio/adept/internal/vault"
 )
 
 /*────────────────────────── tunables / errors ──────────────────────────────*/
 
 const (
-	IdleTTL       = 30 * time.Minute // evict tenant after this idle duration
-	MaxEntries    = 100              // 0 disables size eviction
-	EvictInterval = 5 * time.Minute  // evictor scan cadence
+	IdleTTL         = 30 * time.Minute // default idle eviction (config tenant.idle_ttl)
+	MaxEntries      = 100              // default size cap; 0 disables size eviction
+	EvictInterval   = 5 * time.Minute  // default evictor cadence (SetEvictInterval)
+	NegativeTTL     = 30 * time.Second // remember unknown hosts this long
+	RecheckInterval = 30 * time.Second // on-hit site-row freshness check
+	negativeCap     = 4096             // bound on remembered unknown hosts
+	aliasCap        = 4096             // bound on remembered alias hosts
 )
 
 var ErrNotFound = errors.New("tenant not found")
 
+/*────────────────────────────── evict hooks ────────────────────────────────*/
+
+// Packages that keep per-host state outside the Tenant aggregate (view
+// fragment caches, for example) register a hook so the state is dropped
+// together with the tenant.  Hooks run synchronously inside the evictor.
+var (
+	hookMu      sync.RWMutex
+	evictHooks  []func(host string)
+	reloadHooks []func(host string)
+)
+
+// OnEvict registers fn to be called with the host key of every tenant the
+// cache evicts.  Call from init(); fn must be fast and must not call back
+// into the Cache.
+func OnEvict(fn func(host string)) {
+	hookMu.Lock()
+	evictHooks = append(evictHooks, fn)
+	hookMu.Unlock()
+}
+
+// notifyEvict fans host out to every registered hook.
+func notifyEvict(host string) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	for _, fn := range evictHooks {
+		fn(host)
+	}
+}
+
+// OnThemeReload registers fn to be called with the host of every tenant
+// whose theme was reloaded (see Tenant.ReloadTheme).  Same rules as OnEvict.
+func OnThemeReload(fn func(host string)) {
+	hookMu.Lock()
+	reloadHooks = append(reloadHooks, fn)
+	hookMu.Unlock()
+}
+
+// notifyThemeReload fans host out to every reload hook.
+func notifyThemeReload(host string) {
+	hookMu.RLock()
+	defer hookMu.RUnlock()
+	for _, fn := range reloadHooks {
+		fn(host)
+	}
+}
+
 /*────────────────────────────── Cache type ─────────────────────────────────*/
 
 type Cache struct {
 	globalDB    *sqlx.DB
-	vault       *vault.Client
+	globalRead  *sqlx.DB // optional replica for read-only site queries
+	vault       vault.Secrets
 	log         *zap.SugaredLogger
 	sfg         singleflight.Group // coalesces concurrent loads per host
 	m           sync.Map           // host → *entry
 	evictTicker *time.Ticker
+	stop        chan struct{} // closed by Stop; ends evictLoop
+	stopOnce    sync.Once
 	idleTTL     time.Duration
 	maxEntries  int
+	gen         uint64 // bumped by Invalidate; see invalidate.go
+
+	// Host aliases: request host → canonical host (see hostalias.go).
+	aliasMu sync.Mutex
+	aliases *lru.LRU
+
+	// Negative cache: host → expiry for hosts that resolved to ErrNotFound.
+	// Bounded LRU, so a flood of random Host headers cannot grow it.
+	negMu  sync.Mutex
+	neg    *lru.LRU
+	negTTL time.Duration
+	now    func() time.Time // stubbed in tests
+
+	recheckEvery atomic.Int64 // on-hit site-row freshness check (ns); 0 = off
+
+	// loadSite, stubbed in tests.
+	loader func(context.Context, *sqlx.DB, *meta.Record, vault.Secrets, *zap.SugaredLogger) (*Tenant, error)
 }
 
 // New builds a Cache and starts its background evictor goroutine.
@@ -72,7 +150,7 @@ func New(
 	idleTTL time.Duration,
 	maxEntries int,
 	lg *zap.SugaredLogger,
-	vcli *vault.Client,
+	vcli vault.Secrets,
 ) *Cache {
 
 	c := &Cache{
@@ -81,7 +159,14 @@ func New(
 		idleTTL:    idleTTL,
 		maxEntries: maxEntries,
 		log:        lg,
+		stop:       make(chan struct{}),
+		neg:        lru.New(negativeCap),
+		aliases:    lru.New(aliasCap),
+		negTTL:     NegativeTTL,
+		now:        time.Now,
+		loader:     loadSite,
 	}
+	c.recheckEvery.Store(int64(RecheckInterval))
 	c.evictTicker = time.NewTicker(EvictInterval)
 	go c.evictLoop()
 
@@ -94,27 +179,100 @@ func New(
 
 /*────────────────────────────── cache lookup ──────────────────────────────*/
 
-// Get looks up host in the cache, loading it on demand.
+// Get looks up host in the cache, loading it on demand.  Aliases and
+// wildcard matches share the canonical host's Tenant (see hostalias.go); a
+// redirect-flagged alias returns a *Redirect error instead of a Tenant.
 func (c *Cache) Get(host string) (*Tenant, error) {
 	lookup := resolveLookupHost(host) // alias “localhost” → real host
 
-	// Fast path.
-	if v, ok := c.m.Load(host); ok {
-		ent := v.(*entry)
-		atomic.StoreInt64(&ent.lastSeen, time.Now().UnixNano())
+	// Fast path, directly or through a remembered alias.
+	key := host
+	if tgt, ok := c.aliasOf(host); ok {
+		if tgt.redirect {
+			return nil, &Redirect{Host: tgt.canonical}
+		}
+		key = tgt.canonical
+	}
+	if ten, ok := c.hit(key); ok {
 		c.log.Debugw("tenant cache hit",
-			"tenant", host,
+			"tenant", key,
 			"lookup_host", lookup,
 		)
-		return ent.tenant, nil
+		return ten, nil
+	}
+
+	// Known-missing host: answer without touching the DB.
+	if c.knownMissing(host) {
+		c.log.Debugw("tenant negative cache hit", "tenant", host)
+		return nil, ErrNotFound
 	}
 
-	// Slow path via single-flight.
+	// Slow path via single-flight: resolve host to its site row, then load
+	// the canonical tenant unless another alias already has.
+	var ran bool // false for callers that only waited on another's flight
 	v, err, _ := c.sfg.Do(host, func() (interface{}, error) {
+		ran = true
+		rec, redirect, err := resolveSite(context.Background(), c.globalDB, host)
+		switch {
+		case err == ErrNotFound:
+			c.log.Warnw("tenant not found",
+				"tenant", host,
+				"lookup_host", lookup,
+			)
+			metrics.TenantLoadFailed(metrics.UnknownHost)
+			c.rememberMissing(host)
+			return nil, err
+		case err != nil:
+			c.log.Errorw("tenant load error",
+				"tenant", host,
+				"lookup_host", lookup,
+				"err", err,
+			)
+			metrics.TenantLoadFailed(metrics.UnknownHost) // unresolved: no label slot
+			return nil, err
+		}
+
+		if rec.Host != host {
+			c.rememberAlias(host, rec.Host, redirect)
+			if redirect {
+				return nil, &Redirect{Host: rec.Host}
+			}
+		}
+		return c.load(rec, lookup)
+	})
+	if !ran {
+		metrics.TenantLoadFlights.WithLabelValues(metrics.FlightCoalesced).Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Tenant), nil
+}
+
+// hit returns the cached tenant for key, touching lastSeen and scheduling
+// the on-hit freshness check.
+func (c *Cache) hit(key string) (*Tenant, bool) {
+	v, ok := c.m.Load(key)
+	if !ok {
+		return nil, false
+	}
+	ent := v.(*entry)
+	atomic.StoreInt64(&ent.lastSeen, c.now().UnixNano())
+	c.maybeRecheck(key, ent) // async, throttled; see invalidate.go
+	return ent.tenant, true
+}
+
+// load builds the tenant for rec, single-flighted on the canonical host so
+// concurrent requests through different aliases load it once.
+func (c *Cache) load(rec *meta.Record, lookup string) (*Tenant, error) {
+	host := rec.Host
+	var ran bool
+	v, err, _ := c.sfg.Do("load:"+host, func() (interface{}, error) {
+		ran = true
 		// Double-check after barrier.
 		if v, ok := c.m.Load(host); ok {
 			ent := v.(*entry)
-			atomic.StoreInt64(&ent.lastSeen, time.Now().UnixNano())
+			atomic.StoreInt64(&ent.lastSeen, c.now().UnixNano())
 			c.log.Debugw("tenant cache hit (after barrier)",
 				"tenant", host,
 				"lookup_host", lookup,
@@ -128,26 +286,32 @@ func (c *Cache) Get(host string) (*Tenant, error) {
 			"lookup_host", lookup,
 		)
 
-		ten, err := loadSite(context.Background(), c.globalDB, host, c.vault)
-		switch {
-		case err == ErrNotFound:
-			c.log.Warnw("tenant not found",
-				"tenant", host,
-				"lookup_host", lookup,
-			)
-			metrics.TenantLoadErrorsTotal.Inc()
-			return nil, err
-		case err != nil:
+		gen := atomic.LoadUint64(&c.gen)
+		metrics.TenantLoadFlights.WithLabelValues(metrics.FlightLoad).Inc()
+		ten, err := c.loader(context.Background(), c.readDB(), rec, c.vault, c.log)
+		metrics.TenantLoadDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
 			c.log.Errorw("tenant load error",
 				"tenant", host,
 				"lookup_host", lookup,
 				"err", err,
 			)
-			metrics.TenantLoadErrorsTotal.Inc()
+			metrics.TenantLoadFailed(host)
 			return nil, err
 		}
+		ten.siteDB = c.globalDB // not yet shared: RebuildRouter reads the primary
+
+		// Invalidated mid-load: serve this caller, but do not cache what may
+		// be pre-invalidation state (see invalidate.go).
+		if atomic.LoadUint64(&c.gen) != gen {
+			c.log.Infow("tenant load raced invalidation – not cached", "tenant", host)
+			time.AfterFunc(InvalidateGrace, func() { _ = ten.Close() })
+			return ten, nil
+		}
 
-		ent := &entry{tenant: ten, lastSeen: time.Now().UnixNano()}
+		now := c.now().UnixNano()
+		ent := &entry{tenant: ten, lastSeen: now, loadedAt: now, pinned: ten.pinned()}
+		ent.checkedAt = now
 		c.m.Store(host, ent)
 
 		c.log.Infow("tenant online",
@@ -155,12 +319,157 @@ func (c *Cache) Get(host string) (*Tenant, error) {
 			"lookup_host", lookup,
 			"load_ms", time.Since(start).Milliseconds(),
 		)
-		metrics.TenantLoadTotal.Inc()
-		metrics.ActiveTenants.Inc()
+		metrics.TenantLoaded(host)
 		return ten, nil
 	})
+	if !ran { // another alias of host was already loading it
+		metrics.TenantLoadFlights.WithLabelValues(metrics.FlightCoalesced).Inc()
+	}
 	if err != nil {
 		return nil, err
 	}
 	return v.(*Tenant), nil
 }
+
+/*──────────────────────────── evictor cadence ──────────────────────────────*/
+
+// SetEvictInterval changes how often the evictor scans.  d <= 0 is ignored.
+func (c *Cache) SetEvictInterval(d time.Duration) {
+	if d > 0 {
+		c.evictTicker.Reset(d)
+	}
+}
+
+/*──────────────────────────── read replica ─────────────────────────────────*/
+
+// SetReadDB routes read-only global queries (site_config loads and site
+// listings) to db, a replica of the global database.  Host resolution, the
+// on-hit recheck, and the WatchSites poll stay on the primary so a new or
+// edited site is seen at once.  A replica may lag: a tenant reloaded right
+// after a site_config edit can pick up the old values until replication
+// catches up.  nil disables.
+func (c *Cache) SetReadDB(db *sqlx.DB) { c.globalRead = db }
+
+// readDB returns the global replica when set, else the primary.
+func (c *Cache) readDB() *sqlx.DB {
+	if c.globalRead != nil {
+		return c.globalRead
+	}
+	return c.globalDB
+}
+
+/*──────────────────────────── negative cache ───────────────────────────────*/
+
+// SetNegativeTTL sets how long an unknown host is answered from memory.  A
+// site created meanwhile becomes reachable once its entry lapses.  d <= 0
+// disables negative caching.
+func (c *Cache) SetNegativeTTL(d time.Duration) {
+	c.negMu.Lock()
+	c.negTTL = d
+	c.neg = lru.New(negativeCap)
+	c.negMu.Unlock()
+}
+
+// knownMissing reports whether host has a live negative entry, dropping it
+// once expired.
+func (c *Cache) knownMissing(host string) bool {
+	c.negMu.Lock()
+	defer c.negMu.Unlock()
+	v, ok := c.neg.Get(host)
+	if !ok {
+		return false
+	}
+	if c.now().Before(v.(time.Time)) {
+		return true
+	}
+	c.neg.Remove(host)
+	return false
+}
+
+// rememberMissing records host as unknown for negTTL.
+func (c *Cache) rememberMissing(host string) {
+	c.negMu.Lock()
+	defer c.negMu.Unlock()
+	if c.negTTL > 0 {
+		c.neg.Add(host, c.now().Add(c.negTTL))
+	}
+}
+
+/*────────────────────────────── iteration ──────────────────────────────────*/
+
+// Range calls fn for every cached tenant until fn returns false.  It never
+// loads tenants and does not touch lastSeen.
+func (c *Cache) Range(fn func(host string, t *Tenant) bool) {
+	c.m.Range(func(k, v any) bool {
+		return fn(k.(string), v.(*entry).tenant)
+	})
+}
+
+// Stop ends the background evictor and releases its ticker.  It does not
+// close tenant pools (see CloseAll) and is safe to call more than once.
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() {
+		c.evictTicker.Stop()
+		close(c.stop)
+		c.log.Infow("tenant evictor stopped")
+	})
+}
+
+// CloseAll closes every cached tenant's DB pool and empties the cache.  It
+// is meant for graceful shutdown, after the HTTP server has drained, and
+// returns the number of tenants closed.  Close errors are logged, not
+// returned, so one bad pool never blocks the rest.
+func (c *Cache) CloseAll() int {
+	var n int
+	c.m.Range(func(k, v any) bool {
+		host := k.(string)
+		if err := v.(*entry).tenant.Close(); err != nil {
+			c.log.Warnw("tenant close error", "tenant", host, "err", err)
+		}
+		c.m.Delete(k)
+		metrics.TenantUnloaded(host)
+		n++
+		return true
+	})
+	c.log.Infow("tenant cache drained", "tenants", n)
+	return n
+}
+
+// KnownHost returns nil when host is cached or resolves to an active site,
+// exactly or by alias, and never loads the tenant.  Its signature matches
+// autocert.HostPolicy, so ACME certificates are issued only for hosts
+// Adept actually serves.
+//
+// A host covered only by a wildcard site row is refused: otherwise any
+// label a client puts in SNI would mint a certificate, so wildcard tenants
+// need a wildcard certificate provisioned outside autocert.  Unknown hosts
+// share Get's negative cache, so a scanner cycling names costs one lookup
+// per name per NegativeTTL.
+func (c *Cache) KnownHost(ctx context.Context, host string) error {
+	if strings.HasPrefix(host, "*.") {
+		return fmt.Errorf("tenant: wildcard host %q", host)
+	}
+	if _, ok := c.m.Load(host); ok {
+		return nil
+	}
+	if tgt, ok := c.aliasOf(host); ok {
+		if strings.HasPrefix(tgt.canonical, "*.") {
+			return fmt.Errorf("tenant: host %q only matches wildcard site %q", host, tgt.canonical)
+		}
+		return nil
+	}
+	if c.knownMissing(host) {
+		return fmt.Errorf("tenant: unknown host %q", host)
+	}
+	rec, _, err := resolveSite(ctx, c.globalDB, host)
+	switch {
+	case err == ErrNotFound:
+		c.rememberMissing(host)
+		return fmt.Errorf("tenant: unknown host %q", host)
+	case err != nil:
+		return fmt.Errorf("tenant: host %q: %w", host, err)
+	case strings.HasPrefix(rec.Host, "*."):
+		return fmt.Errorf("tenant: host %q only matches wildcard site %q", host, rec.Host)
+	}
+	return nil
+}
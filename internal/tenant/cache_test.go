@@ -0,0 +1,231 @@
+// internal/tenant/cache_test.go
+//
+// Unit-tests for Cache.CloseAll, Cache.Stop, the negative cache, and
+// load metrics (per-host series, latency, and single-flight coalescing).
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/metrics"
+	"github.com/yanizio/adept/internal/tenant/meta"
+	"github.com/yanizio/adept/internal/vault"
+)
+
+func TestCloseAll_ClosesEveryPool(t *testing.T) {
+	c := New(nil, time.Hour, 0, zap.NewNop().Sugar(), nil)
+
+	var mocks []sqlmock.Sqlmock
+	for _, host := range []string{"a.example", "b.example"} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectClose()
+		mocks = append(mocks, mock)
+		ten := &Tenant{DB: sqlx.NewDb(db, "mysql"), host: host}
+		c.m.Store(host, &entry{tenant: ten, lastSeen: time.Now().UnixNano()})
+	}
+	c.m.Store("nil-db.example", &entry{tenant: &Tenant{}})
+
+	if n := c.CloseAll(); n != 3 {
+		t.Fatalf("CloseAll = %d, want 3", n)
+	}
+	for i, m := range mocks {
+		if err := m.ExpectationsWereMet(); err != nil {
+			t.Fatalf("pool %d: %v", i, err)
+		}
+	}
+	c.Range(func(h string, _ *Tenant) bool {
+		t.Fatalf("cache not empty: %s", h)
+		return false
+	})
+}
+
+func TestStop_IdempotentAndEndsEvictor(t *testing.T) {
+	c := New(nil, time.Hour, 0, zap.NewNop().Sugar(), nil)
+	done := make(chan struct{})
+	go func() { c.evictLoop(); close(done) }() // second loop we can observe
+
+	c.Stop()
+	c.Stop() // must not panic on double close
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("evictLoop still running after Stop")
+	}
+}
+
+// negCache returns a Cache over a sqlmock global DB with a controllable
+// clock.  The evictor is stopped so it never races the test.
+func negCache(t *testing.T) (*Cache, sqlmock.Sqlmock, *time.Time) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(sqlx.NewDb(db, "mysql"), time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	clock := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return clock }
+	c.SetNegativeTTL(time.Minute)
+	return c, mock, &clock
+}
+
+// expectMiss queues the exact and the alias/wildcard lookups for host, both
+// empty.
+func expectMiss(mock sqlmock.Sqlmock, host string) {
+	mock.ExpectQuery("FROM\\s+site").WithArgs(host).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("FROM\\s+site_host_alias").WithArgs(host).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestGet_NegativeCacheSkipsDB(t *testing.T) {
+	c, mock, _ := negCache(t)
+	expectMiss(mock, "probe.example")
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get("probe.example"); err != ErrNotFound {
+			t.Fatalf("lookup %d: err = %v, want ErrNotFound", i, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected exactly one resolution: %v", err)
+	}
+}
+
+func TestGet_NegativeEntryExpires(t *testing.T) {
+	c, mock, clock := negCache(t)
+	expectMiss(mock, "new.example")
+	if _, err := c.Get("new.example"); err != ErrNotFound {
+		t.Fatalf("first lookup: %v", err)
+	}
+
+	// The site is created, then the TTL lapses: the host must reach the
+	// loader again.  site_config fails so the load stops short of Vault.
+	*clock = clock.Add(time.Minute + time.Second)
+	mock.ExpectQuery("FROM\\s+site").WithArgs("new.example").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "host", "theme"}).
+			AddRow(7, "new.example", "base"))
+	mock.ExpectQuery("FROM\\s+site_config").WillReturnError(sql.ErrConnDone)
+
+	if _, err := c.Get("new.example"); err == ErrNotFound {
+		t.Fatal("negative entry outlived its TTL")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet_DBErrorIsNotNegativelyCached(t *testing.T) {
+	c, mock, _ := negCache(t)
+	mock.ExpectQuery("FROM\\s+site").WillReturnError(sql.ErrConnDone)
+	if _, err := c.Get("flaky.example"); err == nil || err == ErrNotFound {
+		t.Fatalf("err = %v, want a DB error", err)
+	}
+	if c.knownMissing("flaky.example") {
+		t.Fatal("transient DB error cached as unknown host")
+	}
+}
+
+func TestGet_NotFoundCountsUnknownHost(t *testing.T) {
+	c, mock, _ := negCache(t)
+	expectMiss(mock, "nosuch.example")
+	unknown := metrics.TenantHostLoadErrorsTotal.WithLabelValues(metrics.UnknownHost)
+	before := testutil.ToFloat64(unknown)
+
+	if _, err := c.Get("nosuch.example"); err != ErrNotFound {
+		t.Fatalf("err = %v", err)
+	}
+	if got := testutil.ToFloat64(unknown); got != before+1 {
+		t.Fatalf("unknown errors = %v, want %v", got, before+1)
+	}
+	if metrics.TenantHostLoadErrorsTotal.DeleteLabelValues("nosuch.example") {
+		t.Fatal("unknown host got its own label")
+	}
+}
+
+// histCount returns the number of observations in h.
+func histCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestGet_LoadTimedAndStampedeCoalesced(t *testing.T) {
+	c, mock, _ := negCache(t)
+	mock.ExpectQuery("FROM\\s+site").WithArgs("stampede.example").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "host"}).AddRow(9, "stampede.example"))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	c.loader = func(_ context.Context, _ *sqlx.DB, rec *meta.Record, _ vault.Secrets, _ *zap.SugaredLogger) (*Tenant, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return &Tenant{Meta: *rec, host: rec.Host}, nil
+	}
+
+	loads := metrics.TenantLoadFlights.WithLabelValues(metrics.FlightLoad)
+	coalesced := metrics.TenantLoadFlights.WithLabelValues(metrics.FlightCoalesced)
+	loads0, coalesced0 := testutil.ToFloat64(loads), testutil.ToFloat64(coalesced)
+	timed0 := histCount(t, metrics.TenantLoadDuration)
+
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Get("stampede.example")
+			errs <- err
+		}()
+	}
+	<-started
+	time.Sleep(50 * time.Millisecond) // let the other callers join the flight
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("loader ran %d times, want 1", n)
+	}
+	if got := testutil.ToFloat64(loads) - loads0; got != 1 {
+		t.Fatalf("load flights = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(coalesced) - coalesced0; got != callers-1 {
+		t.Fatalf("coalesced flights = %v, want %d", got, callers-1)
+	}
+	if got := histCount(t, metrics.TenantLoadDuration) - timed0; got != 1 {
+		t.Fatalf("load duration observations = %d, want 1", got)
+	}
+}
@@ -0,0 +1,113 @@
+// internal/tenant/configcheck.go
+//
+// Load-time check of site_config against declared keys.
+//
+// Context
+// -------
+// checkSiteConfig compares a tenant's rows with component.ConfigKeys() and
+// returns one human-readable problem per key:
+//
+//   - unknown key      → `unknown key "auth.max_atempts" (did you mean
+//     "auth.max_attempts"?)`
+//   - unparsable value → `key "auth.lockout_window" = "15" is not a duration`
+//
+// loadSite logs each problem at WARN.  The check never fails a load, so an
+// experimental or not-yet-declared key is flagged but still usable.
+//
+// Notes
+// -----
+// • Suggestions use Levenshtein distance ≤ 2 against declared names.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+// Keys read by the tenant package itself.
+func init() {
+	component.DeclareConfig(
+		component.ConfigKey{Name: "head.title_suffix", Type: component.ConfigString},
+	)
+}
+
+// checkSiteConfig returns problems sorted by key; nil means clean.
+func checkSiteConfig(cfg SiteConfig, decl map[string]component.ConfigKey) []string {
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []string
+	for _, k := range keys {
+		d, ok := decl[k]
+		if !ok {
+			msg := fmt.Sprintf("unknown key %q", k)
+			if s := suggest(k, decl); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			out = append(out, msg)
+			continue
+		}
+		if !parses(d.Type, cfg[k]) {
+			out = append(out, fmt.Sprintf("key %q = %q is not a %s", k, cfg[k], d.Type))
+		}
+	}
+	return out
+}
+
+// parses reports whether v is valid for t.  Empty values are treated as
+// unset and always pass.
+func parses(t component.ConfigType, v string) bool {
+	if v == "" {
+		return true
+	}
+	var err error
+	switch t {
+	case component.ConfigInt:
+		_, err = strconv.Atoi(v)
+	case component.ConfigBool:
+		_, err = strconv.ParseBool(v)
+	case component.ConfigDuration:
+		_, err = time.ParseDuration(v)
+	}
+	return err == nil
+}
+
+// suggest returns the closest declared name within distance 2, or "".
+func suggest(k string, decl map[string]component.ConfigKey) string {
+	best, bestD := "", 3
+	for name := range decl {
+		if d := levenshtein(k, name); d < bestD || (d == bestD && name < best) {
+			best, bestD = name, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
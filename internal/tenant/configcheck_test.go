@@ -0,0 +1,41 @@
+// internal/tenant/configcheck_test.go
+//
+// Unit-tests for site_config schema checks.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+func TestCheckSiteConfig(t *testing.T) {
+	decl := map[string]component.ConfigKey{
+		"auth.max_attempts":   {Name: "auth.max_attempts", Type: component.ConfigInt},
+		"auth.lockout_window": {Name: "auth.lockout_window", Type: component.ConfigDuration},
+		"head.title_suffix":   {Name: "head.title_suffix"},
+	}
+	cfg := SiteConfig{
+		"auth.max_atempts":    "5",     // typo
+		"auth.lockout_window": "15",    // missing unit
+		"head.title_suffix":   "Adept", // fine
+		"experimental.thing":  "on",    // unknown, nothing close
+		"auth.max_attempts":   "",      // empty counts as unset
+	}
+
+	got := checkSiteConfig(cfg, decl)
+	want := []string{
+		`key "auth.lockout_window" = "15" is not a duration`,
+		`unknown key "auth.max_atempts" (did you mean "auth.max_attempts"?)`,
+		`unknown key "experimental.thing"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("problems =\n%q\nwant\n%q", got, want)
+	}
+}
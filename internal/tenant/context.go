@@ -5,16 +5,30 @@
 // Context
 // -------
 // Components and Widgets need a shared bundle of request-scoped data—URL
-// parts, <head> builder, parsed User-Agent—without reaching back into
-// *http.Request for every field.  `tenant.Context` carries this data and
-// is created once at the top of the handler stack.
+// parts, <head> builder, parsed User-Agent, Geo, the session user, and the
+// *Tenant itself—without reaching back into *http.Request for every field.
+// `tenant.Context` carries this data and is created once at the top of the
+// handler stack.  It also satisfies widget.Context, so widgets receive it
+// as a typed value.
+//
+// The tenant router builds it right after requestinfo.Enrich and the
+// session user lookup (bindUser), and stashes it in the request context
+// (bindContext).  NewContext(r) then returns that bundle, with Request and
+// User refreshed from r, instead of building a second one, so handlers
+// calling it directly keep working and share the request's single <head>
+// builder.
 //
 // In addition, other middleware layers (ACL, alias rewrite) need a way to
 // retrieve the *Tenant aggregate from any `context.Context` without causing
 // import cycles.  We therefore provide:
 //
-//     ctx = tenant.WithContext(r.Context(), t)   // set by root router
+//     ctx = tenant.Bind(r.Context(), t)          // set by root handler
 //     t   = tenant.FromContext(r.Context())      // used downstream
+//     db  = database.Conn(r.Context())           // t's pool
+//
+// Bind is WithContext plus database.WithTenant(ctx, host), so code that
+// only sees a context.Context (form store actions) reaches the tenant's
+// pool; the loader registers it under the same host.
 //
 // Notes
 // -----
@@ -27,7 +41,12 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/yanizio/adept/internal/auth"
+	"github.com/yanizio/adept/internal/database"
 	"github.com/yanizio/adept/internal/head"
+	"github.com/yanizio/adept/internal/requestinfo"
+	"github.com/yanizio/adept/internal/routing"
+	"github.com/yanizio/adept/internal/session"
 	"github.com/yanizio/adept/internal/ua"
 )
 
@@ -37,21 +56,132 @@ import (
 
 // Context bundles request-scoped helpers for Components and Widgets.
 type Context struct {
-	Request *http.Request // Original HTTP request (read-only)
-	Head    *head.Builder // Accumulates <title>, meta tags, etc.
-	URL     URLInfo       // Canonicalised URL parts
-	UA      ua.Info       // Parsed user-agent
-	// Geo, User, Session will be added later.
+	Request *http.Request   // Original HTTP request (read-only)
+	Tenant  *Tenant         // Tenant serving the request; nil outside one
+	Head    *head.Builder   // Accumulates <title>, meta tags, etc.
+	URL     URLInfo         // Canonicalised URL parts
+	UA      ua.Info         // Parsed user-agent
+	Geo     requestinfo.Geo // Client IP, country, and city (requestinfo)
+	User    User            // Session user; zero when anonymous
+}
+
+// User is the visitor as far as the session knows.
+type User struct {
+	ID    int64  // auth.UserID; 0 when the request carries none
+	Email string // session email; "" when logged out
 }
 
-// NewContext builds the per-request helper bundle.
+// LoggedIn reports whether the request carries a session or a user ID.
+func (u User) LoggedIn() bool { return u.ID != 0 || u.Email != "" }
+
+// NewContext returns the per-request helper bundle.  Inside the tenant
+// router it reuses the bundle bindContext stashed, pointed at r.
+// Otherwise it builds one: the CSP nonce minted by middleware.Security is
+// copied into the Builder, and when the request carries a tenant, its
+// head.title_suffix config seeds the <title> suffix and canonical paths
+// are printed as its aliases (routing.PublicPath).
 func NewContext(r *http.Request) *Context {
-	return &Context{
+	if c, ok := r.Context().Value(ctxBundleKey{}).(*Context); ok {
+		if c.Request == r {
+			return c
+		}
+		cp := *c
+		cp.Request = r
+		cp.User = userOf(r) // middleware after bindContext may set it
+		return &cp
+	}
+
+	c := &Context{
 		Request: r,
+		Tenant:  FromContext(r.Context()),
 		Head:    head.New(),
 		URL:     newURLInfo(r),
-		UA:      ua.Parse(r.UserAgent()),
+		User:    userOf(r),
+	}
+	if info := requestinfo.FromContext(r.Context()); info != nil {
+		c.UA, c.Geo = info.UA, info.Geo
+	} else {
+		c.UA = ua.Parse(r.UserAgent())
+	}
+	c.Head.SetNonce(head.NonceFromContext(r.Context()))
+	if c.Tenant != nil {
+		t := c.Tenant
+		c.Head.SetTitleSuffix(t.Config["head.title_suffix"])
+		c.Head.SetPublicPath(func(p string) string { return routing.PublicPath(t, p) })
+	}
+	return c
+}
+
+// userOf reads the session email and auth user ID from r.
+func userOf(r *http.Request) User {
+	var u User
+	u.ID, _ = auth.UserID(r.Context())
+	u.Email, _ = session.CurrentEmail(r)
+	return u
+}
+
+// bindUser resolves the session email to a user ID (auth.LookupUser) and
+// attaches it with auth.WithUser, so acl guards and Context.User see the
+// signed-in user.  An ID set by earlier middleware is kept.  A lookup
+// error is logged and leaves the request anonymous, which ACL guards
+// answer with 401.
+func (t *Tenant) bindUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.UserID(r.Context()); !ok {
+			if email, ok := session.CurrentEmail(r); ok {
+				id, found, err := auth.LookupUser(r.Context(), email)
+				switch {
+				case err != nil:
+					t.GetLogger().Warnw("session user lookup failed", "err", err)
+				case found:
+					r = r.WithContext(auth.WithUser(r.Context(), id))
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ctxBundleKey stores the *Context built by bindContext.
+type ctxBundleKey struct{}
+
+// bindContext builds the request's Context once and stashes it, so
+// NewContext in Components and Widgets does not rebuild it.
+func bindContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := NewContext(r)
+		r = r.WithContext(context.WithValue(r.Context(), ctxBundleKey{}, c))
+		c.Request = r
+		next.ServeHTTP(w, r)
+	})
+}
+
+//
+// widget.Context implementation
+//
+
+// GetRequest returns the original request.
+func (c *Context) GetRequest() *http.Request { return c.Request }
+
+// GetHead returns the per-request <head> builder.
+func (c *Context) GetHead() *head.Builder { return c.Head }
+
+// GetConfig returns the site_config map of the tenant serving this request,
+// or nil when the request carries no tenant.
+func (c *Context) GetConfig() map[string]string {
+	if c.Tenant != nil {
+		return c.Tenant.Config
+	}
+	return nil
+}
+
+// GetLocale returns the tenant locale, defaulting to the site table's
+// "en_US" when the request carries no tenant.
+func (c *Context) GetLocale() string {
+	if c.Tenant != nil && c.Tenant.Meta.Locale != "" {
+		return c.Tenant.Meta.Locale
 	}
+	return "en_US"
 }
 
 //
@@ -66,6 +196,12 @@ func WithContext(ctx context.Context, t *Tenant) context.Context {
 	return context.WithValue(ctx, ctxTenantKey{}, t)
 }
 
+// Bind returns ctx carrying t for FromContext and tagged with t's host for
+// database.Conn and ConnRead.  The root handler calls it once per request.
+func Bind(ctx context.Context, t *Tenant) context.Context {
+	return database.WithTenant(WithContext(ctx, t), t.host)
+}
+
 // FromContext retrieves the *Tenant pointer or nil if absent.
 func FromContext(ctx context.Context) *Tenant {
 	if v := ctx.Value(ctxTenantKey{}); v != nil {
@@ -0,0 +1,61 @@
+// internal/tenant/context_test.go
+//
+// Unit-tests for the per-request Context bundle and bindContext.
+
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yanizio/adept/internal/auth"
+	"github.com/yanizio/adept/internal/requestinfo"
+	"github.com/yanizio/adept/internal/session"
+)
+
+func TestNewContext_BoundOncePerRequest(t *testing.T) {
+	ten := &Tenant{Config: map[string]string{"head.title_suffix": " | Site"}}
+
+	var bound, direct, later *Context
+	var reqAfterAuth *http.Request
+	h := requestinfo.Enrich(bindContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bound = r.Context().Value(ctxBundleKey{}).(*Context)
+		direct = NewContext(r)
+		// A component middleware attaching the user after the bundle exists.
+		reqAfterAuth = r.WithContext(auth.WithUser(r.Context(), 42))
+		later = NewContext(reqAfterAuth)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "http://ctx.example/page", nil)
+	req.RemoteAddr = "203.0.113.9:5555"
+	session.Inject(req, "ada@example.com")
+	req = req.WithContext(WithContext(req.Context(), ten))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if direct != bound {
+		t.Fatal("NewContext rebuilt the bundle bindContext stashed")
+	}
+	if bound.Tenant != ten || bound.GetConfig()["head.title_suffix"] != " | Site" {
+		t.Fatalf("Tenant = %p, want %p", bound.Tenant, ten)
+	}
+	if bound.Geo.IP.String() != "203.0.113.9" {
+		t.Fatalf("Geo = %+v, want the client IP from requestinfo", bound.Geo)
+	}
+	if bound.User != (User{Email: "ada@example.com"}) || !bound.User.LoggedIn() {
+		t.Fatalf("User = %+v", bound.User)
+	}
+	if later.Request != reqAfterAuth || later.Head != bound.Head || later.User.ID != 42 {
+		t.Fatalf("later = %+v; want same Head, new Request, and user 42", later)
+	}
+}
+
+func TestNewContext_Standalone(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://plain.example/", nil)
+	req = req.WithContext(context.Background())
+	c := NewContext(req)
+	if c.Tenant != nil || c.User.LoggedIn() || c.GetLocale() != "en_US" || c.Head == nil {
+		t.Fatalf("standalone Context = %+v", c)
+	}
+}
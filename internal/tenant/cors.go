@@ -0,0 +1,141 @@
+// internal/tenant/cors.go
+//
+// CORS headers and automatic OPTIONS handling.
+//
+// Context
+// -------
+// Components register GET and POST handlers, never OPTIONS, so without help
+// a browser preflight lands in the 405 handler and the cross-origin call
+// fails.  chi reports a known path with an unregistered method as "method
+// not allowed", which is exactly the hook needed: methodNotAllowed hands
+// OPTIONS to handleOptions, which answers from the same probe that builds
+// the Allow header (notallowed.go), so the advertised methods are the ones
+// actually registered for the path.
+//
+// Workflow
+// --------
+//  1. cors (router middleware) adds Access-Control-Allow-Origin and friends
+//     to actual requests from an allowed Origin.
+//  2. OPTIONS with Origin and Access-Control-Request-Method is a preflight:
+//     204 with Allow-Methods (the registered ones), Allow-Headers, and
+//     Max-Age when the origin and method are allowed; a bare 204 otherwise,
+//     which the browser treats as a refusal.
+//  3. Any other OPTIONS gets 204 and an Allow header (RFC 9110 §9.3.7).
+//
+// Per-tenant keys (site_config)
+// -----------------------------
+//   - cors.allowed_origins     comma list of origins, or "*"; empty (the
+//                              default) disables CORS headers entirely
+//   - cors.allowed_headers     request headers a preflight may ask for
+//   - cors.allow_credentials   send Allow-Credentials to origins listed by
+//                              name; never to an origin matched only by "*"
+//   - cors.max_age             how long browsers may cache a preflight
+//
+// Notes
+// -----
+// • A Component that registers its own OPTIONS route keeps full control;
+//   chi only reaches handleOptions when none exists.
+// • "*" with allow_credentials would let any site make credentialed calls,
+//   so a wildcard match always answers a literal "*" without credentials,
+//   which browsers treat as a refusal for credentialed requests.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+const defaultCORSHeaders = "Content-Type, Authorization"
+
+func init() {
+	component.DeclareConfig(
+		component.ConfigKey{Name: "cors.allowed_origins"},
+		component.ConfigKey{Name: "cors.allowed_headers", Default: defaultCORSHeaders},
+		component.ConfigKey{Name: "cors.allow_credentials", Type: component.ConfigBool, Default: "false"},
+		component.ConfigKey{Name: "cors.max_age", Type: component.ConfigDuration, Default: "10m"},
+	)
+}
+
+// corsOrigin returns the Access-Control-Allow-Origin value for origin, or
+// "" when the tenant does not allow it.  An origin listed by name wins over
+// "*", so it can still receive credentials.
+func (t *Tenant) corsOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	allow := ""
+	for _, o := range strings.Split(t.Config.String("cors.allowed_origins", ""), ",") {
+		switch o = strings.TrimSpace(o); {
+		case strings.EqualFold(o, origin):
+			return origin
+		case o == "*":
+			allow = "*"
+		}
+	}
+	return allow
+}
+
+// setCORS writes the headers shared by actual and preflight responses.
+// Credentials go only with an echoed origin, never with "*".
+func (t *Tenant) setCORS(h http.Header, allowOrigin string) {
+	h.Set("Access-Control-Allow-Origin", allowOrigin)
+	if allowOrigin == "*" {
+		return
+	}
+	h.Add("Vary", "Origin")
+	if t.Config.Bool("cors.allow_credentials", false) {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// cors adds CORS headers to actual cross-origin requests.  Preflights are
+// left to handleOptions, which also checks the requested method.
+func (t *Tenant) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isPreflight(r) {
+			if ao := t.corsOrigin(r.Header.Get("Origin")); ao != "" {
+				t.setCORS(w.Header(), ao)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isPreflight reports whether r is a CORS preflight.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// handleOptions answers OPTIONS for a path whose registered methods are
+// allow.  OPTIONS itself is always added to the list.
+func (t *Tenant) handleOptions(w http.ResponseWriter, r *http.Request, allow []string) {
+	if !slices.Contains(allow, http.MethodOptions) {
+		allow = append(allow, http.MethodOptions)
+	}
+	h := w.Header()
+	h.Set("Allow", strings.Join(allow, ", "))
+
+	if isPreflight(r) {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		ao := t.corsOrigin(r.Header.Get("Origin"))
+		reqMethod := r.Header.Get("Access-Control-Request-Method")
+		if ao != "" && slices.Contains(allow, reqMethod) {
+			t.setCORS(h, ao)
+			h.Set("Access-Control-Allow-Methods", strings.Join(allow, ", "))
+			h.Set("Access-Control-Allow-Headers",
+				t.Config.String("cors.allowed_headers", defaultCORSHeaders))
+			maxAge := t.Config.Duration("cors.max_age", 10*time.Minute)
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
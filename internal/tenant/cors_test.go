@@ -0,0 +1,125 @@
+// internal/tenant/cors_test.go
+//
+// Unit-tests for CORS headers and automatic OPTIONS handling.
+//
+// Notes
+// -----
+// • Routers come from notAllowedRouter (notallowed_test.go) with the cors
+//   middleware in front, as in Tenant.Router.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func corsRouter(cfg SiteConfig) http.Handler {
+	ten := &Tenant{Config: cfg}
+	return ten.cors(notAllowedRouter(ten))
+}
+
+func preflight(h http.Handler, target, origin, method string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, target, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPreflight_ReflectsRegisteredMethods(t *testing.T) {
+	h := corsRouter(SiteConfig{
+		"cors.allowed_origins": "https://app.example",
+		"cors.max_age":         "1h",
+	})
+	rr := preflight(h, "/login", "https://app.example", http.MethodPost)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	for k, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example",
+		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+		"Access-Control-Allow-Headers": defaultCORSHeaders,
+		"Access-Control-Max-Age":       "3600",
+		"Allow":                        "GET, POST, OPTIONS",
+	} {
+		if got := rr.Header().Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestPreflight_Refused(t *testing.T) {
+	allowApp := SiteConfig{"cors.allowed_origins": "https://app.example"}
+	cases := []struct {
+		name, origin, method string
+		cfg                  SiteConfig
+	}{
+		{"unknown origin", "https://evil.example", http.MethodPost, allowApp},
+		{"unregistered method", "https://app.example", http.MethodDelete, allowApp},
+		{"cors disabled", "https://app.example", http.MethodPost, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rr := preflight(corsRouter(c.cfg), "/login", c.origin, c.method)
+			if rr.Code != http.StatusNoContent {
+				t.Fatalf("status = %d", rr.Code)
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Fatalf("Allow-Origin = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestOptions_NonCORS(t *testing.T) {
+	h := corsRouter(nil)
+	rr := serve(h, http.MethodOptions, "/api/items/7", "")
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	if got := rr.Header().Get("Allow"); got != "DELETE, OPTIONS" {
+		t.Fatalf("Allow = %q", got)
+	}
+
+	if rr := serve(h, http.MethodOptions, "/nowhere", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown path: status = %d, want 404", rr.Code)
+	}
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	h := corsRouter(SiteConfig{
+		"cors.allowed_origins":   "*, https://app.example",
+		"cors.allow_credentials": "true",
+	})
+	get := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/login", nil)
+		req.Header.Set("Origin", origin)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("https://app.example")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Fatalf("Allow-Origin = %q, want the echoed origin", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Fatalf("Allow-Credentials = %q", got)
+	}
+	if got := rr.Header().Get("Vary"); got != "Origin" {
+		t.Fatalf("Vary = %q", got)
+	}
+
+	// Matched only by "*": never echoed, never credentialed.
+	rr = get("https://evil.example")
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("wildcard Allow-Origin = %q, want *", got)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("wildcard Allow-Credentials = %q, want none", got)
+	}
+}
@@ -0,0 +1,221 @@
+// internal/tenant/diagnose.go
+//
+// Dry-run tenant loader for diagnostics.
+//
+// Context
+// -------
+// When a tenant will not load, the cache logs one "tenant load error" and
+// answers 500.  Diagnose runs the loadSite steps one by one against the
+// live global DB, Vault, and tenant DB, and reports which step failed and
+// why:
+//
+//   - site    – resolveSite: exact host, then alias and wildcard rows.
+//   - config  – site_config rows, plus checkSiteConfig warnings.
+//   - vault   – the tenant DB password at secret/adept/tenant/<key>/db.
+//   - db      – dial and ping the tenant DB with that password.
+//   - theme   – parse the site theme with the enabled Components' trees.
+//
+// Each step runs even when an earlier one failed, unless it needs that
+// step's output: no site row skips everything, and no password skips the
+// dial.  The theme step falls back to "all Components enabled" when the DB
+// is unreachable and says so in a warning.
+//
+// Notes
+// -----
+// • Nothing is cached or registered: no Tenant is built, the Vault read
+//   bypasses the client cache, and the probe pool is closed before return.
+// • Migrations and Component Init hooks are not run; they have side
+//   effects.
+// • Each step is bounded by DiagnoseStepTimeout.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/database"
+	"github.com/yanizio/adept/internal/tenant/meta"
+	"github.com/yanizio/adept/internal/theme"
+	"github.com/yanizio/adept/internal/vault"
+)
+
+// DiagnoseStepTimeout bounds each Diagnose step.
+const DiagnoseStepTimeout = 5 * time.Second
+
+// Diagnose step names, in the order they run.
+const (
+	StepSite   = "site"
+	StepConfig = "config"
+	StepVault  = "vault"
+	StepDB     = "db"
+	StepTheme  = "theme"
+)
+
+// Diagnose step outcomes.
+const (
+	StepOK      = "ok"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// DiagnoseReport is the outcome of one dry-run load.
+type DiagnoseReport struct {
+	Host      string         `json:"host"`
+	Canonical string         `json:"canonical,omitempty"` // site row host, when it differs
+	Redirect  bool           `json:"redirect,omitempty"`  // host is a 301 alias
+	Theme     string         `json:"theme,omitempty"`
+	OK        bool           `json:"ok"`
+	Steps     []DiagnoseStep `json:"steps"`
+}
+
+// DiagnoseStep is one loadSite step and its outcome.
+type DiagnoseStep struct {
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// Step returns the step called name, or nil.
+func (r *DiagnoseReport) Step(name string) *DiagnoseStep {
+	for i := range r.Steps {
+		if r.Steps[i].Name == name {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// diagDeps are the outside calls Diagnose makes; tests swap them.
+type diagDeps struct {
+	secret func(ctx context.Context, path, key string) (string, error)
+	dial   func(ctx context.Context, dsn string) (*sqlx.DB, error)
+}
+
+// Diagnose dry-runs the cold load of host and reports every step.  The
+// error is reserved for calls that cannot produce a report at all; a
+// failing step is in the report with OK false.
+func Diagnose(ctx context.Context, global *sqlx.DB, host string, vcli vault.Secrets) (*DiagnoseReport, error) {
+	deps := diagDeps{
+		secret: func(ctx context.Context, path, key string) (string, error) {
+			if vcli == nil {
+				return "", errors.New("vault client not configured")
+			}
+			return vcli.GetKV(ctx, path, key, 0)
+		},
+		dial: func(ctx context.Context, dsn string) (*sqlx.DB, error) {
+			return database.OpenProvider(ctx, func() string { return dsn },
+				database.Options{MaxOpenConns: 1, MaxIdleConns: 1})
+		},
+	}
+	return diagnose(ctx, global, host, deps)
+}
+
+// Diagnose dry-runs the cold load of host against this cache's global DB
+// and Vault client.  The cache itself is never touched.
+func (c *Cache) Diagnose(ctx context.Context, host string) (*DiagnoseReport, error) {
+	return Diagnose(ctx, c.globalDB, host, c.vault)
+}
+
+func diagnose(ctx context.Context, global *sqlx.DB, host string, deps diagDeps) (*DiagnoseReport, error) {
+	if global == nil {
+		return nil, errors.New("diagnose: no global database")
+	}
+	rep := &DiagnoseReport{Host: host, Steps: []DiagnoseStep{}}
+	run := func(name string, fn func(ctx context.Context, st *DiagnoseStep) error) bool {
+		st := DiagnoseStep{Name: name, Status: StepOK}
+		sctx, cancel := context.WithTimeout(ctx, DiagnoseStepTimeout)
+		start := time.Now()
+		err := fn(sctx, &st)
+		cancel()
+		st.DurationMS = time.Since(start).Milliseconds()
+		if err != nil {
+			st.Status, st.Error = StepFailed, err.Error()
+		}
+		rep.Steps = append(rep.Steps, st)
+		return err == nil
+	}
+	skip := func(reason string, names ...string) {
+		for _, n := range names {
+			rep.Steps = append(rep.Steps, DiagnoseStep{Name: n, Status: StepSkipped, Error: reason})
+		}
+	}
+
+	// 1. site row
+	var rec *meta.Record
+	if !run(StepSite, func(ctx context.Context, _ *DiagnoseStep) error {
+		var err error
+		rec, rep.Redirect, err = resolveSite(ctx, global, host)
+		return err
+	}) {
+		skip("no site row", StepConfig, StepVault, StepDB, StepTheme)
+		return rep, nil
+	}
+	if rec.Host != host {
+		rep.Canonical = rec.Host
+	}
+	rep.Theme = rec.Theme
+
+	// 2. site_config
+	run(StepConfig, func(ctx context.Context, st *DiagnoseStep) error {
+		cfg, err := meta.ConfigBySite(ctx, global, rec.ID)
+		if err != nil {
+			return err
+		}
+		st.Warnings = checkSiteConfig(cfg, component.ConfigKeys())
+		return nil
+	})
+
+	// 3. Vault password
+	key := sanitizeHost(rec.Host)
+	var pw string
+	vaultOK := run(StepVault, func(ctx context.Context, _ *DiagnoseStep) error {
+		var err error
+		pw, err = deps.secret(ctx, fmt.Sprintf("secret/adept/tenant/%s/db", key), "password")
+		return err
+	})
+
+	// 4. tenant DB
+	var db *sqlx.DB
+	if vaultOK {
+		run(StepDB, func(ctx context.Context, _ *DiagnoseStep) error {
+			var err error
+			db, err = deps.dial(ctx, tenantDSN(rec, key, pw))
+			return err
+		})
+	} else {
+		skip("no database password", StepDB)
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	// 5. theme parse
+	run(StepTheme, func(ctx context.Context, st *DiagnoseStep) error {
+		if db == nil {
+			st.Warnings = append(st.Warnings,
+				"component_acl not read; every Component assumed enabled")
+		}
+		enabled, err := enabledComponents(ctx, db)
+		if err != nil {
+			return err
+		}
+		mgr := theme.Manager{BaseDir: themeBaseDir}
+		_, err = mgr.Load(rec.Theme, themeModules(enabled))
+		return err
+	})
+
+	rep.OK = true
+	for _, st := range rep.Steps {
+		rep.OK = rep.OK && st.Status == StepOK
+	}
+	return rep, nil
+}
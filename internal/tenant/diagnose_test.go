@@ -0,0 +1,161 @@
+// internal/tenant/diagnose_test.go
+//
+// Unit-tests for Diagnose: each failing loadSite step must surface in the
+// report with its error, and the steps that depend on it must be skipped.
+//
+// Notes
+// -----
+// • The global DB is sqlmock; Vault and the tenant dial are swapped through
+//   diagDeps.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// diagGlobal returns a sqlmock global DB answering the site row for host
+// with theme "t".
+func diagGlobal(t *testing.T, host string) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { raw.Close() })
+	mock.ExpectQuery("FROM\\s+site").WithArgs(host).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "host", "theme"}).AddRow(4, host, "t"))
+	return sqlx.NewDb(raw, "mysql"), mock
+}
+
+// okDeps returns a password and a tenant DB that answers component_acl.
+func okDeps(t *testing.T) diagDeps {
+	return diagDeps{
+		secret: func(context.Context, string, string) (string, error) { return "pw", nil },
+		dial: func(context.Context, string) (*sqlx.DB, error) {
+			raw, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.ExpectQuery("SELECT component FROM component_acl").
+				WillReturnRows(sqlmock.NewRows([]string{"component"}))
+			return sqlx.NewDb(raw, "mysql"), nil
+		},
+	}
+}
+
+func wantStep(t *testing.T, rep *DiagnoseReport, name, status, errPart string) {
+	t.Helper()
+	st := rep.Step(name)
+	if st == nil {
+		t.Fatalf("step %s missing from %+v", name, rep.Steps)
+	}
+	if st.Status != status || !strings.Contains(st.Error, errPart) {
+		t.Fatalf("step %s = %s %q, want %s containing %q", name, st.Status, st.Error, status, errPart)
+	}
+}
+
+func TestDiagnose_AllStepsOK(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, filepath.Join(themeBaseDir, "t", "templates", "page.html"), `ok`)
+	global, mock := diagGlobal(t, "diag.example")
+	mock.ExpectQuery("FROM\\s+site_config").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("no.such.key", "1"))
+
+	rep, err := diagnose(context.Background(), global, "diag.example", okDeps(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.OK || len(rep.Steps) != 5 || rep.Theme != "t" {
+		t.Fatalf("report = %+v", rep)
+	}
+	for _, name := range []string{StepSite, StepConfig, StepVault, StepDB, StepTheme} {
+		wantStep(t, rep, name, StepOK, "")
+	}
+	if w := rep.Step(StepConfig).Warnings; len(w) == 0 || !strings.Contains(w[0], "no.such.key") {
+		t.Fatalf("config warnings = %v", w)
+	}
+}
+
+func TestDiagnose_UnknownHost(t *testing.T) {
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	expectMiss(mock, "nope.example")
+
+	rep, err := diagnose(context.Background(), sqlx.NewDb(raw, "mysql"), "nope.example", okDeps(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.OK {
+		t.Fatal("unknown host reported OK")
+	}
+	wantStep(t, rep, StepSite, StepFailed, ErrNotFound.Error())
+	for _, name := range []string{StepConfig, StepVault, StepDB, StepTheme} {
+		wantStep(t, rep, name, StepSkipped, "no site row")
+	}
+}
+
+func TestDiagnose_ConfigAndVaultFailures(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, filepath.Join(themeBaseDir, "t", "templates", "page.html"), `ok`)
+	global, mock := diagGlobal(t, "diag.example")
+	mock.ExpectQuery("FROM\\s+site_config").WillReturnError(errors.New("config table gone"))
+
+	deps := okDeps(t)
+	deps.secret = func(_ context.Context, path, _ string) (string, error) {
+		if path != "secret/adept/tenant/diagexample/db" {
+			t.Errorf("secret path = %q", path)
+		}
+		return "", errors.New("permission denied")
+	}
+	rep, err := diagnose(context.Background(), global, "diag.example", deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStep(t, rep, StepConfig, StepFailed, "config table gone")
+	wantStep(t, rep, StepVault, StepFailed, "permission denied")
+	wantStep(t, rep, StepDB, StepSkipped, "no database password")
+
+	// The theme still parses, on the all-enabled assumption.
+	wantStep(t, rep, StepTheme, StepOK, "")
+	if len(rep.Step(StepTheme).Warnings) != 1 || rep.OK {
+		t.Fatalf("theme step = %+v, OK = %v", rep.Step(StepTheme), rep.OK)
+	}
+}
+
+func TestDiagnose_DialAndThemeFailures(t *testing.T) {
+	t.Chdir(t.TempDir()) // no themes/t
+	global, mock := diagGlobal(t, "diag.example")
+	mock.ExpectQuery("FROM\\s+site_config").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}))
+
+	deps := okDeps(t)
+	deps.dial = func(context.Context, string) (*sqlx.DB, error) {
+		return nil, errors.New("connection refused")
+	}
+	rep, err := diagnose(context.Background(), global, "diag.example", deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStep(t, rep, StepConfig, StepOK, "")
+	wantStep(t, rep, StepVault, StepOK, "")
+	wantStep(t, rep, StepDB, StepFailed, "connection refused")
+	wantStep(t, rep, StepTheme, StepFailed, "theme t not found")
+}
+
+func TestDiagnose_NoGlobalDB(t *testing.T) {
+	if _, err := Diagnose(context.Background(), nil, "x.example", nil); err == nil {
+		t.Fatal("nil global DB accepted")
+	}
+}
@@ -4,18 +4,20 @@
 //
 // A live Tenant bundles everything needed to serve one site: its `site` row,
 // per-site DB pool, in-memory config, active Theme, Vault client, renderer,
-// alias-route cache, and a lazily built chi.Router.
+// child logger, alias-route cache, and a lazily built chi.Router.
 
 package tenant
 
 import (
 	"html/template"
-	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 
+	"github.com/yanizio/adept/internal/database"
 	"github.com/yanizio/adept/internal/routing"
 	"github.com/yanizio/adept/internal/tenant/meta"
 	"github.com/yanizio/adept/internal/theme"
@@ -29,6 +31,11 @@ import (
 type entry struct {
 	tenant   *Tenant
 	lastSeen int64 // UnixNano
+	pinned   bool  // exempt from eviction (see preload.go)
+	loadedAt int64 // UnixNano; set once at load (see snapshot.go)
+
+	checkedAt int64 // UnixNano of the last site-row freshness check
+	checking  int32 // 1 while a recheck goroutine runs
 }
 
 //
@@ -38,21 +45,41 @@ type entry struct {
 type Tenant struct {
 	// Core site objects
 	Meta     meta.Record        // Row from `site`
-	Config   map[string]string  // site_config key→value
+	Config   SiteConfig         // site_config key→value, typed accessors
 	DB       *sqlx.DB           // Per-site connection pool
-	Theme    *theme.Theme       // Active theme
-	Renderer *template.Template // Convenience alias: Theme.Renderer
-	Vault    *vault.Client      // Vault client for secret lookup
+	replica  *sqlx.DB           // Optional read replica; read via ReadDB
+	Theme    *theme.Theme       // Active theme; read via GetTheme
+	Renderer *template.Template // Convenience alias: Theme.Renderer; read via GetRenderer
+	Vault    vault.Secrets      // secret store: Vault, or env and file in dev
+
+	// Child logger tagged "tenant"=host; read via GetLogger.
+	log *zap.SugaredLogger
+
+	// Theme hot reload (theme.go) swaps Theme and Renderer under themeMu.
+	themeMu      sync.RWMutex
+	themeModules []string // module list passed to theme.Manager.Load
+
+	// Asset override chain shared by ServeAsset and {{ asset }} (assets.go).
+	assetsOnce sync.Once
+	assets     *theme.Assets
 
 	// Routing data
-	aliasCache *routing.AliasCache
-	routeMode  string // "absolute" | "alias" | "both"
-	routeVer   int    // site.route_version
-	host       string // canonical host, used in logs
-
-	// Cached chi.Router
-	routerOnce sync.Once
-	router     http.Handler
+	aliasOnce  sync.Once
+	aliasCache atomic.Pointer[routing.AliasCache]
+	routeMode  atomic.Pointer[string] // RouteMode*; set by setRoutingMode
+	routeVer   atomic.Int64           // site.route_version; bumped in place by recheck
+	host       string                 // canonical host, used in logs
+	siteDB     *sqlx.DB               // primary global DB, for RebuildRouter's site re-read
+
+	// Per-tenant state owned by other packages (acl caches, …); see
+	// Attachment.  It lives and dies with the Tenant.
+	attachMu sync.Mutex
+	attach   map[any]any
+
+	// Cached chi.Router and the route_version it was built for; rebuilt
+	// when RouteVersion moves (router.go).
+	routerMu sync.Mutex
+	router   atomic.Pointer[builtRouter]
 }
 
 // ---------------------------- helpers used elsewhere -------------------------
@@ -61,21 +88,91 @@ func (t *Tenant) Host() string { return t.host }
 
 // AliasCache returns the per-tenant cache, creating it on first use.
 func (t *Tenant) AliasCache() *routing.AliasCache {
-	if t.aliasCache == nil {
+	t.aliasOnce.Do(func() {
 		// Default TTL 5 min; tweak via config later.
-		t.aliasCache = routing.NewAliasCache(t.DB.DB, 5*time.Minute)
+		t.aliasCache.Store(routing.NewAliasCache(t.ReadDB().DB, 5*time.Minute))
+	})
+	return t.aliasCache.Load()
+}
+
+// RoutingMode returns RouteModeAbsolute, RouteModeAliasOnly, or
+// RouteModeBoth.  A Tenant built outside loadSite reports RouteModeBoth.
+func (t *Tenant) RoutingMode() string {
+	if m := t.routeMode.Load(); m != nil {
+		return *m
 	}
-	return t.aliasCache
+	return RouteModeBoth
 }
 
-func (t *Tenant) RoutingMode() string { return t.routeMode }
-func (t *Tenant) RouteVersion() int   { return t.routeVer }
+func (t *Tenant) RouteVersion() int { return int(t.routeVer.Load()) }
+
+// setRoutingMode records site.routing_mode (see routeModeOf).
+func (t *Tenant) setRoutingMode(col string) {
+	m := routeModeOf(col)
+	t.routeMode.Store(&m)
+}
+
+// SetRouteVersion records a new site.route_version.  The router and the
+// alias cache rebuild on their next request.
+func (t *Tenant) SetRouteVersion(v int) { t.routeVer.Store(int64(v)) }
 
 // component.TenantInfo implementations
-func (t *Tenant) GetDB() *sqlx.DB              { return t.DB }
+func (t *Tenant) GetDB() *sqlx.DB { return t.DB }
+
+// ReadDB returns the tenant's read-replica pool, or the primary DB when no
+// replica is configured (site.db_replica_host).  Use it only for reads that
+// tolerate replication lag; a row written on DB may not be visible here yet.
+func (t *Tenant) ReadDB() *sqlx.DB {
+	if t.replica != nil {
+		return t.replica
+	}
+	return t.DB
+}
 func (t *Tenant) GetConfig() map[string]string { return t.Config }
-func (t *Tenant) GetTheme() *theme.Theme       { return t.Theme }
-func (t *Tenant) GetVault() *vault.Client      { return t.Vault }
+func (t *Tenant) GetTheme() *theme.Theme {
+	t.themeMu.RLock()
+	defer t.themeMu.RUnlock()
+	return t.Theme
+}
+func (t *Tenant) GetVault() vault.Secrets { return t.Vault }
+
+// GetLogger returns the tenant's child logger.  Every entry carries
+// "tenant"=host, so per-site logs can be filtered without threading the host
+// through call-sites.  Tenants built outside loadSite fall back to zap.S().
+func (t *Tenant) GetLogger() *zap.SugaredLogger {
+	if t.log == nil {
+		return zap.S().With("tenant", t.host)
+	}
+	return t.log
+}
+
+// Attachment returns the value stored under key, calling init to create it
+// on first use.  Packages that cannot be imported by tenant (acl, for one)
+// keep per-tenant caches here, so the state is dropped together with the
+// Tenant on eviction or invalidation.  Use an unexported key type.
+func (t *Tenant) Attachment(key any, init func() any) any {
+	t.attachMu.Lock()
+	defer t.attachMu.Unlock()
+	if v, ok := t.attach[key]; ok {
+		return v
+	}
+	if t.attach == nil {
+		t.attach = make(map[any]any)
+	}
+	v := init()
+	t.attach[key] = v
+	return v
+}
 
-// Close is called by the cache evictor on idle or LRU eviction.
-func (t *Tenant) Close() error { return t.DB.Close() }
+// Close is called by the cache evictor on idle or LRU eviction, and by
+// Cache.CloseAll at shutdown.
+func (t *Tenant) Close() error {
+	database.UnregisterTenant(t.host, t.DB)
+	if t.replica != nil {
+		_ = t.replica.Close()
+	}
+	if t.DB == nil {
+		return nil
+	}
+	return t.DB.Close()
+}
@@ -12,14 +12,22 @@
 //   - **LRU eviction**  — if the map still exceeds `maxEntries`, remove the
 //     oldest entries until the cap is met.
 //
-// Each eviction closes the tenant’s DB pool, logs the event, and updates
-// Prometheus metrics.
+// Pinned entries (preloaded sites with cache.pinned, see preload.go) are
+// skipped by both passes.
+//
+// Each eviction closes the tenant’s DB pool, runs the OnEvict hooks, logs one
+// structured INFO line (tenant, reason=idle|lru, idle_seconds), and updates
+// Prometheus metrics.  Every pass ends with a DEBUG summary of entries
+// scanned and evicted.  Idle time is measured with c.now, so tests drive
+// the evictor with a fake clock.
+//
+// Cache.Stop ends the loop and stops the ticker; it is safe to call twice.
 //
 // Notes
 // -----
 //   - All map operations use `sync.Map` APIs; no additional locks needed.
-//   - `metrics.TenantEvictTotal` and `metrics.ActiveTenants` gauge are
-//     updated in-line.
+//   - `metrics.TenantEvicted` updates the global and per-host eviction
+//     counters and active gauges in-line.
 //   - Oxford commas, two spaces after periods, no m-dash.
 package tenant
 
@@ -32,57 +40,89 @@ import (
 )
 
 func (c *Cache) evictLoop() {
-	for range c.evictTicker.C {
-		now := time.Now().UnixNano()
-		var count int
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-c.evictTicker.C:
+			c.evictOnce()
+		}
+	}
+}
+
+// evictOnce runs one idle pass and one LRU pass, then logs a DEBUG summary
+// of how many entries it scanned and evicted.
+func (c *Cache) evictOnce() {
+	now := c.now().UnixNano()
+	var scanned, idleEvicted, lruEvicted int
+
+	//
+	// Idle eviction pass
+	//
+	c.m.Range(func(key, value any) bool {
+		scanned++
+		ent := value.(*entry)
+		if ent.pinned {
+			return true
+		}
+		idle := time.Duration(now - atomic.LoadInt64(&ent.lastSeen))
+		if idle > c.idleTTL && c.evict(key.(string), ent, "idle", idle) {
+			idleEvicted++
+		}
+		return true
+	})
 
-		//
-		// Idle eviction pass
-		//
+	//
+	// LRU eviction pass
+	//
+	if remaining := scanned - idleEvicted; c.maxEntries > 0 && remaining > c.maxEntries {
+		type kv struct {
+			key string
+			ent *entry
+			at  int64
+		}
+		var all []kv
 		c.m.Range(func(key, value any) bool {
-			count++
 			ent := value.(*entry)
-			idle := time.Duration(now-atomic.LoadInt64(&ent.lastSeen)) * time.Nanosecond
-			if idle > c.idleTTL {
-				_ = ent.tenant.Close()
-				c.m.Delete(key)
-				c.log.Infow("tenant evicted (idle)",
-					"tenant", key,
-					"idle_sec", idle.Seconds(),
-				)
-				metrics.TenantEvictTotal.Inc()
-				metrics.ActiveTenants.Dec()
+			if ent.pinned {
+				return true
 			}
+			all = append(all, kv{key: key.(string), ent: ent, at: atomic.LoadInt64(&ent.lastSeen)})
 			return true
 		})
+		sort.Slice(all, func(i, j int) bool { return all[i].at < all[j].at })
 
-		//
-		// LRU eviction pass
-		//
-		if c.maxEntries > 0 && count > c.maxEntries {
-			type kv struct {
-				key string
-				at  int64
-			}
-			var all []kv
-			c.m.Range(func(key, value any) bool {
-				ent := value.(*entry)
-				all = append(all, kv{key: key.(string), at: ent.lastSeen})
-				return true
-			})
-			sort.Slice(all, func(i, j int) bool { return all[i].at < all[j].at })
-
-			for i := 0; i < count-c.maxEntries; i++ {
-				if v, ok := c.m.Load(all[i].key); ok {
-					_ = v.(*entry).tenant.Close()
-					c.m.Delete(all[i].key)
-					c.log.Infow("tenant evicted (LRU)",
-						"tenant", all[i].key,
-					)
-					metrics.TenantEvictTotal.Inc()
-					metrics.ActiveTenants.Dec()
-				}
+		// An entry replaced meanwhile is skipped, so keep going until the
+		// cap is met or the candidates run out.
+		for i := 0; lruEvicted < remaining-c.maxEntries && i < len(all); i++ {
+			if c.evict(all[i].key, all[i].ent, "lru", time.Duration(now-all[i].at)) {
+				lruEvicted++
 			}
 		}
 	}
+
+	c.log.Debugw("tenant eviction pass",
+		"scanned", scanned,
+		"evicted_idle", idleEvicted,
+		"evicted_lru", lruEvicted,
+	)
+}
+
+// evict drops host when ent is still its entry, closes the tenant, runs the
+// OnEvict hooks, and records the eviction.  reason is "idle" or "lru".  It
+// reports whether ent was evicted; false means it was invalidated or
+// reloaded meanwhile and nothing was done.
+func (c *Cache) evict(host string, ent *entry, reason string, idle time.Duration) bool {
+	if !c.m.CompareAndDelete(host, ent) {
+		return false
+	}
+	_ = ent.tenant.Close()
+	notifyEvict(host)
+	c.log.Infow("tenant evicted",
+		"tenant", host,
+		"reason", reason,
+		"idle_seconds", idle.Seconds(),
+	)
+	metrics.TenantEvicted(host)
+	return true
 }
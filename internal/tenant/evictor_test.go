@@ -0,0 +1,139 @@
+// internal/tenant/evictor_test.go
+//
+// Unit-tests for the eviction passes, driven by a fake clock.
+//
+// Context
+// -------
+// Entries are stored directly in the cache map with lastSeen stamps relative
+// to the stubbed c.now, and evictOnce is called by hand, so no test waits
+// on wall time or the background ticker.
+//
+// Notes
+// -----
+// • Log lines are captured with zap's observer core.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// evictCache returns a stopped Cache with a fake clock and observed logs.
+func evictCache(t *testing.T, idleTTL time.Duration, maxEntries int) (*Cache, *time.Time, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	c := New(nil, idleTTL, maxEntries, zap.New(core).Sugar(), nil)
+	t.Cleanup(c.Stop)
+	clock := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return clock }
+	return c, &clock, logs
+}
+
+// seed stores host with lastSeen set to ago before the fake clock.
+func seed(c *Cache, now time.Time, host string, ago time.Duration, pinned bool) {
+	c.m.Store(host, &entry{
+		tenant:   &Tenant{host: host},
+		lastSeen: now.Add(-ago).UnixNano(),
+		pinned:   pinned,
+	})
+}
+
+func cachedHosts(c *Cache) []string {
+	var out []string
+	c.m.Range(func(k, _ any) bool { out = append(out, k.(string)); return true })
+	sort.Strings(out)
+	return out
+}
+
+func TestEvictOnce_Idle(t *testing.T) {
+	c, now, logs := evictCache(t, 10*time.Minute, 0)
+	seed(c, *now, "fresh.example", time.Minute, false)
+	seed(c, *now, "stale.example", 11*time.Minute, false)
+	seed(c, *now, "pinned.example", time.Hour, true)
+
+	c.evictOnce()
+
+	got := cachedHosts(c)
+	want := []string{"fresh.example", "pinned.example"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("cached = %v, want %v", got, want)
+	}
+
+	ev := logs.FilterMessage("tenant evicted").All()
+	if len(ev) != 1 {
+		t.Fatalf("got %d eviction lines, want 1", len(ev))
+	}
+	f := ev[0].ContextMap()
+	if f["tenant"] != "stale.example" || f["reason"] != "idle" || f["idle_seconds"] != 660.0 {
+		t.Fatalf("eviction fields = %v", f)
+	}
+
+	sum := logs.FilterMessage("tenant eviction pass").All()
+	if len(sum) != 1 || sum[0].Level != zapcore.DebugLevel {
+		t.Fatalf("summary lines = %v", sum)
+	}
+	if f := sum[0].ContextMap(); f["scanned"] != int64(3) || f["evicted_idle"] != int64(1) || f["evicted_lru"] != int64(0) {
+		t.Fatalf("summary fields = %v", f)
+	}
+
+	// Advancing the clock ages the survivor past idleTTL.
+	*now = now.Add(10 * time.Minute)
+	c.evictOnce()
+	if got := cachedHosts(c); len(got) != 1 || got[0] != "pinned.example" {
+		t.Fatalf("after clock advance cached = %v", got)
+	}
+}
+
+func TestEvictOnce_LRUCountsAfterIdlePass(t *testing.T) {
+	c, now, logs := evictCache(t, 10*time.Minute, 2)
+	seed(c, *now, "idle.example", time.Hour, false)
+	seed(c, *now, "a.example", 3*time.Minute, false)
+	seed(c, *now, "b.example", 2*time.Minute, false)
+	seed(c, *now, "c.example", time.Minute, false)
+
+	c.evictOnce()
+
+	// idle.example goes on idle; three remain over a cap of two, so only the
+	// least recently used (a.example) goes on LRU.
+	got := cachedHosts(c)
+	if len(got) != 2 || got[0] != "b.example" || got[1] != "c.example" {
+		t.Fatalf("cached = %v, want [b.example c.example]", got)
+	}
+	var reasons []string
+	for _, e := range logs.FilterMessage("tenant evicted").All() {
+		reasons = append(reasons, e.ContextMap()["tenant"].(string)+"="+e.ContextMap()["reason"].(string))
+	}
+	if len(reasons) != 2 || reasons[0] != "idle.example=idle" || reasons[1] != "a.example=lru" {
+		t.Fatalf("evictions = %v", reasons)
+	}
+}
+
+func TestEvict_SkipsReplacedEntry(t *testing.T) {
+	c, now, logs := evictCache(t, 10*time.Minute, 0)
+	seed(c, *now, "a.example", time.Hour, false)
+	v, _ := c.m.Load("a.example")
+	stale := v.(*entry)
+	seed(c, *now, "a.example", 0, false) // reloaded meanwhile
+
+	if c.evict("a.example", stale, "idle", time.Hour) {
+		t.Fatal("evict reported a replaced entry as evicted")
+	}
+	if got := cachedHosts(c); len(got) != 1 {
+		t.Fatalf("cached = %v, want the reloaded entry kept", got)
+	}
+	if n := logs.FilterMessage("tenant evicted").Len(); n != 0 {
+		t.Fatalf("%d eviction lines for a skipped entry", n)
+	}
+
+	v, _ = c.m.Load("a.example")
+	if !c.evict("a.example", v.(*entry), "lru", 0) {
+		t.Fatal("evict of the current entry reported false")
+	}
+}
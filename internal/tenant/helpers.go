@@ -12,11 +12,16 @@
 //
 //   • `sanitizeHost`     — converts the lookup host into a canonical key
 //     that doubles as the DB user name and schema.  Dots are stripped so
-//     “site.yaniz.dev” → “siteyanizdev”.  Uses the result of
-//     `resolveLookupHost`.
+//     “site.yaniz.dev” → “siteyanizdev”, and a wildcard site swaps its “*.”
+//     for a “wc_” prefix so “*.app.example.com” → “wc_appexamplecom”,
+//     never the key of the apex site “app.example.com”.  Uses the result
+//     of `resolveLookupHost`.
 //
-//   • `buildTenantDSN`   — produces the MySQL DSN string given the canonical
-//     key and the Vault-resolved password.
+//   • `buildTenantDSN`   — fills the DSN template (`database.tenant_dsn`)
+//     with the canonical key, the Vault-resolved password, and the DB host
+//     (`site.db_host`, else `database.tenant_db_host`).  The read replica,
+//     when `site.db_replica_host` is set, uses the same template with that
+//     host (`tenantReplicaDSN`).
 //
 // Notes
 // -----
@@ -26,11 +31,11 @@
 package tenant
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
 	"github.com/yanizio/adept/internal/config"
+	"github.com/yanizio/adept/internal/tenant/meta"
 )
 
 //
@@ -59,21 +64,56 @@ func resolveLookupHost(h string) string {
 
 // sanitizeHost converts the lookup host to a canonical key used for
 // database user name, schema, and Vault secret path.  All dots are
-// removed so “api.example.dev” becomes “apiexampledev”.
+// removed so “api.example.dev” becomes “apiexampledev”.  A wildcard host
+// trades its “*.” for “wc_”, keeping it apart from the apex site: the two
+// must never share a user, schema, or secret.
 func sanitizeHost(h string) string {
-	return strings.ReplaceAll(resolveLookupHost(h), ".", "")
+	h = resolveLookupHost(h)
+	prefix := ""
+	if rest, ok := strings.CutPrefix(h, "*."); ok {
+		prefix, h = "wc_", rest
+	}
+	return prefix + strings.ReplaceAll(h, ".", "")
 }
 
 //
 // buildTenantDSN → MySQL DSN
 //
 
-// buildTenantDSN fills the canonical template:
+// tenantDSN builds the DSN for rec from the configured template (see
+// config.Database).  A site-level db_host overrides the global one.
+func tenantDSN(rec *meta.Record, key, pw string) string {
+	tmpl, dbHost := config.DefaultTenantDSN, config.DefaultTenantDBHost
+	if cfg := config.Get(); cfg != nil {
+		tmpl, dbHost = cfg.Database.TenantDSNTemplate(), cfg.Database.TenantHost()
+	}
+	if rec.DBHost != nil && *rec.DBHost != "" {
+		dbHost = *rec.DBHost
+	}
+	return buildTenantDSN(tmpl, key, pw, dbHost)
+}
+
+// tenantReplicaDSN builds the read-replica DSN for rec, or "" when the site
+// has no db_replica_host.
+func tenantReplicaDSN(rec *meta.Record, key, pw string) string {
+	if rec.DBReplica == nil || *rec.DBReplica == "" {
+		return ""
+	}
+	tmpl := config.DefaultTenantDSN
+	if cfg := config.Get(); cfg != nil {
+		tmpl = cfg.Database.TenantDSNTemplate()
+	}
+	return buildTenantDSN(tmpl, key, pw, *rec.DBReplica)
+}
+
+// buildTenantDSN fills the {key}, {password}, and {db_host} placeholders of
+// tmpl.  The default template is
 //
-//	{key}:{password}@tcp(127.0.0.1:3306)/{key}?parseTime=true&loc=Local
-func buildTenantDSN(key, pw string) string {
-	return fmt.Sprintf(
-		"%s:%s@tcp(127.0.0.1:3306)/%s?parseTime=true&loc=Local",
-		key, pw, key,
-	)
+//	{key}:{password}@tcp({db_host})/{key}?parseTime=true&loc=Local
+func buildTenantDSN(tmpl, key, pw, dbHost string) string {
+	return strings.NewReplacer(
+		"{key}", key,
+		"{password}", pw,
+		"{db_host}", dbHost,
+	).Replace(tmpl)
 }
@@ -0,0 +1,78 @@
+// internal/tenant/helpers_test.go
+//
+// Unit-tests for the tenant DSN template and the read-replica fallback.
+
+package tenant
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/config"
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func TestBuildTenantDSN_DefaultMatchesLegacy(t *testing.T) {
+	got := buildTenantDSN(config.DefaultTenantDSN, "siteexample", "pw", config.DefaultTenantDBHost)
+	want := "siteexample:pw@tcp(127.0.0.1:3306)/siteexample?parseTime=true&loc=Local"
+	if got != want {
+		t.Fatalf("DSN = %q, want %q", got, want)
+	}
+}
+
+func TestBuildTenantDSN_CustomTemplate(t *testing.T) {
+	tmpl := "{key}:{password}@tcp({db_host})/tenant_{key}?parseTime=true&tls=custom"
+	got := buildTenantDSN(tmpl, "siteexample", "s3cret", "db7.cluster:3307")
+	want := "siteexample:s3cret@tcp(db7.cluster:3307)/tenant_siteexample?parseTime=true&tls=custom"
+	if got != want {
+		t.Fatalf("DSN = %q, want %q", got, want)
+	}
+}
+
+func TestTenantDSN_SiteHostOverride(t *testing.T) {
+	host := "db-eu.internal:3306"
+	got := tenantDSN(&meta.Record{DBHost: &host}, "k", "pw")
+	want := "k:pw@tcp(db-eu.internal:3306)/k?parseTime=true&loc=Local"
+	if got != want {
+		t.Fatalf("DSN = %q, want %q", got, want)
+	}
+	if got := tenantDSN(&meta.Record{}, "k", "pw"); got !=
+		"k:pw@tcp(127.0.0.1:3306)/k?parseTime=true&loc=Local" {
+		t.Fatalf("no override: DSN = %q", got)
+	}
+}
+
+func TestTenantReplicaDSN(t *testing.T) {
+	if got := tenantReplicaDSN(&meta.Record{}, "k", "pw"); got != "" {
+		t.Fatalf("no replica: DSN = %q, want empty", got)
+	}
+	host := "db-ro.internal:3306"
+	got := tenantReplicaDSN(&meta.Record{DBReplica: &host}, "k", "pw")
+	want := "k:pw@tcp(db-ro.internal:3306)/k?parseTime=true&loc=Local"
+	if got != want {
+		t.Fatalf("DSN = %q, want %q", got, want)
+	}
+}
+
+func TestReadDB_FallsBackToPrimary(t *testing.T) {
+	primary, replica := &sqlx.DB{}, &sqlx.DB{}
+
+	ten := &Tenant{DB: primary}
+	if ten.ReadDB() != primary {
+		t.Fatal("ReadDB without replica should return the primary")
+	}
+	ten.replica = replica
+	if ten.ReadDB() != replica {
+		t.Fatal("ReadDB should prefer the replica")
+	}
+
+	c := &Cache{globalDB: primary}
+	if c.readDB() != primary {
+		t.Fatal("Cache.readDB without replica should return the primary")
+	}
+	c.SetReadDB(replica)
+	if c.readDB() != replica {
+		t.Fatal("Cache.readDB should prefer the replica")
+	}
+}
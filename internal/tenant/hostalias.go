@@ -0,0 +1,106 @@
+// internal/tenant/hostalias.go
+//
+// Alias and wildcard hosts.
+//
+// Context
+// -------
+// One site may answer on many hosts: vanity domains listed in
+// site_host_alias, and every subdomain under a wildcard site row such as
+// "*.app.example.com".  The cache keys Tenants by the canonical host
+// (site.host), so all of those hosts share one aggregate, one DB pool, and
+// one compiled theme instead of N copies.
+//
+// Workflow
+// --------
+//  1. Get misses on the request host and resolves it (resolveSite).
+//  2. A host that differs from the row's canonical host is remembered here,
+//     with its redirect flag, so later requests skip the DB.
+//  3. Non-redirect aliases serve the canonical Tenant directly.  Redirect
+//     aliases make Get return *Redirect; the root handler answers 301 to
+//     the canonical host, keeping path and query.
+//
+// Notes
+// -----
+// • The alias map is a bounded LRU, so a flood of random subdomains under a
+//   wildcard cannot grow it without limit.
+// • Alias edits apply once the alias is forgotten: each mapping lapses
+//   after AliasTTL, Invalidate on the alias host forgets it at once, and
+//   Invalidate on a canonical host (or InvalidateAll) forgets them all.
+//   Site-row edits already invalidate through the poller, but a deleted
+//   alias row or a flipped redirect flag touches no site row, so only the
+//   TTL bounds how long it keeps serving.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"fmt"
+	"time"
+
+	lru "github.com/yanizio/adept/internal/cache"
+)
+
+// Redirect is returned by Cache.Get for an alias flagged to redirect.  Host
+// is the canonical host the client should be sent to.
+type Redirect struct {
+	Host string
+}
+
+func (r *Redirect) Error() string {
+	return fmt.Sprintf("tenant: alias redirects to %q", r.Host)
+}
+
+// AliasTTL bounds how long a remembered alias is trusted before it is
+// resolved against the DB again.
+const AliasTTL = 5 * time.Minute
+
+// aliasTarget is one remembered alias.
+type aliasTarget struct {
+	canonical string
+	redirect  bool
+	expires   time.Time
+}
+
+// aliasOf returns the remembered target of host, dropping an expired one.
+func (c *Cache) aliasOf(host string) (aliasTarget, bool) {
+	c.aliasMu.Lock()
+	defer c.aliasMu.Unlock()
+	v, ok := c.aliases.Get(host)
+	if !ok {
+		return aliasTarget{}, false
+	}
+	tgt := v.(aliasTarget)
+	if !c.now().Before(tgt.expires) {
+		c.aliases.Remove(host)
+		return aliasTarget{}, false
+	}
+	return tgt, true
+}
+
+// rememberAlias records that host resolves to canonical for AliasTTL.
+func (c *Cache) rememberAlias(host, canonical string, redirect bool) {
+	c.aliasMu.Lock()
+	c.aliases.Add(host, aliasTarget{canonical: canonical, redirect: redirect, expires: c.now().Add(AliasTTL)})
+	c.aliasMu.Unlock()
+}
+
+// forgetAlias drops host from the alias map and returns its target.
+func (c *Cache) forgetAlias(host string) (aliasTarget, bool) {
+	c.aliasMu.Lock()
+	defer c.aliasMu.Unlock()
+	v, ok := c.aliases.Get(host)
+	if !ok {
+		return aliasTarget{}, false
+	}
+	c.aliases.Remove(host)
+	return v.(aliasTarget), true
+}
+
+// clearAliases forgets every alias.  Invalidating a canonical host calls it
+// because the LRU cannot be searched by target; aliases re-resolve on their
+// next request.
+func (c *Cache) clearAliases() {
+	c.aliasMu.Lock()
+	c.aliases = lru.New(aliasCap)
+	c.aliasMu.Unlock()
+}
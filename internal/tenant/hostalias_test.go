@@ -0,0 +1,166 @@
+// internal/tenant/hostalias_test.go
+//
+// Unit-tests for alias and wildcard host resolution.
+//
+// Notes
+// -----
+// • The canonical tenant is pre-cached, so a passing test proves aliases
+//   share it rather than loading a copy.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// aliasRows is a ByAlias result pointing at canonical.
+func aliasRows(canonical string, redirect bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "host", "redirect", "prio"}).
+		AddRow(1, canonical, redirect, 0)
+}
+
+// expectAlias queues an exact-host miss followed by an alias hit.
+func expectAlias(mock sqlmock.Sqlmock, host string, rows *sqlmock.Rows, args ...driver.Value) {
+	mock.ExpectQuery("FROM\\s+site").WithArgs(host).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	q := mock.ExpectQuery("FROM\\s+site_host_alias")
+	if len(args) > 0 {
+		q.WithArgs(args...)
+	}
+	q.WillReturnRows(rows)
+}
+
+func TestGet_AliasSharesCanonicalTenant(t *testing.T) {
+	c, mock, _ := negCache(t)
+	cachedTenant(t, c, "site.example", time.Time{})
+	want, _ := c.Get("site.example")
+
+	expectAlias(mock, "vanity.example", aliasRows("site.example", false))
+	for i := 0; i < 3; i++ {
+		got, err := c.Get("vanity.example")
+		if err != nil {
+			t.Fatalf("lookup %d: %v", i, err)
+		}
+		if got != want {
+			t.Fatalf("lookup %d: alias loaded its own Tenant", i)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected exactly one resolution: %v", err)
+	}
+}
+
+func TestGet_RedirectAlias(t *testing.T) {
+	c, mock, _ := negCache(t)
+	expectAlias(mock, "old.example", aliasRows("site.example", true))
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Get("old.example")
+		var rd *Redirect
+		if !errors.As(err, &rd) || rd.Host != "site.example" {
+			t.Fatalf("lookup %d: err = %v, want redirect to site.example", i, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet_WildcardHost(t *testing.T) {
+	c, mock, _ := negCache(t)
+	cachedTenant(t, c, "*.app.example.com", time.Time{})
+	want, _ := c.Get("*.app.example.com")
+
+	expectAlias(mock, "c1.app.example.com", aliasRows("*.app.example.com", false),
+		"c1.app.example.com", "*.app.example.com", "*.example.com")
+	got, err := c.Get("c1.app.example.com")
+	if err != nil || got != want {
+		t.Fatalf("wildcard lookup = %p, %v; want shared tenant", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidate_AliasForgetsMapping(t *testing.T) {
+	shortGrace(t, 0)
+	c, _, _ := negCache(t)
+	c.rememberAlias("old.example", "site.example", true)
+	c.Invalidate("old.example")
+	if _, ok := c.aliasOf("old.example"); ok {
+		t.Fatal("alias survived Invalidate")
+	}
+}
+
+func TestSanitizeHost_Wildcard(t *testing.T) {
+	wc, apex := sanitizeHost("*.app.example.com"), sanitizeHost("app.example.com")
+	if wc != "wc_appexamplecom" || apex != "appexamplecom" {
+		t.Fatalf("sanitizeHost = %q, %q", wc, apex)
+	}
+	if wc == apex {
+		t.Fatal("wildcard and apex sites share a key")
+	}
+}
+
+func TestAliasOf_Expires(t *testing.T) {
+	c, _, clock := negCache(t)
+	c.rememberAlias("old.example", "site.example", false)
+
+	*clock = clock.Add(AliasTTL - time.Second)
+	if _, ok := c.aliasOf("old.example"); !ok {
+		t.Fatal("alias forgotten before AliasTTL")
+	}
+	*clock = clock.Add(time.Second)
+	if _, ok := c.aliasOf("old.example"); ok {
+		t.Fatal("alias served past AliasTTL")
+	}
+}
+
+func TestKnownHost_RefusesWildcardOnly(t *testing.T) {
+	c, mock, _ := negCache(t)
+	expectAlias(mock, "c1.app.example.com", aliasRows("*.app.example.com", false))
+	if err := c.KnownHost(t.Context(), "c1.app.example.com"); err == nil {
+		t.Fatal("wildcard-only host allowed a certificate")
+	}
+
+	c.rememberAlias("c2.app.example.com", "*.app.example.com", false)
+	if err := c.KnownHost(t.Context(), "c2.app.example.com"); err == nil {
+		t.Fatal("remembered wildcard alias allowed a certificate")
+	}
+
+	expectAlias(mock, "vanity.example", aliasRows("site.example", false))
+	if err := c.KnownHost(t.Context(), "vanity.example"); err != nil {
+		t.Fatalf("explicit alias refused: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKnownHost_UsesNegativeCache(t *testing.T) {
+	c, mock, _ := negCache(t)
+	expectMiss(mock, "probe.example")
+	for i := 0; i < 3; i++ {
+		if err := c.KnownHost(t.Context(), "probe.example"); err == nil {
+			t.Fatalf("probe %d: unknown host allowed", i)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected one resolution: %v", err)
+	}
+
+	mock.ExpectQuery("FROM\\s+site").WithArgs("flaky.example").
+		WillReturnError(errors.New("db down"))
+	if err := c.KnownHost(t.Context(), "flaky.example"); err == nil {
+		t.Fatal("DB error allowed a certificate")
+	}
+	if c.knownMissing("flaky.example") {
+		t.Fatal("DB error remembered as a missing host")
+	}
+}
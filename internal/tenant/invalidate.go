@@ -0,0 +1,216 @@
+// internal/tenant/invalidate.go
+//
+// Explicit invalidation and the site-row poller.
+//
+// Context
+// -------
+// Once cached, a tenant keeps its site row, site_config, and theme until it
+// idles out.  Three paths refresh it:
+//
+//   - Invalidate / InvalidateAll – on demand (admin endpoint, tooling).
+//   - On-hit recheck – a cache hit older than RecheckInterval probes
+//     route_version and updated_at in the background (one indexed row, at
+//     most one probe per entry at a time).  A newer updated_at drops the
+//     entry; a route_version bump alone only sets the tenant's version, so
+//     its router and alias cache rebuild in place.  The hit itself never
+//     waits.
+//   - WatchSites – optional poller over the whole site table.  Like the
+//     recheck, it drops on updated_at and applies route_version in place,
+//     but only a version greater than the tenant's.
+//
+// The next request after a drop cold-loads fresh state.
+//
+// Safety while requests are in flight
+// -----------------------------------
+//   - Swap, then close.  The entry leaves the map first, so new requests
+//     load afresh, while requests already holding the old *Tenant keep a
+//     working DB pool for InvalidateGrace before it is closed.
+//   - Load generation.  Invalidation bumps c.gen.  A single-flight load that
+//     began before the bump still answers its waiters, but its result is not
+//     stored (and is retired like an invalidated entry), so a racing request
+//     can never resurrect pre-invalidation state in the cache.
+//
+// Notes
+// -----
+// • Edits to site_config alone do not touch site.updated_at; bump the row
+//   or call POST /admin/tenant/invalidate.
+// • OnEvict hooks run for invalidated hosts so per-host view caches drop too.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanizio/adept/internal/metrics"
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+// InvalidateGrace is how long an invalidated tenant's DB pool stays open
+// for requests that already hold it.  It matches the server WriteTimeout.
+var InvalidateGrace = 15 * time.Second
+
+// Invalidate drops host from the cache and reports whether it was cached.
+// An alias host is forgotten and its canonical tenant dropped; a canonical
+// host also forgets every alias so they re-resolve (see hostalias.go).
+func (c *Cache) Invalidate(host string) bool {
+	atomic.AddUint64(&c.gen, 1)
+	if tgt, ok := c.forgetAlias(host); ok {
+		host = tgt.canonical
+	} else {
+		c.clearAliases()
+	}
+	v, ok := c.m.LoadAndDelete(host)
+	if !ok {
+		return false
+	}
+	c.retire(host, v.(*entry).tenant)
+	metrics.TenantUnloaded(host)
+	c.log.Infow("tenant invalidated", "tenant", host)
+	return true
+}
+
+// InvalidateAll drops every cached tenant and returns how many it removed.
+func (c *Cache) InvalidateAll() int {
+	atomic.AddUint64(&c.gen, 1)
+	c.clearAliases()
+	var n int
+	c.m.Range(func(k, _ any) bool {
+		if v, ok := c.m.LoadAndDelete(k); ok {
+			c.retire(k.(string), v.(*entry).tenant)
+			metrics.TenantUnloaded(k.(string))
+			n++
+		}
+		return true
+	})
+	c.log.Infow("tenant cache invalidated", "tenants", n)
+	return n
+}
+
+// retire runs the OnEvict hooks now and closes t after InvalidateGrace.
+func (c *Cache) retire(host string, t *Tenant) {
+	notifyEvict(host)
+	time.AfterFunc(InvalidateGrace, func() {
+		if err := t.Close(); err != nil {
+			c.log.Warnw("tenant close error", "tenant", host, "err", err)
+		}
+	})
+}
+
+// WatchSites polls the site table every interval and invalidates cached
+// tenants whose row is newer than the cached copy, or no longer active.
+// It blocks until ctx is done.
+func (c *Cache) WatchSites(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	c.log.Infow("site poller online", "interval_sec", interval.Seconds())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			c.invalidateStale()
+		}
+	}
+}
+
+/*──────────────────────────── on-hit recheck ──────────────────────────────*/
+
+// SetRecheckInterval sets how often a cache hit may probe its site row.
+// d <= 0 disables on-hit rechecks.  Safe to call while serving, e.g. on a
+// config reload.
+func (c *Cache) SetRecheckInterval(d time.Duration) { c.recheckEvery.Store(int64(d)) }
+
+// maybeRecheck starts a background freshness probe for ent when its last
+// check is older than recheckEvery and none is running.  The hit itself
+// never waits on the DB; a stale tenant is invalidated for the next one.
+func (c *Cache) maybeRecheck(host string, ent *entry) {
+	every := c.recheckEvery.Load()
+	if every <= 0 || c.globalDB == nil {
+		return
+	}
+	now := c.now().UnixNano()
+	if now-atomic.LoadInt64(&ent.checkedAt) < every {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&ent.checking, 0, 1) {
+		return
+	}
+	atomic.StoreInt64(&ent.checkedAt, now)
+	go func() {
+		defer atomic.StoreInt32(&ent.checking, 0)
+		c.recheck(host, ent)
+	}()
+}
+
+// recheck compares ent's site row with the DB and invalidates host when
+// updated_at moved or the site is no longer active.  A route_version change
+// alone is applied to the live tenant instead.  It reports whether host was
+// invalidated.
+func (c *Cache) recheck(host string, ent *entry) bool {
+	t := ent.tenant
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ver, upd, ok, err := meta.VersionByHost(ctx, c.globalDB, t.Meta.Host)
+	if err != nil {
+		c.log.Debugw("tenant recheck failed", "tenant", host, "err", err)
+		return false
+	}
+	if ok && !upd.After(t.Meta.UpdatedAt) {
+		if ver != t.RouteVersion() {
+			// Only the route version moved: the router and alias cache
+			// rebuild in place on the next request, pools stay warm.
+			c.log.Infow("tenant route_version changed – routes rebuild",
+				"tenant", host, "route_version", ver)
+			t.SetRouteVersion(ver)
+		}
+		return false
+	}
+	// Only drop the entry we checked; a fresher one may already be cached.
+	if v, cur := c.m.Load(host); !cur || v.(*entry) != ent {
+		return false
+	}
+	c.log.Infow("tenant site row changed – invalidating",
+		"tenant", host, "route_version", ver, "active", ok)
+	return c.Invalidate(host)
+}
+
+/*──────────────────────────── site poller ─────────────────────────────────*/
+
+// invalidateStale runs one poll and returns the number of hosts dropped.
+// Like the on-hit recheck it reads the primary: a lagging replica would
+// hide edits, or report a site as gone before it exists there.
+func (c *Cache) invalidateStale() int {
+	recs, err := meta.AllActive(c.globalDB)
+	if err != nil {
+		c.log.Warnw("site poll failed", "err", err)
+		return 0
+	}
+	live := make(map[string]meta.Record, len(recs))
+	for _, r := range recs {
+		live[r.Host] = r
+	}
+
+	var stale []string
+	c.Range(func(host string, t *Tenant) bool {
+		r, ok := live[t.Meta.Host]
+		switch {
+		case !ok || r.UpdatedAt.After(t.Meta.UpdatedAt):
+			stale = append(stale, host)
+		case r.RouteVersion > t.RouteVersion():
+			// As in recheck: routes and aliases rebuild in place.  Only
+			// move forward: the on-hit recheck may have applied a newer
+			// bump since this listing was read.
+			c.log.Infow("tenant route_version changed – routes rebuild",
+				"tenant", host, "route_version", r.RouteVersion)
+			t.SetRouteVersion(r.RouteVersion)
+		}
+		return true
+	})
+	for _, host := range stale {
+		c.Invalidate(host)
+	}
+	return len(stale)
+}
@@ -0,0 +1,264 @@
+// internal/tenant/invalidate_test.go
+//
+// Unit-tests for Invalidate, InvalidateAll, the on-hit recheck, and the
+// site-row poller.
+//
+// Notes
+// -----
+// • InvalidateGrace is shortened per test; pools are sqlmock connections so
+//   the test can see exactly when Close happens.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func shortGrace(t *testing.T, d time.Duration) {
+	old := InvalidateGrace
+	InvalidateGrace = d
+	t.Cleanup(func() { InvalidateGrace = old })
+}
+
+// cachedTenant stores a tenant backed by a sqlmock pool that expects Close.
+func cachedTenant(t *testing.T, c *Cache, host string, updated time.Time) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectClose()
+	ten := &Tenant{
+		DB:   sqlx.NewDb(db, "mysql"),
+		Meta: meta.Record{Host: host, UpdatedAt: updated},
+		host: host,
+	}
+	c.m.Store(host, &entry{tenant: ten, lastSeen: time.Now().UnixNano()})
+	return mock
+}
+
+func TestInvalidate_SwapThenCloseAfterGrace(t *testing.T) {
+	shortGrace(t, 50*time.Millisecond)
+	c := New(nil, time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	mock := cachedTenant(t, c, "a.example", time.Time{})
+
+	var evicted []string
+	OnEvict(func(h string) { evicted = append(evicted, h) })
+
+	if !c.Invalidate("a.example") {
+		t.Fatal("Invalidate reported not cached")
+	}
+	if _, ok := c.m.Load("a.example"); ok {
+		t.Fatal("entry still cached")
+	}
+	if mock.ExpectationsWereMet() == nil {
+		t.Fatal("pool closed before grace period")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("pool not closed after grace: %v", err)
+	}
+	if len(evicted) == 0 || evicted[len(evicted)-1] != "a.example" {
+		t.Fatalf("OnEvict hooks not run: %v", evicted)
+	}
+	if c.Invalidate("a.example") {
+		t.Fatal("second Invalidate should report not cached")
+	}
+}
+
+func TestInvalidateAll(t *testing.T) {
+	shortGrace(t, 0)
+	c := New(nil, time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	cachedTenant(t, c, "a.example", time.Time{})
+	cachedTenant(t, c, "b.example", time.Time{})
+
+	if n := c.InvalidateAll(); n != 2 {
+		t.Fatalf("InvalidateAll = %d", n)
+	}
+}
+
+func TestInvalidateStale_UpdatedOrGone(t *testing.T) {
+	shortGrace(t, 0)
+	db, global, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(sqlx.NewDb(db, "mysql"), time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	replica, _, err := sqlmock.New() // no expectations: the poll must not read it
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReadDB(sqlx.NewDb(replica, "mysql"))
+
+	t0 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cachedTenant(t, c, "same.example", t0)
+	cachedTenant(t, c, "edited.example", t0)
+	cachedTenant(t, c, "gone.example", t0)
+
+	global.ExpectQuery("FROM\\s+site").WillReturnRows(
+		sqlmock.NewRows([]string{"host", "updated_at"}).
+			AddRow("same.example", t0).
+			AddRow("edited.example", t0.Add(time.Minute)))
+
+	if n := c.invalidateStale(); n != 2 {
+		t.Fatalf("invalidated %d, want 2", n)
+	}
+	if _, ok := c.m.Load("same.example"); !ok {
+		t.Fatal("unchanged tenant dropped")
+	}
+	for _, h := range []string{"edited.example", "gone.example"} {
+		if _, ok := c.m.Load(h); ok {
+			t.Fatalf("%s still cached", h)
+		}
+	}
+}
+
+func TestInvalidateStale_RouteVersionAppliedInPlace(t *testing.T) {
+	shortGrace(t, 0)
+	db, global, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(sqlx.NewDb(db, "mysql"), time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+
+	t0 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cachedTenant(t, c, "a.example", t0)
+	v, _ := c.m.Load("a.example")
+	ten := v.(*entry).tenant
+	ten.SetRouteVersion(1)
+
+	global.ExpectQuery("FROM\\s+site").WillReturnRows(
+		sqlmock.NewRows([]string{"host", "updated_at", "route_version"}).
+			AddRow("a.example", t0, 2))
+
+	if n := c.invalidateStale(); n != 0 {
+		t.Fatalf("invalidated %d, want 0", n)
+	}
+	if got, ok := c.m.Load("a.example"); !ok || got.(*entry).tenant != ten {
+		t.Fatal("tenant dropped on a route_version bump")
+	}
+	if ten.RouteVersion() != 2 {
+		t.Fatalf("route version = %d, want 2", ten.RouteVersion())
+	}
+
+	// An older listing never rolls the version back.
+	ten.SetRouteVersion(3)
+	global.ExpectQuery("FROM\\s+site").WillReturnRows(
+		sqlmock.NewRows([]string{"host", "updated_at", "route_version"}).
+			AddRow("a.example", t0, 2))
+	c.invalidateStale()
+	if ten.RouteVersion() != 3 {
+		t.Fatalf("route version = %d, want 3 (kept)", ten.RouteVersion())
+	}
+}
+
+// recheckCache returns a Cache on a sqlmock global DB with a fixed clock.
+func recheckCache(t *testing.T) (*Cache, sqlmock.Sqlmock, *time.Time) {
+	t.Helper()
+	shortGrace(t, 0)
+	db, global, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(sqlx.NewDb(db, "mysql"), time.Hour, 0, zap.NewNop().Sugar(), nil)
+	t.Cleanup(c.Stop)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, global, &now
+}
+
+func TestRecheck_DropsChangedRows(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cols := []string{"route_version", "updated_at"}
+
+	cases := []struct {
+		name string
+		rows *sqlmock.Rows
+		err  error
+		drop bool
+	}{
+		{"unchanged", sqlmock.NewRows(cols).AddRow(3, t0), nil, false},
+		{"route_version", sqlmock.NewRows(cols).AddRow(4, t0), nil, false}, // rebuilt in place
+		{"updated_at", sqlmock.NewRows(cols).AddRow(3, t0.Add(time.Second)), nil, true},
+		{"gone", sqlmock.NewRows(cols), nil, true},
+		{"db error", nil, errors.New("boom"), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, global, _ := recheckCache(t)
+			cachedTenant(t, c, "a.example", t0)
+			v, _ := c.m.Load("a.example")
+			ent := v.(*entry)
+			ent.tenant.Meta.RouteVersion = 3
+			ent.tenant.SetRouteVersion(3)
+
+			q := global.ExpectQuery("SELECT route_version").WithArgs("a.example")
+			if tc.err != nil {
+				q.WillReturnError(tc.err)
+			} else {
+				q.WillReturnRows(tc.rows)
+			}
+
+			if got := c.recheck("a.example", ent); got != tc.drop {
+				t.Fatalf("recheck = %v, want %v", got, tc.drop)
+			}
+			if tc.name == "route_version" && ent.tenant.RouteVersion() != 4 {
+				t.Fatalf("route version = %d, want 4", ent.tenant.RouteVersion())
+			}
+			if err := global.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestMaybeRecheck_Throttled(t *testing.T) {
+	c, global, now := recheckCache(t)
+	t0 := *now
+	cachedTenant(t, c, "a.example", t0)
+	v, _ := c.m.Load("a.example")
+	ent := v.(*entry)
+	ent.checkedAt = now.UnixNano()
+
+	// Within the interval: no probe, no query.
+	*now = now.Add(RecheckInterval - time.Second)
+	c.maybeRecheck("a.example", ent)
+	if atomic.LoadInt32(&ent.checking) != 0 || ent.checkedAt != t0.UnixNano() {
+		t.Fatal("probe started inside the recheck interval")
+	}
+
+	// Past it: one background probe that drops the changed row.
+	global.ExpectQuery("SELECT route_version").
+		WillReturnRows(sqlmock.NewRows([]string{"route_version", "updated_at"}).
+			AddRow(0, t0.Add(time.Second)))
+	*now = now.Add(2 * time.Second)
+	c.maybeRecheck("a.example", ent)
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&ent.checking) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("probe did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := c.m.Load("a.example"); ok {
+		t.Fatal("changed tenant not invalidated")
+	}
+	if err := global.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
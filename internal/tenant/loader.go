@@ -2,52 +2,173 @@
 //
 // host → Tenant loader (Vault-aware).
 //
-// Performs five blocking steps per cold-load and finally runs per-tenant
-// Component initialisers.
+// Resolves the request host to a site row (exact, alias, or wildcard),
+// then performs four blocking steps per cold-load, applies pending Component
+// migrations once the tenant DB is open, and finally runs per-tenant
+// Component initialisers.  The tenant pool is sized from site_config
+// (db.max_open_conns, db.max_idle_conns, db.conn_max_lifetime) when set
+// and valid, else from the defaults below.
 
 package tenant
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 
 	"github.com/yanizio/adept/internal/component"
 	"github.com/yanizio/adept/internal/database"
+	"github.com/yanizio/adept/internal/migrate"
 	"github.com/yanizio/adept/internal/tenant/meta"
 	"github.com/yanizio/adept/internal/theme"
 	"github.com/yanizio/adept/internal/vault"
 )
 
 //
-// loader
+// resolver
 //
 
-// loadSite executes the slow-path load in five well-defined steps, then
-// invokes Init hooks for every registered Component.
-func loadSite(
+// resolveSite maps a request host to its site row: the exact host first,
+// then site_host_alias and wildcard rows (meta.ByAlias).  redirect is true
+// for an alias flagged to 301 to rec.Host.  No match yields ErrNotFound;
+// DB trouble is wrapped, never reported as a 404.
+func resolveSite(
 	ctx context.Context,
 	global *sqlx.DB,
 	host string,
-	vcli *vault.Client,
-) (*Tenant, error) {
+) (rec *meta.Record, redirect bool, err error) {
 
-	// 1. resolve alias and fetch site row
 	lookupHost := resolveLookupHost(host)
-	rec, err := meta.ByHost(ctx, global, lookupHost)
-	if err != nil {
-		return nil, ErrNotFound
+	rec, err = meta.ByHost(ctx, global, lookupHost)
+	if errors.Is(err, sql.ErrNoRows) {
+		rec, redirect, err = meta.ByAlias(ctx, global, lookupHost)
 	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, false, ErrNotFound
+	case err != nil:
+		return nil, false, fmt.Errorf("site lookup: %w", err)
+	}
+	return rec, redirect, nil
+}
+
+//
+// pool sizing
+//
+
+// Per-tenant pool defaults.  Precedence, highest first: a valid site_config
+// override (db.max_open_conns, db.max_idle_conns, db.conn_max_lifetime),
+// then these values.  An override that does not parse or is not positive
+// is ignored with a WARN.  database/sql caps idle at max open by itself.
+const (
+	defaultPoolMaxOpen  = 5
+	defaultPoolMaxIdle  = 2
+	defaultPoolLifetime = 30 * time.Minute
+)
+
+func init() {
+	component.DeclareConfig(
+		component.ConfigKey{Name: "db.max_open_conns", Type: component.ConfigInt, Default: "5"},
+		component.ConfigKey{Name: "db.max_idle_conns", Type: component.ConfigInt, Default: "2"},
+		component.ConfigKey{Name: "db.conn_max_lifetime", Type: component.ConfigDuration, Default: "30m"},
+	)
+}
 
-	// 2. key-value config
+// poolOptions builds the tenant pool options from cfg's db.* overrides.
+func poolOptions(cfg SiteConfig, log *zap.SugaredLogger) database.Options {
+	opts := database.Options{
+		MaxOpenConns:    defaultPoolMaxOpen,
+		MaxIdleConns:    defaultPoolMaxIdle,
+		ConnMaxLifetime: defaultPoolLifetime,
+		Retries:         2,
+		RetryBackoff:    500 * time.Millisecond,
+	}
+	invalid := func(key string) {
+		log.Warnw("site_config pool override invalid – using default",
+			"key", key, "value", cfg[key])
+	}
+	positiveInt := func(key string, dst *int) {
+		if v := cfg[key]; v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				*dst = n
+			} else {
+				invalid(key)
+			}
+		}
+	}
+	positiveInt("db.max_open_conns", &opts.MaxOpenConns)
+	positiveInt("db.max_idle_conns", &opts.MaxIdleConns)
+	if v := cfg["db.conn_max_lifetime"]; v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			opts.ConnMaxLifetime = d
+		} else {
+			invalid("db.conn_max_lifetime")
+		}
+	}
+	return opts
+}
+
+//
+// theme modules
+//
+
+// themeModules returns the module trees handed to theme.Manager.Load: core
+// first, then each enabled Component by name.  A nil or empty set means
+// component_acl is absent or empty, and every registered Component counts
+// as enabled, matching Router.
+func themeModules(enabled map[string]struct{}) []string {
+	if len(enabled) == 0 {
+		enabled = component.AllNames()
+	}
+	names := make([]string, 0, len(enabled))
+	for n := range enabled {
+		if n != "core" {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{"core"}, names...)
+}
+
+//
+// loader
+//
+
+// loadSite executes the slow-path load for an already resolved site row in
+// four well-defined steps, then invokes Init hooks for every registered
+// Component.  The Tenant is keyed by rec.Host, the canonical host, so every
+// alias of a site shares one aggregate.  global only serves the read-only
+// site_config query, so the Cache passes its replica when one is set.  base
+// is the cache logger; the Tenant keeps a child of it tagged with
+// "tenant"=host (see GetLogger).
+func loadSite(
+	ctx context.Context,
+	global *sqlx.DB,
+	rec *meta.Record,
+	vcli vault.Secrets,
+	base *zap.SugaredLogger,
+) (*Tenant, error) {
+
+	host := rec.Host
+	log := base.With("tenant", host)
+
+	// 1. key-value config
 	cfg, err := meta.ConfigBySite(ctx, global, rec.ID)
 	if err != nil {
 		return nil, err
 	}
+	for _, p := range checkSiteConfig(cfg, component.ConfigKeys()) {
+		log.Warnw("site_config: " + p)
+	}
 
-	// 3. resolve password and build DSN
+	// 2. resolve password and build DSN
 	key := sanitizeHost(host)
 	pw, err := vcli.GetKV(
 		ctx,
@@ -58,40 +179,72 @@ func loadSite(
 	if err != nil {
 		return nil, err
 	}
-	dsn := buildTenantDSN(key, pw)
+	dsn := tenantDSN(rec, key, pw)
 
-	// 4. tenant DB pool
-	opts := database.Options{
-		MaxOpenConns:    5,
-		MaxIdleConns:    2,
-		ConnMaxLifetime: 30 * time.Minute,
-		Retries:         2,
-		RetryBackoff:    500 * time.Millisecond,
-	}
+	// 3. tenant DB pool, sized by site_config db.* overrides (poolOptions)
+	opts := poolOptions(cfg, log)
 	db, err := database.OpenProvider(ctx, func() string { return dsn }, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// 5. theme parsing
-	enabledComponents := []string{"core"} // TODO: pull from ACL table
-	mgr := theme.Manager{BaseDir: "themes"}
-	th, err := mgr.Load(rec.Theme, enabledComponents)
+	//    optional read replica; a dead replica degrades to the primary
+	//    rather than failing the load
+	var replica *sqlx.DB
+	if rdsn := tenantReplicaDSN(rec, key, pw); rdsn != "" {
+		replica, err = database.OpenProvider(ctx, func() string { return rdsn }, opts)
+		if err != nil {
+			log.Warnw("read replica unavailable – reads use primary",
+				"replica", *rec.DBReplica, "err", err)
+			replica = nil
+		}
+	}
+
+	//    component migrations: pending Migrations() of every registered
+	//    Component, under an advisory lock (see internal/migrate)
+	if _, err := migrate.Run(ctx, db, component.All(), log); err != nil {
+		_ = db.Close()
+		if replica != nil {
+			_ = replica.Close()
+		}
+		return nil, err
+	}
+
+	// 4. theme parsing: core plus the Components enabled in component_acl,
+	//    so disabled Components' widget and template trees are skipped
+	enabled, err := enabledComponents(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		if replica != nil {
+			_ = replica.Close()
+		}
+		return nil, err
+	}
+	modules := themeModules(enabled)
+	mgr := theme.Manager{BaseDir: themeBaseDir}
+	th, err := mgr.Load(rec.Theme, modules)
 	if err != nil {
 		return nil, err
 	}
 
 	// Assemble Tenant
 	ten := &Tenant{
-		Meta:     *rec,
-		Config:   cfg,
-		DB:       db,
-		Theme:    th,
-		Renderer: th.Renderer,
-		Vault:    vcli, // expose Vault to Components
+		Meta:         *rec,
+		Config:       cfg,
+		DB:           db,
+		replica:      replica,
+		Theme:        th,
+		Renderer:     th.Renderer,
+		Vault:        vcli, // expose Vault to Components
+		themeModules: modules,
+		host:         host,
+		log:          log,
 	}
+	ten.SetRouteVersion(rec.RouteVersion)
+	ten.setRoutingMode(rec.RoutingMode)
+	ten.useAssets(th)
 
-	// Run per-tenant Init hooks (if implemented).
+	// Run per-tenant Init hooks (if implemented), dependencies first.
 	for _, c := range component.All() {
 		if initc, ok := c.(component.Initializer); ok {
 			if err := initc.Init(ten); err != nil {
@@ -100,5 +253,10 @@ func loadSite(
 		}
 	}
 
+	// Register the pools for database.Conn(ctx), which form actions and
+	// other context-only callers use; Bind tags each request with host.
+	database.RegisterTenant(host, db)
+	database.RegisterTenantReplica(host, replica)
+
 	return ten, nil
 }
@@ -0,0 +1,144 @@
+// internal/tenant/loader_test.go
+//
+// Unit-tests for loadSite helpers: per-tenant pool overrides and the theme
+// module list derived from component_acl.
+//
+// Notes
+// -----
+// • WARN lines are captured with zap's observer core.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/theme"
+)
+
+func TestPoolOptions_Overrides(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      SiteConfig
+		open     int
+		idle     int
+		lifetime time.Duration
+		warns    int
+	}{
+		{"defaults", SiteConfig{}, 5, 2, 30 * time.Minute, 0},
+		{"large tenant", SiteConfig{
+			"db.max_open_conns":    "30",
+			"db.max_idle_conns":    "10",
+			"db.conn_max_lifetime": "1h",
+		}, 30, 10, time.Hour, 0},
+		{"tiny site", SiteConfig{"db.max_open_conns": "2", "db.max_idle_conns": "1"},
+			2, 1, 30 * time.Minute, 0},
+		{"unparsable", SiteConfig{"db.max_open_conns": "lots", "db.conn_max_lifetime": "30"},
+			5, 2, 30 * time.Minute, 2},
+		{"not positive", SiteConfig{"db.max_idle_conns": "0", "db.conn_max_lifetime": "-5m"},
+			5, 2, 30 * time.Minute, 2},
+		{"empty ignored", SiteConfig{"db.max_open_conns": ""}, 5, 2, 30 * time.Minute, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			got := poolOptions(tc.cfg, zap.New(core).Sugar())
+			if got.MaxOpenConns != tc.open || got.MaxIdleConns != tc.idle ||
+				got.ConnMaxLifetime != tc.lifetime {
+				t.Fatalf("opts = %d/%d/%v, want %d/%d/%v", got.MaxOpenConns,
+					got.MaxIdleConns, got.ConnMaxLifetime, tc.open, tc.idle, tc.lifetime)
+			}
+			if logs.Len() != tc.warns {
+				t.Fatalf("warns = %d, want %d", logs.Len(), tc.warns)
+			}
+			for _, e := range logs.All() {
+				if _, ok := e.ContextMap()["key"]; !ok {
+					t.Fatalf("warn without key field: %v", e.ContextMap())
+				}
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestThemeModules_SkipsDisabledComponents(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "themes/t/templates/home.html", "home")
+	writeFile(t, "modules/alpha/templates/alpha.html", `{{ define "alpha.html" }}a{{ end }}`)
+	writeFile(t, "modules/beta/templates/beta.html", `{{ define "beta.html" }}b{{ end }}`)
+
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	mock.ExpectQuery("SELECT component FROM component_acl").
+		WillReturnRows(sqlmock.NewRows([]string{"component"}).AddRow("alpha"))
+
+	set, err := enabledComponents(context.Background(), sqlx.NewDb(raw, "mysql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mods := themeModules(set)
+	if len(mods) != 2 || mods[0] != "core" || mods[1] != "alpha" {
+		t.Fatalf("modules = %v, want [core alpha]", mods)
+	}
+
+	mgr := theme.Manager{BaseDir: themeBaseDir}
+	th, err := mgr.Load("t", mods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Renderer.Lookup("alpha.html") == nil {
+		t.Error("enabled component's template was not parsed")
+	}
+	if th.Renderer.Lookup("beta.html") != nil {
+		t.Error("disabled component's template was parsed")
+	}
+}
+
+func TestThemeModules_MissingACLMeansAll(t *testing.T) {
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	mock.ExpectQuery("SELECT component FROM component_acl").
+		WillReturnError(errors.New("Error 1146: Table 'component_acl' doesn't exist"))
+
+	set, err := enabledComponents(context.Background(), sqlx.NewDb(raw, "mysql"))
+	mods := themeModules(set)
+	if err != nil || set != nil || mods[0] != "core" {
+		t.Fatalf("set = %v, err = %v, modules = %v; want nil set and core first", set, err, mods)
+	}
+	for name := range component.AllNames() {
+		found := false
+		for _, m := range mods {
+			found = found || m == name
+		}
+		if !found {
+			t.Fatalf("registered component %q missing from %v", name, mods)
+		}
+	}
+}
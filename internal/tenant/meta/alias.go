@@ -0,0 +1,90 @@
+// internal/tenant/meta/alias.go
+//
+// Alias and wildcard host lookup.
+//
+// Context
+// -------
+// A Host header that misses the exact `ByHost` lookup may still belong to
+// a site in two ways:
+//
+//   • `site_host_alias` — a vanity domain mapped to a canonical site, with
+//     a `redirect` flag saying whether to 301 or to serve in place.
+//   • Wildcard rows    — a `site.host` like "*.app.example.com" that covers
+//     every subdomain below it.
+//
+// `ByAlias` answers both in one round-trip.  An explicit alias beats a
+// wildcard, and a longer (more specific) wildcard beats a shorter one.
+//
+// Notes
+// -----
+//   • Wildcards match one or more leading labels and need at least two
+//     labels after "*.", so "*.com" is never generated.
+//   • Returns sql.ErrNoRows when nothing matches, like `ByHost`.
+//   • Oxford commas, two spaces after periods.
+
+package meta
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// aliasRow is a site Record plus the alias columns of the match.
+type aliasRow struct {
+	Record
+	Redirect bool `db:"redirect"`
+	Prio     int  `db:"prio"`
+}
+
+// WildcardPatterns returns the wildcard site hosts that could cover host,
+// most specific first: "a.b.example.com" → "*.b.example.com",
+// "*.example.com".
+func WildcardPatterns(host string) []string {
+	labels := strings.Split(host, ".")
+	var out []string
+	for i := 1; len(labels)-i >= 2; i++ {
+		out = append(out, "*."+strings.Join(labels[i:], "."))
+	}
+	return out
+}
+
+// ByAlias resolves host through `site_host_alias`, then through wildcard
+// site rows.  redirect reports whether the alias asks for a 301 to
+// rec.Host; wildcard matches never redirect.
+func ByAlias(ctx context.Context, db *sqlx.DB, host string) (rec *Record, redirect bool, err error) {
+	const cols = `s.id, s.host, s.theme, s.locale, s.routing_mode, s.route_version,
+               s.preload, s.db_host, s.db_replica_host,
+               s.suspended_at, s.deleted_at, s.created_at, s.updated_at`
+	q := `
+        SELECT ` + cols + `, a.redirect AS redirect, 0 AS prio
+        FROM   site_host_alias a
+        JOIN   site s ON s.id = a.site_id
+        WHERE  a.alias_host = ?
+          AND  s.suspended_at IS NULL
+          AND  s.deleted_at   IS NULL`
+	args := []any{host}
+
+	if pats := WildcardPatterns(host); len(pats) > 0 {
+		q += `
+        UNION ALL
+        SELECT ` + cols + `, 0 AS redirect, 1 AS prio
+        FROM   site s
+        WHERE  s.host IN (?` + strings.Repeat(", ?", len(pats)-1) + `)
+          AND  s.suspended_at IS NULL
+          AND  s.deleted_at   IS NULL`
+		for _, p := range pats {
+			args = append(args, p)
+		}
+	}
+	q += `
+        ORDER  BY prio, LENGTH(host) DESC
+        LIMIT  1`
+
+	var row aliasRow
+	if err := db.GetContext(ctx, &row, q, args...); err != nil {
+		return nil, false, err
+	}
+	return &row.Record, row.Redirect, nil
+}
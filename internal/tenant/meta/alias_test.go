@@ -0,0 +1,24 @@
+// internal/tenant/meta/alias_test.go
+//
+// Unit-tests for wildcard pattern generation.
+
+package meta
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWildcardPatterns(t *testing.T) {
+	cases := map[string][]string{
+		"a.b.example.com": {"*.b.example.com", "*.example.com"},
+		"c1.example.com":  {"*.example.com"},
+		"example.com":     nil,
+		"localhost":       nil,
+	}
+	for host, want := range cases {
+		if got := WildcardPatterns(host); !reflect.DeepEqual(got, want) {
+			t.Errorf("WildcardPatterns(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
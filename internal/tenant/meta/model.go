@@ -20,6 +20,8 @@
 //	    routing_mode  VARCHAR(6)    NOT NULL DEFAULT 'path',
 //	    route_version INT           NOT NULL DEFAULT 0,
 //	    preload       TINYINT(1)    NOT NULL DEFAULT 0,
+//	    db_host       VARCHAR(256)  NULL,
+//	    db_replica_host VARCHAR(256) NULL,
 //	    suspended_at  TIMESTAMP NULL,
 //	    deleted_at    TIMESTAMP NULL,
 //	    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -46,6 +48,8 @@ type Record struct {
 	RoutingMode  string     `db:"routing_mode"`
 	RouteVersion int        `db:"route_version"`
 	Preload      bool       `db:"preload"`
+	DBHost       *string    `db:"db_host"`         // tenant DB host:port; nil = global default
+	DBReplica    *string    `db:"db_replica_host"` // read-replica host:port; nil = none
 	SuspendedAt  *time.Time `db:"suspended_at"`
 	DeletedAt    *time.Time `db:"deleted_at"`
 	CreatedAt    time.Time  `db:"created_at"`
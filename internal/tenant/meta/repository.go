@@ -9,6 +9,8 @@
 //
 //   • `AllActive` — admin dashboards, cron jobs, batch reports.
 //   • `ByHost`    — tenant loader on first request.
+//   • `VersionByHost` — freshness probe on cache hits.
+//   • `ByAlias`   — alias and wildcard fallback (see alias.go).
 //
 // The DSN column has been dropped from the schema — per-tenant passwords
 // now come from Vault and the DSN is built at runtime.  Only non-secret
@@ -26,7 +28,10 @@ package meta
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -41,7 +46,8 @@ import (
 func AllActive(db *sqlx.DB) ([]Record, error) {
 	const q = `
         SELECT id, host, theme, locale, routing_mode, route_version,
-               preload, suspended_at, deleted_at, created_at, updated_at
+               preload, db_host, db_replica_host,
+               suspended_at, deleted_at, created_at, updated_at
         FROM   site
         WHERE  suspended_at IS NULL
           AND  deleted_at   IS NULL`
@@ -61,7 +67,8 @@ func AllActive(db *sqlx.DB) ([]Record, error) {
 func ByHost(ctx context.Context, db *sqlx.DB, host string) (*Record, error) {
 	const q = `
         SELECT id, host, theme, locale, routing_mode, route_version,
-               preload, suspended_at, deleted_at, created_at, updated_at
+               preload, db_host, db_replica_host,
+               suspended_at, deleted_at, created_at, updated_at
         FROM   site
         WHERE  host = ?
           AND  suspended_at IS NULL
@@ -76,3 +83,28 @@ func ByHost(ctx context.Context, db *sqlx.DB, host string) (*Record, error) {
 	}
 	return &rec, nil
 }
+
+//
+// VersionByHost
+//
+
+// VersionByHost returns the route_version and updated_at of an active site.
+// It is the cheap freshness probe the tenant cache runs on hits; ok is false
+// when the row is gone, suspended, or deleted.
+func VersionByHost(ctx context.Context, db *sqlx.DB, host string) (routeVersion int, updatedAt time.Time, ok bool, err error) {
+	const q = `
+        SELECT route_version, updated_at
+        FROM   site
+        WHERE  host = ?
+          AND  suspended_at IS NULL
+          AND  deleted_at   IS NULL
+        LIMIT  1`
+	err = db.QueryRowxContext(ctx, q, host).Scan(&routeVersion, &updatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, time.Time{}, false, nil
+	case err != nil:
+		return 0, time.Time{}, false, err
+	}
+	return routeVersion, updatedAt, true, nil
+}
@@ -0,0 +1,246 @@
+// internal/tenant/mount.go
+//
+// Mounting Component routers on the tenant router.
+//
+// Context
+// -------
+// chi refuses a second Mount at the same pattern, so Components without a
+// prefix cannot each be mounted at “/”.  mountComponents copies the routes
+// of every root Component into one shared sub-router (middleware included)
+// and mounts that once; Components with a prefix are mounted at it as-is.
+//
+// A Component's prefix is its Prefix() (component.Prefixer), unless the
+// tenant's component_acl.mount_prefix overrides it; an override of "/"
+// mounts a prefixed Component at the root.
+//
+// Middleware
+// ----------
+// A Component's Middlewares() (component.MiddlewareProvider) wrap each of
+// its routes.  For one request the order is:
+//
+//  1. tenant-wide middleware from router.go (alias, requestinfo, cors),
+//  2. the Component's Middlewares(), first entry outermost,
+//  3. the permission check a Guarded Component declares for the route,
+//  4. middleware registered inside the Component's Routes(), and
+//  5. the handler.
+//
+// Root Components keep this per route through the merge, so one root
+// Component's middleware never runs for another's routes, nor for requests
+// that reach NotFound.  A prefixed Component's middleware wraps its whole
+// mount, so it also sees unmatched paths under the prefix.
+//
+// Permissions
+// -----------
+// Routes of a Guarded Component (component/access.go) are re-registered one
+// by one, each wrapped in PermissionGuard for its declared Access; Public
+// and undeclared routes are left as they are.  PermissionGuard is set by
+// internal/acl, which imports this package.  Until it is, a route that
+// declares a permission answers 500 rather than opening up.  A guarded
+// prefixed Component loses any NotFound of its own router to the tenant's.
+//
+// Conflicts
+// ---------
+// Before mounting, the routes of every enabled Component are walked at
+// their effective prefix, and each method and path claimed twice is logged
+// at WARN with the Components involved.  Root routes are copied in
+// component.All() order, so the later Component serves a contested route.
+// A second Component with an already-mounted prefix is skipped rather than
+// panicking the tenant.
+//
+// Notes
+// -----
+// • Alias rewrite runs before routing, so route_alias.target_path must be
+//   the absolute path including any prefix (/auth/login, not /login).
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+// PermissionGuard returns middleware enforcing component/action for the
+// current user.  internal/acl sets it to RequirePermission.
+var PermissionGuard func(component, action string) func(http.Handler) http.Handler
+
+// guardedRoutes returns c.Routes(), with each route c declares a
+// permission for wrapped in PermissionGuard.
+func guardedRoutes(c component.Component) chi.Router {
+	routes := c.Routes()
+	if _, ok := c.(component.Guarded); !ok {
+		return routes
+	}
+	out := chi.NewRouter()
+	_ = chi.Walk(routes, func(method, route string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+		if a, ok := component.AccessOf(c, method, route); ok && !a.Public {
+			mws = append([]func(http.Handler) http.Handler{guard(a)}, mws...)
+		}
+		if method == "*" {
+			out.With(mws...).Handle(route, h)
+			return nil
+		}
+		out.With(mws...).Method(method, route, h)
+		return nil
+	})
+	return out
+}
+
+// guard resolves PermissionGuard per request, so a guard installed after
+// the router was built still applies.
+func guard(a component.Access) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if PermissionGuard == nil {
+				zap.S().Errorw("route declares a permission but no guard is installed",
+					"component", a.Component, "action", a.Action, "path", r.URL.Path)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			PermissionGuard(a.Component, a.Action)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeOwner is the Component behind one mounted method and path, and the
+// Access it declares there.
+type routeOwner struct {
+	component string
+	access    component.Access
+	declared  bool
+}
+
+// mountComponents mounts comps on r: root Components merged at “/”, the
+// rest at their prefix.  overrides maps a Component name to the tenant's
+// mount_prefix for it.  It returns the owner of every route it mounted,
+// keyed by method and full path (see Tenant.Routes).
+func mountComponents(r chi.Router, comps []component.Component, overrides map[string]string, log *zap.SugaredLogger) map[string]routeOwner {
+	prefixOf := func(c component.Component) string {
+		if p, ok := overrides[c.Name()]; ok {
+			return component.NormalizePrefix(p)
+		}
+		return component.PrefixOf(c)
+	}
+
+	for _, col := range component.CollisionsWith(comps, prefixOf) {
+		log.Warnw("component route collision",
+			"method", col.Method, "path", col.Path, "components", col.Components)
+	}
+
+	var root chi.Router
+	mounted := map[string]string{} // prefix → component name
+	owners := map[string]routeOwner{}
+	own := func(c component.Component, prefix string) {
+		_ = chi.Walk(c.Routes(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			a, ok := component.AccessOf(c, method, route)
+			owners[method+" "+joinRoute(prefix, route)] = routeOwner{c.Name(), a, ok}
+			return nil
+		})
+	}
+
+	for _, c := range comps {
+		prefix := prefixOf(c)
+		if prefix != "/" {
+			if prev, dup := mounted[prefix]; dup {
+				log.Warnw("component prefix already mounted – skipped",
+					"prefix", prefix, "component", c.Name(), "mounted", prev)
+				continue
+			}
+			mounted[prefix] = c.Name()
+			sub := guardedRoutes(c)
+			if mws := component.MiddlewaresOf(c); len(mws) > 0 {
+				// A *chi.Mux, not chi.Chain, so the tenant NotFound still
+				// reaches unmatched paths under the prefix.
+				wrapped := chi.NewRouter()
+				wrapped.Use(mws...)
+				wrapped.Mount("/", sub)
+				sub = wrapped
+			}
+			r.Mount(prefix, sub)
+			continue
+		}
+
+		if root == nil {
+			root = chi.NewRouter()
+		}
+		compMws := component.MiddlewaresOf(c)
+		_ = chi.Walk(guardedRoutes(c), func(method, route string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+			mws = append(append([]func(http.Handler) http.Handler(nil), compMws...), mws...)
+			if method == "*" { // registered with Handle: every method
+				root.With(mws...).Handle(route, h)
+				return nil
+			}
+			root.With(mws...).Method(method, route, h)
+			return nil
+		})
+	}
+	if root != nil {
+		r.Mount("/", root)
+	}
+	// Root routes first, the last Component winning; a mounted prefix then
+	// shadows a root route under it, as chi does.
+	for _, c := range comps {
+		if prefixOf(c) == "/" {
+			own(c, "/")
+		}
+	}
+	for prefix, name := range mounted {
+		for _, c := range comps {
+			if c.Name() == name {
+				own(c, prefix)
+			}
+		}
+	}
+	return owners
+}
+
+// joinRoute appends route to a mount prefix without doubling the slash.
+func joinRoute(prefix, route string) string {
+	if prefix == "/" {
+		return route
+	}
+	if route == "/" {
+		return prefix
+	}
+	return prefix + route
+}
+
+// mountPrefixes reads per-tenant prefix overrides from
+// component_acl.mount_prefix.  A schema without the column, like a missing
+// table, means no overrides.
+func mountPrefixes(ctx context.Context, db *sqlx.DB, log *zap.SugaredLogger) map[string]string {
+	if db == nil {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx,
+		`SELECT component, mount_prefix FROM component_acl
+		  WHERE enabled = 1 AND mount_prefix IS NOT NULL AND mount_prefix <> ''`)
+	if err != nil {
+		if !isUnknownTable(err) && !isUnknownColumn(err) {
+			log.Errorw("component_acl mount_prefix query failed", "err", err)
+		}
+		return nil
+	}
+	defer rows.Close()
+
+	out := map[string]string{}
+	for rows.Next() {
+		var name, prefix string
+		if err := rows.Scan(&name, &prefix); err != nil {
+			log.Errorw("component_acl mount_prefix scan", "err", err)
+			return nil
+		}
+		out[name] = prefix
+	}
+	if err := rows.Err(); err != nil {
+		log.Errorw("component_acl mount_prefix rows", "err", err)
+		return nil
+	}
+	return out
+}
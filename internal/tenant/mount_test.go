@@ -0,0 +1,252 @@
+// internal/tenant/mount_test.go
+//
+// Unit-tests for mountComponents.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+// mountComp answers each of its paths with its own name.
+type mountComp struct {
+	name, prefix string
+	paths        []string
+}
+
+func (m mountComp) Name() string                    { return m.name }
+func (m mountComp) Migrations() []string            { return nil }
+func (m mountComp) Init(component.TenantInfo) error { return nil }
+func (m mountComp) Prefix() string                  { return m.prefix }
+func (m mountComp) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler { // component middleware survives the merge
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Component", m.name)
+			next.ServeHTTP(w, req)
+		})
+	})
+	for _, p := range m.paths {
+		r.Get(p, func(w http.ResponseWriter, _ *http.Request) { _, _ = io.WriteString(w, m.name) })
+	}
+	return r
+}
+
+func TestMountComponents_PrefixAndRoot(t *testing.T) {
+	r := chi.NewRouter()
+	mountComponents(r, []component.Component{
+		mountComp{name: "auth", prefix: "/auth", paths: []string{"/login"}},
+		mountComp{name: "blog", paths: []string{"/posts"}},
+		mountComp{name: "shop", paths: []string{"/cart", "/login"}},
+		mountComp{name: "dupe", prefix: "/auth/", paths: []string{"/other"}},
+	}, nil, zap.NewNop().Sugar())
+
+	for path, want := range map[string]string{
+		"/auth/login": "auth",
+		"/posts":      "blog",
+		"/cart":       "shop",
+		"/login":      "shop",
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want || rec.Header().Get("X-Component") != want {
+			t.Errorf("GET %s = %d %q (X-Component %q), want %s",
+				path, rec.Code, rec.Body, rec.Header().Get("X-Component"), want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("skipped duplicate prefix still mounted: %d", rec.Code)
+	}
+}
+
+// mwComp is a mountComp with Middlewares that append to X-Trace.
+type mwComp struct{ mountComp }
+
+func (m mwComp) Middlewares() []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{trace(m.name + ".outer"), trace(m.name + ".inner")}
+}
+
+func trace(step string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("X-Trace", step)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func TestMountComponents_Middlewares(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(trace("global"))
+	mountComponents(r, []component.Component{
+		mwComp{mountComp{name: "auth", prefix: "/auth", paths: []string{"/login"}}},
+		mwComp{mountComp{name: "blog", paths: []string{"/posts"}}},
+		mountComp{name: "shop", paths: []string{"/cart"}},
+	}, nil, zap.NewNop().Sugar())
+	r.NotFound(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) })
+
+	for path, want := range map[string]string{
+		"/auth/login": "global auth.outer auth.inner",
+		"/posts":      "global blog.outer blog.inner",
+		"/cart":       "global", // blog's middleware stays on blog's routes
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := strings.Join(rec.Header().Values("X-Trace"), " "); got != want || rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d trace %q, want %q", path, rec.Code, got, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/missing", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("unmatched path under prefix = %d, want tenant NotFound", rec.Code)
+	}
+}
+
+// guardedComp is a mountComp with an Access map.
+type guardedComp struct {
+	mountComp
+	access map[string]component.Access
+}
+
+func (g guardedComp) Access() map[string]component.Access { return g.access }
+
+func TestMountComponents_DeclaredPermissions(t *testing.T) {
+	defer func(g func(string, string) func(http.Handler) http.Handler) { PermissionGuard = g }(PermissionGuard)
+	PermissionGuard = func(comp, action string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("X-Allow") != comp+"/"+action {
+					http.Error(w, "denied", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+
+	r := chi.NewRouter()
+	mountComponents(r, []component.Component{
+		guardedComp{mountComp{name: "blog", paths: []string{"/posts", "/drafts"}},
+			map[string]component.Access{"/posts": component.Public, "/drafts": component.Permission("edit")}},
+		guardedComp{mountComp{name: "admin", prefix: "/admin", paths: []string{"/users"}},
+			map[string]component.Access{"/*": component.Permission("manage")}},
+	}, nil, zap.NewNop().Sugar())
+
+	for _, tc := range []struct {
+		path, allow string
+		want        int
+	}{
+		{"/posts", "", http.StatusOK},
+		{"/drafts", "", http.StatusForbidden},
+		{"/drafts", "blog/edit", http.StatusOK},
+		{"/admin/users", "", http.StatusForbidden},
+		{"/admin/users", "admin/manage", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("X-Allow", tc.allow)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("GET %s (allow %q) = %d, want %d", tc.path, tc.allow, rec.Code, tc.want)
+		}
+	}
+
+	// Without a guard, declared routes fail closed.
+	PermissionGuard = nil
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/drafts", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("no guard: GET /drafts = %d, want 500", rec.Code)
+	}
+}
+
+func TestMountComponents_CollisionWarnsAndOverride(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	r := chi.NewRouter()
+	mountComponents(r, []component.Component{
+		mountComp{name: "auth", paths: []string{"/login"}},
+		mountComp{name: "sso", paths: []string{"/login"}},
+		mountComp{name: "blog", prefix: "/blog", paths: []string{"/posts"}},
+	}, map[string]string{"sso": "sso/", "blog": "/"}, zap.New(core).Sugar())
+
+	if n := logs.FilterMessage("component route collision").Len(); n != 0 {
+		t.Fatalf("override left %d collisions", n)
+	}
+	for path, want := range map[string]string{"/login": "auth", "/sso/login": "sso", "/posts": "blog"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Body.String() != want {
+			t.Errorf("GET %s = %d %q, want %s", path, rec.Code, rec.Body, want)
+		}
+	}
+
+	// Without the override both claim GET /login; the later one serves it.
+	core, logs = observer.New(zapcore.WarnLevel)
+	r = chi.NewRouter()
+	mountComponents(r, []component.Component{
+		mountComp{name: "auth", paths: []string{"/login"}},
+		mountComp{name: "sso", paths: []string{"/login"}},
+	}, nil, zap.New(core).Sugar())
+
+	col := logs.FilterMessage("component route collision").All()
+	if len(col) != 1 {
+		t.Fatalf("collision lines = %d, want 1", len(col))
+	}
+	f := col[0].ContextMap()
+	if f["method"] != "GET" || f["path"] != "/login" {
+		t.Fatalf("collision fields = %v", f)
+	}
+	if names, _ := f["components"].([]any); len(names) != 2 || names[0] != "auth" || names[1] != "sso" {
+		t.Fatalf("components = %#v", f["components"])
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if rec.Body.String() != "sso" {
+		t.Fatalf("GET /login served by %q, want sso", rec.Body)
+	}
+}
+
+func TestMountPrefixes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	x := sqlx.NewDb(db, "mysql")
+	log := zap.NewNop().Sugar()
+
+	mock.ExpectQuery("SELECT component, mount_prefix FROM component_acl").
+		WillReturnRows(sqlmock.NewRows([]string{"component", "mount_prefix"}).AddRow("auth", "/account"))
+	if got := mountPrefixes(context.Background(), x, log); got["auth"] != "/account" || len(got) != 1 {
+		t.Fatalf("prefixes = %v", got)
+	}
+
+	mock.ExpectQuery("SELECT component, mount_prefix").
+		WillReturnError(errors.New("Error 1054: Unknown column 'mount_prefix'"))
+	if got := mountPrefixes(context.Background(), x, log); got != nil {
+		t.Fatalf("old schema prefixes = %v, want nil", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
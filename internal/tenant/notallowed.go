@@ -0,0 +1,110 @@
+// internal/tenant/notallowed.go
+//
+// 405 Method Not Allowed with an accurate Allow header.
+//
+// Context
+// -------
+// chi lists the permitted methods only in its built-in 405 handler, and it
+// keeps that list private.  Once a custom handler is installed, the list is
+// gone, so methodNotAllowed rebuilds it by probing the tenant router: each
+// candidate method is matched against the request path, descending into
+// mounted Component routers, so the Allow header covers exactly the routes
+// that exist at that path.
+//
+// Response body
+// -------------
+//   - API requests (a path under /api, or an Accept header that asks for
+//     JSON but not HTML) get {"error": …, "allow": […]}.
+//   - Everything else renders the theme's 405.html when present, with
+//     .Config, .Method, and .Allow; otherwise a plain-text 405.
+//
+// Notes
+// -----
+// • The path is probed after alias rewrite, so friendly URLs report the
+//   methods of the route they map to.
+// • OPTIONS is always listed: CORS preflights and plain OPTIONS requests
+//   are answered automatically (cors.go).
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// probeMethods are the methods tried when building the Allow header.
+var probeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// methodNotAllowed answers 405 with an Allow header for the request path.
+// OPTIONS is never refused; it goes to handleOptions (cors.go).
+func (t *Tenant) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	allow := allowedMethods(r)
+	if r.Method == http.MethodOptions {
+		t.handleOptions(w, r, allow)
+		return
+	}
+	if !slices.Contains(allow, http.MethodOptions) {
+		allow = append(allow, http.MethodOptions) // answered by handleOptions
+	}
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": "method not allowed",
+			"allow": allow,
+		})
+		return
+	}
+
+	if tmpl := t.GetRenderer(); tmpl != nil && tmpl.Lookup("405.html") != nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = tmpl.ExecuteTemplate(w, "405.html", map[string]any{
+			"Config": t.Config,
+			"Method": r.Method,
+			"Allow":  allow,
+		})
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// allowedMethods returns the methods the routing tree accepts for r's path.
+func allowedMethods(r *http.Request) []string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	var out []string
+	for _, m := range probeMethods {
+		if rctx.Routes.Match(chi.NewRouteContext(), m, path) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// wantsJSON reports whether r is an API call that expects a JSON body.
+func wantsJSON(r *http.Request) bool {
+	p := r.URL.Path
+	if p == "/api" || strings.HasPrefix(p, "/api/") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") &&
+		!strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,91 @@
+// internal/tenant/notallowed_test.go
+//
+// Unit-tests for the 405 handler: Allow header through mounted routers,
+// JSON for API paths, and the themed HTML body.
+
+package tenant
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// notAllowedRouter mirrors Router(): a component mounted at "/" and the
+// 405 handler installed on the tenant router afterwards.
+func notAllowedRouter(ten *Tenant) http.Handler {
+	comp := chi.NewRouter()
+	comp.Get("/login", func(http.ResponseWriter, *http.Request) {})
+	comp.Post("/login", func(http.ResponseWriter, *http.Request) {})
+	comp.Route("/api", func(api chi.Router) {
+		api.Delete("/items/{id}", func(http.ResponseWriter, *http.Request) {})
+	})
+
+	r := chi.NewRouter()
+	r.Mount("/", comp)
+	r.MethodNotAllowed(ten.methodNotAllowed)
+	return r
+}
+
+func serve(h http.Handler, method, target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMethodNotAllowed_AllowFromMountedRoutes(t *testing.T) {
+	h := notAllowedRouter(&Tenant{})
+	rr := serve(h, http.MethodPut, "/login", "text/html")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	if got := rr.Header().Get("Allow"); got != "GET, POST, OPTIONS" {
+		t.Fatalf("Allow = %q, want %q", got, "GET, POST, OPTIONS")
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("Content-Type = %q, want plain-text fallback", ct)
+	}
+}
+
+func TestMethodNotAllowed_JSONForAPI(t *testing.T) {
+	h := notAllowedRouter(&Tenant{})
+	rr := serve(h, http.MethodGet, "/api/items/7", "")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	if got := rr.Header().Get("Allow"); got != "DELETE, OPTIONS" {
+		t.Fatalf("Allow = %q", got)
+	}
+	var body struct {
+		Error string   `json:"error"`
+		Allow []string `json:"allow"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if len(body.Allow) != 2 || body.Allow[0] != "DELETE" {
+		t.Fatalf("allow = %v", body.Allow)
+	}
+}
+
+func TestMethodNotAllowed_ThemedPage(t *testing.T) {
+	tmpl := template.Must(template.New("405.html").Parse(
+		`<h1>{{ .Method }} not allowed</h1>{{ range .Allow }}<i>{{ . }}</i>{{ end }}`))
+	h := notAllowedRouter(&Tenant{Renderer: tmpl})
+	rr := serve(h, http.MethodDelete, "/login", "text/html")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	if body := rr.Body.String(); body != "<h1>DELETE not allowed</h1><i>GET</i><i>POST</i><i>OPTIONS</i>" {
+		t.Fatalf("body = %q", body)
+	}
+}
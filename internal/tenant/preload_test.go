@@ -0,0 +1,68 @@
+// internal/tenant/preload_test.go
+//
+// Unit-tests for the warm-up worker pool.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func TestWarmHosts_BoundedAndTolerant(t *testing.T) {
+	c := &Cache{log: zap.NewNop().Sugar()}
+
+	var hosts []string
+	for i := 0; i < 20; i++ {
+		hosts = append(hosts, fmt.Sprintf("h%d.example", i))
+	}
+
+	var inflight, peak int64
+	n, failed := c.warmHosts(context.Background(), hosts, func(h string) error {
+		cur := atomic.AddInt64(&inflight, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if cur <= p || atomic.CompareAndSwapInt64(&peak, p, cur) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt64(&inflight, -1)
+		if h == "h3.example" || h == "h7.example" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if n != 18 || failed != 2 {
+		t.Fatalf("warmed = %d, failed = %d; want 18, 2", n, failed)
+	}
+	if peak > PreloadWorkers {
+		t.Fatalf("peak concurrency %d exceeds %d workers", peak, PreloadWorkers)
+	}
+}
+
+func TestPinned_RequiresPreload(t *testing.T) {
+	cfg := SiteConfig{"cache.pinned": "true"}
+	if (&Tenant{Config: cfg}).pinned() {
+		t.Fatal("cache.pinned honoured without preload")
+	}
+	if !(&Tenant{Meta: meta.Record{Preload: true}, Config: cfg}).pinned() {
+		t.Fatal("preloaded + cache.pinned should pin")
+	}
+	if (&Tenant{Meta: meta.Record{Preload: true}}).pinned() {
+		t.Fatal("preload alone should not pin")
+	}
+}
@@ -3,13 +3,27 @@
 // Cached per-tenant router.
 //
 // Each Tenant lazily builds an in-memory chi.Router on first use and caches it
-// in `t.router`.  The router mounts only those Components that are enabled for
+// in `t.router`, tagged with the route_version it was built for; a later
+// version triggers a rebuild (see Router).  The router mounts only those Components that are enabled for
 // the tenant (via `component_acl`) and wires middleware in the following order:
 //
 //   1. **alias-rewrite** – rewrites friendly URLs → absolute component paths
-//   2. **request-info**  – enriches the context with GeoIP / UA hints
-//   3. **component routes** – mounts each enabled Component at “/”
-//   4. **NotFound**      – final fallback renders home.html or 404
+//   2. **request-info**  – enriches the context with GeoIP / UA hints,
+//      resolves the session email to a user ID for ACL checks, then
+//      builds the request's tenant.Context once and stashes it (context.go)
+//   3. **cors**          – CORS headers for allowed origins (cors.go)
+//   4. **assets**        – /assets/* from the site → theme chain (assets.go)
+//   5. **sitemap**       – /sitemap.xml and its parts from route_alias and
+//      enabled SitemapProvider Components (internal/sitemap), unless
+//      site_config sitemap.enabled = false
+//   6. **component routes** – mounts each enabled Component at its Prefix()
+//      or the tenant's component_acl.mount_prefix, or merged with the other
+//      root Components at “/”; route collisions are logged, and each
+//      Component's Middlewares() wrap its own routes (mount.go)
+//   7. **NotFound**      – final fallback renders home.html or 404
+//   8. **MethodNotAllowed** – 405 with an accurate Allow header, JSON for
+//      API routes (notallowed.go); OPTIONS and CORS preflights are
+//      answered here from the registered methods
 //
 // All per-request logging or analytics will be handled later by the analytics
 // package; no experimental middleware is referenced here.
@@ -19,16 +33,19 @@ package tenant
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"go.uber.org/zap"
+	"github.com/jmoiron/sqlx"
 
 	"github.com/yanizio/adept/internal/component"
 	"github.com/yanizio/adept/internal/requestinfo"
 	"github.com/yanizio/adept/internal/routing"
+	"github.com/yanizio/adept/internal/sitemap"
+	"github.com/yanizio/adept/internal/tenant/meta"
 )
 
 const (
@@ -37,74 +54,237 @@ const (
 	RouteModeBoth      = routing.RouteModeBoth
 )
 
-// Router builds (once) and returns the http.Handler for this tenant.
+// routeModeOf maps a site.routing_mode value to a RouteMode* constant.
+// The schema default "path" predates the constants and means alias-enabled
+// with absolute paths still served, i.e. RouteModeBoth; so do "" and
+// unknown values, so a typo never 404s a site or hides its aliases.
+func routeModeOf(col string) string {
+	switch col {
+	case RouteModeAbsolute, RouteModeAliasOnly, RouteModeBoth:
+		return col
+	}
+	return RouteModeBoth
+}
+
+// aclTimeout bounds the component_acl read done while building a router.
+const aclTimeout = 2 * time.Second
+
+// builtRouter is a tenant router, the route_version it was built for, and
+// the Component behind each of its routes (Routes).
+type builtRouter struct {
+	ver     int
+	builtAt time.Time
+	h       chi.Router
+	owners  map[string]routeOwner
+}
+
+// Router returns the http.Handler for this tenant, building it on first
+// use and rebuilding it when RouteVersion has moved since.  A rebuild
+// re-reads component_acl, so enabling a Component takes effect with the
+// next route_version bump.  While one request rebuilds, concurrent requests
+// keep getting the previous router; only the very first build blocks.
+// A build that cannot read component_acl is not cached: the previous
+// router keeps serving, or with none yet the request gets a 503, and the
+// next request tries again.
 func (t *Tenant) Router() http.Handler {
-	t.routerOnce.Do(func() {
-		r := chi.NewRouter()
-
-		// ---------------------------------------------------------------------
-		// 1. Alias rewrite → absolute path.
-		// ---------------------------------------------------------------------
-		r.Use(routing.Middleware(t))
-
-		// ---------------------------------------------------------------------
-		// 2. Enrich request context (GeoIP, UA family, etc.).
-		// ---------------------------------------------------------------------
-		r.Use(requestinfo.Enrich)
-
-		// ---------------------------------------------------------------------
-		// 3. Mount each enabled Component.
-		// ---------------------------------------------------------------------
-		enabled := t.fetchEnabledComponents(context.Background())
-		if len(enabled) == 0 {
-			zap.L().Warn("component_acl empty – mounting all components",
-				zap.String("host", t.Host()))
-			enabled = component.AllNames()
+	ver := t.RouteVersion()
+	cur := t.router.Load()
+	if cur != nil && cur.ver == ver {
+		return cur.h
+	}
+	if cur == nil {
+		t.routerMu.Lock()
+	} else if !t.routerMu.TryLock() {
+		return cur.h // rebuild in progress
+	}
+	defer t.routerMu.Unlock()
+
+	if cur = t.router.Load(); cur != nil && cur.ver == ver {
+		return cur.h // built while we waited
+	}
+	b, err := t.buildRouter(ver)
+	if err != nil {
+		t.GetLogger().Errorw("tenant router not rebuilt", "route_version", ver, "err", err)
+		if cur != nil {
+			return cur.h
 		}
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable),
+				http.StatusServiceUnavailable)
+		})
+	}
+	t.router.Store(b)
+	if cur != nil {
+		t.GetLogger().Infow("tenant router rebuilt",
+			"route_version", ver, "previous", cur.ver)
+	}
+	return b.h
+}
 
-		for _, c := range component.All() {
-			if _, ok := enabled[c.Name()]; ok {
-				r.Mount("/", c.Routes())
-			}
+// RebuildRouter builds a fresh router now and swaps it in, re-reading
+// component_acl and site.routing_mode, without touching the DB pools or
+// cached config.  Requests that already hold the old router finish on it;
+// later ones get the new one.  It waits for a rebuild already in progress.
+// If the site row cannot be read the current routing mode is kept; if
+// component_acl cannot be read the current router is kept and the error
+// returned.
+func (t *Tenant) RebuildRouter() error {
+	t.routerMu.Lock()
+	defer t.routerMu.Unlock()
+	if t.siteDB != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), aclTimeout)
+		rec, err := meta.ByHost(ctx, t.siteDB, t.Meta.Host)
+		cancel()
+		if err != nil {
+			t.GetLogger().Warnw("routing_mode not re-read", "err", err)
+		} else if old := t.RoutingMode(); routeModeOf(rec.RoutingMode) != old {
+			t.setRoutingMode(rec.RoutingMode)
+			t.GetLogger().Infow("tenant routing mode changed",
+				"routing_mode", t.RoutingMode(), "previous", old)
 		}
+	}
+	ver := t.RouteVersion()
+	b, err := t.buildRouter(ver)
+	if err != nil {
+		t.GetLogger().Errorw("tenant router not rebuilt", "route_version", ver, "err", err)
+		return err
+	}
+	t.router.Store(b)
+	t.GetLogger().Infow("tenant router rebuilt", "route_version", ver, "forced", true)
+	return nil
+}
 
-		// ---------------------------------------------------------------------
-		// 4. Fallback – render home page or plain 404.
-		// ---------------------------------------------------------------------
-		r.NotFound(func(w http.ResponseWriter, req *http.Request) {
-			err := t.Renderer.ExecuteTemplate(w, "home.html",
-				map[string]any{"Config": t.Config})
-			if err != nil {
-				http.NotFound(w, req)
-			}
-		})
+// buildRouter assembles a fresh router for route_version ver; see the
+// file header for the order.  It fails, rather than mount every
+// Component, when component_acl exists but cannot be read.
+func (t *Tenant) buildRouter(ver int) (*builtRouter, error) {
+	enabled, prefixes, err := t.fetchEnabledComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	r := chi.NewRouter()
+
+	// ---------------------------------------------------------------------
+	// 1. Alias rewrite → absolute path.
+	// ---------------------------------------------------------------------
+	r.Use(routing.Middleware(t))
+
+	// ---------------------------------------------------------------------
+	// 2. Enrich request context (GeoIP, UA family, session user, etc.).
+	// ---------------------------------------------------------------------
+	r.Use(requestinfo.Enrich)
+	r.Use(t.bindUser)
+	r.Use(bindContext)
+
+	// ---------------------------------------------------------------------
+	// 3. CORS headers on actual requests (preflights: step 7).
+	// ---------------------------------------------------------------------
+	r.Use(t.cors)
+
+	// ---------------------------------------------------------------------
+	// 4. Static assets: sites/<host>/assets → themes/<theme>/assets.
+	// ---------------------------------------------------------------------
+	r.Get(assetPrefix+"*", t.ServeAsset)
+	r.Head(assetPrefix+"*", t.ServeAsset)
 
-		t.router = r
+	// ---------------------------------------------------------------------
+	// 5–6. Sitemap, then each enabled Component at its prefix or at “/”.
+	// ---------------------------------------------------------------------
+	if len(enabled) == 0 {
+		t.GetLogger().Warn("component_acl empty – mounting all components")
+		enabled = component.AllNames()
+	}
+
+	var comps []component.Component
+	for _, c := range component.All() {
+		if _, ok := enabled[c.Name()]; ok {
+			comps = append(comps, c)
+		}
+	}
+	if t.Config.Bool(sitemap.EnabledKey, true) {
+		sm := t.sitemapHandler(comps)
+		for _, p := range []string{sitemap.Path, sitemap.PartPrefix + "{n}.xml"} {
+			r.Get(p, sm.ServeHTTP)
+			r.Head(p, sm.ServeHTTP)
+		}
+	}
+	owners := mountComponents(r, comps, prefixes, t.GetLogger())
+
+	// ---------------------------------------------------------------------
+	// 7. Fallback – render home page or plain 404.
+	// ---------------------------------------------------------------------
+	r.NotFound(func(w http.ResponseWriter, req *http.Request) {
+		err := t.GetRenderer().ExecuteTemplate(w, "home.html",
+			map[string]any{"Config": t.Config})
+		if err != nil {
+			http.NotFound(w, req)
+		}
 	})
-	return t.router
+
+	// ---------------------------------------------------------------------
+	// 8. Wrong method on a known path – chi hands it to every mounted
+	//    Component router too.  Unregistered OPTIONS lands here as well.
+	// ---------------------------------------------------------------------
+	r.MethodNotAllowed(t.methodNotAllowed)
+
+	return &builtRouter{ver: ver, builtAt: time.Now(), h: r, owners: owners}, nil
 }
 
 //
 // helpers
 //
 
-var _ sync.Once // keep go vet happy if routerOnce defined elsewhere
+// sitemapHandler builds the sitemap for this router generation, asking
+// the enabled Components in comps that implement SitemapProvider.
+func (t *Tenant) sitemapHandler(comps []component.Component) *sitemap.Handler {
+	var providers []component.SitemapProvider
+	for _, c := range comps {
+		if p, ok := c.(component.SitemapProvider); ok {
+			providers = append(providers, p)
+		}
+	}
+	base := t.Config.String(sitemap.BaseURLKey, "")
+	if base == "" {
+		base = "https://" + t.host
+	}
+	return sitemap.New(t, providers, sitemap.Options{
+		BaseURL: base,
+		TTL:     t.Config.Duration(sitemap.TTLKey, sitemap.DefaultTTL),
+	})
+}
+
+// fetchEnabledComponents returns a set[name] for components enabled in ACL
+// and their per-tenant mount prefix overrides (mount.go).  Both queries
+// share aclTimeout so a slow tenant DB cannot hang the request that
+// triggered the build.
+func (t *Tenant) fetchEnabledComponents() (map[string]struct{}, map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), aclTimeout)
+	defer cancel()
+	enabled, err := enabledComponents(ctx, t.GetDB())
+	if err != nil {
+		return nil, nil, err
+	}
+	return enabled, mountPrefixes(ctx, t.GetDB(), t.GetLogger()), nil
+}
 
-// fetchEnabledComponents returns a set[name] for components enabled in ACL.
-func (t *Tenant) fetchEnabledComponents(ctx context.Context) map[string]struct{} {
-	db := t.GetDB()
+// enabledComponents reads component_acl from a tenant DB.  A nil set and
+// nil error mean there is no DB or the table is not yet migrated; callers
+// then treat every Component as enabled.  Any other failure, a timeout
+// included, is returned so callers never fail open on it.  loadSite uses
+// it for theme modules before the Tenant exists.
+func enabledComponents(ctx context.Context, db *sqlx.DB) (map[string]struct{}, error) {
 	if db == nil {
-		return nil
+		return nil, nil
 	}
 
 	rows, err := db.QueryContext(ctx,
 		`SELECT component FROM component_acl WHERE enabled = 1`)
 	if err != nil {
 		if isUnknownTable(err) {
-			return nil // ACL table not yet migrated—treat as “all enabled”.
+			return nil, nil // ACL table not yet migrated—treat as “all enabled”.
 		}
-		zap.L().Error("component_acl query failed", zap.Error(err))
-		return nil
+		return nil, fmt.Errorf("component_acl query: %w", err)
 	}
 	defer rows.Close()
 
@@ -112,12 +292,24 @@ func (t *Tenant) fetchEnabledComponents(ctx context.Context) map[string]struct{}
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
-			zap.L().Error("component_acl scan", zap.Error(err))
-			return nil
+			return nil, fmt.Errorf("component_acl scan: %w", err)
 		}
 		set[name] = struct{}{}
 	}
-	return set
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("component_acl rows: %w", err)
+	}
+	return set, nil
+}
+
+// isUnknownColumn recognises MariaDB (1054) and Postgres (42703) “column
+// does not exist” errors, for columns added after a tenant was installed.
+func isUnknownColumn(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "1054") || strings.Contains(msg, "42703")
 }
 
 // isUnknownTable recognises MariaDB (error 1146) and Cockroach/Postgres (42P01)
@@ -0,0 +1,235 @@
+// internal/tenant/router_test.go
+//
+// Unit-tests for the versioned tenant router.
+
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"github.com/yanizio/adept/internal/component"
+)
+
+func TestRouter_RebuildsOnRouteVersion(t *testing.T) {
+	ten := &Tenant{host: "a.example"}
+	ten.SetRouteVersion(1)
+
+	first := ten.Router()
+	if ten.Router() != first {
+		t.Fatal("same route_version rebuilt the router")
+	}
+
+	ten.SetRouteVersion(2)
+	second := ten.Router()
+	if second == first {
+		t.Fatal("route_version bump did not rebuild the router")
+	}
+	if b := ten.router.Load(); b.ver != 2 {
+		t.Fatalf("built version = %d, want 2", b.ver)
+	}
+
+}
+
+func TestRouter_OldRouterDuringRebuild(t *testing.T) {
+	ten := &Tenant{host: "a.example"}
+	old := ten.Router()
+	ten.SetRouteVersion(5)
+
+	// Another request holds the rebuild lock: callers get the old router
+	// instead of waiting.
+	ten.routerMu.Lock()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ten.Router() != old {
+				t.Error("caller did not get the old router mid-rebuild")
+			}
+		}()
+	}
+	wg.Wait()
+	ten.routerMu.Unlock()
+
+	if ten.Router() == old {
+		t.Fatal("router not rebuilt once the lock was free")
+	}
+}
+
+func TestRebuildRouter_SwapsWhileServing(t *testing.T) {
+	ten := &Tenant{host: "a.example"}
+	old := ten.Router()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() { // readers race the swap; -race checks the pointer
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if ten.Router() == nil {
+					t.Error("nil router during rebuild")
+					return
+				}
+			}
+		}()
+	}
+	ten.RebuildRouter()
+	wg.Wait()
+
+	if ten.Router() == old {
+		t.Fatal("RebuildRouter did not swap in a new router")
+	}
+	if b := ten.router.Load(); b.ver != ten.RouteVersion() {
+		t.Fatalf("rebuilt for version %d, want %d", b.ver, ten.RouteVersion())
+	}
+}
+
+func TestRouteModeOf(t *testing.T) {
+	for col, want := range map[string]string{
+		"absolute": RouteModeAbsolute,
+		"alias":    RouteModeAliasOnly,
+		"both":     RouteModeBoth,
+		"path":     RouteModeBoth, // schema default
+		"":         RouteModeBoth,
+		"bogus":    RouteModeBoth,
+	} {
+		if got := routeModeOf(col); got != want {
+			t.Errorf("routeModeOf(%q) = %q, want %q", col, got, want)
+		}
+	}
+}
+
+func TestRebuildRouter_RereadsRoutingMode(t *testing.T) {
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	ten := &Tenant{host: "a.example", siteDB: sqlx.NewDb(raw, "mysql")}
+	ten.Meta.Host = "a.example"
+	ten.setRoutingMode("path")
+
+	mock.ExpectQuery("FROM\\s+site").WithArgs("a.example").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "host", "routing_mode"}).
+			AddRow(1, "a.example", "alias"))
+	mock.ExpectQuery("FROM\\s+site").WithArgs("a.example").
+		WillReturnError(errors.New("db down"))
+
+	ten.RebuildRouter()
+	if got := ten.RoutingMode(); got != RouteModeAliasOnly {
+		t.Fatalf("mode after rebuild = %q, want %q", got, RouteModeAliasOnly)
+	}
+	ten.RebuildRouter() // read fails: mode kept
+	if got := ten.RoutingMode(); got != RouteModeAliasOnly {
+		t.Fatalf("mode after failed re-read = %q", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoutes_ListsLiveRouterWithOwners(t *testing.T) {
+	ten := &Tenant{host: "a.example"}
+	if got := ten.Routes(); len(got) == 0 || got[0].Pattern != assetPrefix+"*" || got[0].Component != "" {
+		t.Fatalf("fresh router routes = %+v, want the asset routes", got)
+	}
+
+	r := chi.NewRouter()
+	r.Get(assetPrefix+"*", ten.ServeAsset)
+	owners := mountComponents(r, []component.Component{
+		guardedComp{mountComp{name: "blog", paths: []string{"/posts", "/drafts"}},
+			map[string]component.Access{"/posts": component.Public, "/drafts": component.Permission("edit")}},
+		mountComp{name: "auth", prefix: "/auth", paths: []string{"/login"}},
+	}, nil, zap.NewNop().Sugar())
+	ten.router.Store(&builtRouter{ver: ten.RouteVersion(), h: r, owners: owners})
+
+	want := []RouteInfo{
+		{Method: "GET", Pattern: "/assets/*"},
+		{Method: "GET", Pattern: "/auth/login", Component: "auth", Undeclared: true},
+		{Method: "GET", Pattern: "/drafts", Component: "blog", Permission: "blog/edit"},
+		{Method: "GET", Pattern: "/posts", Component: "blog", Public: true},
+	}
+	got := ten.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("Routes = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("route %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// aclTenant is a Tenant whose DB is a sqlmock, for component_acl reads.
+func aclTenant(t *testing.T) (*Tenant, sqlmock.Sqlmock) {
+	t.Helper()
+	raw, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { raw.Close() })
+	return &Tenant{host: "a.example", DB: sqlx.NewDb(raw, "mysql")}, mock
+}
+
+func expectACL(mock sqlmock.Sqlmock, names ...string) {
+	rows := sqlmock.NewRows([]string{"component"})
+	for _, n := range names {
+		rows.AddRow(n)
+	}
+	mock.ExpectQuery("SELECT component FROM component_acl").WillReturnRows(rows)
+	mock.ExpectQuery("mount_prefix").
+		WillReturnRows(sqlmock.NewRows([]string{"component", "mount_prefix"}))
+}
+
+func TestRouter_ACLErrorKeepsPreviousRouter(t *testing.T) {
+	ten, mock := aclTenant(t)
+	expectACL(mock, "alpha")
+	old := ten.Router()
+
+	ten.SetRouteVersion(2)
+	mock.ExpectQuery("SELECT component FROM component_acl").
+		WillReturnError(context.DeadlineExceeded)
+	if ten.Router() != old {
+		t.Fatal("ACL timeout replaced the router")
+	}
+	if b := ten.router.Load(); b.ver != 0 {
+		t.Fatalf("cached version = %d after failed build, want 0", b.ver)
+	}
+	if err := ten.RebuildRouter(); err == nil {
+		t.Fatal("RebuildRouter ignored the ACL error")
+	}
+
+	expectACL(mock, "alpha")
+	if ten.Router() == old {
+		t.Fatal("router not rebuilt once the ACL was readable")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouter_ACLErrorOnFirstBuildIs503(t *testing.T) {
+	ten, mock := aclTenant(t)
+	mock.ExpectQuery("SELECT component FROM component_acl").
+		WillReturnRows(sqlmock.NewRows([]string{"component"}).
+			AddRow("alpha").RowError(0, errors.New("conn reset")))
+
+	rec := httptest.NewRecorder()
+	ten.Router().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if ten.router.Load() != nil {
+		t.Fatal("router cached after a failed first build")
+	}
+}
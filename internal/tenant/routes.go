@@ -0,0 +1,72 @@
+// internal/tenant/routes.go
+//
+// Listing of the live tenant router.
+//
+// Context
+// -------
+// Developers asking "what is mounted here, and who owns it?" used to read
+// component code.  Routes walks the router this Tenant is serving right
+// now (chi.Walk) and annotates every method and pattern with the Component
+// that mounted it and the ACL the tenant router wrapped around it, as
+// recorded by mountComponents.  /admin/routes serves it as JSON.
+//
+// Notes
+// -----
+// • Framework routes (/assets/*) have no Component.
+// • Permission is set only where a check is actually applied; Public marks
+//   a route declared open, and Undeclared one no Access covers.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RouteInfo is one method and pattern on the live tenant router.
+type RouteInfo struct {
+	Method     string `json:"method"`
+	Pattern    string `json:"pattern"`
+	Component  string `json:"component,omitempty"`
+	Permission string `json:"permission,omitempty"` // "component/action"
+	Public     bool   `json:"public,omitempty"`
+	Undeclared bool   `json:"undeclared,omitempty"`
+}
+
+// Routes returns every route of the router Router() serves, building it
+// first if needed, sorted by pattern and method.
+func (t *Tenant) Routes() []RouteInfo {
+	t.Router()
+	b := t.router.Load()
+	if b == nil {
+		return nil
+	}
+
+	var out []RouteInfo
+	_ = chi.Walk(b.h, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		info := RouteInfo{Method: method, Pattern: route}
+		if o, ok := b.owners[method+" "+route]; ok {
+			info.Component = o.component
+			switch {
+			case !o.declared:
+				info.Undeclared = true
+			case o.access.Public:
+				info.Public = true
+			default:
+				info.Permission = o.access.Component + "/" + o.access.Action
+			}
+		}
+		out = append(out, info)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Pattern != out[j].Pattern {
+			return out[i].Pattern < out[j].Pattern
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
@@ -0,0 +1,92 @@
+// internal/tenant/siteconfig.go
+//
+// Typed accessors over the site_config key→value map.
+//
+// Context
+// -------
+// site_config stores every value as a string, so each consumer used to
+// re-parse ints, bools, and durations and invent its own handling for
+// missing or malformed keys.  SiteConfig is the same map with helpers:
+//
+//	max := ten.Config.Int("auth.max_attempts", 5)
+//	ttl := ten.Config.Duration("cache.ttl", 5*time.Minute)
+//	raw := ten.Config["theme"]                    // raw access still works
+//
+// Rules
+// -----
+//   - Missing or empty key            → default, silently.
+//   - Present but unparsable          → default, plus a WARN naming the key.
+//   - Bool accepts strconv.ParseBool forms ("1", "true", "FALSE", …).
+//
+// Notes
+// -----
+// • SiteConfig is a defined map type, so it converts freely to and from
+//   map[string]string; component.TenantInfo keeps its raw signature.
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SiteConfig is a tenant's site_config map.
+type SiteConfig map[string]string
+
+// String returns the raw value or def when the key is missing or empty.
+func (c SiteConfig) String(key, def string) string {
+	if v := c[key]; v != "" {
+		return v
+	}
+	return def
+}
+
+// Int parses key as a base-10 integer.
+func (c SiteConfig) Int(key string, def int) int {
+	v, ok := c[key]
+	if !ok || v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		warnParse(key, v, err)
+		return def
+	}
+	return n
+}
+
+// Bool parses key with strconv.ParseBool.
+func (c SiteConfig) Bool(key string, def bool) bool {
+	v, ok := c[key]
+	if !ok || v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		warnParse(key, v, err)
+		return def
+	}
+	return b
+}
+
+// Duration parses key with time.ParseDuration ("90s", "15m", "2h").
+func (c SiteConfig) Duration(key string, def time.Duration) time.Duration {
+	v, ok := c[key]
+	if !ok || v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		warnParse(key, v, err)
+		return def
+	}
+	return d
+}
+
+func warnParse(key, val string, err error) {
+	zap.L().Warn("site_config value invalid – using default",
+		zap.String("key", key), zap.String("value", val), zap.Error(err))
+}
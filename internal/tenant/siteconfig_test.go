@@ -0,0 +1,56 @@
+// internal/tenant/siteconfig_test.go
+//
+// Unit-tests for SiteConfig typed accessors.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSiteConfig_Accessors(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	c := SiteConfig{
+		"n":     "7",
+		"bad_n": "seven",
+		"on":    "true",
+		"ttl":   "90s",
+		"empty": "",
+	}
+
+	if got := c.Int("n", 1); got != 7 {
+		t.Fatalf("Int = %d", got)
+	}
+	if got := c.Int("bad_n", 1); got != 1 {
+		t.Fatalf("Int(bad) = %d, want default", got)
+	}
+	if got := c.Int("missing", 3); got != 3 {
+		t.Fatalf("Int(missing) = %d", got)
+	}
+	if !c.Bool("on", false) || c.Bool("empty", false) {
+		t.Fatal("Bool mismatch")
+	}
+	if got := c.Duration("ttl", time.Second); got != 90*time.Second {
+		t.Fatalf("Duration = %v", got)
+	}
+	if got := c.String("empty", "x"); got != "x" {
+		t.Fatalf("String(empty) = %q", got)
+	}
+	if c["n"] != "7" {
+		t.Fatal("raw map access broken")
+	}
+
+	if logs.Len() != 1 || logs.All()[0].ContextMap()["key"] != "bad_n" {
+		t.Fatalf("want one warning for bad_n, got %v", logs.All())
+	}
+}
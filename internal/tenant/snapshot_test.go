@@ -0,0 +1,83 @@
+// internal/tenant/snapshot_test.go
+//
+// Unit-tests for Cache.Snapshot.
+
+package tenant
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func TestSnapshot_EntriesAndPoolStats(t *testing.T) {
+	c, now, _ := evictCache(t, time.Hour, 0)
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	seed(c, *now, "b.example", 90*time.Second, true)
+	c.m.Store("a.example", &entry{
+		tenant: &Tenant{
+			host: "a.example",
+			Meta: meta.Record{Host: "a.example", Theme: "base"},
+			DB:   sqlx.NewDb(db, "sqlmock"),
+		},
+		lastSeen: now.Add(-time.Minute).UnixNano(),
+		loadedAt: now.Add(-time.Hour).UnixNano(),
+	})
+	v, _ := c.m.Load("a.example")
+	v.(*entry).tenant.SetRouteVersion(7)
+
+	snap := c.Snapshot()
+	if len(snap) != 2 || snap[0].Host != "a.example" || snap[1].Host != "b.example" {
+		t.Fatalf("snapshot hosts = %+v", snap)
+	}
+	a := snap[0]
+	if a.Theme != "base" || a.RouteVersion != 7 || a.IdleSeconds != 60 ||
+		!a.LoadedAt.Equal(now.Add(-time.Hour)) || a.Replica != nil {
+		t.Fatalf("a.example = %+v", a)
+	}
+	if a.Pool.Open != 1 || a.Pool.Idle != 1 || a.Pool.InUse != 0 { // sqlmock opens one conn on New
+		t.Fatalf("pool = %+v", a.Pool)
+	}
+	if b := snap[1]; !b.Pinned || b.IdleSeconds != 90 || b.Pool != (PoolStats{}) {
+		t.Fatalf("b.example = %+v", b)
+	}
+}
+
+// Snapshot and hits run side by side; go test -race checks the entry reads.
+func TestSnapshot_ConcurrentWithHits(t *testing.T) {
+	c, now, _ := evictCache(t, time.Hour, 0)
+	c.SetRecheckInterval(0)
+	for _, h := range []string{"a.example", "b.example", "c.example"} {
+		seed(c, *now, h, 0, false)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				c.hit("b.example")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if n := len(c.Snapshot()); n != 3 {
+					t.Errorf("snapshot len = %d", n)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
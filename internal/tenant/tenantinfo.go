@@ -7,6 +7,8 @@ package tenant
 
 import (
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
 	"github.com/yanizio/adept/internal/theme"
 	"github.com/yanizio/adept/internal/vault"
 )
@@ -17,5 +19,6 @@ type TenantInfo interface {
 	GetDB() *sqlx.DB
 	GetConfig() map[string]string
 	GetTheme() *theme.Theme
-	GetVault() *vault.Client
+	GetVault() vault.Secrets
+	GetLogger() *zap.SugaredLogger // child logger with "tenant"=host
 }
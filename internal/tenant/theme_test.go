@@ -0,0 +1,114 @@
+// internal/tenant/theme_test.go
+//
+// Unit-tests for Tenant.ReloadTheme and its per-tenant log entries.
+//
+// Context
+// -------
+// Each test runs inside a temp directory holding themes/t/templates so the
+// relative themeBaseDir resolves without touching the real tree.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package tenant
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func writeHome(t *testing.T, body string) {
+	t.Helper()
+	dir := filepath.Join("themes", "t", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "home.html"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func renderHome(t *testing.T, ten *Tenant) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := ten.GetRenderer().ExecuteTemplate(&sb, "home.html", nil); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	return sb.String()
+}
+
+func TestReloadTheme_SwapsAndKeepsOldOnError(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeHome(t, "v1")
+
+	var reloaded []string
+	OnThemeReload(func(h string) { reloaded = append(reloaded, h) })
+
+	ten := &Tenant{Meta: meta.Record{Theme: "t"}, host: "a.example"}
+	if err := ten.ReloadTheme(); err != nil {
+		t.Fatalf("initial load: %v", err)
+	}
+	if got := renderHome(t, ten); got != "v1" {
+		t.Fatalf("render = %q", got)
+	}
+
+	writeHome(t, "v2")
+	if err := ten.ReloadTheme(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := renderHome(t, ten); got != "v2" {
+		t.Fatalf("render after reload = %q", got)
+	}
+
+	writeHome(t, "{{ if }}broken")
+	if err := ten.ReloadTheme(); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if got := renderHome(t, ten); got != "v2" {
+		t.Fatalf("old renderer not kept: %q", got)
+	}
+	if len(reloaded) != 2 || reloaded[0] != "a.example" {
+		t.Fatalf("reload hooks = %v, want two calls for a.example", reloaded)
+	}
+}
+
+func TestThemeOf(t *testing.T) {
+	if got := themeOf("themes", filepath.Join("themes", "base", "templates", "x.html")); got != "base" {
+		t.Fatalf("themeOf = %q", got)
+	}
+	if got := themeOf("themes", filepath.Join("other", "x.html")); got != "" {
+		t.Fatalf("outside dir = %q", got)
+	}
+}
+
+func TestReloadTheme_LogsWithTenantField(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeHome(t, "v1")
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	ten := &Tenant{
+		Meta: meta.Record{Theme: "t"},
+		host: "a.example",
+		log:  zap.New(core).Sugar().With("tenant", "a.example"),
+	}
+	if err := ten.ReloadTheme(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	entries := logs.FilterMessage("theme reloaded").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d reload entries, want 1", len(entries))
+	}
+	if got := entries[0].ContextMap()["tenant"]; got != "a.example" {
+		t.Fatalf("tenant field = %v, want a.example", got)
+	}
+}
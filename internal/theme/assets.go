@@ -0,0 +1,266 @@
+// internal/theme/assets.go
+//
+// Fingerprinted static assets for themes, sites, and Components.
+//
+// Context
+// -------
+// {{ asset "css/site.css" }} used to print a plain path, so browsers either
+// revalidated every asset on every page or kept a stale copy after a
+// deploy.  Assets resolves a relative path through an override chain,
+// hashes the winning file, and prints the hash into the file name:
+//
+//	{{ asset "css/site.css" }}   →   /assets/css/site.3f9c2a1b7d0e.css
+//
+// AssetHandler serves that URL, and the plain one, from the same chain, so
+// the template and the served bytes always agree.
+//
+// Override chain
+// --------------
+//  1. Dirs, in order (the tenant passes sites/<host>/assets, then
+//     themes/<theme>/assets)
+//  2. ComponentDir/<comp>/assets/<rest> for a path "<comp>/<rest>", so
+//     "auth/login.css" falls back to components/auth/assets/login.css and
+//     a site or theme can still override it at assets/auth/login.css
+//
+// Caching
+// -------
+//   - A URL whose fingerprint matches the current content gets a one-year
+//     immutable Cache-Control.  So does the legacy ?v=<anything> form.
+//   - A stale fingerprint (the file changed since the page was rendered)
+//     still gets the current file, with no-cache, rather than a 404.
+//   - ETag is the content hash; http.ServeContent answers If-None-Match and
+//     If-Modified-Since with 304.
+//   - Hashes are cached per file and recomputed when size or mtime change.
+//
+// Notes
+// -----
+// • Any "..", ".", or empty segment, absolute path, backslash, or NUL is
+//   refused with 404 before the filesystem is touched.
+// • Directories are never listed; a directory hit is a 404.
+// • Content-Type comes from mime.TypeByExtension, falling back to
+//   application/octet-stream rather than content sniffing.
+// • Oxford commas, two spaces after periods.
+
+package theme
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AssetPrefix is the URL prefix tenant routers serve assets under.
+	AssetPrefix = "/assets/"
+
+	// ComponentDir holds components/<name>/assets trees.
+	ComponentDir = "components"
+
+	// versionParam is the legacy fingerprint query param, app.css?v=3f9c.
+	versionParam = "v"
+
+	// fingerprintLen is the number of hex digits printed into file names.
+	fingerprintLen = 12
+
+	CacheImmutable  = "public, max-age=31536000, immutable"
+	CacheRevalidate = "public, no-cache"
+
+	defaultType = "application/octet-stream"
+)
+
+// Assets resolves and fingerprints asset paths.  The zero value serves
+// nothing; use NewAssets.
+type Assets struct {
+	Prefix       string   // URL prefix, normally AssetPrefix
+	Dirs         []string // asset roots, highest precedence first
+	ComponentDir string   // "" disables the Component fallback
+
+	mu   sync.Mutex
+	sums map[string]fileSum // full path → content hash
+}
+
+type fileSum struct {
+	size int64
+	mod  time.Time
+	hex  string
+}
+
+// NewAssets returns Assets served under prefix from dirs, then from the
+// Component asset trees.
+func NewAssets(prefix string, dirs ...string) *Assets {
+	return &Assets{Prefix: prefix, Dirs: dirs, ComponentDir: ComponentDir}
+}
+
+// URL returns the fingerprinted URL for rel.  A path that does not resolve
+// is returned unfingerprinted, so a missing file 404s visibly.
+func (a *Assets) URL(rel string) string {
+	rel = strings.TrimPrefix(rel, "/")
+	clean, ok := CleanAssetPath(rel)
+	if !ok {
+		return a.Prefix + rel
+	}
+	full, fi, ok := a.resolve(clean)
+	if !ok {
+		return a.Prefix + clean
+	}
+	sum, err := a.sum(full, fi)
+	if err != nil {
+		return a.Prefix + clean
+	}
+	return a.Prefix + withFingerprint(clean, sum[:fingerprintLen])
+}
+
+// AssetHandler serves a's files for GET and HEAD requests under a.Prefix.
+func AssetHandler(a *Assets) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rel, ok := CleanAssetPath(strings.TrimPrefix(r.URL.Path, a.Prefix))
+		if !ok || !strings.HasPrefix(r.URL.Path, a.Prefix) {
+			http.NotFound(w, r)
+			return
+		}
+
+		// A real file wins over a fingerprint-looking name.
+		full, fi, found := a.resolve(rel)
+		fp := ""
+		if !found {
+			if plain, stamp, ok := stripFingerprint(rel); ok {
+				full, fi, found = a.resolve(plain)
+				rel, fp = plain, stamp
+			}
+		}
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+
+		sum, err := a.sum(full, fi)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		f, err := os.Open(full)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		h := w.Header()
+		ctype := mime.TypeByExtension(path.Ext(rel))
+		if ctype == "" {
+			ctype = defaultType
+		}
+		h.Set("Content-Type", ctype)
+		h.Set("ETag", `"`+sum[:2*fingerprintLen]+`"`)
+		if (fp != "" && strings.HasPrefix(sum, fp)) || r.URL.Query().Get(versionParam) != "" {
+			h.Set("Cache-Control", CacheImmutable)
+		} else {
+			h.Set("Cache-Control", CacheRevalidate)
+		}
+		http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+	})
+}
+
+// resolve walks the override chain and returns the first regular file.
+func (a *Assets) resolve(rel string) (string, os.FileInfo, bool) {
+	candidates := make([]string, 0, len(a.Dirs)+1)
+	for _, dir := range a.Dirs {
+		candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(rel)))
+	}
+	if comp, rest, ok := strings.Cut(rel, "/"); ok && a.ComponentDir != "" {
+		candidates = append(candidates,
+			filepath.Join(a.ComponentDir, comp, "assets", filepath.FromSlash(rest)))
+	}
+	for _, full := range candidates {
+		if fi, err := os.Stat(full); err == nil && fi.Mode().IsRegular() {
+			return full, fi, true
+		}
+	}
+	return "", nil, false
+}
+
+// sum returns the hex SHA-256 of full, cached while size and mtime hold.
+func (a *Assets) sum(full string, fi os.FileInfo) (string, error) {
+	a.mu.Lock()
+	s, ok := a.sums[full]
+	a.mu.Unlock()
+	if ok && s.size == fi.Size() && s.mod.Equal(fi.ModTime()) {
+		return s.hex, nil
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	s = fileSum{size: fi.Size(), mod: fi.ModTime(), hex: hex.EncodeToString(h.Sum(nil))}
+
+	a.mu.Lock()
+	if a.sums == nil {
+		a.sums = make(map[string]fileSum)
+	}
+	a.sums[full] = s
+	a.mu.Unlock()
+	return s.hex, nil
+}
+
+// withFingerprint turns css/site.css into css/site.<fp>.css.
+func withFingerprint(rel, fp string) string {
+	dir, file := path.Split(rel)
+	ext := path.Ext(file)
+	return dir + strings.TrimSuffix(file, ext) + "." + fp + ext
+}
+
+// stripFingerprint reverses withFingerprint.  ok is false when rel carries
+// no fingerprint.
+func stripFingerprint(rel string) (plain, fp string, ok bool) {
+	dir, file := path.Split(rel)
+	ext := path.Ext(file)
+	stem := strings.TrimSuffix(file, ext)
+	if i := strings.LastIndexByte(stem, '.'); i > 0 && isFingerprint(stem[i+1:]) {
+		return dir + stem[:i] + ext, stem[i+1:], true // site.<fp>.css
+	}
+	if fp := strings.TrimPrefix(ext, "."); stem != "" && isFingerprint(fp) {
+		return dir + stem, fp, true // LICENSE.<fp>
+	}
+	return "", "", false
+}
+
+// isFingerprint reports whether s is fingerprintLen lowercase hex digits.
+func isFingerprint(s string) bool {
+	if len(s) != fingerprintLen {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// CleanAssetPath validates a relative asset path.  It rejects traversal
+// outright rather than normalising it away.
+func CleanAssetPath(p string) (string, bool) {
+	if p == "" || strings.HasPrefix(p, "/") || strings.ContainsAny(p, "\\\x00") {
+		return "", false
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." || seg == "." || seg == "" {
+			return "", false
+		}
+	}
+	return p, true
+}
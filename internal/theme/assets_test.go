@@ -0,0 +1,112 @@
+// internal/theme/assets_test.go
+//
+// Unit-tests for asset fingerprinting and AssetHandler.
+//
+// Notes
+// -----
+// • Each test runs in a temp directory holding site, theme, and Component
+//   asset trees.
+// • Oxford commas, two spaces after periods.
+
+package theme
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testAssets(t *testing.T) *Assets {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	writeFile(t, "themes/t/assets/css/site.css", "body{}")
+	writeFile(t, "themes/t/assets/LICENSE", "MIT")
+	writeFile(t, "sites/a.example/assets/logo.png", "site-logo")
+	writeFile(t, "components/auth/assets/login.css", "form{}")
+	return NewAssets(AssetPrefix, "sites/a.example/assets", "themes/t/assets")
+}
+
+func serve(a *Assets, target string, hdr map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	AssetHandler(a).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAssets_FingerprintStable(t *testing.T) {
+	a := testAssets(t)
+
+	url := a.URL("css/site.css")
+	if !regexp.MustCompile(`^/assets/css/site\.[0-9a-f]{12}\.css$`).MatchString(url) {
+		t.Fatalf("URL = %q", url)
+	}
+	if again := NewAssets(AssetPrefix, a.Dirs...).URL("/css/site.css"); again != url {
+		t.Fatalf("fingerprint not stable: %q vs %q", again, url)
+	}
+
+	writeFile(t, "themes/t/assets/css/site.css", "body{color:red}")
+	if changed := a.URL("css/site.css"); changed == url {
+		t.Fatal("fingerprint did not follow a content change")
+	}
+
+	if got := a.URL("missing.js"); got != "/assets/missing.js" {
+		t.Fatalf("missing file URL = %q", got)
+	}
+}
+
+func TestAssetHandler_FingerprintedURLs(t *testing.T) {
+	a := testAssets(t)
+
+	for _, rel := range []string{"css/site.css", "LICENSE", "logo.png", "auth/login.css"} {
+		url := a.URL(rel)
+		rr := serve(a, url, nil)
+		if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") != CacheImmutable {
+			t.Errorf("GET %s = %d, Cache-Control %q", url, rr.Code, rr.Header().Get("Cache-Control"))
+		}
+	}
+
+	if rr := serve(a, "/assets/css/site.css", nil); rr.Body.String() != "body{}" ||
+		rr.Header().Get("Cache-Control") != CacheRevalidate {
+		t.Fatalf("plain URL = %q, %q", rr.Body, rr.Header().Get("Cache-Control"))
+	}
+	if rr := serve(a, "/assets/auth/login.css", nil); rr.Body.String() != "form{}" {
+		t.Fatalf("component fallback = %d %q", rr.Code, rr.Body)
+	}
+
+	// A stale fingerprint still gets the current file, but not for a year.
+	rr := serve(a, "/assets/css/site.000000000000.css", nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") != CacheRevalidate {
+		t.Fatalf("stale fingerprint = %d, %q", rr.Code, rr.Header().Get("Cache-Control"))
+	}
+}
+
+func TestAssetHandler_NotModified(t *testing.T) {
+	a := testAssets(t)
+	url := a.URL("css/site.css")
+
+	etag := serve(a, url, nil).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	for _, target := range []string{url, "/assets/css/site.css"} {
+		if rr := serve(a, target, map[string]string{"If-None-Match": etag}); rr.Code != http.StatusNotModified {
+			t.Errorf("If-None-Match on %s: status %d", target, rr.Code)
+		}
+	}
+}
@@ -17,8 +17,9 @@ type Manager struct {
 }
 
 // Load parses the theme’s templates and returns a ready-to-use Theme.
-// Stub helpers (dict, widget, area) are defined so Parse succeeds;
-// real helpers overwrite them at render time.
+// Stub helpers (dict, widget, area, head, user, geo, routePath,
+// queryParam, and url) are defined so Parse succeeds; real helpers
+// overwrite them at render time.
 func (m *Manager) Load(name string, modules []string) (*Theme, error) {
 	root := filepath.Join(m.BaseDir, name)
 	if info, err := os.Stat(root); err != nil || !info.IsDir() {
@@ -30,6 +31,13 @@ func (m *Manager) Load(name string, modules []string) (*Theme, error) {
 		"dict":   func(...any) map[string]any { return map[string]any{} },
 		"widget": func(string, ...any) string { return "" },
 		"area":   func(string) string { return "" },
+		"head":   func() template.HTML { return "" },
+		"user":   func() any { return nil },
+		"geo":    func() any { return nil },
+
+		"routePath":  func() string { return "" },
+		"queryParam": func(string) string { return "" },
+		"url":        func(p string) string { return p },
 	}
 
 	// Base template with asset helper and stub funcs.
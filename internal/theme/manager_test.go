@@ -0,0 +1,31 @@
+// internal/theme/manager_test.go
+//
+// Unit-tests for Manager.Load.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package theme
+
+import (
+	"testing"
+)
+
+// TestLoad_ParsesViewHelpers loads a theme whose templates call the helpers
+// the view engine injects at render time; Parse must not reject them.
+func TestLoad_ParsesViewHelpers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile(t, "themes/t/templates/layout.html",
+		`{{ define "layout" }}<a href="{{ routePath }}?q={{ queryParam "q" }}">x</a>`+
+			`{{ if user.LoggedIn }}{{ user.Email }}{{ end }} {{ geo.CountryISO }}`+
+			`<a href="{{ url "/content/page/view/about" }}">about</a>{{ end }}`)
+
+	th, err := (&Manager{BaseDir: "themes"}).Load("t", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Renderer.Lookup("layout") == nil {
+		t.Fatal("layout not parsed")
+	}
+}
@@ -4,12 +4,13 @@
 //   - Name         – the theme directory name (for example, “base”).
 //   - Root         – absolute path to that directory on disk.
 //   - Renderer     – parsed templates ready for execution.
+//   - Assets       – the asset override chain (assets.go).
 //   - AssetFunc    – helper injected into templates so they can resolve
-//     `{{ asset \"css/main.css\" }}` to a URL.
+//     `{{ asset \"css/main.css\" }}` to a fingerprinted URL.
 //
-// The first milestone is to load templates and provide an AssetFunc that
-// merely prefixes `/themes/<name>/assets/`.  Minification and fingerprinting
-// will be added later by the asset manager.
+// New gives a Theme an Assets over its own assets folder and the Component
+// asset trees; the tenant loader swaps in a chain that starts at the site's
+// folder, so a site override changes the fingerprint too.
 package theme
 
 import (
@@ -22,18 +23,20 @@ type Theme struct {
 	Name      string
 	Root      string
 	Renderer  *template.Template
+	Assets    *Assets
 	AssetFunc func(string) string
 }
 
-// New constructs a Theme with an AssetFunc that points to the assets folder.
+// New constructs a Theme whose AssetFunc fingerprints paths through
+// Assets.  AssetFunc reads Assets on every call, so replacing Assets before
+// the Theme is shared retargets it.
 func New(name, root string, tpl *template.Template) *Theme {
-	assetPrefix := filepath.ToSlash("/themes/" + name + "/assets/")
-	return &Theme{
+	th := &Theme{
 		Name:     name,
 		Root:     root,
 		Renderer: tpl,
-		AssetFunc: func(p string) string {
-			return assetPrefix + p
-		},
+		Assets:   NewAssets(AssetPrefix, filepath.Join(root, "assets")),
 	}
+	th.AssetFunc = func(p string) string { return th.Assets.URL(p) }
+	return th
 }
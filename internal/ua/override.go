@@ -0,0 +1,94 @@
+// internal/ua/override.go
+//
+// Device-class override table.
+//
+// Context
+// -------
+// uasurfer lags behind new hardware, and some UAs are simply misread (a
+// handful of Android tablets report as phones).  Rather than patching the
+// library, operators list corrections in config:
+//
+//	ua:
+//	  device_overrides:
+//	    - pattern: "SM-X[0-9]{3}"    # Galaxy Tab S8/S9
+//	      device:  "Tablet"
+//
+// Parse applies the first matching pattern after the library has run, so
+// Info keeps its shape and every other field still comes from uasurfer.
+//
+// Notes
+// -----
+// • Patterns are Go regular expressions, compiled once by SetOverrides and
+//   swapped in atomically.  A bad pattern rejects the whole table so a typo
+//   never half-applies.
+// • With no overrides configured, Parse pays one atomic load and a nil
+//   check, so the common path is unchanged.  Memoised Parse results are
+//   dropped whenever the table changes.
+// • Oxford commas, two spaces after periods.
+
+package ua
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+)
+
+// Override maps a UA pattern to a device class.
+type Override struct {
+	Pattern string
+	Device  string // "Desktop", "Mobile", "Tablet", or "Other"
+}
+
+type compiledOverride struct {
+	re     *regexp.Regexp
+	device string
+}
+
+var overrides atomic.Pointer[[]compiledOverride]
+
+// SetOverrides compiles list and makes it live.  An empty list clears the
+// table.  On error the previous table stays in place.
+func SetOverrides(list []Override) error {
+	if len(list) == 0 {
+		overrides.Store(nil)
+		purgeParseCache()
+		return nil
+	}
+	out := make([]compiledOverride, 0, len(list))
+	for i, o := range list {
+		if !validDevice(o.Device) {
+			return fmt.Errorf("ua override %d: unknown device %q", i, o.Device)
+		}
+		re, err := regexp.Compile(o.Pattern)
+		if err != nil {
+			return fmt.Errorf("ua override %d: %w", i, err)
+		}
+		out = append(out, compiledOverride{re: re, device: o.Device})
+	}
+	overrides.Store(&out)
+	purgeParseCache()
+	return nil
+}
+
+// applyOverrides returns the override device for raw, if any.
+func applyOverrides(raw string) (string, bool) {
+	p := overrides.Load()
+	if p == nil {
+		return "", false
+	}
+	for _, o := range *p {
+		if o.re.MatchString(raw) {
+			return o.device, true
+		}
+	}
+	return "", false
+}
+
+func validDevice(d string) bool {
+	switch d {
+	case "Desktop", "Mobile", "Tablet", "Other":
+		return true
+	}
+	return false
+}
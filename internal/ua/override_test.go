@@ -0,0 +1,62 @@
+// internal/ua/override_test.go
+//
+// Unit-tests for the device-class override table.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package ua
+
+import "testing"
+
+const (
+	galaxyTab = "Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 " +
+		"(KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+	macChrome = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 " +
+		"(KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36"
+)
+
+func TestOverrides_CorrectDeviceOnly(t *testing.T) {
+	t.Cleanup(func() { _ = SetOverrides(nil) })
+
+	before := Parse(galaxyTab)
+	if before.Device == "Tablet" {
+		t.Skip("uasurfer already classifies this UA as Tablet")
+	}
+
+	if err := SetOverrides([]Override{{Pattern: `SM-X[0-9]{3}`, Device: "Tablet"}}); err != nil {
+		t.Fatal(err)
+	}
+	after := Parse(galaxyTab)
+	if after.Device != "Tablet" {
+		t.Fatalf("Device = %q, want Tablet", after.Device)
+	}
+	after.Device = before.Device
+	if after != before {
+		t.Fatalf("override changed more than Device:\n%+v\n%+v", before, after)
+	}
+
+	if got := Parse(macChrome).Device; got != "Desktop" {
+		t.Fatalf("non-matching UA: Device = %q", got)
+	}
+}
+
+func TestSetOverrides_RejectsBadTableAndKeepsOld(t *testing.T) {
+	t.Cleanup(func() { _ = SetOverrides(nil) })
+
+	if err := SetOverrides([]Override{{Pattern: `Macintosh`, Device: "Other"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][]Override{
+		{{Pattern: `(`, Device: "Tablet"}},
+		{{Pattern: `x`, Device: "Phablet"}},
+	} {
+		if err := SetOverrides(bad); err == nil {
+			t.Fatalf("%+v: expected error", bad)
+		}
+	}
+	if got := Parse(macChrome).Device; got != "Other" {
+		t.Fatalf("previous table lost: Device = %q", got)
+	}
+}
@@ -5,13 +5,34 @@
 // This wrapper isolates the third‑party `github.com/avct/uasurfer` API so
 // the rest of the codebase never sees its enums or structs.  If we ever
 // swap parsers again, only this file changes.
+//
+// Parse memoises results in a mutex-guarded LRU from internal/cache.  The
+// lock is held only for the map lookup and insert, never across parsing.
 package ua
 
 import (
 	"fmt"
 	"strconv"
+	"sync"
 
 	surfer "github.com/avct/uasurfer"
+
+	"github.com/yanizio/adept/internal/cache"
+)
+
+const (
+	// parseCacheSize bounds the memoised Parse results.
+	parseCacheSize = 1024
+
+	// maxCachedUA skips caching for oversized headers, which are almost
+	// always junk and would otherwise dominate the LRU's memory.
+	maxCachedUA = 512
+)
+
+var (
+	parseMu  sync.Mutex
+	parseLRU = cache.New(parseCacheSize)
+	parseGen uint64 // bumped by purgeParseCache
 )
 
 // Info carries the UA attributes used by middleware, Components, and
@@ -29,7 +50,8 @@ import (
 //	IsBot     false
 //	Raw       "Mozilla/5.0 (Macintosh;…"
 //
-// Device will be one of: "Desktop", "Mobile", "Tablet", or "Other".
+// Device will be one of: "Desktop", "Mobile", "Tablet", or "Other".  The
+// override table (override.go) may correct it after parsing.
 type Info struct {
 	Browser   string
 	Version   string
@@ -41,10 +63,41 @@ type Info struct {
 	Raw       string
 }
 
-// Parse converts a raw header into an Info struct.  After the first call
-// the underlying library reuses internal buffers, so Parse allocates only
-// on rarely‑seen strings.
+// Parse converts a raw header into an Info struct.  Results are memoised in
+// a bounded LRU (parseCacheSize entries), so the handful of popular browser
+// strings that dominate traffic skip uasurfer entirely.  A flood of unique
+// UAs only churns the LRU; memory stays capped.
 func Parse(raw string) Info {
+	if len(raw) > maxCachedUA {
+		return parse(raw)
+	}
+	parseMu.Lock()
+	v, ok := parseLRU.Get(raw)
+	gen := parseGen
+	parseMu.Unlock()
+	if ok {
+		return v.(Info)
+	}
+
+	info := parse(raw)
+	parseMu.Lock()
+	if gen == parseGen { // skip if the override table changed meanwhile
+		parseLRU.Add(raw, info)
+	}
+	parseMu.Unlock()
+	return info
+}
+
+// purgeParseCache drops memoised results (the override table changed).
+func purgeParseCache() {
+	parseMu.Lock()
+	parseLRU = cache.New(parseCacheSize)
+	parseGen++
+	parseMu.Unlock()
+}
+
+// parse runs uasurfer and the override table without touching the cache.
+func parse(raw string) Info {
 	ua := surfer.Parse(raw)
 
 	info := Info{
@@ -67,6 +120,9 @@ func Parse(raw string) Info {
 	default:
 		info.Device = "Other"
 	}
+	if dev, ok := applyOverrides(raw); ok {
+		info.Device = dev
+	}
 
 	return info
 }
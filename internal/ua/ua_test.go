@@ -0,0 +1,78 @@
+// internal/ua/ua_test.go
+//
+// Unit-tests and benchmarks for the memoised Parse.
+//
+// Notes
+// -----
+// • Run `go test -bench Parse ./internal/ua` to compare cached and uncached
+//   throughput.
+// • Oxford commas, two spaces after periods.
+
+package ua
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParse_CacheMatchesUncached(t *testing.T) {
+	purgeParseCache()
+	first := Parse(macChrome)
+	if got := Parse(macChrome); got != first {
+		t.Fatalf("cached result differs:\n%+v\n%+v", first, got)
+	}
+	if got := parse(macChrome); got != first {
+		t.Fatalf("cache diverged from parser:\n%+v\n%+v", first, got)
+	}
+}
+
+func TestParse_CacheIsBounded(t *testing.T) {
+	purgeParseCache()
+	for i := 0; i < parseCacheSize*3; i++ {
+		_ = Parse(fmt.Sprintf("flood-bot/%d", i))
+	}
+	_ = Parse(strings.Repeat("x", maxCachedUA+1))
+
+	parseMu.Lock()
+	n := parseLRU.Len()
+	parseMu.Unlock()
+	if n > parseCacheSize {
+		t.Fatalf("cache grew to %d entries, cap %d", n, parseCacheSize)
+	}
+}
+
+func TestParse_ConcurrentSafe(t *testing.T) {
+	purgeParseCache()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if i%3 == 0 {
+					_ = Parse(macChrome)
+				} else {
+					_ = Parse(fmt.Sprintf("ua-%d-%d", g, i%50))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkParse_Cached(b *testing.B) {
+	purgeParseCache()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Parse(macChrome)
+	}
+}
+
+func BenchmarkParse_Uncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = parse(macChrome)
+	}
+}
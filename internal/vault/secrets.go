@@ -0,0 +1,110 @@
+// internal/vault/secrets.go
+//
+// Secrets providers: Vault, or environment variables and a local file.
+//
+// Context
+// -------
+//   - Secrets is the one call Adept makes against a secret store: read one
+//     key of one KV secret.  *Client satisfies it, and so does Local.
+//   - Local serves development and CI, where no Vault runs.  config.Load
+//     picks it when VAULT_ADDR is unset or `secrets.provider: local` is
+//     configured; tenant password lookups then go through it as well.
+//
+// Local lookup order
+// ------------------
+//  1. Environment variable EnvName(path, key): the path and key upper-cased,
+//     every run of other characters turned into "_", joined by "__".
+//     secret/adept/global/db#password → SECRET_ADEPT_GLOBAL_DB__PASSWORD.
+//  2. The YAML file given to NewLocal, a map of secret path → key → value
+//     (example at NewLocal).
+//
+// Notes
+// -----
+//   - Values are plaintext by design.  Keep the file out of git and never
+//     select Local in production; the provider is logged at boot.
+//   - Local ignores ttl: the file is read once by NewLocal and env lookups
+//     are cheap.
+//   - Oxford commas, two spaces after periods.
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secrets reads one key of one KV secret.  ttl > 0 lets the provider serve
+// a cached copy that young; 0 forces a fresh read where caching applies.
+type Secrets interface {
+	GetKV(ctx context.Context, secretPath, key string, ttl time.Duration) (string, error)
+}
+
+var _ Secrets = (*Client)(nil)
+
+// Local resolves secrets from environment variables and a YAML file.
+// Zero value is usable and env-only.
+type Local struct {
+	file    string
+	secrets map[string]map[string]string
+}
+
+// NewLocal loads file, shaped like:
+//
+//	secret/adept/global/db:
+//	  password: "dev"
+//	secret/adept/tenant/examplecom/db:
+//	  password: "dev"
+//
+// A missing file is not an error; lookups then use the environment only.
+// "" means env-only.
+func NewLocal(file string) (*Local, error) {
+	l := &Local{file: file}
+	if file == "" {
+		return l, nil
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &l.secrets); err != nil {
+		return nil, fmt.Errorf("secrets file %s: %w", file, err)
+	}
+	return l, nil
+}
+
+// GetKV returns the env override for secretPath#key, else the file value.
+func (l *Local) GetKV(_ context.Context, secretPath, key string, _ time.Duration) (string, error) {
+	if secretPath == "" || key == "" {
+		return "", errors.New("secret path and key must be non‑empty")
+	}
+	env := EnvName(secretPath, key)
+	if v, ok := os.LookupEnv(env); ok {
+		return v, nil
+	}
+	if v, ok := l.secrets[secretPath][key]; ok {
+		return v, nil
+	}
+	if l.file == "" {
+		return "", fmt.Errorf("secret %s#%s: %s not set", secretPath, key, env)
+	}
+	return "", fmt.Errorf("secret %s#%s: %s not set and not in %s", secretPath, key, env, l.file)
+}
+
+var nonAlnum = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// EnvName returns the environment variable Local checks for secretPath#key.
+func EnvName(secretPath, key string) string {
+	part := func(s string) string {
+		return strings.ToUpper(strings.Trim(nonAlnum.ReplaceAllString(s, "_"), "_"))
+	}
+	return part(secretPath) + "__" + part(key)
+}
@@ -0,0 +1,71 @@
+// internal/vault/secrets_test.go
+//
+// Unit-tests for the local secrets provider.
+
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for in, want := range map[[2]string]string{
+		{"secret/adept/global/db", "password"}:      "SECRET_ADEPT_GLOBAL_DB__PASSWORD",
+		{"secret/adept/tenant/examplecom/db", "pw"}: "SECRET_ADEPT_TENANT_EXAMPLECOM_DB__PW",
+		{"kv/my-app//api", "api.key"}:               "KV_MY_APP_API__API_KEY",
+	} {
+		if got := EnvName(in[0], in[1]); got != want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestLocal_EnvThenFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.dev.yaml")
+	body := "secret/adept/global/db:\n  password: from-file\n  user: adept\n"
+	if err := os.WriteFile(file, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLocal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if v, err := l.GetKV(ctx, "secret/adept/global/db", "password", 0); err != nil || v != "from-file" {
+		t.Fatalf("file lookup = %q, %v", v, err)
+	}
+	t.Setenv("SECRET_ADEPT_GLOBAL_DB__PASSWORD", "from-env")
+	if v, _ := l.GetKV(ctx, "secret/adept/global/db", "password", 0); v != "from-env" {
+		t.Fatalf("env did not win: %q", v)
+	}
+	_, err = l.GetKV(ctx, "secret/adept/global/db", "missing", 0)
+	if err == nil || !strings.Contains(err.Error(), "SECRET_ADEPT_GLOBAL_DB__MISSING") {
+		t.Fatalf("missing key error = %v", err)
+	}
+}
+
+func TestLocal_MissingFileIsEnvOnly(t *testing.T) {
+	l, err := NewLocal(filepath.Join(t.TempDir(), "absent.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KV_APP__TOKEN", "t")
+	if v, err := l.GetKV(context.Background(), "kv/app", "token", 0); err != nil || v != "t" {
+		t.Fatalf("GetKV = %q, %v", v, err)
+	}
+}
+
+func TestLocal_BadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(file, []byte("- not\n- a map\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLocal(file); err == nil {
+		t.Fatal("malformed secrets file accepted")
+	}
+}
@@ -13,6 +13,11 @@
 // ---------------
 //  1. cli, err := vault.New(ctx, log.Printf)       // during boot.
 //  2. pw,  err := cli.GetKV(ctx, path, key, ttl)   // anywhere in the app.
+//  3. cli.Close(); <-cli.Done()                     // at shutdown.
+//
+// The renew loop runs until ctx is cancelled or Close is called, whichever
+// comes first.  Done is closed once the loop has returned, so shutdown can
+// confirm no goroutine is left behind.
 //
 // Build tags: none.
 package vault
@@ -41,6 +46,9 @@ type Client struct {
 
 	cacheMu sync.RWMutex
 	cache   map[string]cached // canonical path#key → value + expiry.
+
+	cancel context.CancelFunc // stops renewLoop
+	done   chan struct{}      // closed when renewLoop returns
 }
 
 type cached struct {
@@ -73,13 +81,19 @@ func New(ctx context.Context, logFn func(string, ...any)) (*Client, error) {
 		apiCli.SetToken(tok)
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
 	c := &Client{
-		api:   apiCli,
-		logFn: logFn,
-		cache: make(map[string]cached),
+		api:    apiCli,
+		logFn:  logFn,
+		cache:  make(map[string]cached),
+		cancel: cancel,
+		done:   make(chan struct{}),
 	}
 
-	go c.renewLoop(ctx)
+	go func() {
+		defer close(c.done)
+		c.renewLoop(ctx)
+	}()
 
 	return c, nil
 }
@@ -128,6 +142,13 @@ func (c *Client) GetKV(ctx context.Context, secretPath, key string, ttl time.Dur
 	return sval, nil
 }
 
+// Close stops the token-renewal loop.  It does not wait; receive from Done
+// for that.  Safe to call more than once.
+func (c *Client) Close() { c.cancel() }
+
+// Done is closed once the renewal loop has exited.
+func (c *Client) Done() <-chan struct{} { return c.done }
+
 //
 // SECTION 2.  Background token renewal
 //
@@ -141,7 +162,10 @@ func (c *Client) renewLoop(ctx context.Context) {
 		}
 
 		// Probe the current token.
-		sec, err := c.api.Auth().Token().RenewSelf(0)
+		sec, err := c.api.Auth().Token().RenewSelfWithContext(ctx, 0)
+		if ctx.Err() != nil {
+			return
+		}
 		if err != nil {
 			c.logFn("vault: token renew self failed: %v", err)
 			backoff(ctx, 30*time.Second)
@@ -0,0 +1,54 @@
+// internal/vault/vault_test.go
+//
+// Unit-tests for the renew-loop lifecycle.
+//
+// Context
+// -------
+// VAULT_ADDR points at a closed port, so every renew attempt fails fast and
+// the loop sits in its back-off; Close or a cancelled parent context must
+// still end it promptly.
+
+package vault
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func offlineVault(t *testing.T) {
+	t.Helper()
+	t.Setenv("VAULT_ADDR", "http://127.0.0.1:1")
+	t.Setenv("VAULT_TOKEN", "test-token")
+}
+
+func waitDone(t *testing.T, c *Client) {
+	t.Helper()
+	select {
+	case <-c.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("renew loop did not exit")
+	}
+}
+
+func TestClose_StopsRenewLoop(t *testing.T) {
+	offlineVault(t)
+	c, err := New(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	waitDone(t, c)
+	c.Close() // second call is harmless
+}
+
+func TestParentCancel_StopsRenewLoop(t *testing.T) {
+	offlineVault(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := New(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	waitDone(t, c)
+}
@@ -0,0 +1,58 @@
+// internal/view/contenttype.go
+//
+// Content-Type for rendered templates.
+//
+// Context
+// -------
+// Render streamed CacheSkip pages without a Content-Type, so net/http
+// sniffed the first 512 bytes; a page opening with a comment or with
+// whitespace and JSON could come out as text/plain, and nosniff then made
+// the browser honour the wrong label.  Every render now declares its type
+// up front, from the logical template name:
+//
+//   - "login", "page.html"        → text/html; charset=utf-8
+//   - "sitemap.xml", "feed.json"  → mime.TypeByExtension, with
+//     charset=utf-8 added to text/* types that lack one
+//
+// A Content-Type the handler set before calling Render is kept.
+// RenderContent returns the same type to string callers (e-mail parts).
+//
+// Notes
+// -----
+// • The type never depends on the rendered bytes, so a 304 and the 200 it
+//   stands in for always agree.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const htmlType = "text/html; charset=utf-8"
+
+// ContentType returns the media type for the logical template name.
+func ContentType(name string) string {
+	ext := strings.ToLower(path.Ext(path.Base(name)))
+	if ext == "" || ext == ".html" || ext == ".htm" {
+		return htmlType
+	}
+	t := mime.TypeByExtension(ext)
+	if t == "" {
+		return htmlType
+	}
+	if strings.HasPrefix(t, "text/") && !strings.Contains(t, "charset=") {
+		t += "; charset=utf-8"
+	}
+	return t
+}
+
+// setContentType labels w for name unless the handler already did.
+func setContentType(w http.ResponseWriter, name string) {
+	if h := w.Header(); h.Get("Content-Type") == "" {
+		h.Set("Content-Type", ContentType(name))
+	}
+}
@@ -0,0 +1,156 @@
+// internal/view/localetheme.go
+//
+// Locale-to-theme mapping.
+//
+// Context
+// -------
+// Some deployments serve a different theme per locale, such as an RTL theme
+// for Arabic and Hebrew.  The mapping comes from config and may be
+// overridden per tenant:
+//
+//	theme:
+//	  locale_map:               # process-wide default
+//	    ar: "rtl"
+//	    he: "rtl"
+//
+//	site_config theme.locale_map = "ar=rtl-gold, fa_IR=rtl-gold"
+//
+// Rules
+// -----
+//   - The resolved locale (Context.GetLocale) is tried exactly, then by its
+//     language ("ar_EG" → "ar").  Matching ignores case and treats "-" as
+//     "_".
+//   - The tenant's site_config map is consulted before the default map, so
+//     a tenant can map one language and inherit the rest.
+//   - No mapping means the tenant's own theme (site.theme, else "default").
+//
+// Composition
+// -----------
+// A mapped theme is layered over the tenant theme rather than replacing
+// it, so an RTL theme ships only the templates it changes:
+//
+//	sites/<host> → themes/<locale theme> → themes/<tenant theme> → components
+//
+// The LRU key carries the whole theme chain, so tenants and locales never
+// share a parsed set built from another chain.
+//
+// Notes
+// -----
+// • A mapped theme name that is not a plain directory name is ignored with
+//   a WARN; it never breaks rendering.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/logger"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// LocaleThemeKey is the site_config override of the default mapping, as
+// comma-separated "locale=theme" pairs.
+const LocaleThemeKey = "theme.locale_map"
+
+// DefaultTheme is used when the tenant names no theme.
+const DefaultTheme = "default"
+
+func init() {
+	component.DeclareConfig(component.ConfigKey{
+		Name: LocaleThemeKey, Type: component.ConfigString, Default: "",
+	})
+}
+
+// localeThemes holds the process-wide default mapping, keys normalised.
+var localeThemes atomic.Pointer[map[string]string]
+
+// SetLocaleThemes installs the default locale-to-theme mapping.  A theme
+// name that could escape themes/ rejects the whole map, so a typo never
+// half-applies.  A nil or empty map clears it.
+func SetLocaleThemes(m map[string]string) error {
+	out := make(map[string]string, len(m))
+	for loc, th := range m {
+		if !validTheme(th) {
+			return fmt.Errorf("%w: theme %q for locale %q", ErrTemplatePath, th, loc)
+		}
+		out[normLocale(loc)] = th
+	}
+	localeThemes.Store(&out)
+	return nil
+}
+
+// themeChain returns the themes ctx renders with, highest precedence first:
+// the mapped locale theme, when there is one, then the tenant theme.
+func themeChain(ctx *tenant.Context) []string {
+	base := DefaultTheme
+	if ctx.Tenant != nil && ctx.Tenant.Meta.Theme != "" {
+		base = ctx.Tenant.Meta.Theme
+	}
+	th := localeTheme(ctx)
+	if th == "" || th == base {
+		return []string{base}
+	}
+	return []string{th, base}
+}
+
+// localeTheme returns the theme mapped to ctx's locale, or "".
+func localeTheme(ctx *tenant.Context) string {
+	loc := normLocale(ctx.GetLocale())
+	lang, _, _ := strings.Cut(loc, "_")
+
+	var site map[string]string
+	if ctx.Tenant != nil {
+		site = parseLocaleMap(ctx.Tenant.Config.String(LocaleThemeKey, ""))
+	}
+	var def map[string]string
+	if p := localeThemes.Load(); p != nil {
+		def = *p
+	}
+
+	keys := []string{loc, lang}
+	for _, k := range keys {
+		th, ok := site[k]
+		if !ok {
+			continue
+		}
+		if validTheme(th) {
+			return th
+		}
+		logger.FromContext(ctx.Request.Context()).Warn("locale theme ignored",
+			"key", LocaleThemeKey, "locale", k, "theme", th)
+	}
+	for _, k := range keys {
+		if th, ok := def[k]; ok {
+			return th // validated by SetLocaleThemes
+		}
+	}
+	return ""
+}
+
+// parseLocaleMap reads "ar=rtl, he_IL=rtl".  Malformed pairs are skipped.
+func parseLocaleMap(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		loc, th, ok := strings.Cut(pair, "=")
+		loc, th = strings.TrimSpace(loc), strings.TrimSpace(th)
+		if ok && loc != "" && th != "" {
+			out[normLocale(loc)] = th
+		}
+	}
+	return out
+}
+
+// normLocale folds "ar-EG" and "AR_eg" to "ar_eg".
+func normLocale(l string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(l), "-", "_"))
+}
+
+// validTheme accepts a plain theme directory name.
+func validTheme(th string) bool { return th != "" && onlyChars(th, "_-") }
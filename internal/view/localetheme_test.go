@@ -0,0 +1,92 @@
+// internal/view/localetheme_test.go
+//
+// Unit-tests for the locale-to-theme mapping.
+
+package view
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/tenant/meta"
+)
+
+func localeCtx(host, theme, locale string, cfg tenant.SiteConfig) *tenant.Context {
+	req := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+	ctx := tenant.NewContext(req)
+	ctx.Tenant = &tenant.Tenant{Meta: meta.Record{Theme: theme, Locale: locale}, Config: cfg}
+	return ctx
+}
+
+func TestThemeChain_Mapping(t *testing.T) {
+	if err := SetLocaleThemes(map[string]string{"ar": "rtl", "he-IL": "rtl-he"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetLocaleThemes(nil) })
+
+	cases := []struct {
+		theme, locale string
+		cfg           tenant.SiteConfig
+		want          []string
+	}{
+		{"base", "en_US", nil, []string{"base"}},           // no mapping
+		{"", "en_US", nil, []string{DefaultTheme}},         // no tenant theme
+		{"base", "ar_EG", nil, []string{"rtl", "base"}},    // language fallback
+		{"base", "he_il", nil, []string{"rtl-he", "base"}}, // case and "-" folded
+		{"rtl", "ar", nil, []string{"rtl"}},                // same theme once
+		{"base", "ar_EG", tenant.SiteConfig{LocaleThemeKey: "ar_EG=gold, fr=x"}, []string{"gold", "base"}},
+		{"base", "ar_EG", tenant.SiteConfig{LocaleThemeKey: "ar=../etc"}, []string{"rtl", "base"}},
+	}
+	for _, tc := range cases {
+		got := themeChain(localeCtx("lt.example", tc.theme, tc.locale, tc.cfg))
+		if strings.Join(got, ">") != strings.Join(tc.want, ">") {
+			t.Errorf("themeChain(%q, %q, %v) = %v, want %v", tc.theme, tc.locale, tc.cfg, got, tc.want)
+		}
+	}
+
+	if err := SetLocaleThemes(map[string]string{"ar": "../x"}); !errors.Is(err, ErrTemplatePath) {
+		t.Fatalf("bad theme err = %v", err)
+	}
+}
+
+func TestRender_LocaleThemeLayering(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := SetLocaleThemes(map[string]string{"ar": "rtl"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetLocaleThemes(nil) })
+
+	writeTemplate(t, filepath.Join("themes", "rtl", "components", "demo", "templates", "page.html"), `rtl`)
+	writeTemplate(t, filepath.Join("themes", "base", "components", "demo", "templates", "page.html"), `base`)
+	writeTemplate(t, filepath.Join("themes", "base", "components", "demo", "templates", "other.html"), `base-other`)
+
+	render := func(locale, name string) string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		if err := Render(localeCtx("lt.example", "base", locale, nil), rr, "demo", name, nil, CacheDefault); err != nil {
+			t.Fatalf("render %s/%s: %v", locale, name, err)
+		}
+		return rr.Body.String()
+	}
+
+	// Same host, cached sets: the key must keep the two chains apart.
+	if got := render("ar_EG", "page"); got != "rtl" {
+		t.Fatalf("ar page = %q", got)
+	}
+	if got := render("en_US", "page"); got != "base" {
+		t.Fatalf("en page = %q", got)
+	}
+	if got := render("ar_EG", "page"); got != "rtl" {
+		t.Fatalf("ar page (cached) = %q", got)
+	}
+
+	// The RTL theme lacks other.html, so the tenant theme supplies it.
+	if got := render("ar_EG", "other"); got != "base-other" {
+		t.Fatalf("ar other = %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+// internal/view/paths.go
+//
+// Validation of the host, Component, and template name that load joins
+// into filesystem paths.
+//
+// Context
+// -------
+// load builds sites/<host>/components/<comp>/templates/<name>.html and its
+// theme and Component fallbacks.  Host comes from the request, and comp and
+// name come from code that may pass user input through, so a Host header
+// such as "../../etc" or a name such as "../../secrets" could point Stat and
+// ParseGlob outside the template trees.  templatePaths refuses such input
+// with ErrTemplatePath before the filesystem is touched:
+//
+//   - host   – the tenant's canonical host when the request is bound to a
+//     Tenant (the same folder /assets uses), else Request.Host without its
+//     port, as tenant lookup sees it.  Letters, digits, "-", and "." only,
+//     with an optional leading "*." for wildcard sites; no empty labels.
+//   - comp   – letters, digits, "_", and "-".
+//   - name   – one or more "/"-separated segments of letters, digits, "_",
+//     "-", and "."; "." and ".." segments are refused, so nested names
+//     such as "widgets/menu" keep working.
+//   - theme  – each name in the theme chain (localetheme.go), as comp.
+//
+// Notes
+// -----
+// • Validation rejects rather than cleans: a path that needs cleaning is
+//   never one a legitimate caller built.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// ErrTemplatePath rejects a host, Component, or template name that could
+// escape the template trees.
+var ErrTemplatePath = errors.New("view: invalid template path")
+
+// templateHost returns the host whose sites/ folder ctx may read.
+func templateHost(ctx *tenant.Context) string {
+	if ctx.Tenant != nil && ctx.Tenant.Host() != "" {
+		return ctx.Tenant.Host()
+	}
+	h := ctx.Request.Host
+	if i := strings.IndexByte(h, ':'); i != -1 {
+		h = h[:i]
+	}
+	return h
+}
+
+// templatePaths validates host, comp, and name and returns the lookup
+// chain, highest precedence first.
+func templatePaths(host string, themes []string, comp, name string) ([]string, error) {
+	for _, th := range themes {
+		if !validTheme(th) {
+			return nil, fmt.Errorf("%w: theme %q", ErrTemplatePath, th)
+		}
+	}
+	switch {
+	case !validHost(host):
+		return nil, fmt.Errorf("%w: host %q", ErrTemplatePath, host)
+	case !validComp(comp):
+		return nil, fmt.Errorf("%w: component %q", ErrTemplatePath, comp)
+	case !validName(name):
+		return nil, fmt.Errorf("%w: name %q", ErrTemplatePath, name)
+	}
+	rel := filepath.FromSlash(name) + ".html"
+	paths := []string{filepath.Join("sites", host, "components", comp, "templates", rel)}
+	for _, th := range themes {
+		paths = append(paths, filepath.Join("themes", th, "components", comp, "templates", rel))
+	}
+	return append(paths, filepath.Join("components", comp, "templates", rel)), nil
+}
+
+// validHost accepts DNS-style names and a leading "*." wildcard.
+func validHost(h string) bool {
+	h = strings.TrimPrefix(h, "*.")
+	if h == "" {
+		return false
+	}
+	for _, label := range strings.Split(h, ".") {
+		if label == "" || !onlyChars(label, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+func validComp(c string) bool { return c != "" && onlyChars(c, "_-") }
+
+// validName accepts "login" and "widgets/menu", never "." or "..".
+func validName(n string) bool {
+	if n == "" {
+		return false
+	}
+	for _, seg := range strings.Split(n, "/") {
+		if seg == "" || seg == "." || seg == ".." || !onlyChars(seg, "_-.") {
+			return false
+		}
+	}
+	return true
+}
+
+// onlyChars reports whether s holds only ASCII letters, digits, and extra.
+func onlyChars(s, extra string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(extra, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+// internal/view/paths_test.go
+//
+// Unit-tests for template path validation.
+
+package view
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestTemplatePaths_RejectsTraversal(t *testing.T) {
+	cases := []struct{ host, comp, name string }{
+		{"../../etc", "demo", "page"},
+		{"a..example", "demo", "page"},
+		{"evil/example", "demo", "page"},
+		{"", "demo", "page"},
+		{"ok.example", "../auth", "page"},
+		{"ok.example", "de mo", "page"},
+		{"ok.example", "demo", "../../secret"},
+		{"ok.example", "demo", "widgets/../../x"},
+		{"ok.example", "demo", "/abs"},
+		{"ok.example", "demo", `a\b`},
+		{"ok.example", "demo", ""},
+	}
+	for _, tc := range cases {
+		if _, err := templatePaths(tc.host, []string{"default"}, tc.comp, tc.name); !errors.Is(err, ErrTemplatePath) {
+			t.Errorf("templatePaths(%q, %q, %q) err = %v", tc.host, tc.comp, tc.name, err)
+		}
+	}
+
+	paths, err := templatePaths("*.app.example", []string{"default"}, "blog", "widgets/menu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join("sites", "*.app.example", "components", "blog", "templates", "widgets", "menu.html")
+	if paths[0] != want {
+		t.Fatalf("paths[0] = %q, want %q", paths[0], want)
+	}
+}
+
+func TestRender_NestedNameAndHostConfinement(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTemplate(t, filepath.Join("components", "demo", "templates", "widgets", "menu.html"), `<nav>{{ . }}</nav>`)
+
+	render := func(host, name string) (string, error) {
+		req := httptest.NewRequest(http.MethodGet, "http://placeholder/", nil)
+		req.Host = host
+		rr := httptest.NewRecorder()
+		err := Render(tenant.NewContext(req), rr, "demo", name, "m", CacheDefault)
+		return rr.Body.String(), err
+	}
+
+	if out, err := render("nested.example:8080", "widgets/menu"); err != nil || out != "<nav>m</nav>" {
+		t.Fatalf("nested name = %q, %v", out, err)
+	}
+	if _, err := render("../../etc", "widgets/menu"); !errors.Is(err, ErrTemplatePath) {
+		t.Fatalf("crafted Host err = %v", err)
+	}
+	if _, err := render("nested.example", "widgets/../../../x"); !errors.Is(err, ErrTemplatePath) {
+		t.Fatalf("traversing name err = %v", err)
+	}
+}
@@ -5,13 +5,18 @@
 //
 // Public helpers
 // --------------
-//   - Render         – write rendered HTML to an http.ResponseWriter.
+//   - Render         – write rendered HTML to an http.ResponseWriter, with
+//                      an ETag and 304 support unless CacheSkip (etag.go).
 //   - RenderToString – return template.HTML (widgets, e-mails).
+//   - RenderContent  – the same plus its Content-Type (contenttype.go).
 //
-// Lookup precedence (first hit wins):
+// Lookup precedence (first hit wins; host, comp, and name are validated
+// first, see paths.go):
 //   1. sites/<host>/components/<comp>/templates/<tpl>.html
-//   2. themes/<theme>/components/<comp>/templates/<tpl>.html
-//   3. components/<comp>/templates/<tpl>.html
+//   2. themes/<locale theme>/components/<comp>/templates/<tpl>.html, when
+//      the locale maps to a theme (localetheme.go)
+//   3. themes/<tenant theme>/components/<comp>/templates/<tpl>.html
+//   4. components/<comp>/templates/<tpl>.html
 //
 // All templates in the same directory are parsed as one set so sub-templates
 // ({{ template "row" . }}) work out-of-the-box.
@@ -24,6 +29,26 @@
 //   • Callers now pass the logical name (e.g. "login"); view.Render figures
 //     out the concrete template automatically.
 //
+// Request binding
+// ---------------
+// The LRU holds master sets that are never executed.  Each render clones
+// the master and attaches helpers (widget, area, head, routePath,
+// queryParam, url, user, geo, and asset) bound to the current
+// tenant.Context, so a theme's {{ head }} always reflects this request:
+//
+//	{{ if user.LoggedIn }}Hi {{ user.Email }}{{ end }}
+//	{{ with geo }}{{ .CountryISO }}{{ end }}
+//
+// A page that prints user or geo differs per visitor; render it with
+// CacheSkip or keep it out of shared caches.
+//
+// Sandbox
+// -------
+// A tenant with site_config templates.sandbox = true gets its own
+// sites/<host> templates parsed with a restricted func map and confined to
+// the site directory (sandbox.go).  Theme and Component templates are
+// unaffected.
+//
 // Style
 // -----
 // • Oxford commas, two spaces after periods.
@@ -35,12 +60,15 @@ import (
 	"html/template"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/requestinfo"
 	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/theme"
 	"github.com/yanizio/adept/internal/widget"
 )
 
@@ -58,9 +86,33 @@ const (
 )
 
 // Parsed template sets per tenant; tweak capacity when perf-testing.
-var tmplLRU = cache.New(1024)
+// cache.LRU is not goroutine-safe, so tmplMu guards every access.
+var (
+	tmplMu  sync.Mutex
+	tmplLRU = cache.New(1024)
+)
 var once sync.Once
 
+// init drops a host's parsed templates and widget fragments when its theme
+// is hot-reloaded, so edits to site templates show up together with it.
+func init() {
+	tenant.OnThemeReload(func(host string) {
+		purgeTemplates(host)
+		purgeWidgetHost(host)
+	})
+}
+
+// purgeTemplates removes every cached template set for host.  Keys start
+// with the template host (paths.go).
+func purgeTemplates(host string) {
+	tmplMu.Lock()
+	defer tmplMu.Unlock()
+	tmplLRU.RemoveIf(func(k any) bool {
+		h, _, _ := strings.Cut(k.(string), "::")
+		return h == host
+	})
+}
+
 //
 // public helpers
 //
@@ -76,26 +128,55 @@ var once sync.Once
 //     on that root template name.
 //
 // Either style works; developers can choose per component.
+//
+// CacheDefault and CacheForce pages are rendered into a buffer first so
+// they can carry a strong ETag, and a GET or HEAD whose If-None-Match
+// matches gets 304 with no body (etag.go).  CacheSkip pages (forms with
+// CSRF tokens) still stream and never get an ETag.
 func Render(ctx *tenant.Context, w http.ResponseWriter, comp, name string, data any, policy CachePolicy) error {
 	t, err := load(ctx, comp, name, policy)
 	if err != nil {
 		return err
 	}
-	return t.ExecuteTemplate(w, execName(t, name), data)
+	setContentType(w, name) // contenttype.go
+	if policy == CacheSkip {
+		return t.ExecuteTemplate(w, execName(t, name), data)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, execName(t, name), data); err != nil {
+		return err
+	}
+	return writeWithETag(w, ctx.Request, buf.Bytes())
 }
 
 // RenderToString executes and returns HTML (used by widgets and e-mail
 // generators).  It mirrors Render, but writes to a buffer instead of w.
 func RenderToString(ctx *tenant.Context, comp, name string, data any) (template.HTML, CachePolicy, error) {
-	t, err := load(ctx, comp, name, CacheDefault)
+	out, err := RenderContent(ctx, comp, name, data)
 	if err != nil {
 		return "", CacheSkip, err
 	}
+	return template.HTML(out.Body), out.Policy, nil
+}
+
+// Rendered is one template's output and the type it should be sent as.
+type Rendered struct {
+	Body        string
+	ContentType string // ContentType(name), e.g. for an e-mail MIME part
+	Policy      CachePolicy
+}
+
+// RenderContent is RenderToString plus the output's Content-Type.
+func RenderContent(ctx *tenant.Context, comp, name string, data any) (Rendered, error) {
+	t, err := load(ctx, comp, name, CacheDefault)
+	if err != nil {
+		return Rendered{Policy: CacheSkip}, err
+	}
 	var buf bytes.Buffer
 	if err := t.ExecuteTemplate(&buf, execName(t, name), data); err != nil {
-		return "", CacheSkip, err
+		return Rendered{Policy: CacheSkip}, err
 	}
-	return template.HTML(buf.String()), CacheDefault, nil
+	return Rendered{Body: buf.String(), ContentType: ContentType(name), Policy: CacheDefault}, nil
 }
 
 //
@@ -105,21 +186,27 @@ func RenderToString(ctx *tenant.Context, comp, name string, data any) (template.
 // load finds and (if necessary) parses the template set for the given tenant,
 // component, and base name, obeying the provided cache policy.
 func load(ctx *tenant.Context, comp, name string, policy CachePolicy) (*template.Template, error) {
-	theme := "default" // TODO: derive from tenant once theme support lands
-	key := strings.Join([]string{ctx.Request.Host, theme, comp, name}, "::")
+	themes := themeChain(ctx) // localetheme.go
+	host := templateHost(ctx)
+	paths, err := templatePaths(host, themes, comp, name) // paths.go
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join([]string{host, strings.Join(themes, ">"), comp, name}, "::")
+	if sandboxed(ctx) {
+		key += "::sandbox"
+	}
 
 	if policy != CacheSkip {
-		if v, ok := tmplLRU.Get(key); ok {
-			return v.(*template.Template), nil
+		tmplMu.Lock()
+		v, ok := tmplLRU.Get(key)
+		tmplMu.Unlock()
+		if ok {
+			set := v.(*tmplSet)
+			return bind(set.t, ctx, set.sandbox)
 		}
 	}
 
-	paths := []string{
-		filepath.Join("sites", ctx.Request.Host, "components", comp, "templates", name+".html"),
-		filepath.Join("themes", theme, "components", comp, "templates", name+".html"),
-		filepath.Join("components", comp, "templates", name+".html"),
-	}
-
 	var base string
 	for _, p := range paths {
 		if _, err := os.Stat(p); err == nil {
@@ -131,34 +218,72 @@ func load(ctx *tenant.Context, comp, name string, policy CachePolicy) (*template
 		return nil, os.ErrNotExist
 	}
 
-	// Parse all *.html in the same directory so sub-templates work.
+	// Parse all *.html in the same directory so sub-templates work.  Site
+	// templates of a sandboxed tenant get the restricted parse (sandbox.go).
 	dir := filepath.Dir(base)
-	pattern := filepath.Join(dir, "*.html")
+	set := &tmplSet{sandbox: base == paths[0] && sandboxed(ctx)}
 
-	t, err := template.New(name).Funcs(buildFuncMap(ctx)).ParseGlob(pattern)
+	if set.sandbox {
+		set.t, err = parseSandboxed(name, host, dir)
+	} else {
+		set.t, err = template.New(name).Funcs(buildFuncMap(nil)).
+			ParseGlob(filepath.Join(dir, "*.html"))
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	if policy != CacheSkip {
-		tmplLRU.Add(key, t)
+		tmplMu.Lock()
+		tmplLRU.Add(key, set)
+		tmplMu.Unlock()
 	}
-	return t, nil
+	return bind(set.t, ctx, set.sandbox)
+}
+
+// tmplSet is one cached master set and how it was parsed.
+type tmplSet struct {
+	t       *template.Template
+	sandbox bool
+}
+
+// bind clones the cached master set and attaches request-scoped helpers.
+// The master is never executed, so Clone stays legal, and helpers such as
+// widget and head always see the current request, not the first one.  A
+// sandboxed set only ever gets the restricted map.
+func bind(master *template.Template, ctx *tenant.Context, sandbox bool) (*template.Template, error) {
+	t, err := master.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if sandbox {
+		return t.Funcs(sandboxFuncMap(ctx)), nil
+	}
+	return t.Funcs(buildFuncMap(ctx)), nil
 }
 
 //
 // func-map builders
 //
 
+// buildFuncMap returns helpers bound to rctx.  A nil rctx yields parse-time
+// placeholders; bind swaps in the real ones before execution.
 func buildFuncMap(rctx *tenant.Context) template.FuncMap {
 	fm := template.FuncMap{
 		"dict":   dict,
 		"widget": widgetFunc(rctx),
 		"area":   areaFunc(rctx),
+		"head":   headFunc(rctx),
+		"user":   userFunc(rctx),
+		"geo":    geoFunc(rctx),
+		"asset":  assetFunc(rctx),
 	}
 	for k, v := range uaFuncMap() { // UA helpers (browser/os parsing)
 		fm[k] = v
 	}
+	for k, v := range urlFuncMap(rctx) { // routePath, queryParam, url
+		fm[k] = v
+	}
 	return fm
 }
 
@@ -169,11 +294,13 @@ func buildFuncMap(rctx *tenant.Context) template.FuncMap {
 // execName picks the template name to execute.
 //
 // Priority:
-//  1. If the set has "<name>.html" (file-based template), run that.
+//  1. If the set has "<name>.html" (file-based template), run that.  Sets
+//     name files by base name, so "widgets/menu" runs "menu.html".
 //  2. Otherwise, fall back to "<name>" (root template defined in code).
 func execName(t *template.Template, name string) string {
-	if tmpl := t.Lookup(name + ".html"); tmpl != nil {
-		return name + ".html"
+	file := path.Base(name) + ".html"
+	if tmpl := t.Lookup(file); tmpl != nil {
+		return file
 	}
 	return name
 }
@@ -188,23 +315,96 @@ func dict(kv ...any) map[string]any {
 	return m
 }
 
-// widgetFunc renders a registered widget and returns safe HTML.  Errors are
-// hidden behind <!-- comments --> so end-users never see stack traces.
+// *tenant.Context is the widget.Context every widget receives.
+var _ widget.Context = (*tenant.Context)(nil)
+
+// widgetFunc renders a registered widget and returns safe HTML.  Errors,
+// panics, and timeouts (widgetsafe.go) are hidden behind <!-- comments -->
+// so end-users never see stack traces.  Declared params are checked first
+// (widgetparams.go).
+//
+// Fragments are served from the widget cache (widgetcache.go) when present;
+// otherwise Render runs and its CachePolicy decides whether to store them,
+// unless the HTML carries the request's CSP nonce.
 func widgetFunc(rctx *tenant.Context) func(string, map[string]any) template.HTML {
 	return func(key string, params map[string]any) template.HTML {
 		w := widget.Lookup(key)
 		if w == nil {
 			return template.HTML("<!-- widget not found -->")
 		}
-		html, _, err := w.Render(rctx, params)
+		params, comment, ok := checkParams(rctx, key, w, params)
+		if !ok {
+			return template.HTML(comment)
+		}
+
+		ck := newWidgetKey(templateHost(rctx), key, params)
+		if html, ok := cachedWidget(ck); ok {
+			recordWidgetCache(true)
+			return template.HTML(html)
+		}
+
+		html, policy, err := safeRender(rctx, key, w, params)
 		if err != nil {
-			return template.HTML("<!-- widget error -->")
+			return template.HTML(widgetComment(err))
+		}
+		var nonce string
+		if rctx.Head != nil {
+			nonce = rctx.Head.Nonce()
+		}
+		if storeWidget(ck, w, html, CachePolicy(policy), nonce) {
+			recordWidgetCache(false)
 		}
 		return template.HTML(html)
 	}
 }
 
-// areaFunc is a stub until the widget-area feature lands.
+// headFunc renders the full <head> payload: {{ head }}.
+func headFunc(rctx *tenant.Context) func() template.HTML {
+	return func() template.HTML {
+		if rctx == nil || rctx.Head == nil {
+			return ""
+		}
+		return rctx.Head.RenderAll()
+	}
+}
+
+// userFunc returns the session user: {{ user.Email }}.  Zero outside a
+// request.
+func userFunc(rctx *tenant.Context) func() tenant.User {
+	return func() tenant.User {
+		if rctx == nil {
+			return tenant.User{}
+		}
+		return rctx.User
+	}
+}
+
+// geoFunc returns the client's Geo: {{ geo.CountryISO }}.  Zero outside a
+// request or without a GeoIP database.
+func geoFunc(rctx *tenant.Context) func() requestinfo.Geo {
+	return func() requestinfo.Geo {
+		if rctx == nil {
+			return requestinfo.Geo{}
+		}
+		return rctx.Geo
+	}
+}
+
+// assetFunc returns an asset's fingerprinted URL through the tenant's
+// theme, the same chain /assets/* serves: {{ asset "css/site.css" }}.
+func assetFunc(rctx *tenant.Context) func(string) string {
+	return func(p string) string {
+		if rctx != nil && rctx.Tenant != nil {
+			if th := rctx.Tenant.GetTheme(); th != nil && th.AssetFunc != nil {
+				return th.AssetFunc(p)
+			}
+		}
+		return theme.AssetPrefix + strings.TrimPrefix(p, "/")
+	}
+}
+
+// areaFunc is a stub until the widget-area feature lands.  When it renders
+// widgets it must go through safeRender, as widgetFunc does.
 func areaFunc(_ *tenant.Context) func(string) template.HTML {
 	return func(string) template.HTML { return "" }
 }
@@ -0,0 +1,162 @@
+// internal/view/render_test.go
+//
+// Unit-tests for request binding of cached template sets.
+//
+// Context
+// -------
+// Parsed sets are cached per host and component.  Helpers such as head and
+// widget close over the request, so every render must see its own
+// tenant.Context, never the one that happened to populate the cache.
+//
+// Notes
+// -----
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestRender_HeadFuncUsesCurrentRequest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("components", "demo", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "page.html"),
+		[]byte(`<head>{{ head }}</head>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(title string) string {
+		req := httptest.NewRequest(http.MethodGet, "http://bind.example/", nil)
+		ctx := tenant.NewContext(req)
+		ctx.Head.SetTitle(title)
+		rr := httptest.NewRecorder()
+		if err := Render(ctx, rr, "demo", "page", nil, CacheDefault); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return rr.Body.String()
+	}
+
+	first := render("First")
+	second := render("Second") // served from the template cache
+	if !strings.Contains(first, "<title>First</title>") {
+		t.Fatalf("first render: %s", first)
+	}
+	if !strings.Contains(second, "<title>Second</title>") {
+		t.Fatalf("cached set bound to a stale request: %s", second)
+	}
+}
+
+func TestRender_ETagThen304(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("components", "demo", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(`<p>{{ . }}</p>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(policy CachePolicy, inm string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://etag.example/", nil)
+		if inm != "" {
+			req.Header.Set("If-None-Match", inm)
+		}
+		rr := httptest.NewRecorder()
+		if err := Render(tenant.NewContext(req), rr, "demo", "page", "hi", policy); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return rr
+	}
+
+	first := render(CacheDefault, "")
+	tag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != "<p>hi</p>" || !strings.HasPrefix(tag, `"`) {
+		t.Fatalf("first = %d %q etag %q", first.Code, first.Body, tag)
+	}
+
+	for _, inm := range []string{tag, `"other", W/` + tag, "*"} {
+		again := render(CacheForce, inm)
+		if again.Code != http.StatusNotModified || again.Body.Len() != 0 || again.Header().Get("ETag") != tag {
+			t.Fatalf("If-None-Match %s = %d %q", inm, again.Code, again.Body)
+		}
+	}
+	if stale := render(CacheDefault, `"stale"`); stale.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match = %d, want 200", stale.Code)
+	}
+
+	skip := render(CacheSkip, tag)
+	if skip.Code != http.StatusOK || skip.Header().Get("ETag") != "" || skip.Body.String() != "<p>hi</p>" {
+		t.Fatalf("CacheSkip = %d etag %q", skip.Code, skip.Header().Get("ETag"))
+	}
+}
+
+func TestRender_UserAndGeoHelpers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("components", "demo", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "who.html"),
+		[]byte(`{{ if user.LoggedIn }}{{ user.Email }}{{ else }}anon{{ end }}/{{ geo.CountryISO }}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://who.example/", nil)
+	ctx := tenant.NewContext(req)
+	ctx.User.Email = "ada@example.com"
+	ctx.Geo.CountryISO = "NZ"
+	rr := httptest.NewRecorder()
+	if err := Render(ctx, rr, "demo", "who", nil, CacheSkip); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if got := rr.Body.String(); got != "ada@example.com/NZ" {
+		t.Fatalf("body = %q", got)
+	}
+}
+
+func TestRender_ContentType(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTemplate(t, filepath.Join("components", "demo", "templates", "page.html"), `  {"looks": "like json"}`)
+	writeTemplate(t, filepath.Join("components", "demo", "templates", "sitemap.xml.html"), `<urlset/>`)
+
+	render := func(name, preset string, policy CachePolicy) string {
+		req := httptest.NewRequest(http.MethodGet, "http://ctype.example/", nil)
+		rr := httptest.NewRecorder()
+		if preset != "" {
+			rr.Header().Set("Content-Type", preset)
+		}
+		if err := Render(tenant.NewContext(req), rr, "demo", name, nil, policy); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+		return rr.Header().Get("Content-Type")
+	}
+
+	for _, policy := range []CachePolicy{CacheSkip, CacheDefault} {
+		if got := render("page", "", policy); got != "text/html; charset=utf-8" {
+			t.Errorf("page (policy %d) = %q", policy, got)
+		}
+		if got := render("page", "application/json", policy); got != "application/json" {
+			t.Errorf("handler type overridden (policy %d): %q", policy, got)
+		}
+	}
+	if got := render("sitemap.xml", "", CacheSkip); got != "text/xml; charset=utf-8" {
+		t.Errorf("sitemap.xml = %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://ctype.example/", nil)
+	out, err := RenderContent(tenant.NewContext(req), "demo", "sitemap.xml", nil)
+	if err != nil || out.Body != "<urlset/>" || out.ContentType != "text/xml; charset=utf-8" {
+		t.Fatalf("RenderContent = %+v, %v", out, err)
+	}
+}
@@ -0,0 +1,143 @@
+// internal/view/sandbox.go
+//
+// Restricted helpers and path confinement for tenant-authored templates.
+//
+// Context
+// -------
+// Templates under sites/<host>/ may be uploaded by the tenant rather than
+// written by us.  html/template escapes output, but a template can still
+// call every helper in the func map (widget runs arbitrary Go and SQL), the
+// built-in call invokes any func value it can reach, and a symlink in the
+// site folder can pull in a file from anywhere on disk.
+//
+// A tenant opts in with site_config templates.sandbox = true.  Then, and
+// only for sets whose winning template lives under sites/<host>/:
+//
+//   - The set is parsed and executed with sandboxFuncMap, an allow-list of
+//     helpers.  A template that names anything else (widget, user, …)
+//     fails to parse, so the error surfaces on first render rather than
+//     half-way through a page.
+//   - The built-in call is replaced by a func that always errors.
+//   - Every parsed file must resolve, after symlinks, inside
+//     sites/<host>/; otherwise the whole set is refused with
+//     ErrOutsideSite.
+//
+// Theme and Component templates are first-party and keep the full func
+// map, for sandboxed tenants too.
+//
+// Notes
+// -----
+// • The sandbox limits helpers and files, not data.  Methods on the value
+//   passed to Render are still callable, so handlers must not hand raw
+//   pools or clients to templates that a tenant can override.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanizio/adept/internal/component"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// SandboxKey is the site_config flag that sandboxes site templates.
+const SandboxKey = "templates.sandbox"
+
+func init() {
+	component.DeclareConfig(component.ConfigKey{
+		Name: SandboxKey, Type: component.ConfigBool, Default: "false",
+	})
+}
+
+var (
+	// ErrOutsideSite rejects a sandboxed set that reaches outside the
+	// site directory.
+	ErrOutsideSite = errors.New("view: template outside site directory")
+
+	errSandboxCall = errors.New("call is not allowed in sandboxed templates")
+)
+
+// sandboxFuncs lists the helpers sandboxed templates may use.  Every entry
+// is read-only and request-scoped; widget is the notable omission.
+var sandboxFuncs = []string{
+	"dict", "asset", "head", "area", "routePath", "queryParam", "url", "geo",
+}
+
+// sandboxed reports whether rctx's tenant opted in to the sandbox.
+func sandboxed(rctx *tenant.Context) bool {
+	return rctx != nil && rctx.Tenant != nil &&
+		rctx.Tenant.Config.Bool(SandboxKey, false)
+}
+
+// sandboxFuncMap is buildFuncMap cut down to sandboxFuncs, with call
+// disabled.
+func sandboxFuncMap(rctx *tenant.Context) template.FuncMap {
+	full := buildFuncMap(rctx)
+	fm := make(template.FuncMap, len(sandboxFuncs)+1)
+	for _, k := range sandboxFuncs {
+		fm[k] = full[k]
+	}
+	fm["call"] = func(any, ...any) (any, error) { return nil, errSandboxCall }
+	return fm
+}
+
+// parseSandboxed parses every *.html in dir, which must lie inside
+// sites/<host>, with the restricted func map.
+func parseSandboxed(name, host, dir string) (*template.Template, error) {
+	if !validHost(host) {
+		return nil, fmt.Errorf("%w: host %q", ErrOutsideSite, host)
+	}
+	root := filepath.Join("sites", host)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, os.ErrNotExist
+	}
+	for _, f := range files {
+		if err := confine(root, f); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := template.New(name).Funcs(sandboxFuncMap(nil)).ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("sandboxed template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// confine returns ErrOutsideSite unless p, with symlinks resolved, lies
+// inside root.
+func confine(root, p string) error {
+	realRoot, err := realAbs(root)
+	if err != nil {
+		return err
+	}
+	realPath, err := realAbs(p)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(realRoot, realPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrOutsideSite, p)
+	}
+	return nil
+}
+
+// realAbs resolves symlinks in p and makes it absolute.
+func realAbs(p string) (string, error) {
+	r, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(r)
+}
@@ -0,0 +1,111 @@
+// internal/view/sandbox_test.go
+//
+// Unit-tests for the tenant template sandbox.
+//
+// Notes
+// -----
+// • Each test runs in a temp dir laid out like the app root (sites/,
+//   components/).
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func writeTemplate(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sandboxRender(t *testing.T, host string, sandbox bool, comp, name string, data any) (string, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+	ctx := tenant.NewContext(req)
+	ctx.Tenant = &tenant.Tenant{Config: tenant.SiteConfig{SandboxKey: "false"}}
+	if sandbox {
+		ctx.Tenant.Config[SandboxKey] = "true"
+	}
+	rr := httptest.NewRecorder()
+	err := Render(ctx, rr, comp, name, data, CacheSkip)
+	return rr.Body.String(), err
+}
+
+func TestSandbox_SiteTemplatesGetRestrictedFuncs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTemplate(t, filepath.Join("sites", "sb.example", "components", "demo", "templates", "page.html"),
+		`{{ widget "x" nil }}`)
+	writeTemplate(t, filepath.Join("sites", "sb.example", "components", "demo", "templates", "ok.html"),
+		`<p>{{ routePath }}</p>`)
+	writeTemplate(t, filepath.Join("components", "demo", "templates", "trusted.html"),
+		`{{ widget "x" nil }}`)
+
+	// Opted out: the site template keeps the full map.
+	if _, err := sandboxRender(t, "sb.example", false, "demo", "page", nil); err != nil {
+		t.Fatalf("unsandboxed render: %v", err)
+	}
+
+	// Opted in: widget is not defined for the site set.
+	_, err := sandboxRender(t, "sb.example", true, "demo", "page", nil)
+	if err == nil || !strings.Contains(err.Error(), `"widget" not defined`) {
+		t.Fatalf("sandboxed widget call: err = %v", err)
+	}
+	if _, err := sandboxRender(t, "sb.example", true, "demo", "ok", nil); err == nil {
+		// ok.html shares a set with page.html, so it is refused too.
+		t.Fatal("set with a forbidden helper parsed")
+	}
+
+	// First-party Component templates keep the full map.
+	if out, err := sandboxRender(t, "sb.example", true, "demo", "trusted", nil); err != nil ||
+		!strings.Contains(out, "widget not found") {
+		t.Fatalf("trusted render = %q, %v", out, err)
+	}
+}
+
+func TestSandbox_CallDisabled(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTemplate(t, filepath.Join("sites", "sb.example", "components", "demo", "templates", "page.html"),
+		`<p>{{ call .F }}</p>`)
+	data := map[string]any{"F": func() string { return "ran" }}
+
+	if out, err := sandboxRender(t, "sb.example", false, "demo", "page", data); err != nil || out != "<p>ran</p>" {
+		t.Fatalf("unsandboxed call = %q, %v", out, err)
+	}
+	out, err := sandboxRender(t, "sb.example", true, "demo", "page", data)
+	if !errors.Is(err, errSandboxCall) || strings.Contains(out, "ran") {
+		t.Fatalf("sandboxed call = %q, %v", out, err)
+	}
+}
+
+func TestSandbox_SymlinkOutsideSiteRefused(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTemplate(t, filepath.Join("secret", "leak.html"), `{{ define "leak" }}secret{{ end }}`)
+	dir := filepath.Join("sites", "sb.example", "components", "demo", "templates")
+	writeTemplate(t, filepath.Join(dir, "page.html"), `{{ template "leak" }}`)
+	abs, _ := filepath.Abs(filepath.Join("secret", "leak.html"))
+	if err := os.Symlink(abs, filepath.Join(dir, "leak.html")); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+
+	_, err := sandboxRender(t, "sb.example", true, "demo", "page", nil)
+	if !errors.Is(err, ErrOutsideSite) {
+		t.Fatalf("err = %v, want ErrOutsideSite", err)
+	}
+	if out, err := sandboxRender(t, "sb.example", false, "demo", "page", nil); err != nil || out != "secret" {
+		t.Fatalf("unsandboxed render = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,62 @@
+// internal/view/urlhelpers.go
+//
+// URL-related template helpers for analytics and canonical tags.
+//
+// Context
+// -------
+// Themes emitting analytics snippets need the current path and a handful
+// of query parameters (utm_source, gclid) without handler plumbing:
+//
+//	<meta name="page" content="{{ routePath }}">
+//	<script>track({{ routePath }}, {{ queryParam "utm_source" }})</script>
+//
+// Links to Component routes go through url, which prints the tenant's
+// friendly alias when one exists (routing.PublicPath):
+//
+//	<a href="{{ url "/content/page/view/about" }}">About</a>   → /about
+//
+// Notes
+// -----
+// • Helpers return plain strings, never template.HTML or template.JS, so
+//   html/template escapes them for the context they land in: entity-escaped
+//   in attributes, a quoted and escaped literal inside <script>, and
+//   percent-encoded in URLs.
+// • Missing parameters, and renders without a request, yield "".
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"html/template"
+
+	"github.com/yanizio/adept/internal/routing"
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+// urlFuncMap returns helpers bound to rctx's URLInfo.
+func urlFuncMap(rctx *tenant.Context) template.FuncMap {
+	return template.FuncMap{
+		// routePath is the request path with one leading slash ("/" at root).
+		"routePath": func() string {
+			if rctx == nil {
+				return ""
+			}
+			return "/" + rctx.URL.Route
+		},
+		// queryParam returns the first value of key, or "".
+		"queryParam": func(key string) string {
+			if rctx == nil {
+				return ""
+			}
+			return rctx.URL.Query.Get(key)
+		},
+		// url maps an absolute Component path to its public alias, or
+		// returns it unchanged.
+		"url": func(p string) string {
+			if rctx == nil || rctx.Tenant == nil {
+				return p
+			}
+			return routing.PublicPath(rctx.Tenant, p)
+		},
+	}
+}
@@ -0,0 +1,54 @@
+// internal/view/urlhelpers_test.go
+//
+// Unit-tests for routePath and queryParam, including contextual escaping.
+
+package view
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanizio/adept/internal/tenant"
+)
+
+func TestURLHelpers_EscapedPerContext(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("components", "demo", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	page := `<a data-utm="{{ queryParam "utm_source" }}">{{ routePath }}</a>` +
+		`<script>track({{ queryParam "utm_source" }}, {{ queryParam "missing" }})</script>`
+	if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(page), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := `x"></a><script>alert(1)</script>`
+	req := httptest.NewRequest(http.MethodGet, "http://url.example/blog/post?utm_source="+
+		strings.NewReplacer(`"`, "%22", "<", "%3C", ">", "%3E", " ", "%20").Replace(src), nil)
+	rr := httptest.NewRecorder()
+	if err := Render(tenant.NewContext(req), rr, "demo", "page", nil, CacheDefault); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := rr.Body.String()
+
+	if strings.Contains(out, "<script>alert(1)") {
+		t.Fatalf("query value not escaped: %s", out)
+	}
+	if !strings.Contains(out, `data-utm="x&#34;&gt;&lt;/a&gt;`) {
+		t.Fatalf("attribute context: %s", out)
+	}
+	if !strings.Contains(out, `track("x\"\u003e\u003c/a\u003e`) {
+		t.Fatalf("JS context: %s", out)
+	}
+	if !strings.Contains(out, `, "")`) {
+		t.Fatalf("missing param should render as empty string: %s", out)
+	}
+	if !strings.Contains(out, ">/blog/post</a>") {
+		t.Fatalf("routePath: %s", out)
+	}
+}
@@ -0,0 +1,183 @@
+// internal/view/widgetcache.go
+//
+// Fragment cache for rendered widgets.
+//
+// Context
+// -------
+// Widget.Render returns a CachePolicy hint next to its HTML.  widgetFunc
+// consults this cache before calling Render and stores the result afterwards
+// unless the widget asked for CacheSkip (forms carrying CSRF tokens do) or
+// the HTML contains the request's CSP nonce, which a later request must
+// never replay.
+//
+// CacheDefault fragments are cached, so a widget whose output depends on
+// the visitor (IP, UA, session) must return CacheSkip.  Widgets registered
+// with widget.RegisterLegacy predate the cache; their CacheDefault is
+// treated as CacheSkip.
+//
+// Keys combine the tenant host (templateHost, as the template LRU uses, so
+// eviction purges them), the widget key, and a stable hash of the params
+// map, so `{{ widget "nav/menu" (dict "depth" 2) }}` and the same call
+// with depth 3 are cached separately.
+//
+// Lifetime
+// --------
+//   - CacheDefault → widgetTTL (SetWidgetCacheTTL, default 5 min).
+//   - CacheForce   → forceTTL (one hour).
+//   - A widget implementing widget.TTLWidget overrides both.
+//   - Every entry for a host is purged when the tenant cache evicts it.
+//
+// Metrics
+// -------
+//   - widget_cache_hits_total per fragment served from the cache;
+//     widget_cache_misses_total per render whose result was stored.
+//     Uncacheable renders (CacheSkip, nonce-bearing) count as neither.
+//   - widget_cache_entries tracks the LRU size after every change.
+//
+// Notes
+// -----
+// • cache.LRU is not goroutine-safe; wcMu guards every access.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanizio/adept/internal/cache"
+	"github.com/yanizio/adept/internal/metrics"
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/widget"
+)
+
+const (
+	widgetCacheCap  = 4096             // fragments across all tenants
+	defaultWidgetTT = 5 * time.Minute  // CacheDefault lifetime
+	forceTTL        = 60 * time.Minute // CacheForce lifetime
+)
+
+var (
+	wcMu      sync.Mutex
+	widgetLRU = cache.New(widgetCacheCap)
+	widgetTTL = defaultWidgetTT
+)
+
+// widgetEntry is one cached fragment plus its expiry.
+type widgetEntry struct {
+	html string
+	exp  time.Time
+}
+
+// widgetKey is the composite LRU key; host is kept separate so eviction can
+// purge a tenant without parsing strings.
+type widgetKey struct {
+	host string
+	id   string
+	sum  uint64
+}
+
+// SetWidgetCacheTTL changes the lifetime of CacheDefault fragments.  A zero
+// or negative value disables widget caching entirely.
+func SetWidgetCacheTTL(d time.Duration) {
+	wcMu.Lock()
+	widgetTTL = d
+	wcMu.Unlock()
+}
+
+func init() {
+	tenant.OnEvict(purgeWidgetHost)
+}
+
+// cachedWidget returns a live fragment for key, if any.
+func cachedWidget(k widgetKey) (string, bool) {
+	wcMu.Lock()
+	defer wcMu.Unlock()
+	v, ok := widgetLRU.Get(k)
+	if !ok {
+		return "", false
+	}
+	ent := v.(widgetEntry)
+	if time.Now().After(ent.exp) {
+		widgetLRU.Remove(k)
+		metrics.WidgetCacheEntries.Set(float64(widgetLRU.Len()))
+		return "", false
+	}
+	return ent.html, true
+}
+
+// storeWidget records html under key when policy and TTL allow it and html
+// does not carry nonce, the request's CSP nonce.  It reports whether html
+// was stored.
+func storeWidget(k widgetKey, w widget.Widget, html string, policy CachePolicy, nonce string) bool {
+	if policy == CacheSkip || (nonce != "" && strings.Contains(html, nonce)) {
+		return false
+	}
+
+	wcMu.Lock()
+	defer wcMu.Unlock()
+
+	ttl := widgetTTL
+	if ttl <= 0 {
+		return false // caching disabled globally
+	}
+	if policy == CacheForce {
+		ttl = forceTTL
+	}
+	if tw, ok := w.(widget.TTLWidget); ok {
+		ttl = tw.CacheTTL()
+	}
+	if ttl <= 0 {
+		return false
+	}
+	widgetLRU.Add(k, widgetEntry{html: html, exp: time.Now().Add(ttl)})
+	metrics.WidgetCacheEntries.Set(float64(widgetLRU.Len()))
+	return true
+}
+
+// purgeWidgetHost drops every fragment cached for host.
+func purgeWidgetHost(host string) {
+	wcMu.Lock()
+	widgetLRU.RemoveIf(func(k any) bool { return k.(widgetKey).host == host })
+	metrics.WidgetCacheEntries.Set(float64(widgetLRU.Len()))
+	wcMu.Unlock()
+}
+
+// newWidgetKey builds the cache key for one widget call.
+func newWidgetKey(host, id string, params map[string]any) widgetKey {
+	return widgetKey{host: host, id: id, sum: hashParams(params)}
+}
+
+// hashParams returns a stable FNV-1a hash of params.  Keys are sorted so map
+// iteration order never changes the result.
+func hashParams(params map[string]any) uint64 {
+	h := fnv.New64a()
+	if len(params) == 0 {
+		return h.Sum64()
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s=%#v;", k, params[k])
+	}
+	_, _ = h.Write([]byte(sb.String()))
+	return h.Sum64()
+}
+
+// recordWidgetCache bumps the hit or miss counter.
+func recordWidgetCache(hit bool) {
+	if hit {
+		metrics.WidgetCacheHitsTotal.Inc()
+		return
+	}
+	metrics.WidgetCacheMissesTotal.Inc()
+}
@@ -0,0 +1,153 @@
+// internal/view/widgetcache_test.go
+//
+// Unit-tests for the widget fragment cache.
+
+package view
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/yanizio/adept/internal/head"
+	"github.com/yanizio/adept/internal/metrics"
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/widget"
+)
+
+// countWidget counts Render calls and returns a fixed policy.
+type countWidget struct {
+	id     string
+	policy CachePolicy
+	n      *atomic.Int32
+}
+
+func (c countWidget) ID() string { return c.id }
+func (c countWidget) Render(_ widget.Context, p map[string]any) (string, int, error) {
+	return fmt.Sprintf("<p>%d %v</p>", c.n.Add(1), p["depth"]), int(c.policy), nil
+}
+
+func widgetRenderer(host string) func(string, map[string]any) string {
+	req := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+	render := widgetFunc(tenant.NewContext(req))
+	return func(key string, params map[string]any) string { return string(render(key, params)) }
+}
+
+func TestWidgetCache_SkipAlwaysRenders(t *testing.T) {
+	var n atomic.Int32
+	widget.Register(countWidget{id: "test/skip", policy: CacheSkip, n: &n})
+	render := widgetRenderer("skip.example")
+	misses := testutil.ToFloat64(metrics.WidgetCacheMissesTotal)
+
+	first, second := render("test/skip", nil), render("test/skip", nil)
+	if first == second || n.Load() != 2 {
+		t.Fatalf("CacheSkip renders = %d (%q, %q), want 2 distinct", n.Load(), first, second)
+	}
+	if got := testutil.ToFloat64(metrics.WidgetCacheMissesTotal) - misses; got != 0 {
+		t.Fatalf("misses += %v, want 0 for uncacheable renders", got)
+	}
+}
+
+// nonceWidget stamps the request's CSP nonce onto an inline script.
+type nonceWidget struct{ n *atomic.Int32 }
+
+func (nonceWidget) ID() string { return "test/nonce" }
+func (w nonceWidget) Render(ctx widget.Context, _ map[string]any) (string, int, error) {
+	w.n.Add(1)
+	return `<script nonce="` + ctx.GetHead().Nonce() + `">go()</script>`, int(CacheForce), nil
+}
+
+func TestWidgetCache_NeverStoresNonce(t *testing.T) {
+	var n atomic.Int32
+	widget.Register(nonceWidget{n: &n})
+	t.Cleanup(func() { purgeWidgetHost("nonce.example") })
+	render := func(nonce string) string {
+		req := httptest.NewRequest(http.MethodGet, "http://nonce.example/", nil)
+		req = req.WithContext(head.WithNonce(req.Context(), nonce))
+		return string(widgetFunc(tenant.NewContext(req))("test/nonce", nil))
+	}
+
+	first, second := render("n0nce-one"), render("n0nce-two")
+	if n.Load() != 2 {
+		t.Fatalf("renders = %d, want 2: a nonce-bearing fragment was cached", n.Load())
+	}
+	if !strings.Contains(second, "n0nce-two") || strings.Contains(second, "n0nce-one") {
+		t.Fatalf("second request replayed the first nonce: %q (first %q)", second, first)
+	}
+}
+
+func TestWidgetCache_ForceServesFromCache(t *testing.T) {
+	var n atomic.Int32
+	widget.Register(countWidget{id: "test/force", policy: CacheForce, n: &n})
+	t.Cleanup(func() { purgeWidgetHost("force.example"); purgeWidgetHost("other.example") })
+	render := widgetRenderer("force.example")
+	hits := testutil.ToFloat64(metrics.WidgetCacheHitsTotal)
+
+	params := map[string]any{"depth": 2}
+	first := render("test/force", params)
+	second := render("test/force", map[string]any{"depth": 2})
+	if first != second || n.Load() != 1 {
+		t.Fatalf("CacheForce renders = %d (%q, %q), want 1", n.Load(), first, second)
+	}
+	if got := testutil.ToFloat64(metrics.WidgetCacheHitsTotal) - hits; got != 1 {
+		t.Fatalf("hits += %v, want 1", got)
+	}
+	if testutil.ToFloat64(metrics.WidgetCacheEntries) < 1 {
+		t.Fatal("widget_cache_entries not updated")
+	}
+
+	// Other params and other hosts are separate entries.
+	render("test/force", map[string]any{"depth": 3})
+	widgetRenderer("other.example")("test/force", params)
+	if n.Load() != 3 {
+		t.Fatalf("renders = %d, want 3", n.Load())
+	}
+}
+
+// legacyWidget is a pre-Context widget returning the zero policy.
+type legacyWidget struct{ n *atomic.Int32 }
+
+func (legacyWidget) ID() string { return "test/legacy" }
+func (w legacyWidget) Render(any, map[string]any) (string, int, error) {
+	return fmt.Sprintf("<p>%d</p>", w.n.Add(1)), int(CacheDefault), nil
+}
+
+func TestWidgetCache_LegacyDefaultNotCached(t *testing.T) {
+	var n atomic.Int32
+	widget.RegisterLegacy(legacyWidget{n: &n})
+	t.Cleanup(func() { purgeWidgetHost("legacy.example") })
+	render := widgetRenderer("legacy.example")
+
+	if first, second := render("test/legacy", nil), render("test/legacy", nil); first == second || n.Load() != 2 {
+		t.Fatalf("legacy renders = %d (%q, %q), want 2 distinct", n.Load(), first, second)
+	}
+}
+
+func TestWidgetCache_KeyedOnTenantHost(t *testing.T) {
+	var n atomic.Int32
+	widget.Register(countWidget{id: "test/host", policy: CacheForce, n: &n})
+	t.Cleanup(func() { purgeWidgetHost("a.example"); purgeWidgetHost("b.example") })
+	// As a server sees it: origin-form URL, host only in the Host header.
+	render := func(host string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host + ":8443"
+		return string(widgetFunc(tenant.NewContext(req))("test/host", nil))
+	}
+
+	a := render("a.example")
+	if b := render("b.example"); b == a || n.Load() != 2 {
+		t.Fatalf("renders = %d (%q, %q): hosts shared a fragment", n.Load(), a, b)
+	}
+	if render("a.example") != a || n.Load() != 2 {
+		t.Fatalf("renders = %d, want a.example served from the cache", n.Load())
+	}
+	purgeWidgetHost("a.example")
+	if render("a.example") == a || n.Load() != 3 {
+		t.Fatalf("renders = %d, want a re-render after purging a.example", n.Load())
+	}
+}
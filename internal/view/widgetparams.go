@@ -0,0 +1,50 @@
+// internal/view/widgetparams.go
+//
+// Parameter checking for widgets that declare their params.
+//
+// Context
+// -------
+// widgetFunc calls checkParams before the widget cache and Render.  For a
+// widget.ParamWidget the template's dict is validated and defaulted by
+// widget.CheckParams; a mistake becomes a comment naming the widget and
+// the param, plus a WARN, instead of a blank fragment:
+//
+//	<!-- widget blog/recent: param "tag" is required -->
+//
+// Notes
+// -----
+// • The checked map, not the template's, is what the cache key and Render
+//   see, so {{ widget "w" }} and {{ widget "w" (dict "limit" 5) }} share a
+//   fragment when 5 is the default.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yanizio/adept/internal/logger"
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/widget"
+)
+
+// checkParams returns the params Render should get.  ok is false, with the
+// comment to print, when a ParamWidget's params do not validate.
+func checkParams(rctx *tenant.Context, key string, w widget.Widget, params map[string]any) (map[string]any, string, bool) {
+	pw, ok := w.(widget.ParamWidget)
+	if !ok {
+		return params, "", true
+	}
+	checked, err := widget.CheckParams(pw.Params(), params)
+	if err != nil {
+		ctx := context.Background()
+		if rctx != nil && rctx.Request != nil {
+			ctx = rctx.Request.Context()
+		}
+		logger.FromContext(ctx).Warn("widget params invalid", "widget", key, "err", err.Error())
+		msg := strings.ReplaceAll("widget "+key+": "+err.Error(), "--", "- -")
+		return nil, "<!-- " + msg + " -->", false
+	}
+	return checked, "", true
+}
@@ -0,0 +1,111 @@
+// internal/view/widgetsafe.go
+//
+// Panic recovery and render deadlines for widgets.
+//
+// Context
+// -------
+// A widget that panics, or loops forever, used to take the whole page down
+// with it.  safeRender wraps every Widget.Render call made by the view
+// engine (widgetFunc now, areaFunc once areas render widgets):
+//
+//   - A panic is recovered, logged with its stack through the request's
+//     logger, and turned into errWidgetPanic.
+//   - Render runs under a deadline: the widget's RenderTimeout() when it
+//     implements widget.TimeoutWidget, WidgetTimeout otherwise.  On overrun
+//     the page gets <!-- widget timeout --> and moves on.
+//
+// Notes
+// -----
+// • The deadline is on the context of GetRequest(), so a widget doing I/O
+//   should pass r.Context() down and stop early.  Go cannot kill a
+//   goroutine: a widget that ignores the context keeps running after the
+//   page is sent, and its result is discarded.
+// • A zero or negative timeout runs Render inline, with recovery only.
+// • Oxford commas, two spaces after periods.
+
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/yanizio/adept/internal/logger"
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/widget"
+)
+
+// WidgetTimeout bounds one widget render unless the widget sets its own.
+var WidgetTimeout = 2 * time.Second
+
+var (
+	errWidgetPanic   = errors.New("widget panicked")
+	errWidgetTimeout = errors.New("widget timed out")
+)
+
+// widgetTimeout returns the render deadline for w.
+func widgetTimeout(w widget.Widget) time.Duration {
+	if tw, ok := w.(widget.TimeoutWidget); ok {
+		return tw.RenderTimeout()
+	}
+	return WidgetTimeout
+}
+
+type renderResult struct {
+	html   string
+	policy int
+	err    error
+}
+
+// safeRender calls w.Render with panic recovery and a deadline.
+func safeRender(rctx *tenant.Context, key string, w widget.Widget, params map[string]any) (string, int, error) {
+	d := widgetTimeout(w)
+	if d <= 0 || rctx == nil || rctx.Request == nil {
+		res := recoverRender(rctx, key, w, params)
+		return res.html, res.policy, res.err
+	}
+
+	ctx, cancel := context.WithTimeout(rctx.Request.Context(), d)
+	defer cancel()
+	bound := *rctx
+	bound.Request = rctx.Request.WithContext(ctx)
+
+	done := make(chan renderResult, 1) // buffered: a late widget never blocks
+	go func() { done <- recoverRender(&bound, key, w, params) }()
+
+	select {
+	case res := <-done:
+		return res.html, res.policy, res.err
+	case <-ctx.Done():
+		logger.FromContext(ctx).Warn("widget render timed out",
+			"widget", key, "timeout", d.String())
+		return "", 0, errWidgetTimeout
+	}
+}
+
+// recoverRender runs w.Render and converts a panic into errWidgetPanic.
+func recoverRender(rctx *tenant.Context, key string, w widget.Widget, params map[string]any) (res renderResult) {
+	defer func() {
+		if v := recover(); v != nil {
+			ctx := context.Background()
+			if rctx != nil && rctx.Request != nil {
+				ctx = rctx.Request.Context()
+			}
+			logger.FromContext(ctx).Error("widget panic",
+				"widget", key, "panic", fmt.Sprint(v), "stack", string(debug.Stack()))
+			res = renderResult{err: errWidgetPanic}
+		}
+	}()
+	html, policy, err := w.Render(rctx, params)
+	return renderResult{html, policy, err}
+}
+
+// widgetComment is what the page shows in place of a failed widget.
+func widgetComment(err error) string {
+	if errors.Is(err, errWidgetTimeout) {
+		return "<!-- widget timeout -->"
+	}
+	return "<!-- widget error -->"
+}
@@ -0,0 +1,85 @@
+// internal/view/widgetsafe_test.go
+//
+// Unit-tests for widget panic recovery, render deadlines, and declared
+// params.
+
+package view
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanizio/adept/internal/tenant"
+	"github.com/yanizio/adept/internal/widget"
+)
+
+type panicWidget struct{}
+
+func (panicWidget) ID() string { return "test/panic" }
+func (panicWidget) Render(widget.Context, map[string]any) (string, int, error) {
+	panic("boom")
+}
+
+// slowWidget blocks until its request context is done.
+type slowWidget struct{ timeout time.Duration }
+
+func (slowWidget) ID() string                     { return "test/slow" }
+func (s slowWidget) RenderTimeout() time.Duration { return s.timeout }
+func (slowWidget) Render(ctx widget.Context, _ map[string]any) (string, int, error) {
+	<-ctx.GetRequest().Context().Done()
+	return "<p>late</p>", int(CacheSkip), nil
+}
+
+func TestWidgetFunc_PanicAndTimeout(t *testing.T) {
+	widget.Register(panicWidget{})
+	widget.Register(slowWidget{timeout: 20 * time.Millisecond})
+
+	req := httptest.NewRequest(http.MethodGet, "http://safe.example/", nil)
+	render := widgetFunc(tenant.NewContext(req))
+
+	if got := render("test/panic", nil); got != "<!-- widget error -->" {
+		t.Fatalf("panicking widget = %q", got)
+	}
+
+	start := time.Now()
+	if got := render("test/slow", nil); got != "<!-- widget timeout -->" {
+		t.Fatalf("slow widget = %q", got)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("timeout took %v", took)
+	}
+}
+
+// paramWidget echoes its checked params.
+type paramWidget struct{}
+
+func (paramWidget) ID() string { return "test/params" }
+func (paramWidget) Params() []widget.ParamSpec {
+	return []widget.ParamSpec{
+		{Name: "tag", Type: widget.ParamString, Required: true},
+		{Name: "limit", Type: widget.ParamInt, Default: 5},
+	}
+}
+func (paramWidget) Render(_ widget.Context, p map[string]any) (string, int, error) {
+	return fmt.Sprintf("<p>%v/%v</p>", p["tag"], p["limit"]), int(CacheSkip), nil
+}
+
+func TestWidgetFunc_CheckedParams(t *testing.T) {
+	widget.Register(paramWidget{})
+	req := httptest.NewRequest(http.MethodGet, "http://params.example/", nil)
+	render := widgetFunc(tenant.NewContext(req))
+
+	if got := render("test/params", nil); got != `<!-- widget test/params: param "tag" is required -->` {
+		t.Fatalf("missing required = %q", got)
+	}
+	if got := render("test/params", map[string]any{"tag": "go"}); got != "<p>go/5</p>" {
+		t.Fatalf("default fill = %q", got)
+	}
+	if got := render("test/params", map[string]any{"tag": "go", "limit": "x"}); !strings.Contains(string(got), `param "limit" must be int`) {
+		t.Fatalf("mistyped = %q", got)
+	}
+}
@@ -0,0 +1,165 @@
+// internal/widget/params.go
+//
+// Declared, typed widget parameters.
+//
+// Context
+// -------
+// Render takes map[string]any, so {{ widget "blog/recent" (dict "limt" 5) }}
+// used to render with limit silently zero.  A widget that implements
+// ParamWidget declares its parameters; the view engine runs CheckParams
+// before Render and shows a descriptive comment instead of calling it:
+//
+//	func (RecentWidget) Params() []widget.ParamSpec {
+//	    return []widget.ParamSpec{
+//	        {Name: "limit", Type: widget.ParamInt, Default: 5},
+//	        {Name: "tag", Type: widget.ParamString, Required: true},
+//	    }
+//	}
+//
+// Rules
+// -----
+//   - Missing required param                → error.
+//   - Missing optional param with Default   → Default is filled in.
+//   - Present but wrong type                → error.
+//   - Key not declared (usually a typo)     → error.
+//
+// Numbers are normalised: any integer kind becomes int for ParamInt, and
+// any integer or float kind becomes float64 for ParamFloat, so Render can
+// type-assert without guessing what the template produced.
+//
+// Notes
+// -----
+// • Widgets that do not implement ParamWidget get their params unchecked,
+//   exactly as before.
+// • Oxford commas, two spaces after periods.
+
+package widget
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ParamType is the expected Go shape of a widget parameter.
+type ParamType int
+
+const (
+	ParamAny    ParamType = iota // any value, unchecked
+	ParamString                  // string
+	ParamInt                     // any integer kind, passed on as int
+	ParamFloat                   // any number, passed on as float64
+	ParamBool                    // bool
+)
+
+// String names the type in error messages.
+func (t ParamType) String() string {
+	switch t {
+	case ParamString:
+		return "string"
+	case ParamInt:
+		return "int"
+	case ParamFloat:
+		return "float"
+	case ParamBool:
+		return "bool"
+	}
+	return "any"
+}
+
+// ParamSpec declares one parameter.  Default, when set, must already have
+// the normalised type (int for ParamInt, float64 for ParamFloat).
+type ParamSpec struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	Default  any
+}
+
+// ParamWidget is optional.  A widget that implements it has its params
+// checked and defaulted by CheckParams before every Render.
+type ParamWidget interface {
+	Params() []ParamSpec
+}
+
+// ParamError describes the first problem CheckParams found.
+type ParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("param %q %s", e.Param, e.Reason)
+}
+
+// CheckParams validates params against specs and returns a new map with
+// defaults filled in and numbers normalised.  params itself is never
+// modified.
+func CheckParams(specs []ParamSpec, params map[string]any) (map[string]any, error) {
+	known := make(map[string]bool, len(specs))
+	out := make(map[string]any, len(specs))
+	for _, s := range specs {
+		known[s.Name] = true
+		v, ok := params[s.Name]
+		if !ok || v == nil {
+			switch {
+			case s.Required:
+				return nil, &ParamError{s.Name, "is required"}
+			case s.Default != nil:
+				out[s.Name] = s.Default
+			}
+			continue
+		}
+		nv, ok := coerce(s.Type, v)
+		if !ok {
+			return nil, &ParamError{s.Name,
+				fmt.Sprintf("must be %s, got %T", s.Type, v)}
+		}
+		out[s.Name] = nv
+	}
+
+	// Sorted so the reported key is stable across renders.
+	var unknown []string
+	for k := range params {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &ParamError{unknown[0], "is not declared"}
+	}
+	return out, nil
+}
+
+// coerce checks v against t and returns it in normalised form.
+func coerce(t ParamType, v any) (any, bool) {
+	rv := reflect.ValueOf(v)
+	switch t {
+	case ParamString:
+		s, ok := v.(string)
+		return s, ok
+	case ParamBool:
+		b, ok := v.(bool)
+		return b, ok
+	case ParamInt:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return int(rv.Int()), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int(rv.Uint()), true
+		}
+		return nil, false
+	case ParamFloat:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return rv.Float(), true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()), true
+		}
+		return nil, false
+	}
+	return v, true
+}
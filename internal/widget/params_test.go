@@ -0,0 +1,62 @@
+// internal/widget/params_test.go
+//
+// Unit-tests for CheckParams.
+
+package widget
+
+import (
+	"errors"
+	"testing"
+)
+
+var recentSpecs = []ParamSpec{
+	{Name: "tag", Type: ParamString, Required: true},
+	{Name: "limit", Type: ParamInt, Default: 5},
+	{Name: "ratio", Type: ParamFloat},
+	{Name: "extra", Type: ParamAny},
+}
+
+func TestCheckParams_MissingRequired(t *testing.T) {
+	for _, params := range []map[string]any{nil, {"limit": 3}, {"tag": nil}} {
+		_, err := CheckParams(recentSpecs, params)
+		var pe *ParamError
+		if !errors.As(err, &pe) || pe.Param != "tag" || err.Error() != `param "tag" is required` {
+			t.Fatalf("CheckParams(%v) err = %v", params, err)
+		}
+	}
+}
+
+func TestCheckParams_FillsDefaultsAndNormalises(t *testing.T) {
+	in := map[string]any{"tag": "go", "ratio": int64(2)}
+	got, err := CheckParams(recentSpecs, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["limit"] != 5 || got["ratio"] != 2.0 || got["tag"] != "go" {
+		t.Fatalf("checked = %#v", got)
+	}
+	if _, ok := got["extra"]; ok {
+		t.Fatal("optional param without default was filled")
+	}
+	if _, ok := in["limit"]; ok {
+		t.Fatal("input map modified")
+	}
+
+	got, err = CheckParams(recentSpecs, map[string]any{"tag": "go", "limit": uint8(9)})
+	if err != nil || got["limit"] != 9 {
+		t.Fatalf("uint8 limit = %#v, %v", got["limit"], err)
+	}
+}
+
+func TestCheckParams_WrongTypeAndUnknown(t *testing.T) {
+	cases := map[string]map[string]any{
+		`param "limit" must be int, got string`: {"tag": "go", "limit": "5"},
+		`param "tag" must be string, got int`:   {"tag": 7},
+		`param "limt" is not declared`:          {"tag": "go", "limt": 5},
+	}
+	for want, params := range cases {
+		if _, err := CheckParams(recentSpecs, params); err == nil || err.Error() != want {
+			t.Errorf("CheckParams(%v) err = %v, want %s", params, err, want)
+		}
+	}
+}
@@ -14,14 +14,34 @@
 //
 //	{{ widget "auth/login" (dict "limit" 5) }}
 //
-// Params are optional.  The helper looks up the widget, invokes
+// Params are optional.  The helper looks up the widget, checks params
+// against its ParamSpecs when it declares them (params.go), invokes
 // `Render`, and returns `template.HTML`.
 package widget
 
 import (
+	"net/http"
 	"sync"
+	"time"
+
+	"github.com/yanizio/adept/internal/head"
 )
 
+// Context is the typed render context handed to every widget.  The concrete
+// *tenant.Context satisfies it; the interface lives here so widget packages
+// never import tenant and no widget needs an unchecked type assertion.
+//
+//   - GetRequest – the original *http.Request (read-only).
+//   - GetConfig  – tenant site_config map; nil outside a tenant request.
+//   - GetHead    – per-request <head> builder.
+//   - GetLocale  – tenant locale such as "en_US".
+type Context interface {
+	GetRequest() *http.Request
+	GetConfig() map[string]string
+	GetHead() *head.Builder
+	GetLocale() string
+}
+
 // Widget represents a view fragment that can be embedded inside any page
 // template.  Render returns the generated HTML and a cache policy hint.
 // Params are an arbitrary key‑value map passed from the template.
@@ -39,10 +59,53 @@ import (
 //
 // Render MUST be concurrency‑safe; multiple goroutines may call it.
 type Widget interface {
+	ID() string
+	Render(ctx Context, params map[string]any) (html string, policy int, err error)
+}
+
+// LegacyWidget is the pre-Context signature.
+//
+// Deprecated: implement Widget with a typed Context instead.  RegisterLegacy
+// keeps out-of-tree widgets working until they migrate.
+type LegacyWidget interface {
 	ID() string
 	Render(rctx any, params map[string]any) (html string, policy int, err error)
 }
 
+// Cache policy values shared with internal/view (view.CacheDefault and
+// view.CacheSkip).
+const (
+	policyDefault = 0
+	policySkip    = 1
+)
+
+// legacyAdapter lets a LegacyWidget satisfy Widget.  Legacy widgets predate
+// the fragment cache, when the zero policy meant "not cached", so their
+// CacheDefault is served as CacheSkip; CacheForce still opts in.
+type legacyAdapter struct{ LegacyWidget }
+
+func (a legacyAdapter) Render(ctx Context, params map[string]any) (string, int, error) {
+	html, policy, err := a.LegacyWidget.Render(ctx, params)
+	if policy == policyDefault {
+		policy = policySkip
+	}
+	return html, policy, err
+}
+
+// TTLWidget is optional.  A widget that implements it overrides the default
+// lifetime the view engine uses when caching its rendered fragment.  A zero
+// or negative duration disables caching for that widget.
+type TTLWidget interface {
+	CacheTTL() time.Duration
+}
+
+// TimeoutWidget is optional.  RenderTimeout overrides the view engine's
+// default render deadline (view.WidgetTimeout); zero or negative disables
+// the deadline for that widget.  The deadline is on GetRequest().Context().
+type TimeoutWidget interface {
+	RenderTimeout() time.Duration
+}
+
 var (
 	mu       sync.RWMutex
 	registry = map[string]Widget{}
@@ -56,6 +119,12 @@ func Register(w Widget) {
 	mu.Unlock()
 }
 
+// RegisterLegacy wraps a widget that still takes `rctx any`.  The value it
+// receives is the same concrete context a typed widget would get.
+//
+// Deprecated: migrate to Widget and call Register.
+func RegisterLegacy(w LegacyWidget) { Register(legacyAdapter{w}) }
+
 // Lookup returns the widget or nil.
 func Lookup(key string) Widget {
 	mu.RLock()
@@ -7,6 +7,8 @@ CREATE TABLE `site` (
   `routing_mode`  VARCHAR(6)    NOT NULL DEFAULT 'path',
   `route_version` INT           NOT NULL DEFAULT 0,
   `preload`       TINYINT(1)    NOT NULL DEFAULT 0,
+  `db_host`       VARCHAR(256)  NULL,              -- tenant DB host:port override
+  `db_replica_host` VARCHAR(256) NULL,             -- optional read-replica host:port
   `suspended_at`  TIMESTAMP NULL,
   `deleted_at`    TIMESTAMP NULL,
   `created_at`    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -27,6 +29,22 @@ SELECT
     END AS status
 FROM site;
 
+-- Vanity domains: alias_host serves (redirect = 0) or 301s to (redirect = 1)
+-- its site.  Wildcard coverage lives in site.host itself ("*.app.example.com").
+CREATE TABLE `site_host_alias` (
+  `alias_host`  VARCHAR(256) NOT NULL,
+  `site_id`     BIGINT       NOT NULL,
+  `redirect`    TINYINT(1)   NOT NULL DEFAULT 0,
+  `created_at`  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`alias_host`),
+  KEY `idx_site_host_alias_site` (`site_id`),
+   CONSTRAINT fk_site_host_alias_site
+      FOREIGN KEY (site_id)
+      REFERENCES site(id)
+      ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE  = utf8mb4_unicode_ci;
+
 CREATE TABLE `site_config` (
   `site_id`  BIGINT NOT NULL,
   `key`      VARCHAR(64)  NOT NULL,
@@ -109,3 +127,13 @@ CREATE TABLE `user_profile` (
   `updated_at`       TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Email dedup marks (internal/message/dedup.go).  One row per email that
+-- went out with a DedupKey; a retry finding an unexpired row is skipped.
+-- Expired rows are deleted by the application at most once a minute.
+CREATE TABLE IF NOT EXISTS message_dedup (
+  dedup_key          VARCHAR(191) NOT NULL,
+  expires_at         TIMESTAMP    NOT NULL,
+  PRIMARY KEY (dedup_key),
+  KEY idx_message_dedup_expires (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -1,6 +1,7 @@
 CREATE TABLE component_acl (
     component   VARCHAR(64)  PRIMARY KEY,                   -- 'content', 'shop'
     enabled     BOOL         NOT NULL DEFAULT TRUE,
+    mount_prefix VARCHAR(64) NULL,                          -- overrides Prefix(); '/' = root
     updated_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
                            ON UPDATE CURRENT_TIMESTAMP
 );
@@ -9,6 +10,9 @@ CREATE INDEX idx_component_acl_enabled ON component_acl (enabled);
 CREATE TABLE route_alias (
     alias_path   VARCHAR(255) PRIMARY KEY,
     target_path  VARCHAR(255) NOT NULL,
+    kind         ENUM('rewrite','redirect_permanent','redirect_temporary')
+                              NOT NULL DEFAULT 'rewrite',
+    canonical    BOOL         NOT NULL DEFAULT FALSE,       -- preferred alias of its target
     created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
                            ON UPDATE CURRENT_TIMESTAMP
@@ -82,3 +86,97 @@ COMMENT ON TABLE  form_submission IS
     'Archive of generic form submissions generated by internal/form store action.';
 COMMENT ON COLUMN form_submission.data IS
     'Sanitized key/value pairs of the submitted form.';
+
+
+-- Adept – forms: submission audit metadata.
+--
+-- Context
+--   Written by the store action only for forms with `audit: true`
+--   (internal/form/audit.go).  One row per stored submission, keyed by the
+--   table the submission went to and its id.  ip is personal data.
+--
+-- Retention
+--   Purge rows past the tenant's retention window (form.AuditRetention,
+--   90 days, is the suggested default) with form.PurgeAudit; the
+--   submissions themselves are untouched.
+--
+
+CREATE TABLE IF NOT EXISTS form_submission_meta (
+    id               BIGINT PRIMARY KEY AUTO_INCREMENT,
+    submission_table VARCHAR(64)  NOT NULL,
+    submission_id    BIGINT       NOT NULL,
+    ip               VARCHAR(45)  NULL,       -- IPv4 or IPv6 text form
+    ua_family        VARCHAR(64)  NOT NULL DEFAULT '',
+    submitted_at     TIMESTAMP    NOT NULL DEFAULT NOW(),
+    spam_score       DOUBLE       NULL,       -- NULL when the form is not scored
+    UNIQUE KEY form_submission_meta_row (submission_table, submission_id)
+);
+
+CREATE INDEX form_submission_meta_submitted_at_idx
+    ON form_submission_meta (submitted_at);
+
+
+-- Adept – forms: spam quarantine.
+--
+-- Context
+--   Submissions whose spam score reaches the form's spam_threshold land
+--   here instead of running any action (internal/form/spam.go).  Same
+--   shape as form_submission plus the score, so a false positive can be
+--   copied across and replayed by hand.
+--
+
+CREATE TABLE IF NOT EXISTS form_quarantine (
+    id           BIGINT PRIMARY KEY AUTO_INCREMENT,
+    form_id      TEXT        NOT NULL,
+    submitted_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    data         JSON        NOT NULL,
+    spam_score   DOUBLE      NOT NULL
+);
+
+CREATE INDEX form_quarantine_submitted_at_idx
+    ON form_quarantine (submitted_at DESC);
+
+
+-- Adept – auth component: "remember me" refresh tokens.
+--
+-- Context
+--   Only the SHA-256 of each token is stored.  Tokens are single use: a
+--   redeemed row gets used_at and a successor in the same family.  Replaying
+--   a used token revokes the whole family.
+--
+
+CREATE TABLE IF NOT EXISTS auth_refresh_token (
+    token_hash  CHAR(64)     PRIMARY KEY,
+    family      VARCHAR(64)  NOT NULL,
+    email       VARCHAR(254) NOT NULL,
+    expires_at  TIMESTAMP    NOT NULL,
+    used_at     TIMESTAMP    NULL,
+    revoked_at  TIMESTAMP    NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_auth_refresh_family (family),
+    INDEX idx_auth_refresh_email (email)
+);
+
+-- Applied Component migrations (internal/migrate creates this on demand too).
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    component   VARCHAR(64)  NOT NULL,
+    version     INT          NOT NULL,
+    checksum    CHAR(64)     NOT NULL,
+    applied_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (component, version)
+);
+
+-- Adept – generic key-value store (internal/kv).
+--
+-- Context
+--   Small per-tenant Component state.  Keys are namespaced by Component,
+--   e.g. "shop:cart_seq".  expires_at NULL means no expiry; expired rows
+--   are filtered on read and purged lazily.
+--
+
+CREATE TABLE IF NOT EXISTS kv_store (
+    kv_key      VARCHAR(191) PRIMARY KEY,
+    kv_value    MEDIUMBLOB   NOT NULL,
+    expires_at  TIMESTAMP(6) NULL,
+    INDEX idx_kv_store_expires (expires_at)
+);